@@ -6,20 +6,31 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	pb "github.com/franciscozamorau/osmi-protobuf/gen/pb"
+	"github.com/franciscozamorau/osmi-server/internal/api/grpc/interceptors"
+	"github.com/franciscozamorau/osmi-server/internal/api/health"
 	handlersgrpc "github.com/franciscozamorau/osmi-server/internal/application/handlers/grpc"
 	"github.com/franciscozamorau/osmi-server/internal/application/services"
 	"github.com/franciscozamorau/osmi-server/internal/config"
 	"github.com/franciscozamorau/osmi-server/internal/database"
 	"github.com/franciscozamorau/osmi-server/internal/infrastructure/cache"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/httpclient"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/messaging"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/metrics"
 	"github.com/franciscozamorau/osmi-server/internal/infrastructure/payment"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/instrumented"
 	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/utils"
+	"github.com/franciscozamorau/osmi-server/internal/qrcode"
 	"github.com/franciscozamorau/osmi-server/internal/shared/security"
-	"github.com/joho/godotenv"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -28,14 +39,25 @@ func main() {
 	log.Println("🚀 OSMI Server - ARQUITECTURA COMPLETA")
 	log.Println("=======================================")
 
-	cfg := config.Load()
-	_ = godotenv.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Invalid configuration: %v", err)
+	}
 
-	if err := database.Init(); err != nil {
+	if err := database.Init(cfg.Database); err != nil {
 		log.Fatalf("❌ Failed to initialize database pool: %v", err)
 	}
 	defer database.Close()
 
+	if cfg.Database.RunMigrations {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		err := database.Migrate(ctx)
+		cancel()
+		if err != nil {
+			log.Fatalf("❌ Failed to run migrations: %v", err)
+		}
+	}
+
 	// ================================================
 	// REPOSITORIOS
 	// ================================================
@@ -51,6 +73,72 @@ func main() {
 	orderRepo := postgres.NewOrderRepository(database.Pool)
 	paymentRepo := postgres.NewPaymentRepository(database.Pool)
 
+	ticketTypeRepo.SetSweepBatchSize(cfg.Tickets.SweepBatchSize)
+
+	customerRepo.SetMaxPage(cfg.Pagination.MaxPage)
+	eventRepo.SetMaxPage(cfg.Pagination.MaxPage)
+	ticketTypeRepo.SetMaxPage(cfg.Pagination.MaxPage)
+	organizerRepo.SetMaxPage(cfg.Pagination.MaxPage)
+	venueRepo.SetMaxPage(cfg.Pagination.MaxPage)
+	orderRepo.SetMaxPage(cfg.Pagination.MaxPage)
+
+	if database.Replica != nil {
+		eventRepo.EnableReadReplica(database.Replica)
+	}
+
+	if cfg.Features.BufferedShareCount {
+		eventRepo.EnableBufferedShareCount(cfg.Features.ShareCountFlushInterval)
+		log.Printf("✅ Share count buffer activo (flush cada %s)", cfg.Features.ShareCountFlushInterval)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := eventRepo.Close(ctx); err != nil {
+			log.Printf("⚠️ Error flushing share counts: %v", err)
+		}
+	}()
+
+	reservationSweeper := services.NewReservationSweeper(ticketTypeRepo, cfg.Tickets.SweepInterval, utils.GlobalLogger)
+	reservationSweeper.Start()
+	log.Printf("✅ Barredor de reservas activo (cada %s)", cfg.Tickets.SweepInterval)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := reservationSweeper.Stop(ctx); err != nil {
+			log.Printf("⚠️ Error deteniendo barredor de reservas: %v", err)
+		}
+	}()
+
+	if cfg.Notifications.ProviderURL != "" {
+		notificationSender := services.NewNotificationSender(
+			postgres.NewNotificationOutboxRepository(database.Pool),
+			messaging.NewHTTPNotificationProvider(httpclient.New(cfg.HTTPClient), cfg.Notifications.ProviderURL, cfg.Notifications.ProviderAPIKey),
+			cfg.Notifications.Interval,
+			cfg.Notifications.BatchSize,
+			cfg.Notifications.SendingTimeout,
+			utils.GlobalLogger,
+		)
+		notificationSender.Start()
+		log.Printf("✅ Envío de notificaciones activo (cada %s)", cfg.Notifications.Interval)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+			defer cancel()
+			if err := notificationSender.Stop(ctx); err != nil {
+				log.Printf("⚠️ Error deteniendo envío de notificaciones: %v", err)
+			}
+		}()
+	} else {
+		log.Println("⚠️ Envío de notificaciones desactivado (NOTIFICATIONS_PROVIDER_URL vacío)")
+	}
+
+	// Los servicios usan los repositorios instrumentados para atribuir la carga
+	// de la base de datos por repositorio y método
+	repoMetrics := metrics.NewRegistry()
+	eventRepository := instrumented.NewEventRepository(eventRepo, repoMetrics)
+	customerRepository := instrumented.NewCustomerRepository(customerRepo, repoMetrics)
+	eventRepository.SetLogger(utils.GlobalLogger)
+	customerRepository.SetLogger(utils.GlobalLogger)
+
 	// ================================================
 	// SERVICIOS DE SEGURIDAD
 	// ================================================
@@ -75,32 +163,49 @@ func main() {
 		log.Println("✅ Redis connected")
 	}
 
-	customerService := services.NewCustomerService(customerRepo)
+	customerService := services.NewCustomerService(customerRepository)
 	ticketService := services.NewTicketService(
 		ticketRepo,
 		ticketTypeRepo,
-		eventRepo,
-		customerRepo,
+		eventRepository,
+		customerRepository,
 		nil,
+		cfg.Tickets.MaxPerTransaction,
 	)
-	ticketTypeService := services.NewTicketTypeService(ticketTypeRepo, eventRepo)
+	ticketService.SetCodeRetry(cfg.Tickets.CodeMaxAttempts, cfg.Tickets.CodeRetryBackoff)
+	if err := ticketService.SetCodePrefix(cfg.Tickets.CodePrefix); err != nil {
+		log.Fatalf("❌ Invalid TICKET_CODE_PREFIX: %v", err)
+	}
+	if err := ticketService.SetCheckInWindow(cfg.Tickets.CheckInEarlyEntry, cfg.Tickets.CheckInGrace); err != nil {
+		log.Fatalf("❌ Invalid check-in window: %v", err)
+	}
+	if cfg.QRCode.Secret != "" {
+		qrGenerator, err := newQRGenerator(cfg.QRCode, httpclient.New(cfg.HTTPClient))
+		if err != nil {
+			log.Fatalf("❌ Error configurando QR: %v", err)
+		}
+		ticketService.EnableQRCodes(qrGenerator)
+		log.Printf("✅ QR de tickets activo (storage %s)", cfg.QRCode.Storage)
+	}
+	ticketTypeService := services.NewTicketTypeService(ticketTypeRepo, eventRepository)
 	eventService := services.NewEventService(
-		eventRepo,
+		eventRepository,
 		organizerRepo,
 		venueRepo,
 		categoryRepo,
 		ticketTypeRepo,
 	)
+	eventService.SetEventTypeAllowlist(cfg.Events.TypeAllowlist)
 	userService := services.NewUserService(
 		userRepo,
-		customerRepo,
+		customerRepository,
 		nil,
 		hasher,
 		jwtService,
 		redisClient,
 	)
-	categoryService := services.NewCategoryService(categoryRepo, eventRepo)
-	orderService := services.NewOrderService(orderRepo, customerRepo, ticketTypeRepo, ticketRepo)
+	categoryService := services.NewCategoryService(categoryRepo, eventRepository)
+	orderService := services.NewOrderService(orderRepo, customerRepository, ticketTypeRepo, ticketRepo)
 
 	// Servicio de pagos con Stripe
 	stripeClient := payment.NewStripeClient(cfg.Stripe.SecretKey)
@@ -142,44 +247,108 @@ func main() {
 
 	log.Println("✅ Handler unificado creado")
 
+	// Métricas en /metrics del health server
+	rpcMetrics := metrics.NewRPCRegistry()
+	metricsHandler := metrics.Handler(rpcMetrics, repoMetrics, poolStats)
+
+	// Las llamadas con bearer token llevan el usuario y su rol en el contexto
+	auth := interceptors.UnaryAuth(jwtService, func(ctx context.Context, userPublicID string) (string, error) {
+		user, err := userService.GetUserByPublicID(ctx, userPublicID)
+		if err != nil {
+			return "", err
+		}
+		return user.GetRole(), nil
+	})
+
 	// Iniciar servidor gRPC
-	startServer(handler, cfg.GRPCPort)
+	startServer(handler, cfg.Server, auth, rpcMetrics, metricsHandler)
 }
 
-func startServer(handler *handlersgrpc.Handler, port string) {
-	address := ":" + port
-	server := grpc.NewServer()
+// poolStats lee las estadísticas del pool; devuelve false si la base de datos
+// todavía no está inicializada
+func poolStats() (metrics.PoolStats, bool) {
+	stat := database.GetStats()
+	if stat == nil {
+		return metrics.PoolStats{}, false
+	}
+	return metrics.PoolStats{
+		Total: stat.TotalConns(),
+		Idle:  stat.IdleConns(),
+		Max:   stat.MaxConns(),
+	}, true
+}
+
+func startServer(handler *handlersgrpc.Handler, cfg config.ServerConfig, auth grpc.UnaryServerInterceptor, rpcMetrics *metrics.RPCRegistry, metricsHandler http.Handler) {
+	address := cfg.GRPCAddress
+	chain := []grpc.UnaryServerInterceptor{
+		interceptors.UnaryRequestID(),
+		interceptors.UnaryMetrics(rpcMetrics),
+		interceptors.UnaryLogging(utils.GlobalLogger),
+		auth,
+	}
+	// Después de logging y métricas para que los rechazos queden registrados
+	if cfg.RateLimitRPS > 0 {
+		chain = append(chain, interceptors.UnaryRateLimit(interceptors.NewTokenBucket(cfg.RateLimitRPS, cfg.RateLimitBurst)))
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(chain...))
 
 	pb.RegisterOsmiServiceServer(server, handler)
 	reflection.Register(server)
 
-	go func() {
-		http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-			defer cancel()
-
-			if err := database.Pool.Ping(ctx); err != nil {
-				w.WriteHeader(http.StatusServiceUnavailable)
-				w.Write([]byte(`{"status":"unhealthy"}`))
-				return
-			}
-
-			w.Header().Set("Content-Type", "application/json")
-			w.Write([]byte(`{"status":"healthy","service":"osmi-server"}`))
-		})
-
-		log.Printf("Health check en :%s/health", "8081")
-		http.ListenAndServe(":8081", nil)
-	}()
+	var serving atomic.Bool
+	serving.Store(true)
+	healthServer, err := health.Start(cfg.HealthAddress, health.NewHandler(&serving, database.Pool.Ping, metricsHandler))
+	if err != nil {
+		log.Fatalf("❌ Error iniciando health server: %v", err)
+	}
 
 	lis, err := net.Listen("tcp", address)
 	if err != nil {
 		log.Fatalf("❌ Error escuchando: %v", err)
 	}
 
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		<-sigCh
+
+		// Primero NOT_SERVING para que el balanceador deje de enviar tráfico
+		serving.Store(false)
+
+		log.Println("🛑 Apagando health server...")
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		if err := healthServer.Shutdown(ctx); err != nil {
+			log.Printf("⚠️ Error apagando health server: %v", err)
+		}
+		cancel()
+
+		log.Println("🛑 Apagando servidor gRPC...")
+		server.GracefulStop()
+	}()
+
 	log.Printf("🚀gRPC server en %s", address)
 
 	if err := server.Serve(lis); err != nil {
 		log.Fatalf("❌ Error sirviendo: %v", err)
 	}
 }
+
+// newQRGenerator arma el generador de QR con el storage configurado
+func newQRGenerator(cfg config.QRCodeConfig, client *http.Client) (*qrcode.Generator, error) {
+	var storage qrcode.Storage
+	switch cfg.Storage {
+	case "s3":
+		storage = &qrcode.S3Storage{
+			Bucket:        cfg.S3Bucket,
+			Region:        cfg.S3Region,
+			Endpoint:      cfg.S3Endpoint,
+			AccessKey:     cfg.S3AccessKey,
+			SecretKey:     cfg.S3SecretKey,
+			PublicBaseURL: cfg.S3PublicURL,
+			Client:        client,
+		}
+	default:
+		storage = qrcode.NewLocalStorage(cfg.LocalDir, cfg.BaseURL)
+	}
+	return qrcode.NewGenerator(cfg.Secret, storage, cfg.Size)
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/franciscozamorau/osmi-server/internal/config"
 	"github.com/franciscozamorau/osmi-server/internal/database"
 )
 
@@ -19,7 +20,12 @@ func main() {
 	log.Println("======================================")
 	log.Printf("⏱️ Intervalo de ejecución: %s", workerInterval)
 
-	if err := database.Init(); err != nil {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Invalid configuration: %v", err)
+	}
+
+	if err := database.Init(cfg.Database); err != nil {
 		log.Fatalf("❌ Failed to initialize database connection: %v", err)
 	}
 	defer database.Close()
@@ -1,6 +1,20 @@
 // internal/api/dto/common/pagination.go
 package common
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrValidation indica parámetros de consulta inválidos
+var ErrValidation = errors.New("validation error")
+
+// DefaultMaxPage es la página más profunda permitida con paginación por offset
+const DefaultMaxPage = 1000
+
+// DefaultPageSize es el tamaño de página usado cuando no se indica uno válido
+const DefaultPageSize = 20
+
 // Pagination define la paginación estándar
 type Pagination struct {
 	Page     int `json:"page" form:"page" query:"page"`
@@ -13,7 +27,7 @@ func NewPagination(page, pageSize int) Pagination {
 		page = 1
 	}
 	if pageSize < 1 {
-		pageSize = 20
+		pageSize = DefaultPageSize
 	}
 	if pageSize > 100 {
 		pageSize = 100
@@ -25,6 +39,39 @@ func NewPagination(page, pageSize int) Pagination {
 	}
 }
 
+// Normalize aplica los valores por defecto y rechaza páginas más profundas que
+// maxPage, que obligarían a Postgres a recorrer y descartar demasiadas filas con
+// OFFSET. maxPage <= 0 usa DefaultMaxPage.
+func (p Pagination) Normalize(maxPage int) (Pagination, error) {
+	if maxPage <= 0 {
+		maxPage = DefaultMaxPage
+	}
+	normalized := NewPagination(p.Page, p.PageSize)
+	if normalized.Page > maxPage {
+		return normalized, fmt.Errorf("%w: page %d exceeds maximum of %d, use cursor pagination", ErrValidation, normalized.Page, maxPage)
+	}
+	return normalized, nil
+}
+
+// NormalizeOffset aplica el mismo límite a consultas que reciben limit/offset
+// directamente. Un limit <= 0 pasa a DefaultPageSize y un offset negativo a 0
+// antes de calcular la profundidad, para que no sirvan para saltarse el límite.
+func NormalizeOffset(limit, offset, maxPage int) (int, int, error) {
+	if maxPage <= 0 {
+		maxPage = DefaultMaxPage
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if page := offset/limit + 1; page > maxPage {
+		return limit, offset, fmt.Errorf("%w: offset %d exceeds maximum page %d, use cursor pagination", ErrValidation, offset, maxPage)
+	}
+	return limit, offset, nil
+}
+
 // Offset calcula el offset para consultas SQL
 func (p Pagination) Offset() int {
 	return (p.Page - 1) * p.PageSize
@@ -0,0 +1,55 @@
+package common
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPaginationNormalizeMaxPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       Pagination
+		maxPage  int
+		wantPage int
+		wantErr  bool
+	}{
+		{"defaults", Pagination{}, 50, 1, false},
+		{"deep but bounded", Pagination{Page: 50, PageSize: 100}, 50, 50, false},
+		{"over limit", Pagination{Page: 51, PageSize: 20}, 50, 51, true},
+		{"unset max uses default", Pagination{Page: DefaultMaxPage + 1}, 0, DefaultMaxPage + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.in.Normalize(tt.maxPage)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrValidation) {
+				t.Errorf("error = %v, want ErrValidation", err)
+			}
+			if got.Page != tt.wantPage {
+				t.Errorf("Page = %d, want %d", got.Page, tt.wantPage)
+			}
+		})
+	}
+}
+
+func TestNormalizeOffset(t *testing.T) {
+	if _, _, err := NormalizeOffset(20, 180, 10); err != nil {
+		t.Errorf("offset on the last allowed page: %v", err)
+	}
+	if _, _, err := NormalizeOffset(20, 200, 10); !errors.Is(err, ErrValidation) {
+		t.Errorf("offset past the last page error = %v, want ErrValidation", err)
+	}
+
+	// Un limit no positivo no desactiva el límite de profundidad
+	for _, limit := range []int{0, -5} {
+		if _, _, err := NormalizeOffset(limit, 1_000_000, 10); !errors.Is(err, ErrValidation) {
+			t.Errorf("NormalizeOffset(%d, 1000000) error = %v, want ErrValidation", limit, err)
+		}
+	}
+	limit, offset, err := NormalizeOffset(0, -10, 10)
+	if err != nil || limit != DefaultPageSize || offset != 0 {
+		t.Errorf("NormalizeOffset(0, -10) = %d, %d, %v; want %d, 0, nil", limit, offset, err, DefaultPageSize)
+	}
+}
@@ -7,7 +7,7 @@ type EventFilter struct {
 	OrganizerID *string  `json:"organizer_id,omitempty" validate:"omitempty,uuid4"`
 	CategoryID  *string  `json:"category_id,omitempty" validate:"omitempty,uuid4"`
 	VenueID     *string  `json:"venue_id,omitempty" validate:"omitempty,uuid4"`
-	EventType   *string  `json:"event_type,omitempty" validate:"omitempty,oneof=in_person virtual hybrid"`
+	EventType   *string  `json:"event_type,omitempty" validate:"omitempty,max=50"`
 	Status      *string  `json:"status,omitempty"`
 	Country     *string  `json:"country,omitempty"`
 	City        *string  `json:"city,omitempty"`
@@ -9,7 +9,7 @@ type CreateEventRequest struct {
 	Slug                string   `json:"slug,omitempty" validate:"omitempty,slug"`
 	ShortDescription    string   `json:"short_description,omitempty" validate:"omitempty,max=500"`
 	Description         string   `json:"description" validate:"required,min=10"`
-	EventType           string   `json:"event_type" validate:"required,oneof=in_person virtual hybrid"`
+	EventType           string   `json:"event_type" validate:"required,max=50"`
 	CoverImageURL       string   `json:"cover_image_url,omitempty" validate:"omitempty,url"`
 	BannerImageURL      string   `json:"banner_image_url,omitempty" validate:"omitempty,url"`
 	Timezone            string   `json:"timezone" validate:"required"`
@@ -34,13 +34,14 @@ type CreateEventRequest struct {
 	AllowReservations   bool     `json:"allow_reservations,omitempty"`
 	ReservationDuration int      `json:"reservation_duration,omitempty" validate:"omitempty,min=1"`
 	CategoryIDs         []string `json:"category_ids,omitempty"`
+	TicketCodePrefix    string   `json:"ticket_code_prefix,omitempty" validate:"omitempty,alphanum,max=10"`
 }
 
 type UpdateEventRequest struct {
 	Name             *string  `json:"name,omitempty" validate:"omitempty,min=3,max=255"`
 	ShortDescription *string  `json:"short_description,omitempty" validate:"omitempty,max=500"`
 	Description      *string  `json:"description,omitempty" validate:"omitempty,min=10"`
-	EventType        *string  `json:"event_type,omitempty" validate:"omitempty,oneof=in_person virtual hybrid"`
+	EventType        *string  `json:"event_type,omitempty" validate:"omitempty,max=50"`
 	CoverImageURL    *string  `json:"cover_image_url,omitempty" validate:"omitempty,url"`
 	BannerImageURL   *string  `json:"banner_image_url,omitempty" validate:"omitempty,url"`
 	Timezone         *string  `json:"timezone,omitempty"`
@@ -54,6 +55,7 @@ type UpdateEventRequest struct {
 	MaxAttendees     *int     `json:"max_attendees,omitempty" validate:"omitempty,min=1"`
 	AgeRestriction   *int     `json:"age_restriction,omitempty" validate:"omitempty,min=0,max=120"`
 	Tags             []string `json:"tags,omitempty"`
+	TicketCodePrefix *string  `json:"ticket_code_prefix,omitempty" validate:"omitempty,alphanum,max=10"`
 }
 
 type PublishEventRequest struct {
@@ -4,44 +4,45 @@ package event
 import "time"
 
 type EventResponse struct {
-	ID               string           `json:"id"`
-	Organizer        OrganizerInfo    `json:"organizer"`
-	Venue            VenueInfo        `json:"venue,omitempty"`
-	PrimaryCategory  CategoryInfo     `json:"primary_category,omitempty"`
-	Categories       []CategoryInfo   `json:"categories"`
-	Name             string           `json:"name"`
-	Slug             string           `json:"slug"`
-	ShortDescription string           `json:"short_description,omitempty"`
-	Description      string           `json:"description"`
-	EventType        string           `json:"event_type"`
-	CoverImageURL    string           `json:"cover_image_url,omitempty"`
-	BannerImageURL   string           `json:"banner_image_url,omitempty"`
-	GalleryImages    []string         `json:"gallery_images"`
-	Timezone         string           `json:"timezone"`
-	StartsAt         time.Time        `json:"starts_at"`
-	EndsAt           time.Time        `json:"ends_at"`
-	DoorsOpenAt      time.Time        `json:"doors_open_at,omitempty"`
-	DoorsCloseAt     time.Time        `json:"doors_close_at,omitempty"`
-	VenueName        string           `json:"venue_name,omitempty"`
-	AddressFull      string           `json:"address_full,omitempty"`
-	City             string           `json:"city,omitempty"`
-	State            string           `json:"state,omitempty"`
-	Country          string           `json:"country,omitempty"`
-	Status           string           `json:"status"`
-	Visibility       string           `json:"visibility"`
-	IsFeatured       bool             `json:"is_featured"`
-	IsFree           bool             `json:"is_free"`
-	MaxAttendees     int              `json:"max_attendees,omitempty"`
-	MinAttendees     int              `json:"min_attendees"`
-	Tags             []string         `json:"tags"`
-	AgeRestriction   int              `json:"age_restriction,omitempty"`
-	ViewCount        int              `json:"view_count"`
-	FavoriteCount    int              `json:"favorite_count"`
-	ShareCount       int              `json:"share_count"`
-	TicketTypes      []TicketTypeInfo `json:"ticket_types"`
-	PublishedAt      time.Time        `json:"published_at,omitempty"`
-	CreatedAt        time.Time        `json:"created_at"`
-	UpdatedAt        time.Time        `json:"updated_at"`
+	ID                 string           `json:"id"`
+	Organizer          OrganizerInfo    `json:"organizer"`
+	Venue              VenueInfo        `json:"venue,omitempty"`
+	PrimaryCategory    CategoryInfo     `json:"primary_category,omitempty"`
+	Categories         []CategoryInfo   `json:"categories"`
+	Name               string           `json:"name"`
+	Slug               string           `json:"slug"`
+	ShortDescription   string           `json:"short_description,omitempty"`
+	Description        string           `json:"description"`
+	EventType          string           `json:"event_type"`
+	CoverImageURL      string           `json:"cover_image_url,omitempty"`
+	BannerImageURL     string           `json:"banner_image_url,omitempty"`
+	GalleryImages      []string         `json:"gallery_images"`
+	Timezone           string           `json:"timezone"`
+	StartsAt           time.Time        `json:"starts_at"`
+	EndsAt             time.Time        `json:"ends_at"`
+	DoorsOpenAt        time.Time        `json:"doors_open_at,omitempty"`
+	DoorsCloseAt       time.Time        `json:"doors_close_at,omitempty"`
+	VenueName          string           `json:"venue_name,omitempty"`
+	AddressFull        string           `json:"address_full,omitempty"`
+	City               string           `json:"city,omitempty"`
+	State              string           `json:"state,omitempty"`
+	Country            string           `json:"country,omitempty"`
+	Status             string           `json:"status"`
+	CancellationReason string           `json:"cancellation_reason,omitempty"`
+	Visibility         string           `json:"visibility"`
+	IsFeatured         bool             `json:"is_featured"`
+	IsFree             bool             `json:"is_free"`
+	MaxAttendees       int              `json:"max_attendees,omitempty"`
+	MinAttendees       int              `json:"min_attendees"`
+	Tags               []string         `json:"tags"`
+	AgeRestriction     int              `json:"age_restriction,omitempty"`
+	ViewCount          int              `json:"view_count"`
+	FavoriteCount      int              `json:"favorite_count"`
+	ShareCount         int              `json:"share_count"`
+	TicketTypes        []TicketTypeInfo `json:"ticket_types"`
+	PublishedAt        time.Time        `json:"published_at,omitempty"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
 }
 
 type EventStatsResponse struct {
@@ -12,8 +12,11 @@ type CreateTicketRequest struct {
 	EventID      string `json:"event_id" validate:"required"`
 	CustomerID   string `json:"customer_id" validate:"required"`
 	TicketTypeID string `json:"ticketTypeId" validate:"required"`
-	Quantity     int32  `json:"quantity" validate:"required,min=1,max=10"`
+	Quantity     int32  `json:"quantity" validate:"required,min=1"`
 	UserID       string `json:"user_id,omitempty"`
+
+	// BypassQuantityLimit omite el máximo por transacción; sólo para flujos administrativos
+	BypassQuantityLimit bool `json:"-"`
 }
 
 // Validate valida la estructura
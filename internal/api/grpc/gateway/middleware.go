@@ -0,0 +1,103 @@
+// Package gateway contiene los middlewares HTTP del gateway JSON
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/franciscozamorau/osmi-server/internal/api/grpc/interceptors"
+	"github.com/franciscozamorau/osmi-server/internal/config"
+)
+
+// CORS agrega los headers CORS sólo para los orígenes de la lista y responde
+// directamente las peticiones preflight (OPTIONS). Los orígenes listados se
+// reflejan con Allow-Credentials; "*" permite cualquier origen pero sin
+// credenciales, porque reflejar un origen arbitrario con cookies o Authorization
+// expondría la API a cualquier sitio.
+func CORS(cfg config.GatewayConfig, next http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(cfg.AllowedOrigins))
+	allowAny := false
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		switch origin {
+		case "":
+		case "*":
+			allowAny = true
+		default:
+			allowed[origin] = true
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		h := w.Header()
+		h.Add("Vary", "Origin")
+
+		switch {
+		case origin == "":
+			next.ServeHTTP(w, r)
+			return
+		case allowed[origin]:
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Credentials", "true")
+		case allowAny:
+			h.Set("Access-Control-Allow-Origin", "*")
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			h.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Auth exige un bearer token válido salvo en las rutas públicas.
+// Los preflight no llevan Authorization, por eso se dejan pasar.
+func Auth(cfg config.GatewayConfig, validator interceptors.TokenValidator, next http.Handler) http.Handler {
+	public := make(map[string]bool, len(cfg.PublicPaths))
+	for _, path := range cfg.PublicPaths {
+		if path = strings.TrimSpace(path); path != "" {
+			public[path] = true
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || public[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := r.Header.Get("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			writeUnauthorized(w, "authorization token not found")
+			return
+		}
+
+		if _, err := validator.ValidateToken(token); err != nil {
+			writeUnauthorized(w, "invalid token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeUnauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	w.WriteHeader(http.StatusUnauthorized)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": msg,
+		"code":  "Unauthenticated",
+	})
+}
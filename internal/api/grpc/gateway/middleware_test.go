@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/franciscozamorau/osmi-server/internal/config"
+	"github.com/franciscozamorau/osmi-server/internal/shared/security"
+)
+
+type fakeValidator struct{}
+
+func (fakeValidator) ValidateToken(token string) (*security.Claims, error) {
+	if token != "good" {
+		return nil, errors.New("bad token")
+	}
+	return &security.Claims{UserID: "user-1"}, nil
+}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func serve(h http.Handler, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name            string
+		origins         []string
+		origin          string
+		wantOrigin      string
+		wantCredentials bool
+	}{
+		{"listed origin", []string{"https://dash.example"}, "https://dash.example", "https://dash.example", true},
+		{"listed with trailing slash", []string{"https://dash.example/"}, "https://dash.example", "https://dash.example", true},
+		{"unlisted origin", []string{"https://dash.example"}, "https://evil.example", "", false},
+		{"wildcard without credentials", []string{"*"}, "https://evil.example", "*", false},
+		{"listed wins over wildcard", []string{"*", "https://dash.example"}, "https://dash.example", "https://dash.example", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := CORS(config.GatewayConfig{AllowedOrigins: tt.origins}, okHandler)
+			rec := serve(h, http.MethodGet, "/v1/events", map[string]string{"Origin": tt.origin})
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials") == "true"; got != tt.wantCredentials {
+				t.Errorf("Allow-Credentials = %v, want %v", got, tt.wantCredentials)
+			}
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", rec.Code)
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	cfg := config.GatewayConfig{AllowedOrigins: []string{"https://dash.example"}, RequireAuth: true}
+	h := CORS(cfg, Auth(cfg, fakeValidator{}, okHandler))
+
+	rec := serve(h, http.MethodOptions, "/v1/events", map[string]string{
+		"Origin":                        "https://dash.example",
+		"Access-Control-Request-Method": "POST",
+	})
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("preflight status = %d, want 204", rec.Code)
+	}
+	if rec.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Error("preflight without Access-Control-Allow-Methods")
+	}
+
+	// Un preflight de un origen no listado no recibe permisos
+	rec = serve(h, http.MethodOptions, "/v1/events", map[string]string{
+		"Origin":                        "https://evil.example",
+		"Access-Control-Request-Method": "POST",
+	})
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Header().Get("Access-Control-Allow-Methods") != "" {
+		t.Errorf("unlisted preflight got CORS headers: %v", rec.Header())
+	}
+}
+
+func TestAuth(t *testing.T) {
+	cfg := config.GatewayConfig{RequireAuth: true, PublicPaths: []string{"/health", "/v1/health"}}
+	h := Auth(cfg, fakeValidator{}, okHandler)
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{"valid token", "/v1/events", "Bearer good", http.StatusOK},
+		{"missing token", "/v1/events", "", http.StatusUnauthorized},
+		{"invalid token", "/v1/events", "Bearer bad", http.StatusUnauthorized},
+		{"not a bearer token", "/v1/events", "Basic Zm9vOmJhcg==", http.StatusUnauthorized},
+		{"public path", "/health", "", http.StatusOK},
+		{"public path prefix is not public", "/healthz", "", http.StatusUnauthorized},
+		{"public path child is not public", "/v1/health/../events", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.header != "" {
+				headers["Authorization"] = tt.header
+			}
+			rec := serve(h, http.MethodGet, tt.path, headers)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Error("401 without WWW-Authenticate: Bearer")
+			}
+		})
+	}
+}
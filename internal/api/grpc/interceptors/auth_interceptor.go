@@ -1 +1,68 @@
 package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
+	"github.com/franciscozamorau/osmi-server/internal/shared/security"
+)
+
+// TokenValidator valida el bearer token; lo implementa security.JWTService
+type TokenValidator interface {
+	ValidateToken(tokenString string) (*security.Claims, error)
+}
+
+// RoleResolver devuelve el rol (admin, staff, customer) del usuario del token
+type RoleResolver func(ctx context.Context, userPublicID string) (string, error)
+
+// UnaryAuth identifica al usuario cuando la llamada trae un bearer token y guarda
+// su id y rol en el contexto. Las llamadas sin token siguen como anónimas: cada
+// handler decide si exige usuario. Un token inválido se rechaza con Unauthenticated.
+func UnaryAuth(validator TokenValidator, resolveRole RoleResolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		token, present := bearerToken(ctx)
+		if !present {
+			return handler(ctx, req)
+		}
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+		}
+
+		claims, err := validator.ValidateToken(token)
+		if err != nil || claims.UserID == "" {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		role, err := resolveRole(ctx, claims.UserID)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "unknown user")
+		}
+
+		ctx = appctx.WithUserID(ctx, claims.UserID)
+		ctx = appctx.WithUserRole(ctx, role)
+		return handler(ctx, req)
+	}
+}
+
+// bearerToken extrae el token del header authorization. present indica si el
+// header venía; token es "" si no tenía la forma "Bearer <token>".
+func bearerToken(ctx context.Context) (token string, present bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	token, _ = strings.CutPrefix(values[0], "Bearer ")
+	if token == values[0] {
+		return "", true
+	}
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,75 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
+	"github.com/franciscozamorau/osmi-server/internal/shared/security"
+)
+
+// fakeValidator acepta sólo el token "good" y lo asigna al usuario user-1
+type fakeValidator struct{}
+
+func (fakeValidator) ValidateToken(token string) (*security.Claims, error) {
+	if token != "good" {
+		return nil, errors.New("bad token")
+	}
+	return &security.Claims{UserID: "user-1"}, nil
+}
+
+func adminResolver(ctx context.Context, userPublicID string) (string, error) {
+	if userPublicID != "user-1" {
+		return "", errors.New("user not found")
+	}
+	return "admin", nil
+}
+
+func withAuthorization(value string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", value))
+}
+
+func TestUnaryAuth(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test/Method"}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		resolve  RoleResolver
+		wantCode codes.Code
+		wantUser string
+		wantRole string
+	}{
+		{"anonymous", context.Background(), adminResolver, codes.OK, "", ""},
+		{"valid token", withAuthorization("Bearer good"), adminResolver, codes.OK, "user-1", "admin"},
+		{"invalid token", withAuthorization("Bearer bad"), adminResolver, codes.Unauthenticated, "", ""},
+		{"not a bearer token", withAuthorization("good"), adminResolver, codes.Unauthenticated, "", ""},
+		{"unknown user", withAuthorization("Bearer good"), func(context.Context, string) (string, error) {
+			return "", errors.New("user not found")
+		}, codes.Unauthenticated, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser, gotRole string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				gotUser = appctx.UserIDFromContext(ctx)
+				gotRole = appctx.UserRoleFromContext(ctx)
+				return "ok", nil
+			}
+
+			_, err := UnaryAuth(fakeValidator{}, tt.resolve)(tt.ctx, nil, info, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			if gotUser != tt.wantUser || gotRole != tt.wantRole {
+				t.Errorf("context user/role = %q/%q, want %q/%q", gotUser, gotRole, tt.wantUser, tt.wantRole)
+			}
+		})
+	}
+}
@@ -1 +1,67 @@
 package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/utils"
+)
+
+// UnaryLogging registra cada RPC con método, duración, peer, código gRPC y el
+// request id del contexto, el mismo que DatabaseLoggerContext agrega a las
+// consultas. Debe ir después de UnaryRequestID en la cadena.
+func UnaryLogging(logger *utils.Logger) grpc.UnaryServerInterceptor {
+	if logger == nil {
+		logger = utils.GlobalLogger
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		fields := map[string]interface{}{
+			"request_id": appctx.RequestIDFromContext(ctx),
+			"method":     info.FullMethod,
+			"duration":   time.Since(start).String(),
+			"peer":       peerAddress(ctx),
+			"code":       code.String(),
+		}
+
+		switch {
+		case err == nil:
+			logger.Info("gRPC request", fields)
+		case isServerError(code):
+			logger.Error("gRPC request failed", err, fields)
+		default:
+			fields["error"] = err.Error()
+			logger.Warn("gRPC request rejected", fields)
+		}
+
+		return resp, err
+	}
+}
+
+// peerAddress devuelve la dirección del cliente o "unknown"
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
+
+// isServerError distingue fallos del servidor de errores atribuibles al cliente
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss,
+		codes.Unimplemented, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/utils"
+)
+
+func TestUnaryLoggingLevelsAndRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	interceptor := UnaryLogging(utils.NewLogger("test").WithCallerInfo(false))
+	ctx := appctx.WithRequestID(context.Background(), "req-7")
+	info := &grpc.UnaryServerInfo{FullMethod: "/osmi.OsmiService/GetEvent"}
+
+	tests := []struct {
+		name  string
+		err   error
+		level string
+		code  string
+	}{
+		{"success", nil, "INFO", "OK"},
+		{"client error", status.Error(codes.NotFound, "event not found"), "WARN", "NotFound"},
+		{"server error", status.Error(codes.Internal, "db down"), "ERROR", "Internal"},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		handler := func(context.Context, interface{}) (interface{}, error) { return "resp", tt.err }
+		resp, err := interceptor(ctx, nil, info, handler)
+		if resp != "resp" || err != tt.err {
+			t.Errorf("%s: interceptor changed the handler result: %v, %v", tt.name, resp, err)
+		}
+
+		out := buf.String()
+		for _, want := range []string{" " + tt.level + " ", "request_id=req-7", "method=/osmi.OsmiService/GetEvent", "code=" + tt.code, "peer=unknown"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: log %q missing %q", tt.name, out, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/metrics"
+)
+
+// UnaryMetrics registra en registry la duración y el código gRPC de cada RPC
+func UnaryMetrics(registry *metrics.RPCRegistry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		registry.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return resp, err
+	}
+}
@@ -0,0 +1,41 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/metrics"
+)
+
+func TestUnaryMetricsRecordsMethodAndCode(t *testing.T) {
+	registry := metrics.NewRPCRegistry()
+	interceptor := UnaryMetrics(registry)
+	info := &grpc.UnaryServerInfo{FullMethod: "/osmi.OsmiService/GetEvent"}
+
+	ok := func(context.Context, interface{}) (interface{}, error) { return "resp", nil }
+	notFound := func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	}
+	if resp, err := interceptor(context.Background(), nil, info, ok); resp != "resp" || err != nil {
+		t.Fatalf("interceptor = %v, %v; want the handler result", resp, err)
+	}
+	interceptor(context.Background(), nil, info, ok)
+	if _, err := interceptor(context.Background(), nil, info, notFound); status.Code(err) != codes.NotFound {
+		t.Fatalf("err = %v, want the handler error", err)
+	}
+
+	got := map[string]int64{}
+	for _, s := range registry.Snapshot() {
+		if s.Method != info.FullMethod {
+			t.Errorf("unexpected method %q", s.Method)
+		}
+		got[s.Code] = s.Calls
+	}
+	if len(got) != 2 || got["OK"] != 2 || got["NotFound"] != 1 {
+		t.Errorf("calls by code = %v, want OK:2 NotFound:1", got)
+	}
+}
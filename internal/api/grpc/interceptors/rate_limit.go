@@ -0,0 +1,65 @@
+package interceptors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// TokenBucket es un limitador de tasa global: recarga rate tokens por segundo
+// hasta burst, y cada RPC consume uno.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket crea un bucket lleno con rate tokens por segundo y capacidad burst
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// Take consume un token. Si no hay, devuelve false y el tiempo hasta que se
+// recargue el siguiente.
+func (b *TokenBucket) Take() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// UnaryRateLimit rechaza con ResourceExhausted, RetryInfo y trailer retry-after
+// las RPC que exceden el bucket
+func UnaryRateLimit(bucket *TokenBucket) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if ok, wait := bucket.Take(); !ok {
+			return nil, RateLimitedError(ctx, wait)
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,107 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// trailerStream captura los trailers que fija el interceptor
+type trailerStream struct {
+	grpc.ServerTransportStream
+	trailer metadata.MD
+}
+
+func (s *trailerStream) Method() string { return "/test/Method" }
+
+func (s *trailerStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func retryDelay(t *testing.T, err error) time.Duration {
+	t.Helper()
+	st := status.Convert(err)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration()
+		}
+	}
+	t.Fatalf("error %v has no RetryInfo detail", err)
+	return 0
+}
+
+func TestRateLimitedErrorCarriesRetryInfo(t *testing.T) {
+	stream := &trailerStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+
+	err := RateLimitedError(ctx, 1500*time.Millisecond)
+
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("code = %v, want ResourceExhausted", status.Code(err))
+	}
+	if delay := retryDelay(t, err); delay != 1500*time.Millisecond {
+		t.Errorf("RetryDelay = %v, want 1.5s", delay)
+	}
+	if got := stream.trailer.Get(RetryAfterKey); len(got) != 1 || got[0] != "2" {
+		t.Errorf("retry-after trailer = %v, want [2]", got)
+	}
+}
+
+func TestRetryAfterErrorEnforcesMinimum(t *testing.T) {
+	err := RetryAfterError(context.Background(), codes.Unavailable, "service overloaded", 0)
+
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("code = %v, want Unavailable", status.Code(err))
+	}
+	if delay := retryDelay(t, err); delay < minRetryAfter {
+		t.Errorf("RetryDelay = %v, want at least %v", delay, minRetryAfter)
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := NewTokenBucket(2, 1)
+	bucket.now = func() time.Time { return now }
+	bucket.last = now
+
+	if ok, _ := bucket.Take(); !ok {
+		t.Fatal("first take should succeed")
+	}
+	ok, wait := bucket.Take()
+	if ok {
+		t.Fatal("second take should be rejected")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait = %v, want 500ms", wait)
+	}
+
+	now = now.Add(wait)
+	if ok, _ := bucket.Take(); !ok {
+		t.Error("take after refill should succeed")
+	}
+}
+
+func TestUnaryRateLimitRejectsWithRetryInfo(t *testing.T) {
+	interceptor := UnaryRateLimit(NewTokenBucket(1, 1))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/test/Method"}
+
+	if _, err := interceptor(context.Background(), nil, info, handler); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+
+	_, err := interceptor(context.Background(), nil, info, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("code = %v, want ResourceExhausted", status.Code(err))
+	}
+	if delay := retryDelay(t, err); delay <= 0 {
+		t.Errorf("RetryDelay = %v, want positive", delay)
+	}
+}
@@ -0,0 +1,55 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
+)
+
+// RequestIDKey es la clave de metadata con la que el cliente envía el request id
+// y con la que se le devuelve en el trailer
+const RequestIDKey = "x-request-id"
+
+// maxRequestIDLength acota el request id que se acepta del cliente
+const maxRequestIDLength = 128
+
+// UnaryRequestID toma el x-request-id de la metadata entrante (o genera uno si
+// falta o no es válido), lo guarda en el contexto y lo devuelve al cliente en
+// el trailer. El registro de la llamada lo hace UnaryLogging.
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := incomingRequestID(ctx)
+		ctx = appctx.WithRequestID(ctx, requestID)
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(RequestIDKey, requestID))
+
+		return handler(ctx, req)
+	}
+}
+
+// incomingRequestID devuelve el request id enviado por el cliente si es válido
+func incomingRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDKey); len(values) > 0 && validRequestID(values[0]) {
+			return values[0]
+		}
+	}
+	return uuid.New().String()
+}
+
+// validRequestID rechaza ids vacíos, demasiado largos o con caracteres no
+// imprimibles, para que un cliente no pueda inyectar líneas en los logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
+)
+
+// callWithRequestID ejecuta UnaryRequestID con la metadata entrante md y
+// devuelve el request id visto por el handler y el trailer enviado al cliente
+func callWithRequestID(t *testing.T, md metadata.MD) (string, string) {
+	t.Helper()
+	stream := &trailerStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	if md != nil {
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+
+	var seen string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen = appctx.RequestIDFromContext(ctx)
+		return "ok", nil
+	}
+	if _, err := UnaryRequestID()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Method"}, handler); err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+
+	echoed := stream.trailer.Get(RequestIDKey)
+	if len(echoed) != 1 {
+		t.Fatalf("trailer %s = %v, want exactly one value", RequestIDKey, echoed)
+	}
+	return seen, echoed[0]
+}
+
+func TestUnaryRequestIDEchoesProvidedID(t *testing.T) {
+	seen, echoed := callWithRequestID(t, metadata.Pairs(RequestIDKey, "client-req-42"))
+	if seen != "client-req-42" || echoed != "client-req-42" {
+		t.Errorf("context/trailer = %q/%q, want client-req-42", seen, echoed)
+	}
+}
+
+func TestUnaryRequestIDGeneratesWhenAbsentOrInvalid(t *testing.T) {
+	tests := map[string]metadata.MD{
+		"no metadata":   nil,
+		"header absent": metadata.Pairs("other", "x"),
+		"log injection": metadata.Pairs(RequestIDKey, "abc\nlevel=error"),
+		"too long":      metadata.Pairs(RequestIDKey, strings.Repeat("a", maxRequestIDLength+1)),
+		"empty header":  metadata.Pairs(RequestIDKey, ""),
+	}
+	for name, md := range tests {
+		t.Run(name, func(t *testing.T) {
+			seen, echoed := callWithRequestID(t, md)
+			if _, err := uuid.Parse(echoed); err != nil {
+				t.Errorf("trailer = %q, want a generated UUID", echoed)
+			}
+			if seen != echoed {
+				t.Errorf("context id %q differs from trailer %q", seen, echoed)
+			}
+		})
+	}
+
+	_, first := callWithRequestID(t, nil)
+	_, second := callWithRequestID(t, nil)
+	if first == second {
+		t.Errorf("generated ids repeat: %q", first)
+	}
+}
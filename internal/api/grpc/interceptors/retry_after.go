@@ -0,0 +1,49 @@
+package interceptors
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RetryAfterKey es la clave de metadata con los segundos a esperar antes de reintentar
+const RetryAfterKey = "retry-after"
+
+// minRetryAfter evita sugerir reintentos inmediatos
+const minRetryAfter = time.Second
+
+// RetryAfterError construye un error con el código dado que incluye un detalle
+// RetryInfo y agrega el trailer retry-after a la respuesta. Hoy solo lo usa
+// UnaryRateLimit (ResourceExhausted con el tiempo de recarga del bucket); el
+// servidor no tiene circuit breaker, así que no hay rechazos Unavailable que
+// lo necesiten.
+func RetryAfterError(ctx context.Context, code codes.Code, msg string, retryAfter time.Duration) error {
+	if retryAfter < minRetryAfter {
+		retryAfter = minRetryAfter
+	}
+
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterKey, strconv.FormatInt(seconds, 10)))
+
+	st := status.New(code, msg)
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// RateLimitedError es el atajo para rechazos del rate limiter
+func RateLimitedError(ctx context.Context, retryAfter time.Duration) error {
+	return RetryAfterError(ctx, codes.ResourceExhausted, "rate limit exceeded", retryAfter)
+}
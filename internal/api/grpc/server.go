@@ -13,26 +13,34 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	pb "github.com/franciscozamorau/osmi-protobuf/gen/pb"
+	"github.com/franciscozamorau/osmi-server/internal/api/grpc/gateway"
+	"github.com/franciscozamorau/osmi-server/internal/api/grpc/interceptors"
 	"github.com/franciscozamorau/osmi-server/internal/config"
 )
 
 type Server struct {
-	config      *config.Config
-	logger      *zap.Logger
-	grpcServer  *grpc.Server
-	httpServer  *http.Server
-	grpcHandler *grpc.Handler
+	config         *config.Config
+	logger         *zap.Logger
+	grpcServer     *grpc.Server
+	httpServer     *http.Server
+	grpcHandler    *grpc.Handler
+	tokenValidator interceptors.TokenValidator
 }
 
+// NewServer crea el servidor gRPC + gateway. tokenValidator es el mismo
+// security.JWTService del interceptor de gRPC; el gateway lo usa con
+// GATEWAY_REQUIRE_AUTH.
 func NewServer(
 	cfg *config.Config,
 	logger *zap.Logger,
 	grpcHandler *grpc.Handler,
+	tokenValidator interceptors.TokenValidator,
 ) *Server {
 	return &Server{
-		config:      cfg,
-		logger:      logger,
-		grpcHandler: grpcHandler,
+		config:         cfg,
+		logger:         logger,
+		grpcHandler:    grpcHandler,
+		tokenValidator: tokenValidator,
 	}
 }
 
@@ -109,7 +117,10 @@ func (s *Server) StartHTTPGateway() error {
 	}
 
 	// Configurar router HTTP con middleware
-	router := s.setupRouter(mux)
+	router, err := s.setupRouter(mux)
+	if err != nil {
+		return err
+	}
 
 	s.httpServer = &http.Server{
 		Addr:         fmt.Sprintf(":%d", s.config.HTTPPort),
@@ -134,10 +145,23 @@ func (s *Server) StartHTTPGateway() error {
 	return nil
 }
 
-func (s *Server) setupRouter(gwMux *runtime.ServeMux) http.Handler {
-	// Este método será implementado en el router HTTP
-	// Por ahora, retornamos el mux directamente
-	return gwMux
+func (s *Server) setupRouter(gwMux *runtime.ServeMux) (http.Handler, error) {
+	var handler http.Handler = gwMux
+
+	gatewayCfg := s.config.Gateway
+	if gatewayCfg.RequireAuth {
+		if s.tokenValidator == nil {
+			return nil, fmt.Errorf("GATEWAY_REQUIRE_AUTH is set but no token validator was configured")
+		}
+		handler = gateway.Auth(gatewayCfg, s.tokenValidator, handler)
+	}
+
+	// CORS va por fuera para que también las respuestas 401 lleven los headers
+	if len(gatewayCfg.AllowedOrigins) > 0 {
+		handler = gateway.CORS(gatewayCfg, handler)
+	}
+
+	return handler, nil
 }
 
 func (s *Server) customErrorHandler(
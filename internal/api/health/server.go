@@ -0,0 +1,67 @@
+// internal/api/health/server.go
+package health
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// NewHandler arma el mux de /health y /metrics. Mientras serving sea false
+// /health responde 503 shutting_down; si ping falla responde 503 unhealthy.
+func NewHandler(serving *atomic.Bool, ping func(ctx context.Context) error, metricsHandler http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	if metricsHandler != nil {
+		mux.Handle("/metrics", metricsHandler)
+	}
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if !serving.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"shutting_down"}`))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+
+		if err := ping(ctx); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"unhealthy"}`))
+			return
+		}
+
+		w.Write([]byte(`{"status":"healthy","service":"osmi-server"}`))
+	})
+	return mux
+}
+
+// Start escucha en address y sirve handler en segundo plano. El puerto se abre
+// antes de volver, así que un error de bind se reporta aquí; el *http.Server
+// devuelto (con Addr igual a la dirección real) se apaga con Shutdown.
+func Start(address string, handler http.Handler) (*http.Server, error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{
+		Addr:              lis.Addr().String(),
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Health check en %s/health", srv.Addr)
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("⚠️ Health server detenido: %v", err)
+		}
+	}()
+
+	return srv, nil
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, url string) int {
+	t.Helper()
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestHealthReportsShutdownThenStopsAccepting(t *testing.T) {
+	var serving atomic.Bool
+	serving.Store(true)
+	srv, err := Start("127.0.0.1:0", NewHandler(&serving, func(context.Context) error { return nil }, nil))
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	url := "http://" + srv.Addr + "/health"
+
+	if code := get(t, url); code != http.StatusOK {
+		t.Fatalf("healthy status = %d, want 200", code)
+	}
+
+	// Durante el drenado primero se reporta NOT_SERVING
+	serving.Store(false)
+	if code := get(t, url); code != http.StatusServiceUnavailable {
+		t.Errorf("draining status = %d, want 503", code)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	conn, err := net.DialTimeout("tcp", srv.Addr, 200*time.Millisecond)
+	if err == nil {
+		conn.Close()
+		t.Error("health server still accepts connections after Shutdown")
+	}
+}
+
+func TestHealthReportsFailedPing(t *testing.T) {
+	var serving atomic.Bool
+	serving.Store(true)
+	srv, err := Start("127.0.0.1:0", NewHandler(&serving, func(context.Context) error { return errors.New("db down") }, nil))
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	if code := get(t, "http://"+srv.Addr+"/health"); code != http.StatusServiceUnavailable {
+		t.Errorf("status with failed ping = %d, want 503", code)
+	}
+}
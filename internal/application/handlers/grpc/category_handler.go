@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	osmi "github.com/franciscozamorau/osmi-protobuf/gen/pb"
@@ -9,6 +10,7 @@ import (
 	"github.com/franciscozamorau/osmi-server/internal/api/helpers"
 	"github.com/franciscozamorau/osmi-server/internal/application/services"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -68,6 +70,27 @@ func (h *CategoryHandler) CreateCategory(ctx context.Context, req *osmi.CreateCa
 	return h.categoryToResponse(category, req.EventId), nil
 }
 
+// GetCategory obtiene una categoría activa por su ID público. Una categoría
+// desactivada responde FailedPrecondition en lugar de NotFound.
+func (h *CategoryHandler) GetCategory(ctx context.Context, req *osmi.GetCategoryRequest) (*osmi.CategoryResponse, error) {
+	if req.PublicId == "" {
+		return nil, status.Error(codes.InvalidArgument, "category public_id is required")
+	}
+
+	category, err := h.categoryService.GetActiveCategory(ctx, req.PublicId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCategoryNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, repository.ErrInactive):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return h.categoryToResponse(category, category.EventID), nil
+}
+
 // GetEventCategories obtiene las categorías de un evento
 func (h *CategoryHandler) GetEventCategories(ctx context.Context, req *osmi.GetEventCategoriesRequest) (*osmi.CategoryListResponse, error) {
 	if req.PublicId == "" {
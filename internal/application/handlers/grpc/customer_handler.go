@@ -3,12 +3,14 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	osmi "github.com/franciscozamorau/osmi-protobuf/gen/pb"
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	customerdto "github.com/franciscozamorau/osmi-server/internal/api/dto/customer"
 	"github.com/franciscozamorau/osmi-server/internal/api/helpers"
 	"github.com/franciscozamorau/osmi-server/internal/application/services"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -41,9 +43,10 @@ func (h *CustomerHandler) CreateCustomer(ctx context.Context, req *osmi.CreateCu
 
 	// Convertir a request compatible con el servicio
 	createReq := &services.CreateCustomerRequest{
-		Name:  req.Name,
-		Email: req.Email,
-		Phone: req.Phone,
+		Name:   req.Name,
+		Email:  req.Email,
+		Phone:  req.Phone,
+		Source: req.Source,
 	}
 
 	customer, err := h.customerService.CreateCustomer(ctx, createReq)
@@ -116,6 +119,12 @@ func (h *CustomerHandler) UpdateCustomer(ctx context.Context, req *osmi.UpdateCu
 
 	customer, err := h.customerService.UpdateCustomer(ctx, req.PublicId, updateReq)
 	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCustomerNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, repository.ErrInactive):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
@@ -148,6 +148,7 @@ func (h *EventHandler) GetEvent(ctx context.Context, req *osmi.GetEventRequest)
 		return nil, status.Error(codes.NotFound, err.Error())
 	}
 
+	services.SetCancellationReasonHeader(ctx, event)
 	return h.eventToProto(event), nil
 }
 
@@ -295,6 +296,7 @@ func (h *EventHandler) UpdateEvent(ctx context.Context, req *osmi.UpdateEventReq
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	services.SetCancellationReasonHeader(ctx, event)
 	return h.eventToProto(event), nil
 }
 
@@ -321,6 +323,7 @@ func (h *EventHandler) eventToProto(event *entities.Event) *osmi.EventResponse {
 		Tags:             []string{},
 		IsActive:         event.Status != "cancelled" && event.Status != "archived",
 		IsPublished:      event.Status == "published" || event.Status == "live",
+		Status:           event.Status,
 		ImageUrl:         helpers.SafeStringPtr(event.CoverImageURL),
 		BannerUrl:        helpers.SafeStringPtr(event.BannerImageURL),
 		CreatedAt:        timestamppb.New(event.CreatedAt),
@@ -70,6 +70,10 @@ func (h *Handler) CreateCategory(ctx context.Context, req *osmi.CreateCategoryRe
 	return h.categoryHandler.CreateCategory(ctx, req)
 }
 
+func (h *Handler) GetCategory(ctx context.Context, req *osmi.GetCategoryRequest) (*osmi.CategoryResponse, error) {
+	return h.categoryHandler.GetCategory(ctx, req)
+}
+
 func (h *Handler) GetEventCategories(ctx context.Context, req *osmi.GetEventCategoriesRequest) (*osmi.CategoryListResponse, error) {
 	return h.categoryHandler.GetEventCategories(ctx, req)
 }
@@ -3,6 +3,7 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"log"
 	"strconv"
 
@@ -11,7 +12,11 @@ import (
 	ticketdto "github.com/franciscozamorau/osmi-server/internal/api/dto/ticket"
 	"github.com/franciscozamorau/osmi-server/internal/api/helpers"
 	"github.com/franciscozamorau/osmi-server/internal/application/services"
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/domain/valueobjects"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -49,12 +54,18 @@ func (h *TicketHandler) CreateTicket(ctx context.Context, req *osmi.CreateTicket
 		TicketTypeID: req.TicketTypeId,
 		Quantity:     req.Quantity,
 		UserID:       req.UserId,
+		// Sólo un administrador autenticado (ver interceptors.UnaryAuth) omite el
+		// máximo de tickets por transacción
+		BypassQuantityLimit: enums.UserRole(appctx.UserRoleFromContext(ctx)).IsAdmin(),
 	}
 
 	log.Printf("📦 Creando ticket con CustomerID: %q", createReq.CustomerID)
 
 	ticket, err := h.ticketService.CreateTicket(ctx, createReq)
 	if err != nil {
+		if errors.Is(err, services.ErrTooManyTickets) || errors.Is(err, repository.ErrNotEnoughTickets) {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
@@ -69,14 +80,26 @@ func (h *TicketHandler) ReserveTicket(ctx context.Context, req *osmi.ReserveTick
 	}
 	// 🔥 COMENTADO: if req.UserId == "" { return nil, status.Error(codes.InvalidArgument, "user_id is required") }
 
+	// TicketTypeId puede ser un tipo de ticket o un ticket pregenerado (ver
+	// TicketService.ReserveTicket); sin expires_at se usa la vigencia por defecto
 	reserveReq := &ticketdto.ReserveTicketRequest{
-		TicketID:  req.TicketTypeId,
-		UserID:    req.UserId, // Puede estar vacío
-		ExpiresAt: req.ExpiresAt.AsTime(),
+		TicketID: req.TicketTypeId,
+		UserID:   req.UserId, // Puede estar vacío al reservar por tipo de ticket
+	}
+	if req.ExpiresAt != nil {
+		reserveReq.ExpiresAt = req.ExpiresAt.AsTime()
 	}
 
 	ticket, err := h.ticketService.ReserveTicket(ctx, reserveReq)
 	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotEnoughTickets):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		case errors.Is(err, repository.ErrTicketNotAvailable):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, repository.ErrUserNotFound), errors.Is(err, repository.ErrTicketTypeNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
@@ -125,6 +148,15 @@ func (h *TicketHandler) CheckInTicket(ctx context.Context, req *osmi.CheckInTick
 
 	ticket, err := h.ticketService.CheckInTicket(ctx, checkinReq)
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidQRCode):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case errors.Is(err, repository.ErrTicketNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, services.ErrCheckInNotOpen), errors.Is(err, services.ErrCheckInClosed),
+			errors.Is(err, repository.ErrTicketNotAvailable):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
@@ -136,23 +168,30 @@ func (h *TicketHandler) TransferTicket(ctx context.Context, req *osmi.TransferTi
 	if req.TicketId == "" {
 		return nil, status.Error(codes.InvalidArgument, "ticket_id is required")
 	}
-	// 🔥 COMENTADO: validación de from_customer_id (temporalmente)
-	// if req.FromCustomerId == "" {
-	//     return nil, status.Error(codes.InvalidArgument, "from_customer_id is required")
-	// }
+	if req.FromCustomerId == "" {
+		return nil, status.Error(codes.InvalidArgument, "from_customer_id is required")
+	}
 	if req.ToCustomerId == "" {
 		return nil, status.Error(codes.InvalidArgument, "to_customer_id is required")
 	}
 
 	transferReq := &ticketdto.TransferTicketRequest{
 		TicketID:       req.TicketId,
-		FromCustomerID: req.FromCustomerId, // Puede estar vacío
+		FromCustomerID: req.FromCustomerId,
 		ToCustomerID:   req.ToCustomerId,
 		Token:          req.Token,
 	}
 
 	ticket, err := h.ticketService.TransferTicket(ctx, transferReq)
 	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTicketNotFound), errors.Is(err, repository.ErrCustomerNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, repository.ErrTicketOwnerMismatch):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case errors.Is(err, repository.ErrTicketNotAvailable):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
@@ -180,6 +219,32 @@ func (h *TicketHandler) UpdateTicket(ctx context.Context, req *osmi.UpdateTicket
 	return h.ticketToProto(ticket), nil
 }
 
+// UpdateTicketStatus cambia el estado de un ticket; repetir el estado actual no es error
+func (h *TicketHandler) UpdateTicketStatus(ctx context.Context, req *osmi.UpdateTicketStatusRequest) (*osmi.TicketResponse, error) {
+	if req.TicketId == "" {
+		return nil, status.Error(codes.InvalidArgument, "ticket_id is required")
+	}
+	if req.Status == "" {
+		return nil, status.Error(codes.InvalidArgument, "status is required")
+	}
+	if !enums.TicketStatus(req.Status).IsValid() {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid ticket status: %s", req.Status)
+	}
+
+	ticket, err := h.ticketService.UpdateTicketStatus(ctx, req.TicketId, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTicketNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, repository.ErrInvalidTicketStatus):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return h.ticketToProto(ticket), nil
+}
+
 // GetTicket obtiene un ticket por ID
 func (h *TicketHandler) GetTicket(ctx context.Context, req *osmi.GetTicketRequest) (*osmi.TicketResponse, error) {
 	if req.Id == "" {
@@ -307,6 +372,7 @@ func (h *TicketHandler) ticketToProto(ticket *entities.Ticket) *osmi.TicketRespo
 		EventDate:     "",
 		Location:      ticket.Location, // 🔥 NUEVO
 		Price:         ticket.FinalPrice,
+		Currency:      valueobjects.CurrencyOrDefault(ticket.Currency),
 		CategoryName:  ticket.CategoryName, // 🔥 NUEVO
 		SeatNumber:    "",
 		CustomerName:  "",
@@ -158,11 +158,21 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *categorydto.C
 	return category, nil
 }
 
-// GetCategory obtiene una categoría por su ID público
+// GetCategory obtiene una categoría por su ID público, esté activa o no
 func (s *CategoryService) GetCategory(ctx context.Context, publicID string) (*entities.Category, error) {
 	category, err := s.categoryRepo.GetByPublicID(ctx, publicID)
 	if err != nil {
-		return nil, fmt.Errorf("category not found: %s", publicID)
+		return nil, fmt.Errorf("failed to get category %s: %w", publicID, err)
+	}
+	return category, nil
+}
+
+// GetActiveCategory obtiene una categoría activa. Devuelve ErrCategoryNotFound si
+// no existe y ErrInactive si está desactivada.
+func (s *CategoryService) GetActiveCategory(ctx context.Context, publicID string) (*entities.Category, error) {
+	category, err := s.categoryRepo.GetActiveByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active category %s: %w", publicID, err)
 	}
 	return category, nil
 }
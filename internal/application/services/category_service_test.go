@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+// lookupCategoryRepo implementa sólo las búsquedas por ID público
+type lookupCategoryRepo struct {
+	repository.CategoryRepository
+	categories map[string]*entities.Category
+}
+
+func (r *lookupCategoryRepo) GetByPublicID(_ context.Context, publicID string) (*entities.Category, error) {
+	category, ok := r.categories[publicID]
+	if !ok {
+		return nil, repository.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (r *lookupCategoryRepo) GetActiveByPublicID(ctx context.Context, publicID string) (*entities.Category, error) {
+	category, err := r.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if !category.IsActive {
+		return nil, fmt.Errorf("%w: category %s", repository.ErrInactive, publicID)
+	}
+	return category, nil
+}
+
+func TestCategoryServiceGetCategoryIncludesInactive(t *testing.T) {
+	ctx := context.Background()
+	repo := &lookupCategoryRepo{categories: map[string]*entities.Category{
+		"active":   {PublicID: "active", IsActive: true},
+		"inactive": {PublicID: "inactive", IsActive: false},
+	}}
+	service := NewCategoryService(repo, nil)
+
+	for _, id := range []string{"active", "inactive"} {
+		if _, err := service.GetCategory(ctx, id); err != nil {
+			t.Errorf("GetCategory(%s): %v", id, err)
+		}
+	}
+	if _, err := service.GetCategory(ctx, "missing"); !errors.Is(err, repository.ErrCategoryNotFound) {
+		t.Errorf("GetCategory(missing) error = %v, want ErrCategoryNotFound", err)
+	}
+
+	if _, err := service.GetActiveCategory(ctx, "active"); err != nil {
+		t.Errorf("GetActiveCategory(active): %v", err)
+	}
+	if _, err := service.GetActiveCategory(ctx, "inactive"); !errors.Is(err, repository.ErrInactive) {
+		t.Errorf("GetActiveCategory(inactive) error = %v, want ErrInactive", err)
+	}
+	if _, err := service.GetActiveCategory(ctx, "missing"); !errors.Is(err, repository.ErrCategoryNotFound) {
+		t.Errorf("GetActiveCategory(missing) error = %v, want ErrCategoryNotFound", err)
+	}
+}
@@ -4,6 +4,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
@@ -15,9 +16,10 @@ import (
 
 // CreateCustomerRequest - Versión compatible con handler
 type CreateCustomerRequest struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Phone string `json:"phone"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Phone  string `json:"phone"`
+	Source string `json:"source,omitempty"`
 }
 
 // UpdateCustomerRequest - DTO para actualizar cliente
@@ -61,6 +63,12 @@ func (s *CustomerService) CreateCustomer(ctx context.Context, req *CreateCustome
 		phonePtr = nil
 	}
 
+	var sourcePtr *string
+	if req.Source != "" {
+		source := strings.ToLower(strings.TrimSpace(req.Source))
+		sourcePtr = &source
+	}
+
 	customer := &entities.Customer{
 		PublicID:        uuid.New().String(),
 		FullName:        req.Name,
@@ -74,6 +82,7 @@ func (s *CustomerService) CreateCustomer(ctx context.Context, req *CreateCustome
 		IsVIP:           false,
 		CustomerSegment: "new",
 		LifetimeValue:   0,
+		Source:          sourcePtr,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
@@ -105,8 +114,8 @@ func (s *CustomerService) GetCustomer(ctx context.Context, publicID string) (*en
 
 // UpdateCustomer actualiza la información de un cliente
 func (s *CustomerService) UpdateCustomer(ctx context.Context, publicID string, req *UpdateCustomerRequest) (*entities.Customer, error) {
-	// Obtener el cliente existente
-	customer, err := s.customerRepo.GetByPublicID(ctx, publicID)
+	// Obtener el cliente existente; no se editan clientes desactivados
+	customer, err := s.customerRepo.GetActiveByPublicID(ctx, publicID)
 	if err != nil {
 		return nil, fmt.Errorf("customer not found: %w", err)
 	}
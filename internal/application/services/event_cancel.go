@@ -0,0 +1,68 @@
+// internal/application/services/event_cancel.go
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// defaultCancelReason se registra al cancelar sin razón en la petición ni en la metadata
+const defaultCancelReason = "cancelled via API"
+
+// CancelReasonMetadataKey es la clave de metadata gRPC con la razón de cancelación
+const CancelReasonMetadataKey = "x-cancel-reason"
+
+// CancellationReasonHeaderKey es el header de respuesta con la razón de
+// cancelación de un evento cancelado
+const CancellationReasonHeaderKey = "x-cancellation-reason"
+
+// maxCancelReasonLength acota la razón recibida antes de guardarla
+const maxCancelReasonLength = 500
+
+// cancelReason resuelve la razón de cancelación: primero la de la petición (si
+// el mensaje trae GetReason), luego la metadata x-cancel-reason y por último
+// defaultCancelReason. La razón se recorta a maxCancelReasonLength caracteres.
+func cancelReason(ctx context.Context, req interface{}) string {
+	if withReason, ok := req.(interface{ GetReason() string }); ok {
+		if reason := normalizeCancelReason(withReason.GetReason()); reason != "" {
+			return reason
+		}
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get(CancelReasonMetadataKey) {
+			if reason := normalizeCancelReason(value); reason != "" {
+				return reason
+			}
+		}
+	}
+
+	return defaultCancelReason
+}
+
+func normalizeCancelReason(reason string) string {
+	reason = strings.TrimSpace(reason)
+	if runes := []rune(reason); len(runes) > maxCancelReasonLength {
+		reason = strings.TrimSpace(string(runes[:maxCancelReasonLength]))
+	}
+	return reason
+}
+
+// SetCancellationReasonHeader envía la razón de cancelación como header de la
+// respuesta cuando el evento está cancelado y la tiene registrada
+func SetCancellationReasonHeader(ctx context.Context, event *entities.Event) {
+	if md := cancellationReasonHeader(event); md != nil {
+		_ = grpc.SetHeader(ctx, md)
+	}
+}
+
+func cancellationReasonHeader(event *entities.Event) metadata.MD {
+	if event == nil || !event.IsCancelled() || event.CancellationReason == nil {
+		return nil
+	}
+	return metadata.Pairs(CancellationReasonHeaderKey, *event.CancellationReason)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"google.golang.org/grpc/metadata"
+)
+
+type reasonRequest struct{ reason string }
+
+func (r reasonRequest) GetReason() string { return r.reason }
+
+func TestCancelReason(t *testing.T) {
+	withMetadata := func(reason string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(CancelReasonMetadataKey, reason))
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  interface{}
+		want string
+	}{
+		{"no reason", context.Background(), struct{}{}, defaultCancelReason},
+		{"request reason", context.Background(), reasonRequest{"  venue flooded "}, "venue flooded"},
+		{"metadata reason", withMetadata("artist ill"), struct{}{}, "artist ill"},
+		{"request wins over metadata", withMetadata("artist ill"), reasonRequest{"venue flooded"}, "venue flooded"},
+		{"blank request falls back to metadata", withMetadata("artist ill"), reasonRequest{"   "}, "artist ill"},
+		{"blank metadata falls back to default", withMetadata("  "), reasonRequest{""}, defaultCancelReason},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cancelReason(tt.ctx, tt.req); got != tt.want {
+				t.Errorf("cancelReason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelReasonTruncatesLongReasons(t *testing.T) {
+	long := strings.Repeat("ñ", maxCancelReasonLength+20)
+	got := cancelReason(context.Background(), reasonRequest{long})
+	if n := len([]rune(got)); n != maxCancelReasonLength {
+		t.Errorf("reason length = %d runes, want %d", n, maxCancelReasonLength)
+	}
+}
+
+func TestCancellationReasonHeader(t *testing.T) {
+	reason := "venue flooded"
+
+	tests := []struct {
+		name  string
+		event *entities.Event
+		want  []string
+	}{
+		{"nil event", nil, nil},
+		{"published event", &entities.Event{Status: "published", CancellationReason: &reason}, nil},
+		{"cancelled without reason", &entities.Event{Status: "cancelled"}, nil},
+		{"cancelled with reason", &entities.Event{Status: "cancelled", CancellationReason: &reason}, []string{reason}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cancellationReasonHeader(tt.event).Get(CancellationReasonHeaderKey)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || len(got) != len(tt.want) {
+				t.Errorf("header = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
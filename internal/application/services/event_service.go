@@ -5,11 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/franciscozamorau/osmi-server/internal/api/dto"
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	eventdto "github.com/franciscozamorau/osmi-server/internal/api/dto/event"
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
 	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
@@ -22,6 +24,9 @@ type EventService struct {
 	venueRepo      repository.VenueRepository
 	categoryRepo   repository.CategoryRepository
 	ticketTypeRepo repository.TicketTypeRepository
+
+	// eventTypes son los event_type aceptados; nil no valida
+	eventTypes map[string]struct{}
 }
 
 func NewEventService(
@@ -58,6 +63,11 @@ func (s *EventService) CreateEvent(ctx context.Context, req *eventdto.CreateEven
 		venueID = &venue.ID
 	}
 
+	eventType, err := s.normalizeEventType(req.EventType)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validar categoría primaria
 	var primaryCategoryID *int64
 	if req.PrimaryCategoryID != "" {
@@ -122,6 +132,16 @@ func (s *EventService) CreateEvent(ctx context.Context, req *eventdto.CreateEven
 		ageRestriction = &age
 	}
 
+	var settings *entities.EventSettings
+	if req.TicketCodePrefix != "" {
+		if err := entities.ValidateTicketCodePrefix(req.TicketCodePrefix); err != nil {
+			return nil, err
+		}
+		defaults := entities.GetDefaultSettings()
+		defaults.TicketCodePrefix = strings.ToUpper(req.TicketCodePrefix)
+		settings = &defaults
+	}
+
 	// Crear evento con conversiones de tipos correctas
 	event := &entities.Event{
 		PublicID:            uuid.New().String(),
@@ -132,7 +152,7 @@ func (s *EventService) CreateEvent(ctx context.Context, req *eventdto.CreateEven
 		Slug:                req.Slug,
 		ShortDescription:    stringPtr(req.ShortDescription),
 		Description:         stringPtr(req.Description),
-		EventType:           stringPtr(req.EventType),
+		EventType:           stringPtr(eventType),
 		CoverImageURL:       stringPtr(req.CoverImageURL),
 		BannerImageURL:      stringPtr(req.BannerImageURL),
 		GalleryImages:       nil,
@@ -157,7 +177,7 @@ func (s *EventService) CreateEvent(ctx context.Context, req *eventdto.CreateEven
 		RequiresApproval:    req.RequiresApproval,
 		AllowReservations:   req.AllowReservations,
 		ReservationDuration: int(req.ReservationDuration),
-		Settings:            nil,
+		Settings:            settings,
 		CreatedAt:           time.Now(),
 		UpdatedAt:           time.Now(),
 	}
@@ -207,6 +227,13 @@ func (s *EventService) UpdateEvent(ctx context.Context, eventID string, req *eve
 	if req.Description != nil {
 		event.Description = req.Description
 	}
+	if req.EventType != nil {
+		eventType, err := s.normalizeEventType(*req.EventType)
+		if err != nil {
+			return nil, err
+		}
+		event.EventType = &eventType
+	}
 	if req.Status != nil {
 		// Validar transición de estado
 		if !isValidEventStatusTransition(event.Status, *req.Status) {
@@ -251,6 +278,17 @@ func (s *EventService) UpdateEvent(ctx context.Context, eventID string, req *eve
 	if req.Timezone != nil {
 		event.Timezone = *req.Timezone
 	}
+	if req.TicketCodePrefix != nil {
+		// Cadena vacía vuelve al prefijo por defecto del servidor
+		if *req.TicketCodePrefix != "" {
+			if err := entities.ValidateTicketCodePrefix(*req.TicketCodePrefix); err != nil {
+				return nil, err
+			}
+		}
+		settings := event.GetSettings()
+		settings.TicketCodePrefix = strings.ToUpper(*req.TicketCodePrefix)
+		event.Settings = &settings
+	}
 
 	event.UpdatedAt = time.Now()
 
@@ -261,7 +299,8 @@ func (s *EventService) UpdateEvent(ctx context.Context, eventID string, req *eve
 	return event, nil
 }
 
-// PublishEvent publica un evento (lo hace visible para ventas)
+// PublishEvent publica un evento (lo hace visible para ventas). El actor del
+// historial es el usuario autenticado del contexto.
 func (s *EventService) PublishEvent(ctx context.Context, eventID string, publishAt *time.Time) (*entities.Event, error) {
 	event, err := s.eventRepo.GetByPublicID(ctx, eventID)
 	if err != nil {
@@ -278,19 +317,40 @@ func (s *EventService) PublishEvent(ctx context.Context, eventID string, publish
 		return nil, errors.New("event must have at least one active ticket type to be published")
 	}
 
-	event.Status = string(enums.EventStatusPublished)
+	publishedAt := time.Now()
 	if publishAt != nil {
-		event.PublishedAt = publishAt
-	} else {
-		now := time.Now()
-		event.PublishedAt = &now
+		publishedAt = *publishAt
 	}
-	event.UpdatedAt = time.Now()
 
-	if err := s.eventRepo.Update(ctx, event); err != nil {
+	if err := s.eventRepo.Publish(ctx, event.ID, publishedAt, appctx.UserIDFromContext(ctx)); err != nil {
 		return nil, fmt.Errorf("failed to publish event: %w", err)
 	}
 
+	event.Status = string(enums.EventStatusPublished)
+	event.PublishedAt = &publishedAt
+	event.UpdatedAt = time.Now()
+
+	return event, nil
+}
+
+// UnpublishEvent regresa un evento publicado a borrador
+func (s *EventService) UnpublishEvent(ctx context.Context, eventID string) (*entities.Event, error) {
+	event, err := s.eventRepo.GetByPublicID(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("event not found: %w", err)
+	}
+
+	if event.Status != string(enums.EventStatusPublished) {
+		return nil, errors.New("event is not published")
+	}
+
+	if err := s.eventRepo.Unpublish(ctx, event.ID, appctx.UserIDFromContext(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to unpublish event: %w", err)
+	}
+
+	event.Status = string(enums.EventStatusDraft)
+	event.UpdatedAt = time.Now()
+
 	return event, nil
 }
 
@@ -315,13 +375,14 @@ func (s *EventService) CancelEvent(ctx context.Context, eventID string, reason s
 		}
 	}
 
-	event.Status = string(enums.EventStatusCancelled)
-	event.UpdatedAt = time.Now()
-
-	if err := s.eventRepo.Update(ctx, event); err != nil {
+	if err := s.eventRepo.Cancel(ctx, event.ID, reason); err != nil {
 		return nil, fmt.Errorf("failed to cancel event: %w", err)
 	}
 
+	event.Status = string(enums.EventStatusCancelled)
+	event.CancellationReason = &reason
+	event.UpdatedAt = time.Now()
+
 	return event, nil
 }
 
@@ -354,6 +415,13 @@ func (s *EventService) ListEvents(ctx context.Context, filter eventdto.EventFilt
 	if filter.CategoryID != nil {
 		dbFilter["category_id"] = *filter.CategoryID
 	}
+	if filter.EventType != nil {
+		eventType, err := s.normalizeEventType(*filter.EventType)
+		if err != nil {
+			return nil, 0, err
+		}
+		dbFilter["event_type"] = eventType
+	}
 	if filter.Status != nil {
 		dbFilter["status"] = filter.Status
 	}
@@ -411,12 +479,15 @@ func (s *EventService) GetEventStats(ctx context.Context, eventID string) (*dto.
 	}
 
 	var ticketsSold, totalRevenue float64
-	var totalCapacity int64
+	var ticketsAvailable int64
 
 	for _, tt := range ticketTypes {
 		ticketsSold += float64(tt.SoldQuantity)
 		totalRevenue += float64(tt.SoldQuantity) * tt.BasePrice
-		totalCapacity += int64(tt.TotalQuantity)
+		// La disponibilidad (total - vendidos - reservados) se calcula sólo en la entidad
+		if available := tt.GetAvailableQuantity(); available > 0 {
+			ticketsAvailable += int64(available)
+		}
 	}
 
 	avgTicketPrice := 0.0
@@ -424,12 +495,6 @@ func (s *EventService) GetEventStats(ctx context.Context, eventID string) (*dto.
 		avgTicketPrice = totalRevenue / ticketsSold
 	}
 
-	// Tickets disponibles = capacidad total - vendidos
-	ticketsAvailable := totalCapacity - int64(ticketsSold)
-	if ticketsAvailable < 0 {
-		ticketsAvailable = 0
-	}
-
 	return &dto.EventStatsResponse{
 		TicketsSold:      int64(ticketsSold),
 		TicketsAvailable: ticketsAvailable,
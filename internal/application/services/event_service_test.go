@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+// publishEventRepo implementa sólo lo que usan PublishEvent y UnpublishEvent;
+// el resto de la interfaz queda sin implementar (nil embebido)
+type publishEventRepo struct {
+	repository.EventRepository
+	event  *entities.Event
+	actors []string
+}
+
+func (r *publishEventRepo) GetByPublicID(context.Context, string) (*entities.Event, error) {
+	copied := *r.event
+	return &copied, nil
+}
+
+func (r *publishEventRepo) Publish(_ context.Context, _ int64, _ time.Time, actor string) error {
+	r.actors = append(r.actors, actor)
+	r.event.Status = string(enums.EventStatusPublished)
+	return nil
+}
+
+func (r *publishEventRepo) Unpublish(_ context.Context, _ int64, actor string) error {
+	r.actors = append(r.actors, actor)
+	r.event.Status = string(enums.EventStatusDraft)
+	return nil
+}
+
+type activeTicketTypesRepo struct {
+	repository.TicketTypeRepository
+}
+
+func (activeTicketTypesRepo) FindByEvent(context.Context, int64, bool) ([]*entities.TicketType, error) {
+	return []*entities.TicketType{{ID: 1}}, nil
+}
+
+func TestEventServicePublishRecordsActorFromContext(t *testing.T) {
+	repo := &publishEventRepo{event: &entities.Event{ID: 7, Status: string(enums.EventStatusDraft)}}
+	s := NewEventService(repo, nil, nil, nil, activeTicketTypesRepo{})
+
+	ctx := appctx.WithUserID(context.Background(), "user-123")
+	if _, err := s.PublishEvent(ctx, "evt", nil); err != nil {
+		t.Fatalf("PublishEvent: %v", err)
+	}
+	if _, err := s.UnpublishEvent(ctx, "evt"); err != nil {
+		t.Fatalf("UnpublishEvent: %v", err)
+	}
+	// Sin usuario autenticado el actor queda vacío
+	if _, err := s.PublishEvent(context.Background(), "evt", nil); err != nil {
+		t.Fatalf("anonymous PublishEvent: %v", err)
+	}
+
+	want := []string{"user-123", "user-123", ""}
+	if len(repo.actors) != len(want) {
+		t.Fatalf("actors = %q, want %q", repo.actors, want)
+	}
+	for i := range want {
+		if repo.actors[i] != want[i] {
+			t.Errorf("actor %d = %q, want %q", i, repo.actors[i], want[i])
+		}
+	}
+}
+
+// statsTicketTypesRepo devuelve tipos de ticket fijos para GetEventStats
+type statsTicketTypesRepo struct {
+	repository.TicketTypeRepository
+	types []*entities.TicketType
+}
+
+func (r statsTicketTypesRepo) FindByEvent(context.Context, int64, bool) ([]*entities.TicketType, error) {
+	return r.types, nil
+}
+
+func TestEventServiceGetEventStatsSubtractsSoldOnce(t *testing.T) {
+	repo := &publishEventRepo{event: &entities.Event{ID: 7}}
+	types := statsTicketTypesRepo{types: []*entities.TicketType{
+		{ID: 1, TotalQuantity: 100, SoldQuantity: 30, BasePrice: 10},
+		{ID: 2, TotalQuantity: 50, SoldQuantity: 20, ReservedQuantity: 10, BasePrice: 20},
+		// Sobrevendido: no debe restar disponibilidad a los demás tipos
+		{ID: 3, TotalQuantity: 5, SoldQuantity: 6, BasePrice: 5},
+	}}
+	s := NewEventService(repo, nil, nil, nil, types)
+
+	stats, err := s.GetEventStats(context.Background(), "evt")
+	if err != nil {
+		t.Fatalf("GetEventStats: %v", err)
+	}
+	if stats.TicketsAvailable != 70+20 {
+		t.Errorf("TicketsAvailable = %d, want 90", stats.TicketsAvailable)
+	}
+	if stats.TicketsSold != 56 {
+		t.Errorf("TicketsSold = %d, want 56", stats.TicketsSold)
+	}
+
+	single := statsTicketTypesRepo{types: []*entities.TicketType{{ID: 1, TotalQuantity: 100, SoldQuantity: 30}}}
+	stats, err = NewEventService(repo, nil, nil, nil, single).GetEventStats(context.Background(), "evt")
+	if err != nil {
+		t.Fatalf("GetEventStats: %v", err)
+	}
+	if stats.TicketsAvailable != 70 {
+		t.Errorf("TicketsAvailable = %d, want 70", stats.TicketsAvailable)
+	}
+}
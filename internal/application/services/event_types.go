@@ -0,0 +1,42 @@
+// internal/application/services/event_types.go
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidEventType indica un event_type fuera de la lista permitida
+var ErrInvalidEventType = errors.New("event type not allowed")
+
+// SetEventTypeAllowlist fija los event_type que acepta el servicio (sin
+// distinguir mayúsculas); una lista vacía desactiva la validación
+func (s *EventService) SetEventTypeAllowlist(types []string) {
+	if len(types) == 0 {
+		s.eventTypes = nil
+		return
+	}
+
+	allowlist := make(map[string]struct{}, len(types))
+	for _, t := range types {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			allowlist[t] = struct{}{}
+		}
+	}
+	s.eventTypes = allowlist
+}
+
+// normalizeEventType valida eventType contra la lista permitida y lo devuelve
+// normalizado en minúsculas. Sin lista configurada lo devuelve sin cambios.
+func (s *EventService) normalizeEventType(eventType string) (string, error) {
+	if s.eventTypes == nil {
+		return eventType, nil
+	}
+
+	normalized := strings.ToLower(strings.TrimSpace(eventType))
+	if _, ok := s.eventTypes[normalized]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidEventType, eventType)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
+	eventdto "github.com/franciscozamorau/osmi-server/internal/api/dto/event"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+func TestEventServiceNormalizeEventType(t *testing.T) {
+	s := NewEventService(nil, nil, nil, nil, nil)
+
+	// Sin lista configurada no se valida ni se normaliza
+	if got, err := s.normalizeEventType("Anything"); err != nil || got != "Anything" {
+		t.Errorf("without allowlist = %q, %v; want the value unchanged", got, err)
+	}
+
+	s.SetEventTypeAllowlist([]string{" Concert", "conference", ""})
+	if got, err := s.normalizeEventType(" CONCERT "); err != nil || got != "concert" {
+		t.Errorf("allowed type = %q, %v; want concert", got, err)
+	}
+	if _, err := s.normalizeEventType("in_person"); !errors.Is(err, ErrInvalidEventType) {
+		t.Errorf("unknown type: err = %v, want ErrInvalidEventType", err)
+	}
+
+	// Cada servicio lleva su propia lista
+	if got, err := NewEventService(nil, nil, nil, nil, nil).normalizeEventType("in_person"); err != nil || got != "in_person" {
+		t.Errorf("other service = %q, %v; want its own empty allowlist", got, err)
+	}
+
+	s.SetEventTypeAllowlist(nil)
+	if _, err := s.normalizeEventType("in_person"); err != nil {
+		t.Errorf("cleared allowlist: %v", err)
+	}
+}
+
+type eventTypeOrganizerRepo struct {
+	repository.OrganizerRepository
+}
+
+func (eventTypeOrganizerRepo) FindByPublicID(context.Context, string) (*entities.Organizer, error) {
+	return &entities.Organizer{ID: 1}, nil
+}
+
+// listFilterEventRepo guarda el filtro que recibe List
+type listFilterEventRepo struct {
+	repository.EventRepository
+	filter map[string]interface{}
+}
+
+func (r *listFilterEventRepo) List(_ context.Context, filter map[string]interface{}, _, _ int) ([]*entities.Event, int64, error) {
+	r.filter = filter
+	return nil, 0, nil
+}
+
+func TestEventServiceEnforcesEventTypeAllowlist(t *testing.T) {
+	ctx := context.Background()
+	repo := &listFilterEventRepo{}
+	s := NewEventService(repo, eventTypeOrganizerRepo{}, nil, nil, nil)
+	s.SetEventTypeAllowlist([]string{"concert", "conference"})
+
+	_, err := s.CreateEvent(ctx, &eventdto.CreateEventRequest{OrganizerID: "org", EventType: "hybrid"})
+	if !errors.Is(err, ErrInvalidEventType) {
+		t.Errorf("CreateEvent: err = %v, want ErrInvalidEventType", err)
+	}
+
+	page := commondto.Pagination{Page: 1, PageSize: 10}
+	eventType := "Concert"
+	if _, _, err := s.ListEvents(ctx, eventdto.EventFilter{EventType: &eventType}, page); err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if repo.filter["event_type"] != "concert" {
+		t.Errorf("list filter event_type = %v, want concert", repo.filter["event_type"])
+	}
+
+	eventType = "hybrid"
+	if _, _, err := s.ListEvents(ctx, eventdto.EventFilter{EventType: &eventType}, page); !errors.Is(err, ErrInvalidEventType) {
+		t.Errorf("ListEvents with unknown type: err = %v, want ErrInvalidEventType", err)
+	}
+}
@@ -0,0 +1,132 @@
+// internal/application/services/event_update.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+// ErrInvalidEventUpdate indica una petición de UpdateEvent mal formada
+// (ID, fechas o rango de fechas inválidos)
+var ErrInvalidEventUpdate = errors.New("invalid event update")
+
+// ErrInvalidEventStatusTransition indica un cambio de estado no permitido por
+// enums.EventStatus.CanTransitionTo
+var ErrInvalidEventStatusTransition = errors.New("invalid event status transition")
+
+// UpdateEventRequest es la petición de UpdateEvent: el ID público del evento y
+// los campos de osmi.EventRequest a cambiar. Los campos vacíos no se tocan.
+type UpdateEventRequest struct {
+	PublicId         string
+	Name             string
+	Description      string
+	ShortDescription string
+	StartDate        string // RFC3339
+	EndDate          string // RFC3339
+	Location         string
+	VenueDetails     string
+	Tags             []string
+	ImageUrl         string
+	BannerUrl        string
+	MaxAttendees     int32
+	Status           string
+}
+
+// updateEvent carga el evento req.PublicId, le aplica los campos no vacíos de
+// req, lo guarda y devuelve la versión releída del repositorio
+func updateEvent(ctx context.Context, events repository.EventRepository, req *UpdateEventRequest) (*entities.Event, error) {
+	if !isValidUUID(req.PublicId) {
+		return nil, fmt.Errorf("%w: invalid event ID format: must be a valid UUID", ErrInvalidEventUpdate)
+	}
+
+	event, err := events.GetByPublicID(ctx, req.PublicId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event %s: %w", req.PublicId, err)
+	}
+
+	if err := applyEventUpdate(event, req); err != nil {
+		return nil, err
+	}
+	event.UpdatedAt = time.Now()
+
+	if err := events.Update(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to update event: %w", err)
+	}
+
+	updated, err := events.GetByPublicID(ctx, req.PublicId)
+	if err != nil {
+		return nil, fmt.Errorf("event updated but retrieval failed: %w", err)
+	}
+	return updated, nil
+}
+
+// applyEventUpdate copia sobre event los campos no vacíos de req; los opcionales
+// que no vienen (descripción, banner, etc.) se conservan. El rango de fechas se
+// valida con el resultado y el cambio de estado con CanTransitionTo.
+func applyEventUpdate(event *entities.Event, req *UpdateEventRequest) error {
+	if name := strings.TrimSpace(req.Name); name != "" {
+		event.Name = name
+	}
+	if req.Description != "" {
+		description := req.Description
+		event.Description = &description
+	}
+	if req.ShortDescription != "" {
+		shortDescription := req.ShortDescription
+		event.ShortDescription = &shortDescription
+	}
+	if req.StartDate != "" {
+		startsAt, err := time.Parse(time.RFC3339, req.StartDate)
+		if err != nil {
+			return fmt.Errorf("%w: invalid start_date format: %v", ErrInvalidEventUpdate, err)
+		}
+		event.StartsAt = startsAt
+	}
+	if req.EndDate != "" {
+		endsAt, err := time.Parse(time.RFC3339, req.EndDate)
+		if err != nil {
+			return fmt.Errorf("%w: invalid end_date format: %v", ErrInvalidEventUpdate, err)
+		}
+		event.EndsAt = endsAt
+	}
+	if !isDateRangeValid(event.StartsAt, event.EndsAt) {
+		return fmt.Errorf("%w: end_date cannot be before start_date", ErrInvalidEventUpdate)
+	}
+	if location := strings.TrimSpace(req.Location); location != "" {
+		event.VenueName = &location
+	}
+	if req.VenueDetails != "" {
+		venueDetails := req.VenueDetails
+		event.AddressFull = &venueDetails
+	}
+	if len(req.Tags) > 0 {
+		tags := append([]string(nil), req.Tags...)
+		event.Tags = &tags
+	}
+	if req.ImageUrl != "" {
+		imageURL := req.ImageUrl
+		event.CoverImageURL = &imageURL
+	}
+	if req.BannerUrl != "" {
+		bannerURL := req.BannerUrl
+		event.BannerImageURL = &bannerURL
+	}
+	if req.MaxAttendees > 0 {
+		maxAttendees := int(req.MaxAttendees)
+		event.MaxAttendees = &maxAttendees
+	}
+	if req.Status != "" && req.Status != event.Status {
+		if !enums.EventStatus(event.Status).CanTransitionTo(enums.EventStatus(req.Status)) {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidEventStatusTransition, event.Status, req.Status)
+		}
+		event.Status = req.Status
+	}
+	return nil
+}
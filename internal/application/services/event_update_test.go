@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+const updateEventID = "3f1c9a52-8d4e-4b7a-9c1d-2e5f6a7b8c9d"
+
+// updateEventRepo guarda el evento en memoria; el resto de la interfaz queda
+// sin implementar (nil embebido)
+type updateEventRepo struct {
+	repository.EventRepository
+	event   *entities.Event
+	updates int
+}
+
+func (r *updateEventRepo) GetByPublicID(_ context.Context, publicID string) (*entities.Event, error) {
+	if r.event == nil || r.event.PublicID != publicID {
+		return nil, repository.ErrEventNotFound
+	}
+	copied := *r.event
+	return &copied, nil
+}
+
+func (r *updateEventRepo) Update(_ context.Context, event *entities.Event) error {
+	r.updates++
+	copied := *event
+	r.event = &copied
+	return nil
+}
+
+func newUpdateEventRepo() *updateEventRepo {
+	description := "Descripción original"
+	banner := "https://cdn.example/banner.png"
+	venue := "Foro Sol"
+	return &updateEventRepo{event: &entities.Event{
+		ID:             1,
+		PublicID:       updateEventID,
+		Name:           "Concierto",
+		Description:    &description,
+		BannerImageURL: &banner,
+		VenueName:      &venue,
+		Status:         string(enums.EventStatusDraft),
+		StartsAt:       time.Date(2026, 12, 1, 20, 0, 0, 0, time.UTC),
+		EndsAt:         time.Date(2026, 12, 1, 23, 0, 0, 0, time.UTC),
+	}}
+}
+
+func TestUpdateEventKeepsOmittedFields(t *testing.T) {
+	repo := newUpdateEventRepo()
+
+	got, err := updateEvent(context.Background(), repo, &UpdateEventRequest{
+		PublicId: updateEventID,
+		Name:     "  Concierto de gala ",
+		EndDate:  "2026-12-02T01:00:00Z",
+		Status:   string(enums.EventStatusPublished),
+	})
+	if err != nil {
+		t.Fatalf("updateEvent: %v", err)
+	}
+	if repo.updates != 1 {
+		t.Errorf("Update called %d times, want 1", repo.updates)
+	}
+	if got.Name != "Concierto de gala" || got.Status != string(enums.EventStatusPublished) {
+		t.Errorf("name/status = %q/%q, want the requested values", got.Name, got.Status)
+	}
+	if !got.EndsAt.Equal(time.Date(2026, 12, 2, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("EndsAt = %v, want 2026-12-02T01:00:00Z", got.EndsAt)
+	}
+	if got.Description == nil || *got.Description != "Descripción original" {
+		t.Errorf("Description = %v, want it kept", got.Description)
+	}
+	if got.BannerImageURL == nil || *got.BannerImageURL != "https://cdn.example/banner.png" {
+		t.Errorf("BannerImageURL = %v, want it kept", got.BannerImageURL)
+	}
+	if got.VenueName == nil || *got.VenueName != "Foro Sol" {
+		t.Errorf("VenueName = %v, want it kept", got.VenueName)
+	}
+}
+
+func TestUpdateEventRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		req  UpdateEventRequest
+		want error
+	}{
+		{"malformed id", UpdateEventRequest{PublicId: "evt-1"}, ErrInvalidEventUpdate},
+		{"unknown event", UpdateEventRequest{PublicId: "0b7e2f1a-3c4d-4e5f-8a9b-1c2d3e4f5a6b"}, repository.ErrEventNotFound},
+		{"unparseable start", UpdateEventRequest{PublicId: updateEventID, StartDate: "tomorrow"}, ErrInvalidEventUpdate},
+		{"end before start", UpdateEventRequest{PublicId: updateEventID, EndDate: "2026-11-30T20:00:00Z"}, ErrInvalidEventUpdate},
+		{"invalid transition", UpdateEventRequest{PublicId: updateEventID, Status: string(enums.EventStatusCompleted)}, ErrInvalidEventStatusTransition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newUpdateEventRepo()
+			if _, err := updateEvent(context.Background(), repo, &tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if repo.updates != 0 {
+				t.Errorf("Update called %d times for a rejected request", repo.updates)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// internal/application/services/notification_sender.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/httpclient"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/utils"
+)
+
+// NotificationProvider es el proveedor externo (email, sms, push).
+// Send debe deduplicar por idempotencyKey y Lookup devuelve el id del mensaje
+// ya aceptado para esa llave, si existe.
+type NotificationProvider interface {
+	Send(ctx context.Context, notification *entities.Notification, idempotencyKey string) (string, error)
+	Lookup(ctx context.Context, idempotencyKey string) (providerMessageID string, found bool, err error)
+}
+
+// defaultNotificationRetryDelay se usa si la notificación no trae retry_delay
+const defaultNotificationRetryDelay = time.Minute
+
+// deadLetterErrorCode marca las notificaciones que agotaron sus reintentos
+const deadLetterErrorCode = "dead_letter"
+
+// NotificationSender ejecuta el paso de envío de forma idempotente: si el worker
+// cae después de enviar pero antes de MarkAsSent, el reintento consulta al
+// proveedor con la misma llave en lugar de reenviar.
+type NotificationSender struct {
+	outbox         repository.NotificationOutbox
+	provider       NotificationProvider
+	interval       time.Duration
+	batchSize      int
+	sendingTimeout time.Duration
+	logger         *utils.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewNotificationSender crea el worker; no corre hasta llamar a Start.
+// sendingTimeout es cuánto puede quedar un mensaje en "sending" antes de
+// reclamarlo de nuevo: debe superar el timeout de una petición al proveedor.
+func NewNotificationSender(
+	outbox repository.NotificationOutbox,
+	provider NotificationProvider,
+	interval time.Duration,
+	batchSize int,
+	sendingTimeout time.Duration,
+	logger *utils.Logger,
+) *NotificationSender {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+	if sendingTimeout <= 0 {
+		sendingTimeout = 5 * time.Minute
+	}
+	if logger == nil {
+		logger = utils.GlobalLogger
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &NotificationSender{
+		outbox:         outbox,
+		provider:       provider,
+		interval:       interval,
+		batchSize:      batchSize,
+		sendingTimeout: sendingTimeout,
+		logger:         logger,
+		ctx:            ctx,
+		cancel:         cancel,
+		done:           make(chan struct{}),
+	}
+}
+
+// Start lanza la goroutine que reclama y envía notificaciones
+func (s *NotificationSender) Start() {
+	go s.run()
+}
+
+func (s *NotificationSender) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.poll()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// poll reclama un lote y lo entrega. Los errores de envío ya quedaron
+// registrados en el mensaje; aquí sólo se cuentan.
+func (s *NotificationSender) poll() {
+	claimed, err := s.outbox.ClaimDue(s.ctx, s.batchSize, s.sendingTimeout)
+	if err != nil {
+		if s.ctx.Err() == nil {
+			s.logger.Error("failed to claim notifications", err)
+		}
+		return
+	}
+
+	var failed int
+	for _, notification := range claimed {
+		if s.ctx.Err() != nil {
+			return
+		}
+		if err := s.Deliver(s.ctx, notification); err != nil {
+			failed++
+		}
+	}
+	if len(claimed) > 0 {
+		s.logger.Info("processed notifications", map[string]interface{}{
+			"claimed": len(claimed),
+			"failed":  failed,
+		})
+	}
+}
+
+// Stop detiene el worker y espera a que termine el lote en curso o a que ctx
+// expire. Es seguro llamarlo más de una vez.
+func (s *NotificationSender) Stop(ctx context.Context) error {
+	s.once.Do(s.cancel)
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// notificationIdempotencyKey es estable entre reintentos de la misma notificación
+func notificationIdempotencyKey(notification *entities.Notification) string {
+	return "osmi-notification-" + strconv.FormatInt(notification.ID, 10)
+}
+
+// Deliver envía una notificación ya reclamada por el worker. notification.Status
+// es el estado previo al reclamo: "sending" indica un intento interrumpido.
+func (s *NotificationSender) Deliver(ctx context.Context, notification *entities.Notification) error {
+	// Ya quedó registrado el envío: nada que hacer
+	if notification.ProviderMessageID != nil && *notification.ProviderMessageID != "" {
+		return nil
+	}
+
+	key := notificationIdempotencyKey(notification)
+
+	// Un intento previo quedó en "sending": pudo haberse enviado antes de la caída
+	if enums.NotificationStatus(notification.Status) == enums.NotificationStatusSending {
+		messageID, found, err := s.provider.Lookup(ctx, key)
+		if err != nil {
+			return s.handleSendError(ctx, notification, err)
+		}
+		if found {
+			return s.markSent(ctx, notification, messageID)
+		}
+	} else if err := s.outbox.UpdateStatus(ctx, notification.ID, string(enums.NotificationStatusSending)); err != nil {
+		return fmt.Errorf("failed to mark notification as sending: %w", err)
+	}
+
+	messageID, err := s.provider.Send(ctx, notification, key)
+	if err != nil {
+		return s.handleSendError(ctx, notification, err)
+	}
+
+	return s.markSent(ctx, notification, messageID)
+}
+
+func (s *NotificationSender) markSent(ctx context.Context, notification *entities.Notification, messageID string) error {
+	sentAt := time.Now().UTC().Format(time.RFC3339)
+	if err := s.outbox.MarkAsSent(ctx, notification.ID, sentAt, messageID); err != nil {
+		return fmt.Errorf("failed to mark notification as sent: %w", err)
+	}
+	notification.MarkAsSent(messageID, nil)
+	return nil
+}
+
+// handleSendError reprograma con backoff los errores reintentables y manda a
+// dead-letter los definitivos o los que agotaron max_attempts
+func (s *NotificationSender) handleSendError(ctx context.Context, notification *entities.Notification, sendErr error) error {
+	if err := s.outbox.IncrementAttempts(ctx, notification.ID); err != nil {
+		return fmt.Errorf("failed to increment notification attempts: %w", err)
+	}
+	notification.Attempts++
+
+	if !errors.Is(sendErr, httpclient.ErrRetryable) || notification.Attempts >= notification.MaxAttempts {
+		if err := s.outbox.MarkAsFailed(ctx, notification.ID, sendErr.Error(), deadLetterErrorCode); err != nil {
+			return fmt.Errorf("failed to dead-letter notification: %w", err)
+		}
+		return fmt.Errorf("notification %d dead-lettered after %d attempts: %w", notification.ID, notification.Attempts, sendErr)
+	}
+
+	baseDelay := time.Duration(notification.RetryDelay) * time.Second
+	if baseDelay <= 0 {
+		baseDelay = defaultNotificationRetryDelay
+	}
+	delay := enums.NotificationStatusFailed.GetNextRetryDelay(notification.Attempts, baseDelay)
+	nextRetryAt := time.Now().Add(delay).UTC().Format(time.RFC3339)
+
+	if err := s.outbox.AddErrorToHistory(ctx, notification.ID, sendErr.Error(), "retryable"); err != nil {
+		return fmt.Errorf("failed to record notification error: %w", err)
+	}
+	if err := s.outbox.SetNextRetry(ctx, notification.ID, nextRetryAt); err != nil {
+		return fmt.Errorf("failed to schedule notification retry: %w", err)
+	}
+
+	return fmt.Errorf("notification %d send failed, retry scheduled at %s: %w", notification.ID, nextRetryAt, sendErr)
+}
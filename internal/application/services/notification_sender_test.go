@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/httpclient"
+)
+
+// fakeOutbox guarda los mensajes en memoria con la misma semántica de ClaimDue
+// que el repositorio de postgres
+type fakeOutbox struct {
+	rows          map[int64]*entities.Notification
+	failMarkSent  int // cuántas llamadas a MarkAsSent fallan (simula la caída)
+	deadLettered  map[int64]string
+	now           time.Time
+	lastClaimedAt map[int64]time.Time
+}
+
+func newFakeOutbox(rows ...*entities.Notification) *fakeOutbox {
+	o := &fakeOutbox{
+		rows:          map[int64]*entities.Notification{},
+		deadLettered:  map[int64]string{},
+		now:           time.Now(),
+		lastClaimedAt: map[int64]time.Time{},
+	}
+	for _, n := range rows {
+		o.rows[n.ID] = n
+	}
+	return o
+}
+
+func (o *fakeOutbox) ClaimDue(_ context.Context, limit int, sendingTimeout time.Duration) ([]*entities.Notification, error) {
+	var claimed []*entities.Notification
+	for id := int64(1); id <= int64(len(o.rows)) && len(claimed) < limit; id++ {
+		n := o.rows[id]
+		due := false
+		switch enums.NotificationStatus(n.Status) {
+		case enums.NotificationStatusPending, enums.NotificationStatusRetrying:
+			due = n.NextRetryAt == nil || !n.NextRetryAt.After(o.now)
+		case enums.NotificationStatusSending:
+			due = o.now.Sub(o.lastClaimedAt[id]) > sendingTimeout
+		}
+		if !due {
+			continue
+		}
+		copied := *n
+		claimed = append(claimed, &copied)
+		n.Status = string(enums.NotificationStatusSending)
+		o.lastClaimedAt[id] = o.now
+	}
+	return claimed, nil
+}
+
+func (o *fakeOutbox) UpdateStatus(_ context.Context, id int64, status string) error {
+	o.rows[id].Status = status
+	return nil
+}
+
+func (o *fakeOutbox) MarkAsSent(_ context.Context, id int64, _ string, providerMessageID string) error {
+	if o.failMarkSent > 0 {
+		o.failMarkSent--
+		return errors.New("connection reset")
+	}
+	o.rows[id].Status = string(enums.NotificationStatusSent)
+	o.rows[id].ProviderMessageID = &providerMessageID
+	return nil
+}
+
+func (o *fakeOutbox) MarkAsFailed(_ context.Context, id int64, _ string, errorCode string) error {
+	o.rows[id].Status = string(enums.NotificationStatusFailed)
+	o.deadLettered[id] = errorCode
+	return nil
+}
+
+func (o *fakeOutbox) IncrementAttempts(_ context.Context, id int64) error {
+	o.rows[id].Attempts++
+	return nil
+}
+
+func (o *fakeOutbox) SetNextRetry(_ context.Context, id int64, nextRetryAt string) error {
+	at, err := time.Parse(time.RFC3339, nextRetryAt)
+	if err != nil {
+		return err
+	}
+	o.rows[id].Status = string(enums.NotificationStatusRetrying)
+	o.rows[id].NextRetryAt = &at
+	return nil
+}
+
+func (o *fakeOutbox) AddErrorToHistory(context.Context, int64, string, string) error {
+	return nil
+}
+
+// fakeProvider deduplica por llave como exige NotificationProvider
+type fakeProvider struct {
+	accepted map[string]string
+	sends    int
+	errs     []error // errores a devolver en los siguientes Send, en orden
+}
+
+func newFakeProvider() *fakeProvider {
+	return &fakeProvider{accepted: map[string]string{}}
+}
+
+func (p *fakeProvider) Send(_ context.Context, _ *entities.Notification, key string) (string, error) {
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return "", err
+	}
+	p.sends++
+	id := fmt.Sprintf("msg-%d", p.sends)
+	p.accepted[key] = id
+	return id, nil
+}
+
+func (p *fakeProvider) Lookup(_ context.Context, key string) (string, bool, error) {
+	id, ok := p.accepted[key]
+	return id, ok, nil
+}
+
+func pendingNotification(id int64) *entities.Notification {
+	return &entities.Notification{
+		ID:          id,
+		Channel:     "email",
+		Status:      string(enums.NotificationStatusPending),
+		MaxAttempts: 3,
+		RetryDelay:  1,
+	}
+}
+
+func TestNotificationSenderRecoversCrashWithoutResending(t *testing.T) {
+	outbox := newFakeOutbox(pendingNotification(1))
+	provider := newFakeProvider()
+	sender := NewNotificationSender(outbox, provider, time.Second, 10, time.Minute, nil)
+
+	// El envío llega al proveedor pero el worker cae antes de MarkAsSent
+	outbox.failMarkSent = 1
+	sender.poll()
+	if provider.sends != 1 || outbox.rows[1].Status != string(enums.NotificationStatusSending) {
+		t.Fatalf("after crash: sends = %d, status = %s; want 1 send stuck in sending", provider.sends, outbox.rows[1].Status)
+	}
+
+	// Antes del timeout nadie vuelve a reclamarlo
+	sender.poll()
+	if provider.sends != 1 {
+		t.Fatalf("message reclaimed before sending timeout: sends = %d", provider.sends)
+	}
+
+	outbox.now = outbox.now.Add(2 * time.Minute)
+	sender.poll()
+
+	if provider.sends != 1 {
+		t.Errorf("recovery resent the notification: sends = %d, want 1", provider.sends)
+	}
+	row := outbox.rows[1]
+	if row.Status != string(enums.NotificationStatusSent) || row.ProviderMessageID == nil || *row.ProviderMessageID != "msg-1" {
+		t.Errorf("after recovery: status = %s, provider id = %v; want sent with msg-1", row.Status, row.ProviderMessageID)
+	}
+}
+
+func TestNotificationSenderRecoversCrashBeforeSend(t *testing.T) {
+	n := pendingNotification(1)
+	n.Status = string(enums.NotificationStatusSending)
+	outbox := newFakeOutbox(n)
+	provider := newFakeProvider()
+	sender := NewNotificationSender(outbox, provider, time.Second, 10, time.Minute, nil)
+
+	// Quedó en "sending" sin que el proveedor lo recibiera
+	outbox.lastClaimedAt[1] = outbox.now.Add(-2 * time.Minute)
+	sender.poll()
+
+	if provider.sends != 1 || outbox.rows[1].Status != string(enums.NotificationStatusSent) {
+		t.Errorf("sends = %d, status = %s; want it sent exactly once", provider.sends, outbox.rows[1].Status)
+	}
+}
+
+func TestNotificationSenderRetriesThenDeadLetters(t *testing.T) {
+	outbox := newFakeOutbox(pendingNotification(1))
+	provider := newFakeProvider()
+	retryable := fmt.Errorf("%w: status 503", httpclient.ErrRetryable)
+	provider.errs = []error{retryable, retryable, retryable}
+	sender := NewNotificationSender(outbox, provider, time.Second, 10, time.Minute, nil)
+
+	sender.poll()
+	row := outbox.rows[1]
+	if row.Status != string(enums.NotificationStatusRetrying) || row.NextRetryAt == nil {
+		t.Fatalf("after first failure: status = %s, next retry = %v; want retrying with a next retry", row.Status, row.NextRetryAt)
+	}
+
+	// El reintento respeta el backoff
+	sender.poll()
+	if row.Attempts != 1 {
+		t.Fatalf("retried before next_retry_at: attempts = %d", row.Attempts)
+	}
+
+	for i := 0; i < 2; i++ {
+		outbox.now = *row.NextRetryAt
+		sender.poll()
+	}
+
+	if row.Attempts != 3 || outbox.deadLettered[1] != deadLetterErrorCode {
+		t.Errorf("attempts = %d, dead letter = %q; want 3 attempts then %q", row.Attempts, outbox.deadLettered[1], deadLetterErrorCode)
+	}
+	if provider.sends != 0 {
+		t.Errorf("sends = %d, want 0", provider.sends)
+	}
+}
+
+func TestNotificationSenderDeadLettersPermanentErrors(t *testing.T) {
+	outbox := newFakeOutbox(pendingNotification(1))
+	provider := newFakeProvider()
+	provider.errs = []error{errors.New("delivery rejected: status 400")}
+	sender := NewNotificationSender(outbox, provider, time.Second, 10, time.Minute, nil)
+
+	sender.poll()
+
+	if outbox.rows[1].Attempts != 1 || outbox.deadLettered[1] != deadLetterErrorCode {
+		t.Errorf("attempts = %d, dead letter = %q; want dead-lettered on first attempt", outbox.rows[1].Attempts, outbox.deadLettered[1])
+	}
+}
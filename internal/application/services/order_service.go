@@ -2,7 +2,6 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
@@ -49,16 +48,24 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *orderdto.CreateOrde
 	var totalAmount float64
 	var tickets []*entities.Ticket
 
-	for _, item := range req.Items {
+	// Reservar todos los tipos juntos: el lote bloquea en orden de id y evita deadlocks
+	ticketTypes := make([]*entities.TicketType, len(req.Items))
+	quantities := make(map[int64]int, len(req.Items))
+	for i, item := range req.Items {
 		ticketType, err := s.ticketTypeRepo.FindByPublicID(ctx, item.TicketTypeID)
 		if err != nil {
 			return nil, nil, fmt.Errorf("ticket type not found: %w", err)
 		}
+		ticketTypes[i] = ticketType
+		quantities[ticketType.ID] += item.Quantity
+	}
 
-		available, err := s.ticketTypeRepo.CheckAvailability(ctx, ticketType.ID, item.Quantity)
-		if err != nil || !available {
-			return nil, nil, errors.New("not enough tickets available")
-		}
+	if err := s.ticketTypeRepo.ReserveTicketsBatchTx(ctx, tx, quantities); err != nil {
+		return nil, nil, err
+	}
+
+	for idx, item := range req.Items {
+		ticketType := ticketTypes[idx]
 
 		for i := 0; i < item.Quantity; i++ {
 			ticket := &entities.Ticket{
@@ -67,7 +74,7 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *orderdto.CreateOrde
 				EventID:              ticketType.EventID,
 				CustomerID:           &customer.ID,
 				Code:                 fmt.Sprintf("ORD-%d-%d-%s", ticketType.EventID, ticketType.ID, uuid.New().String()[:8]),
-				SecretHash:           uuid.New().String(),
+				SecretHash:           entities.NewTicketSecretHash(),
 				Status:               "reserved",
 				FinalPrice:           ticketType.BasePrice,
 				Currency:             ticketType.Currency,
@@ -85,11 +92,6 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *orderdto.CreateOrde
 
 			tickets = append(tickets, ticket)
 			totalAmount += ticket.FinalPrice
-
-			err = s.ticketTypeRepo.ReserveTicketsTx(ctx, tx, ticketType.ID, 1)
-			if err != nil {
-				return nil, nil, err
-			}
 		}
 	}
 
@@ -8,6 +8,7 @@ import (
 
 	paymentdto "github.com/franciscozamorau/osmi-server/internal/api/dto/payment"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
 	"github.com/franciscozamorau/osmi-server/internal/infrastructure/payment"
 	"github.com/stripe/stripe-go/v81"
@@ -89,17 +90,16 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req *paymentdto.Crea
 	pi, err := s.stripeClient.CreatePaymentIntent(amountCents, req.Currency, order.PublicID)
 	if err != nil {
 		payment.Status = "failed"
-		_ = s.paymentRepo.Update(ctx, payment)
+		_ = s.paymentRepo.UpdateStatus(ctx, payment.ID, payment.Status, nil)
 		return nil, fmt.Errorf("failed to create Stripe payment intent: %w", err)
 	}
 
-	// 7. Actualizar payment con datos de Stripe
-	payment.ProviderTransactionID = &pi.ID
-	payment.Status = "processing"
-
-	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+	// 7. Actualizar payment con datos de Stripe (el cambio de estado se valida en UpdateStatus)
+	if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, string(enums.PaymentStatusAuthorized), map[string]interface{}{"transaction_id": pi.ID}); err != nil {
 		return nil, fmt.Errorf("failed to update payment with Stripe data: %w", err)
 	}
+	payment.ProviderTransactionID = &pi.ID
+	payment.Status = string(enums.PaymentStatusAuthorized)
 
 	// 8. Devolver respuesta con client_secret para el frontend
 	paymentID := fmt.Sprintf("%d", payment.ID)
@@ -151,19 +151,23 @@ func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, sign
 		return fmt.Errorf("payment not found for transaction: %s", paymentIntent.ID)
 	}
 
-	// Idempotencia: si ya está completed o refunded, salir
-	if payment.Status == "completed" || payment.Status == "refunded" {
+	// Idempotencia: si ya está cobrado o reembolsado, salir
+	current := enums.PaymentStatus(payment.Status)
+	if current.IsSuccessful() || current.IsRefunded() {
 		return nil
 	}
 
-	// Actualizar payment
-	payment.Status = "completed"
-	now := time.Now()
-	payment.ProcessedAt = &now
+	// Los pagos creados con el flujo anterior (processing) se cierran como completed
+	target := enums.PaymentStatusCaptured
+	if current == enums.PaymentStatusProcessing {
+		target = enums.PaymentStatusCompleted
+	}
 
-	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+	// Actualizar payment; UpdateStatus valida la transición y registra processed_at
+	if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, string(target), nil); err != nil {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
+	now := time.Now()
 
 	// Actualizar orden (marcar payment_status = paid)
 	order, err := s.orderRepo.FindByID(ctx, payment.OrderID)
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stripe/stripe-go/v81"
+	"github.com/stripe/stripe-go/v81/webhook"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+// statusOnlyPaymentRepo registra los cambios de estado; Update queda sin
+// implementar para que cualquier escritura que se salte UpdateStatus falle
+type statusOnlyPaymentRepo struct {
+	repository.PaymentRepository
+	payment *entities.Payment
+	changes []string
+}
+
+func (r *statusOnlyPaymentRepo) FindByTransactionID(context.Context, string) (*entities.Payment, error) {
+	copied := *r.payment
+	return &copied, nil
+}
+
+func (r *statusOnlyPaymentRepo) UpdateStatus(_ context.Context, paymentID int64, status string, _ map[string]interface{}) error {
+	r.changes = append(r.changes, fmt.Sprintf("%d:%s", paymentID, status))
+	r.payment.Status = status
+	return nil
+}
+
+type paidOrderRepo struct {
+	repository.OrderRepository
+	order *entities.Order
+}
+
+func (r *paidOrderRepo) FindByID(context.Context, int64) (*entities.Order, error) {
+	return r.order, nil
+}
+
+func (r *paidOrderRepo) Update(context.Context, *entities.Order) error {
+	return nil
+}
+
+func signedPaymentIntentSucceeded(t *testing.T, secret, intentID string) ([]byte, string) {
+	t.Helper()
+	payload := []byte(fmt.Sprintf(`{
+		"id": "evt_test",
+		"object": "event",
+		"api_version": %q,
+		"type": "payment_intent.succeeded",
+		"data": {"object": {"id": %q, "object": "payment_intent"}}
+	}`, stripe.APIVersion, intentID))
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload:   payload,
+		Secret:    secret,
+		Timestamp: time.Now(),
+	})
+	return signed.Payload, signed.Header
+}
+
+func TestPaymentServiceHandleWebhookRoutesStatusThroughUpdateStatus(t *testing.T) {
+	const secret = "whsec_test"
+	tests := []struct {
+		name, from, want string
+	}{
+		{"authorized payment is captured", "authorized", "9:captured"},
+		{"legacy processing payment is completed", "processing", "9:completed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments := &statusOnlyPaymentRepo{payment: &entities.Payment{ID: 9, OrderID: 4, Status: tt.from}}
+			orders := &paidOrderRepo{order: &entities.Order{ID: 4, Status: "pending"}}
+			s := NewPaymentService(payments, orders, nil, nil, nil, secret)
+
+			payload, header := signedPaymentIntentSucceeded(t, secret, "pi_123")
+			if err := s.HandleWebhook(context.Background(), payload, header); err != nil {
+				t.Fatalf("HandleWebhook: %v", err)
+			}
+			if len(payments.changes) != 1 || payments.changes[0] != tt.want {
+				t.Errorf("status changes = %v, want [%s]", payments.changes, tt.want)
+			}
+			if orders.order.PaymentStatus != "paid" {
+				t.Errorf("order payment status = %q, want paid", orders.order.PaymentStatus)
+			}
+
+			// Un webhook repetido no vuelve a cambiar el estado
+			if err := s.HandleWebhook(context.Background(), payload, header); err != nil {
+				t.Fatalf("repeated HandleWebhook: %v", err)
+			}
+			if len(payments.changes) != 1 {
+				t.Errorf("status changes after retry = %v, want a single change", payments.changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/utils"
+)
+
+// reservationReleaser libera las reservas vencidas y devuelve cuántas liberó
+type reservationReleaser interface {
+	ReleaseExpiredReservations(ctx context.Context) (int64, error)
+}
+
+// ReservationSweeper libera periódicamente los tickets reservados cuya
+// expiración ya pasó.
+type ReservationSweeper struct {
+	releaser reservationReleaser
+	interval time.Duration
+	logger   *utils.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewReservationSweeper crea el barredor; no corre hasta llamar a Start
+func NewReservationSweeper(releaser reservationReleaser, interval time.Duration, logger *utils.Logger) *ReservationSweeper {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if logger == nil {
+		logger = utils.GlobalLogger
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ReservationSweeper{
+		releaser: releaser,
+		interval: interval,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start lanza la goroutine de barrido
+func (s *ReservationSweeper) Start() {
+	go s.run()
+}
+
+// run ejecuta un barrido en cada intervalo hasta que se detenga el barredor
+func (s *ReservationSweeper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// sweep libera las reservas vencidas. Un barrido interrumpido por Stop no se
+// reporta como error: lo pendiente queda para el siguiente arranque.
+func (s *ReservationSweeper) sweep() {
+	ctx, cancel := context.WithTimeout(s.ctx, s.interval)
+	defer cancel()
+
+	released, err := s.releaser.ReleaseExpiredReservations(ctx)
+	if err != nil && s.ctx.Err() == nil {
+		s.logger.Error("failed to release expired reservations", err, map[string]interface{}{
+			"released": released,
+		})
+		return
+	}
+	if released > 0 {
+		s.logger.Info("released expired reservations", map[string]interface{}{
+			"released": released,
+		})
+	}
+}
+
+// Stop cancela el barrido en curso y espera a que la goroutine termine o a
+// que ctx expire. Es seguro llamarlo más de una vez.
+func (s *ReservationSweeper) Stop(ctx context.Context) error {
+	s.once.Do(s.cancel)
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingReleaser cuenta barridos y bloquea cada uno hasta que se cancele ctx
+type blockingReleaser struct {
+	mu     sync.Mutex
+	calls  int
+	called chan struct{}
+}
+
+func (r *blockingReleaser) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	r.calls++
+	r.mu.Unlock()
+	select {
+	case r.called <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestReservationSweeperStopCancelsSweepInProgress(t *testing.T) {
+	releaser := &blockingReleaser{called: make(chan struct{}, 1)}
+	s := NewReservationSweeper(releaser, 10*time.Millisecond, nil)
+	s.Start()
+
+	select {
+	case <-releaser.called:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper never ran")
+	}
+
+	// El barrido en curso dura hasta su timeout; Stop debe cortarlo antes
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(stopCtx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := s.Stop(stopCtx); err != nil {
+		t.Errorf("second Stop: %v", err)
+	}
+
+	releaser.mu.Lock()
+	calls := releaser.calls
+	releaser.mu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+	releaser.mu.Lock()
+	defer releaser.mu.Unlock()
+	if releaser.calls != calls {
+		t.Errorf("sweeps after Stop = %d, want none", releaser.calls-calls)
+	}
+}
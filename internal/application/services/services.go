@@ -3,6 +3,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"regexp"
@@ -14,28 +15,15 @@ import (
 	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// isDateRangeValid valida que endDate sea después de startDate
-func isDateRangeValid(start, end time.Time) bool {
-	return !end.Before(start)
-}
-
 // =============================================================================
 // FUNCIONES HELPER
 // =============================================================================
 
-// isValidUUID valida si un string es un UUID válido
-func isValidUUID(u string) bool {
-	if u == "" {
-		return false
-	}
-	pattern := `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`
-	match, _ := regexp.MatchString(pattern, strings.ToLower(u))
-	return match
-}
-
 // isValidEmail valida si un string es un email válido
 func isValidEmail(email string) bool {
 	if email == "" {
@@ -195,9 +183,71 @@ func (s *Server) GetEvent(ctx context.Context, req *osmi.GetEventRequest) (*osmi
 		return nil, fmt.Errorf("event not found with id: %s", req.PublicId)
 	}
 
+	SetCancellationReasonHeader(ctx, event)
 	return s.mapEventToResponse(event), nil
 }
 
+// UpdateEvent aplica los campos no vacíos de req sobre el evento req.PublicId
+// (ver updateEvent) y devuelve el evento actualizado
+func (s *Server) UpdateEvent(ctx context.Context, req *UpdateEventRequest) (*osmi.EventResponse, error) {
+	log.Printf("Updating event: %s", req.PublicId)
+
+	event, err := updateEvent(ctx, s.EventRepo, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidEventUpdate):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, repository.ErrEventNotFound):
+			return nil, status.Errorf(codes.NotFound, "event not found with id: %s", req.PublicId)
+		case errors.Is(err, ErrInvalidEventStatusTransition):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		log.Printf("Error updating event: %v", err)
+		return nil, status.Error(codes.Internal, "error updating event")
+	}
+
+	SetCancellationReasonHeader(ctx, event)
+	return s.mapEventToResponse(event), nil
+}
+
+// CancelEvent cancela un evento por su ID público con la razón de la petición
+// o de la metadata x-cancel-reason (ver cancelReason). Un evento ya terminado
+// (cancelado, completado o archivado) responde FailedPrecondition.
+func (s *Server) CancelEvent(ctx context.Context, req *osmi.EventLookup) (*osmi.EventResponse, error) {
+	log.Printf("Cancelling event: %s", req.PublicId)
+
+	if !isValidUUID(req.PublicId) {
+		return nil, status.Error(codes.InvalidArgument, "invalid event ID format: must be a valid UUID")
+	}
+
+	event, err := s.EventRepo.GetByPublicID(ctx, req.PublicId)
+	if err != nil {
+		if errors.Is(err, repository.ErrEventNotFound) {
+			return nil, status.Errorf(codes.NotFound, "event not found with id: %s", req.PublicId)
+		}
+		log.Printf("Error getting event: %v", err)
+		return nil, status.Error(codes.Internal, "error retrieving event")
+	}
+
+	if enums.EventStatus(event.Status).IsEnded() {
+		return nil, status.Errorf(codes.FailedPrecondition, "event is already %s", event.Status)
+	}
+
+	if err := s.EventRepo.Cancel(ctx, event.ID, cancelReason(ctx, req)); err != nil {
+		log.Printf("Error cancelling event: %v", err)
+		return nil, status.Error(codes.Internal, "error cancelling event")
+	}
+
+	cancelledEvent, err := s.EventRepo.GetByPublicID(ctx, req.PublicId)
+	if err != nil {
+		log.Printf("Error retrieving cancelled event: %v", err)
+		return nil, status.Error(codes.Internal, "event cancelled but retrieval failed")
+	}
+
+	SetCancellationReasonHeader(ctx, cancelledEvent)
+	return s.mapEventToResponse(cancelledEvent), nil
+}
+
 // ListEvents implementa el método gRPC para listar eventos
 func (s *Server) ListEvents(ctx context.Context, req *osmi.ListEventsRequest) (*osmi.EventListResponse, error) {
 	log.Println("Listing events with filters")
@@ -487,8 +537,8 @@ func (s *Server) CreateTicket(ctx context.Context, req *osmi.CreateTicketRequest
 	if req.Quantity <= 0 {
 		req.Quantity = 1
 	}
-	if req.Quantity > 10 {
-		return nil, fmt.Errorf("cannot create more than 10 tickets at once")
+	if err := ValidateTicketQuantity(req.Quantity, DefaultMaxTicketsPerTransaction, false); err != nil {
+		return nil, err
 	}
 
 	return nil, fmt.Errorf("CreateTicket method is under development")
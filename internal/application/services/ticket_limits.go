@@ -0,0 +1,164 @@
+// internal/application/services/ticket_limits.go
+package services
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"strings"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+// ErrTooManyTickets indica que la cantidad supera el máximo por transacción
+var ErrTooManyTickets = errors.New("too many tickets in a single transaction")
+
+// DefaultMaxTicketsPerTransaction es el límite cuando no se configura otro. Es
+// distinto de max_per_order del tipo de ticket: limita cuántos se emiten en una
+// sola llamada.
+const DefaultMaxTicketsPerTransaction = 10
+
+// ValidateTicketQuantity valida la cantidad pedida contra limit. bypassLimit sólo
+// debe usarse en flujos administrativos de confianza (p. ej. emisión masiva de cortesías).
+func ValidateTicketQuantity(quantity int32, limit int, bypassLimit bool) error {
+	if quantity <= 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+	if !bypassLimit && int(quantity) > limit {
+		return fmt.Errorf("%w: cannot create more than %d tickets at once", ErrTooManyTickets, limit)
+	}
+	return nil
+}
+
+// ErrCodeGenerationFailed indica que no se obtuvo un código de ticket único tras
+// todos los intentos; la operación completa puede reintentarse
+var ErrCodeGenerationFailed = errors.New("failed to generate a unique ticket code")
+
+// Valores por defecto de reintento de códigos de ticket
+const (
+	DefaultTicketCodeMaxAttempts  = 5
+	DefaultTicketCodeRetryBackoff = 20 * time.Millisecond
+)
+
+// SetCodeRetry configura intentos y backoff base para códigos duplicados;
+// valores no positivos se ignoran y se conservan los actuales
+func (s *TicketService) SetCodeRetry(maxAttempts int, backoff time.Duration) {
+	if maxAttempts > 0 {
+		s.codeMaxAttempts = maxAttempts
+	}
+	if backoff > 0 {
+		s.codeRetryBackoff = backoff
+	}
+}
+
+// withTicketCodeRetry ejecuta fn y la repite mientras falle por código duplicado,
+// con backoff lineal más jitter. fn debe generar un código nuevo en cada intento
+// (recibe el número de intento) y, si usa transacción, abrir una nueva: un
+// unique violation aborta la transacción en curso.
+func (s *TicketService) withTicketCodeRetry(ctx context.Context, fn func(attempt int) error) error {
+	for attempt := 0; attempt < s.codeMaxAttempts; attempt++ {
+		err := fn(attempt)
+		if !errors.Is(err, repository.ErrTicketDuplicateCode) {
+			return err
+		}
+
+		if attempt == s.codeMaxAttempts-1 {
+			break
+		}
+		wait := s.codeRetryBackoff*time.Duration(attempt+1) +
+			time.Duration(mathrand.Int63n(int64(s.codeRetryBackoff)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return fmt.Errorf("%w after %d attempts", ErrCodeGenerationFailed, s.codeMaxAttempts)
+}
+
+// ticketCodeAlphabet son los caracteres del sufijo aleatorio de los códigos; se
+// omiten 0/O y 1/I para que se puedan capturar a mano sin confusiones
+const ticketCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// ticketCodeSuffix devuelve n caracteres aleatorios de ticketCodeAlphabet
+// (crypto/rand, 5 bits por carácter)
+func ticketCodeSuffix(n int) string {
+	buf := make([]byte, n)
+	if _, err := cryptorand.Read(buf); err != nil {
+		// Sin entropía del sistema se recurre a math/rand; el índice único de
+		// code sigue impidiendo duplicados
+		mathrand.Read(buf)
+	}
+	for i, b := range buf {
+		buf[i] = ticketCodeAlphabet[int(b)%len(ticketCodeAlphabet)]
+	}
+	return string(buf)
+}
+
+// DefaultTicketCodePrefix es el prefijo de los eventos que no configuran uno propio
+const DefaultTicketCodePrefix = "TKT"
+
+// SetCodePrefix configura el prefijo de los códigos de los eventos que no definen
+// uno propio. Un prefijo inválido se rechaza y se conserva el actual.
+func (s *TicketService) SetCodePrefix(prefix string) error {
+	if err := entities.ValidateTicketCodePrefix(prefix); err != nil {
+		return err
+	}
+	s.codePrefix = strings.ToUpper(prefix)
+	return nil
+}
+
+// ticketCodePrefix devuelve el prefijo configurado en el evento o el del servidor
+func (s *TicketService) ticketCodePrefix(event *entities.Event) string {
+	if prefix := event.GetSettings().TicketCodePrefix; prefix != "" {
+		return prefix
+	}
+	return s.codePrefix
+}
+
+// Errores de la ventana de check-in
+var (
+	ErrCheckInNotOpen       = errors.New("check-in not available yet")
+	ErrCheckInClosed        = errors.New("check-in period has ended")
+	ErrInvalidCheckInWindow = errors.New("invalid check-in window")
+)
+
+// Ventana de check-in por defecto relativa a starts_at/ends_at del evento
+const (
+	DefaultCheckInEarlyEntry = time.Hour
+	DefaultCheckInGrace      = 2 * time.Hour
+)
+
+// SetCheckInWindow configura cuánto antes del inicio abre el check-in y cuánto
+// después del fin sigue abierto. Un valor negativo se rechaza con
+// ErrInvalidCheckInWindow y se conserva la ventana actual.
+func (s *TicketService) SetCheckInWindow(earlyEntry, grace time.Duration) error {
+	if earlyEntry < 0 || grace < 0 {
+		return fmt.Errorf("%w: early entry %s and grace %s must not be negative", ErrInvalidCheckInWindow, earlyEntry, grace)
+	}
+	s.checkInEarlyEntry = earlyEntry
+	s.checkInGrace = grace
+	return nil
+}
+
+// checkInWindowError indica si now cae fuera de la ventana de check-in del
+// evento. CheckInWindowMinutes de la configuración del evento decide cuánto
+// antes del inicio abre; sin valor se usa el del servicio.
+func (s *TicketService) checkInWindowError(event *entities.Event, now time.Time) error {
+	earlyEntry := s.checkInEarlyEntry
+	if event.Settings != nil && event.Settings.CheckInWindowMinutes > 0 {
+		earlyEntry = time.Duration(event.Settings.CheckInWindowMinutes) * time.Minute
+	}
+
+	if now.Before(event.StartsAt.Add(-earlyEntry)) {
+		return ErrCheckInNotOpen
+	}
+	if now.After(event.EndsAt.Add(s.checkInGrace)) {
+		return ErrCheckInClosed
+	}
+	return nil
+}
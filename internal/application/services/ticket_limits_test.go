@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ticketdto "github.com/franciscozamorau/osmi-server/internal/api/dto/ticket"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+func TestValidateTicketQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int32
+		bypass   bool
+		wantErr  error
+	}{
+		{"within limit", 3, false, nil},
+		{"over limit", 4, false, ErrTooManyTickets},
+		{"admin override", 50, true, nil},
+		{"zero", 0, true, errors.New("quantity must be greater than 0")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTicketQuantity(tt.quantity, 3, tt.bypass)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("error = %v, want nil", err)
+			case tt.wantErr == ErrTooManyTickets && !errors.Is(err, ErrTooManyTickets):
+				t.Errorf("error = %v, want ErrTooManyTickets", err)
+			case tt.wantErr != nil && err == nil:
+				t.Errorf("error = nil, want %v", tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTicketServiceMaxPerTransaction(t *testing.T) {
+	if s := NewTicketService(nil, nil, nil, nil, nil, 0); s.maxPerTransaction != DefaultMaxTicketsPerTransaction {
+		t.Errorf("default limit = %d, want %d", s.maxPerTransaction, DefaultMaxTicketsPerTransaction)
+	}
+
+	// El límite configurado se aplica antes de tocar los repositorios
+	s := NewTicketService(nil, nil, nil, nil, nil, 2)
+	_, err := s.CreateTicket(context.Background(), &ticketdto.CreateTicketRequest{Quantity: 3})
+	if !errors.Is(err, ErrTooManyTickets) {
+		t.Errorf("CreateTicket(3) with limit 2 error = %v, want ErrTooManyTickets", err)
+	}
+}
+
+// fastTicketCodeRetry devuelve un servicio con backoff corto para los tests
+func fastTicketCodeRetry(maxAttempts int) *TicketService {
+	s := NewTicketService(nil, nil, nil, nil, nil, 0)
+	s.SetCodeRetry(maxAttempts, time.Millisecond)
+	return s
+}
+
+func TestSetCodeRetryIgnoresNonPositive(t *testing.T) {
+	s := NewTicketService(nil, nil, nil, nil, nil, 0)
+	if s.codeMaxAttempts != DefaultTicketCodeMaxAttempts || s.codeRetryBackoff != DefaultTicketCodeRetryBackoff {
+		t.Errorf("defaults = %d/%s, want %d/%s", s.codeMaxAttempts, s.codeRetryBackoff, DefaultTicketCodeMaxAttempts, DefaultTicketCodeRetryBackoff)
+	}
+	s.SetCodeRetry(8, 5*time.Millisecond)
+	s.SetCodeRetry(0, -time.Second)
+	if s.codeMaxAttempts != 8 || s.codeRetryBackoff != 5*time.Millisecond {
+		t.Errorf("after invalid values = %d/%s, want 8/5ms", s.codeMaxAttempts, s.codeRetryBackoff)
+	}
+}
+
+func TestWithTicketCodeRetryRecoversFromDuplicates(t *testing.T) {
+	s := fastTicketCodeRetry(5)
+
+	calls := 0
+	err := s.withTicketCodeRetry(context.Background(), func(attempt int) error {
+		calls++
+		if attempt < 3 {
+			return fmt.Errorf("insert: %w", repository.ErrTicketDuplicateCode)
+		}
+		return nil
+	})
+	if err != nil || calls != 4 {
+		t.Errorf("err = %v after %d calls, want success on the 4th", err, calls)
+	}
+}
+
+func TestWithTicketCodeRetryGivesUp(t *testing.T) {
+	s := fastTicketCodeRetry(3)
+
+	calls := 0
+	err := s.withTicketCodeRetry(context.Background(), func(int) error {
+		calls++
+		return repository.ErrTicketDuplicateCode
+	})
+	if !errors.Is(err, ErrCodeGenerationFailed) || calls != 3 {
+		t.Errorf("err = %v after %d calls, want ErrCodeGenerationFailed after 3", err, calls)
+	}
+
+	// Otros errores no se reintentan
+	calls = 0
+	boom := errors.New("boom")
+	if err := s.withTicketCodeRetry(context.Background(), func(int) error { calls++; return boom }); err != boom || calls != 1 {
+		t.Errorf("err = %v after %d calls, want boom without retry", err, calls)
+	}
+}
+
+// codeStore simula el índice único de ticketing.tickets.code
+type codeStore struct {
+	mu    sync.Mutex
+	codes map[string]bool
+}
+
+func (c *codeStore) insert(code string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.codes[code] {
+		return repository.ErrTicketDuplicateCode
+	}
+	c.codes[code] = true
+	return nil
+}
+
+func TestTicketCodeRetryUnderConcurrency(t *testing.T) {
+	s := fastTicketCodeRetry(DefaultTicketCodeMaxAttempts)
+	event := &entities.Event{ID: 42}
+	store := &codeStore{codes: map[string]bool{}}
+
+	const buyers = 200
+	var wg sync.WaitGroup
+	errs := make(chan error, buyers)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.withTicketCodeRetry(context.Background(), func(attempt int) error {
+				// El primer intento de todos choca con el mismo código
+				code := "TKT-42-7-COLLIDE"
+				if attempt > 0 {
+					code = s.generateTicketCode(event, 7, attempt)
+				}
+				return store.insert(code)
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("spurious failure: %v", err)
+		}
+	}
+	if len(store.codes) != buyers {
+		t.Errorf("stored %d unique codes, want %d", len(store.codes), buyers)
+	}
+}
+
+func TestTicketCodeSuffix(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		suffix := ticketCodeSuffix(8)
+		if len(suffix) != 8 || strings.Trim(suffix, ticketCodeAlphabet) != "" {
+			t.Fatalf("suffix %q is not 8 characters of the code alphabet", suffix)
+		}
+		seen[suffix] = true
+	}
+	if len(seen) < 990 {
+		t.Errorf("%d distinct suffixes out of 1000, want them to be random", len(seen))
+	}
+}
+
+func TestGenerateTicketCodePrefix(t *testing.T) {
+	s := NewTicketService(nil, nil, nil, nil, nil, 0)
+
+	branded := &entities.Event{ID: 9, Settings: &entities.EventSettings{TicketCodePrefix: "ACME"}}
+	if code := s.generateTicketCode(branded, 3, 0); !strings.HasPrefix(code, "ACME-9-3-") {
+		t.Errorf("branded code = %q, want ACME-9-3-...", code)
+	}
+
+	plain := &entities.Event{ID: 9}
+	if code := s.generateTicketCode(plain, 3, 0); !strings.HasPrefix(code, DefaultTicketCodePrefix+"-9-3-") {
+		t.Errorf("default code = %q, want %s-9-3-...", code, DefaultTicketCodePrefix)
+	}
+
+	if err := s.SetCodePrefix("osmi"); err != nil {
+		t.Fatalf("SetCodePrefix(osmi): %v", err)
+	}
+	if code := s.generateTicketCode(plain, 3, 0); !strings.HasPrefix(code, "OSMI-9-3-") {
+		t.Errorf("code with server prefix = %q, want OSMI-9-3-...", code)
+	}
+	// Un prefijo de servidor inválido se rechaza y se conserva el anterior
+	if err := s.SetCodePrefix("bad-prefix"); err == nil {
+		t.Error("SetCodePrefix(bad-prefix) error = nil, want a validation error")
+	}
+	if code := s.generateTicketCode(plain, 3, 0); !strings.HasPrefix(code, "OSMI-9-3-") {
+		t.Errorf("code after invalid server prefix = %q, want OSMI-9-3-...", code)
+	}
+}
+
+func TestCheckInWindowUsesEventSettings(t *testing.T) {
+	s := NewTicketService(nil, nil, nil, nil, nil, 0)
+	if err := s.SetCheckInWindow(30*time.Minute, time.Hour); err != nil {
+		t.Fatalf("SetCheckInWindow: %v", err)
+	}
+
+	startsAt := time.Date(2091, time.March, 1, 20, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(3 * time.Hour)
+	plain := &entities.Event{StartsAt: startsAt, EndsAt: endsAt}
+	early := &entities.Event{StartsAt: startsAt, EndsAt: endsAt, Settings: &entities.EventSettings{CheckInWindowMinutes: 120}}
+
+	tests := []struct {
+		name  string
+		event *entities.Event
+		now   time.Time
+		want  error
+	}{
+		{"default window not open", plain, startsAt.Add(-45 * time.Minute), ErrCheckInNotOpen},
+		{"default window open", plain, startsAt.Add(-15 * time.Minute), nil},
+		{"event window open earlier", early, startsAt.Add(-90 * time.Minute), nil},
+		{"event window not open", early, startsAt.Add(-150 * time.Minute), ErrCheckInNotOpen},
+		{"grace keeps the server default", early, endsAt.Add(30 * time.Minute), nil},
+		{"closed after grace", early, endsAt.Add(90 * time.Minute), ErrCheckInClosed},
+		// Sin minutos configurados manda el default del servidor
+		{"zero minutes falls back", &entities.Event{StartsAt: startsAt, EndsAt: endsAt, Settings: &entities.EventSettings{}}, startsAt.Add(-45 * time.Minute), ErrCheckInNotOpen},
+	}
+	for _, tt := range tests {
+		if err := s.checkInWindowError(tt.event, tt.now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSetCheckInWindowRejectsNegative(t *testing.T) {
+	s := NewTicketService(nil, nil, nil, nil, nil, 0)
+	if err := s.SetCheckInWindow(0, 0); err != nil {
+		t.Fatalf("SetCheckInWindow(0, 0): %v", err)
+	}
+	for _, tt := range []struct{ early, grace time.Duration }{{-time.Minute, time.Hour}, {time.Hour, -time.Minute}} {
+		if err := s.SetCheckInWindow(tt.early, tt.grace); !errors.Is(err, ErrInvalidCheckInWindow) {
+			t.Errorf("SetCheckInWindow(%s, %s) error = %v, want ErrInvalidCheckInWindow", tt.early, tt.grace, err)
+		}
+	}
+	// Un valor rechazado no reemplaza la ventana configurada por el default
+	if s.checkInEarlyEntry != 0 || s.checkInGrace != 0 {
+		t.Errorf("window = %s/%s after rejected values, want 0s/0s", s.checkInEarlyEntry, s.checkInGrace)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
@@ -13,6 +14,8 @@ import (
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
 	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/domain/valueobjects"
+	"github.com/franciscozamorau/osmi-server/internal/qrcode"
 	"github.com/google/uuid"
 )
 
@@ -22,37 +25,102 @@ type TicketService struct {
 	eventRepo      repository.EventRepository
 	customerRepo   repository.CustomerRepository
 	orderRepo      repository.OrderRepository
+	qrGenerator    ticketQRGenerator
+
+	// maxPerTransaction limita cuántos tickets emite una sola llamada
+	maxPerTransaction int
+
+	// Reintentos ante códigos de ticket duplicados (SetCodeRetry)
+	codeMaxAttempts  int
+	codeRetryBackoff time.Duration
+
+	// codePrefix es el prefijo de código de los eventos sin uno propio (SetCodePrefix)
+	codePrefix string
+
+	// Ventana de check-in relativa a starts_at/ends_at (SetCheckInWindow)
+	checkInEarlyEntry time.Duration
+	checkInGrace      time.Duration
 }
 
+// ticketQRGenerator renderiza y publica el QR de un ticket y devuelve su URL;
+// Verify valida la firma del payload escaneado en el check-in
+type ticketQRGenerator interface {
+	Generate(ctx context.Context, ticket *entities.Ticket) (string, error)
+	Verify(payload string) (qrcode.Payload, error)
+}
+
+// ErrInvalidQRCode indica un QR escaneado con firma inválida o que no
+// corresponde al ticket
+var ErrInvalidQRCode = errors.New("invalid ticket qr code")
+
+// ErrInvalidReservationExpiry indica una expiración de reserva que ya pasó
+var ErrInvalidReservationExpiry = errors.New("reservation expiry must be in the future")
+
+// ErrSelfTransfer indica una transferencia cuyo destinatario ya es el dueño
+var ErrSelfTransfer = errors.New("cannot transfer a ticket to its current owner")
+
+// defaultReservationTTL es la vigencia de una reserva cuando no se indica expiración
+const defaultReservationTTL = 15 * time.Minute
+
 func NewTicketService(
 	ticketRepo repository.TicketRepository,
 	ticketTypeRepo repository.TicketTypeRepository,
 	eventRepo repository.EventRepository,
 	customerRepo repository.CustomerRepository,
 	orderRepo repository.OrderRepository,
+	maxPerTransaction int,
 ) *TicketService {
+	if maxPerTransaction <= 0 {
+		maxPerTransaction = DefaultMaxTicketsPerTransaction
+	}
 	return &TicketService{
-		ticketRepo:     ticketRepo,
-		ticketTypeRepo: ticketTypeRepo,
-		eventRepo:      eventRepo,
-		customerRepo:   customerRepo,
-		orderRepo:      orderRepo,
+		ticketRepo:        ticketRepo,
+		ticketTypeRepo:    ticketTypeRepo,
+		eventRepo:         eventRepo,
+		customerRepo:      customerRepo,
+		orderRepo:         orderRepo,
+		maxPerTransaction: maxPerTransaction,
+		codeMaxAttempts:   DefaultTicketCodeMaxAttempts,
+		codeRetryBackoff:  DefaultTicketCodeRetryBackoff,
+		codePrefix:        DefaultTicketCodePrefix,
+		checkInEarlyEntry: DefaultCheckInEarlyEntry,
+		checkInGrace:      DefaultCheckInGrace,
 	}
 }
 
+// EnableQRCodes hace que los tickets creados salgan con su QR generado
+func (s *TicketService) EnableQRCodes(generator ticketQRGenerator) {
+	s.qrGenerator = generator
+}
+
+// attachQRCode genera el QR y guarda su URL. Un fallo no deshace la venta: el
+// ticket queda sin QR y se registra el error.
+func (s *TicketService) attachQRCode(ctx context.Context, ticket *entities.Ticket) {
+	if s.qrGenerator == nil {
+		return
+	}
+
+	url, err := s.qrGenerator.Generate(ctx, ticket)
+	if err != nil {
+		log.Printf("⚠️ Error generating QR for ticket %s: %v", ticket.PublicID, err)
+		return
+	}
+	if err := s.ticketRepo.SetQRCodeURL(ctx, ticket.ID, url); err != nil {
+		log.Printf("⚠️ Error saving QR for ticket %s: %v", ticket.PublicID, err)
+		return
+	}
+	ticket.QRCodeData = &url
+}
+
 // CreateTicket crea un nuevo ticket vendido (flujo directo - temporal)
 func (s *TicketService) CreateTicket(ctx context.Context, req *ticketdto.CreateTicketRequest) (*entities.Ticket, error) {
-	ticketType, err := s.ticketTypeRepo.FindByPublicID(ctx, req.TicketTypeID)
-	if err != nil {
-		return nil, fmt.Errorf("ticket type not found: %w", err)
+	if err := ValidateTicketQuantity(req.Quantity, s.maxPerTransaction, req.BypassQuantityLimit); err != nil {
+		return nil, err
 	}
 
-	available, err := s.ticketTypeRepo.CheckAvailability(ctx, ticketType.ID, int(req.Quantity))
+	ticketType, err := s.ticketTypeRepo.FindByPublicID(ctx, req.TicketTypeID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking availability: %w", err)
-	}
-	if !available {
-		return nil, errors.New("ticket type not available")
+		return nil, fmt.Errorf("ticket type not found: %w", err)
 	}
 
 	customer, err := s.customerRepo.GetByPublicID(ctx, req.CustomerID)
@@ -78,11 +146,11 @@ func (s *TicketService) CreateTicket(ctx context.Context, req *ticketdto.CreateT
 		TicketTypeID:  ticketType.ID,
 		EventID:       event.ID,
 		CustomerID:    &customer.ID,
-		Code:          s.generateTicketCode(event.ID, ticketType.ID, 0),
-		SecretHash:    uuid.New().String(),
+		Code:          s.generateTicketCode(event, ticketType.ID, 0),
+		SecretHash:    entities.NewTicketSecretHash(),
 		Status:        string(enums.TicketStatusSold),
 		FinalPrice:    finalPrice,
-		Currency:      ticketType.Currency,
+		Currency:      valueobjects.CurrencyOrDefault(ticketType.Currency),
 		TaxAmount:     taxAmount,
 		AttendeeName:  nil,
 		AttendeeEmail: nil,
@@ -96,28 +164,108 @@ func (s *TicketService) CreateTicket(ctx context.Context, req *ticketdto.CreateT
 		return nil, fmt.Errorf("invalid ticket: %w", err)
 	}
 
-	err = s.ticketRepo.Create(ctx, ticket)
+	// El cupo se valida y descuenta con la fila bloqueada dentro de la misma
+	// transacción que inserta el ticket; si el código choca se reintenta todo.
+	err = s.withTicketCodeRetry(ctx, func(attempt int) error {
+		if attempt > 0 {
+			ticket.Code = s.generateTicketCode(event, ticketType.ID, attempt)
+		}
+		return s.sellTicketOnce(ctx, ticket, int(req.Quantity))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create ticket: %w", err)
+		return nil, err
 	}
 
-	err = s.ticketTypeRepo.SellTicketsDirect(ctx, ticketType.ID, int(req.Quantity))
-	if err != nil {
-		_ = s.ticketRepo.Delete(ctx, ticket.ID)
-		return nil, fmt.Errorf("failed to update ticket type sales: %w", err)
-	}
+	s.attachQRCode(ctx, ticket)
 
 	go s.customerRepo.UpdateStats(ctx, customer.ID, finalPrice)
 
 	return ticket, nil
 }
 
-// ReserveTicket reserva un ticket con bloqueo FOR UPDATE
+// sellTicketOnce descuenta quantity del tipo de ticket con bloqueo FOR UPDATE e
+// inserta el ticket en una sola transacción
+func (s *TicketService) sellTicketOnce(ctx context.Context, ticket *entities.Ticket, quantity int) error {
+	tx, err := s.ticketRepo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.ticketTypeRepo.SellTicketsWithLock(ctx, tx, ticket.TicketTypeID, quantity); err != nil {
+		return err
+	}
+
+	if err := s.ticketRepo.CreateTx(ctx, tx, ticket); err != nil {
+		return fmt.Errorf("failed to create ticket: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ReserveTicket reserva un ticket hasta req.ExpiresAt (15 minutos si no se
+// indica). Si req.TicketID es un ticket pregenerado se reserva ese ticket a
+// nombre de req.UserID; si no, se toma como tipo de ticket y se crea la reserva
+// con bloqueo FOR UPDATE, reintentando con otro código si el generado choca.
 func (s *TicketService) ReserveTicket(ctx context.Context, req *ticketdto.ReserveTicketRequest) (*entities.Ticket, error) {
 	if req.TicketID == "" {
 		return nil, errors.New("ticket_type_id is required")
 	}
 
+	expiresAt := req.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(defaultReservationTTL)
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidReservationExpiry
+	}
+
+	existing, err := s.ticketRepo.GetByPublicID(ctx, req.TicketID)
+	if err == nil {
+		return s.reserveExistingTicket(ctx, existing, req.UserID, expiresAt)
+	}
+	if !errors.Is(err, repository.ErrTicketNotFound) {
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	var ticket *entities.Ticket
+	err = s.withTicketCodeRetry(ctx, func(attempt int) error {
+		var err error
+		ticket, err = s.reserveTicketOnce(ctx, req.TicketID, expiresAt, attempt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// reserveExistingTicket reserva un ticket pregenerado; el repositorio cambia su
+// estado y descuenta el cupo del tipo en la misma transacción
+func (s *TicketService) reserveExistingTicket(ctx context.Context, ticket *entities.Ticket, userID string, expiresAt time.Time) (*entities.Ticket, error) {
+	if userID == "" {
+		return nil, errors.New("user_id is required to reserve a ticket")
+	}
+
+	event, err := s.eventRepo.GetByID(ctx, ticket.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("event not found: %w", err)
+	}
+	if !event.AllowReservations {
+		return nil, errors.New("event does not allow reservations")
+	}
+
+	if err := s.ticketRepo.ReserveTicket(ctx, ticket.PublicID, userID, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to reserve ticket: %w", err)
+	}
+
+	return s.ticketRepo.GetByPublicID(ctx, ticket.PublicID)
+}
+
+func (s *TicketService) reserveTicketOnce(ctx context.Context, ticketTypeID string, reservationExpiresAt time.Time, attempt int) (*entities.Ticket, error) {
 	quantity := 1
 
 	// Iniciar transacción
@@ -127,7 +275,7 @@ func (s *TicketService) ReserveTicket(ctx context.Context, req *ticketdto.Reserv
 	}
 	defer tx.Rollback(ctx)
 
-	ticketType, err := s.ticketTypeRepo.FindByPublicID(ctx, req.TicketID)
+	ticketType, err := s.ticketTypeRepo.FindByPublicID(ctx, ticketTypeID)
 	if err != nil {
 		return nil, fmt.Errorf("ticket type not found: %w", err)
 	}
@@ -147,7 +295,6 @@ func (s *TicketService) ReserveTicket(ctx context.Context, req *ticketdto.Reserv
 		return nil, errors.New("event does not allow reservations")
 	}
 
-	reservationExpiresAt := time.Now().Add(15 * time.Minute)
 	now := time.Now()
 
 	ticket := &entities.Ticket{
@@ -155,11 +302,11 @@ func (s *TicketService) ReserveTicket(ctx context.Context, req *ticketdto.Reserv
 		TicketTypeID:         ticketType.ID,
 		EventID:              event.ID,
 		CustomerID:           nil,
-		Code:                 s.generateTicketCode(event.ID, ticketType.ID, 0),
-		SecretHash:           uuid.New().String(),
+		Code:                 s.generateTicketCode(event, ticketType.ID, attempt),
+		SecretHash:           entities.NewTicketSecretHash(),
 		Status:               string(enums.TicketStatusReserved),
 		FinalPrice:           ticketType.GetFinalPrice(),
-		Currency:             ticketType.Currency,
+		Currency:             valueobjects.CurrencyOrDefault(ticketType.Currency),
 		TaxAmount:            ticketType.BasePrice * ticketType.TaxRate,
 		ReservedAt:           &now,
 		ReservationExpiresAt: &reservationExpiresAt,
@@ -183,18 +330,25 @@ func (s *TicketService) ReserveTicket(ctx context.Context, req *ticketdto.Reserv
 	return ticket, nil
 }
 
-// CheckInTicket marca un ticket como usado
+// CheckInTicket marca un ticket como usado si el evento está dentro de su ventana
+// de check-in. Es el check-in con validación de horario que se expone por RPC
+// (TicketHandler.CheckInTicket); el Server heredado de services.go no lo duplica.
 func (s *TicketService) CheckInTicket(ctx context.Context, req *ticketdto.CheckInTicketRequest) (*entities.Ticket, error) {
 	if req.TicketID == "" {
 		return nil, errors.New("ticket_id is required")
 	}
 
-	ticket, err := s.ticketRepo.GetByPublicID(ctx, req.TicketID)
+	method, err := enums.ParseCheckInMethod(req.Method)
 	if err != nil {
-		return nil, fmt.Errorf("ticket not found: %w", err)
+		return nil, err
+	}
+
+	ticket, err := s.resolveCheckInTicket(ctx, req.TicketID, method)
+	if err != nil {
+		return nil, err
 	}
 
-	if ticket.Status != string(enums.TicketStatusSold) {
+	if !enums.TicketStatus(ticket.Status).CanCheckIn() {
 		return nil, errors.New("ticket is not valid for check-in")
 	}
 
@@ -207,12 +361,8 @@ func (s *TicketService) CheckInTicket(ctx context.Context, req *ticketdto.CheckI
 		return nil, fmt.Errorf("event not found: %w", err)
 	}
 
-	now := time.Now()
-	if now.Before(event.StartsAt.Add(-1 * time.Hour)) {
-		return nil, errors.New("check-in not available yet")
-	}
-	if now.After(event.EndsAt.Add(2 * time.Hour)) {
-		return nil, errors.New("check-in period has ended")
+	if err := s.checkInWindowError(event, time.Now()); err != nil {
+		return nil, err
 	}
 
 	var validatorID *int64
@@ -220,7 +370,7 @@ func (s *TicketService) CheckInTicket(ctx context.Context, req *ticketdto.CheckI
 		// TODO: Validar validador cuando exista auth
 	}
 
-	err = s.ticketRepo.CheckIn(ctx, ticket.ID, req.Method, req.Location, validatorID)
+	err = s.ticketRepo.CheckIn(ctx, ticket.ID, method, req.Location, validatorID)
 	if err != nil {
 		return nil, fmt.Errorf("check-in failed: %w", err)
 	}
@@ -233,51 +383,54 @@ func (s *TicketService) CheckInTicket(ctx context.Context, req *ticketdto.CheckI
 	return updatedTicket, nil
 }
 
-// TransferTicket transfiere un ticket
-func (s *TicketService) TransferTicket(ctx context.Context, req *ticketdto.TransferTicketRequest) (*entities.Ticket, error) {
-	if req.TicketID == "" {
-		return nil, errors.New("ticket_id is required")
+// resolveCheckInTicket obtiene el ticket a validar. Con QR habilitado, un
+// check-in por QR recibe en ticketID el payload escaneado: se verifica la firma
+// y que código y evento coincidan con el ticket guardado.
+func (s *TicketService) resolveCheckInTicket(ctx context.Context, ticketID string, method enums.CheckInMethod) (*entities.Ticket, error) {
+	if method != enums.CheckInMethodQR || s.qrGenerator == nil {
+		ticket, err := s.ticketRepo.GetByPublicID(ctx, ticketID)
+		if err != nil {
+			return nil, fmt.Errorf("ticket not found: %w", err)
+		}
+		return ticket, nil
 	}
-	if req.ToCustomerID == "" {
-		return nil, errors.New("to_customer_id is required")
+
+	payload, err := s.qrGenerator.Verify(ticketID)
+	if err != nil {
+		return nil, ErrInvalidQRCode
 	}
 
-	ticket, err := s.ticketRepo.GetByPublicID(ctx, req.TicketID)
+	ticket, err := s.ticketRepo.GetByPublicID(ctx, payload.PublicID)
 	if err != nil {
 		return nil, fmt.Errorf("ticket not found: %w", err)
 	}
-
-	// Si se proporciona from_customer_id, validar ownership
-	if req.FromCustomerID != "" {
-		fromCustomer, err := s.customerRepo.GetByPublicID(ctx, req.FromCustomerID)
-		if err != nil {
-			return nil, fmt.Errorf("sender customer not found: %w", err)
-		}
-		if ticket.CustomerID == nil || *ticket.CustomerID != fromCustomer.ID {
-			return nil, errors.New("ticket does not belong to sender")
-		}
+	if ticket.Code != payload.Code || ticket.EventID != payload.EventID {
+		return nil, ErrInvalidQRCode
 	}
+	return ticket, nil
+}
 
-	if !ticket.CanBeTransferred() {
-		return nil, errors.New("ticket cannot be transferred")
+// TransferTicket transfiere un ticket vendido de un cliente a otro. El
+// repositorio valida dueño y estado y registra el cambio en una sola transacción.
+func (s *TicketService) TransferTicket(ctx context.Context, req *ticketdto.TransferTicketRequest) (*entities.Ticket, error) {
+	if req.TicketID == "" {
+		return nil, errors.New("ticket_id is required")
 	}
-
-	toCustomer, err := s.customerRepo.GetByPublicID(ctx, req.ToCustomerID)
-	if err != nil {
-		return nil, fmt.Errorf("recipient customer not found: %w", err)
+	if req.FromCustomerID == "" {
+		return nil, errors.New("from_customer_id is required")
 	}
-
-	transferToken := req.Token
-	if transferToken == "" {
-		transferToken = uuid.New().String()
+	if req.ToCustomerID == "" {
+		return nil, errors.New("to_customer_id is required")
+	}
+	if strings.EqualFold(req.FromCustomerID, req.ToCustomerID) {
+		return nil, ErrSelfTransfer
 	}
 
-	err = s.ticketRepo.Transfer(ctx, ticket.ID, toCustomer.ID, transferToken)
-	if err != nil {
+	if err := s.ticketRepo.TransferTicket(ctx, req.TicketID, req.FromCustomerID, req.ToCustomerID); err != nil {
 		return nil, fmt.Errorf("transfer failed: %w", err)
 	}
 
-	updatedTicket, err := s.ticketRepo.GetByID(ctx, ticket.ID)
+	updatedTicket, err := s.ticketRepo.GetByPublicID(ctx, req.TicketID)
 	if err != nil {
 		return nil, fmt.Errorf("ticket transferred but retrieval failed: %w", err)
 	}
@@ -482,6 +635,34 @@ func (s *TicketService) UpdateTicket(ctx context.Context, ticketID string, req *
 	return ticket, nil
 }
 
+// UpdateTicketStatus cambia el estado de un ticket. Pedir el estado actual es un
+// no-op idempotente y devuelve el ticket sin cambios.
+func (s *TicketService) UpdateTicketStatus(ctx context.Context, ticketID string, newStatus string) (*entities.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByPublicID(ctx, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("ticket not found: %w", err)
+	}
+
+	if ticket.Status == newStatus {
+		return ticket, nil
+	}
+
+	if !enums.CanTransitionTicket(enums.TicketStatus(ticket.Status), enums.TicketStatus(newStatus)) {
+		return nil, fmt.Errorf("%w: from %s to %s", repository.ErrInvalidTicketStatus, ticket.Status, newStatus)
+	}
+
+	if err := s.ticketRepo.UpdateStatus(ctx, ticket.ID, enums.TicketStatus(newStatus)); err != nil {
+		return nil, fmt.Errorf("failed to update ticket status: %w", err)
+	}
+
+	updatedTicket, err := s.ticketRepo.GetByID(ctx, ticket.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ticket status updated but retrieval failed: %w", err)
+	}
+
+	return updatedTicket, nil
+}
+
 // CancelTicket cancela un ticket
 func (s *TicketService) CancelTicket(ctx context.Context, ticketID string) (*entities.Ticket, error) {
 	ticket, err := s.ticketRepo.GetByPublicID(ctx, ticketID)
@@ -539,9 +720,15 @@ func (s *TicketService) ValidateTicket(ctx context.Context, code, secretHash str
 	return ticket, nil
 }
 
-// generateTicketCode genera un código único para el ticket usando UUID
-func (s *TicketService) generateTicketCode(eventID, ticketTypeID int64, attempt int) string {
-	return fmt.Sprintf("TKT-%d-%d-%s", eventID, ticketTypeID, uuid.New().String()[:8])
+// generateTicketCode genera un código para el ticket con el prefijo del evento (o el
+// del servidor) y un sufijo aleatorio. Cada reintento agrega dos caracteres más
+// para reducir la probabilidad de otra colisión.
+func (s *TicketService) generateTicketCode(event *entities.Event, ticketTypeID int64, attempt int) string {
+	suffixLen := 8 + 2*attempt
+	if suffixLen > 32 {
+		suffixLen = 32
+	}
+	return fmt.Sprintf("%s-%d-%d-%s", s.ticketCodePrefix(event), event.ID, ticketTypeID, ticketCodeSuffix(suffixLen))
 }
 
 // PurchaseTicket convierte una reserva en venta (CON BLOQUEO FOR UPDATE)
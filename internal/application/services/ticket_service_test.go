@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	ticketdto "github.com/franciscozamorau/osmi-server/internal/api/dto/ticket"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+var errNoTx = errors.New("no transactions in this fake")
+
+// reserveTicketRepo simula un ticket pregenerado; BeginTx sólo registra que se
+// tomó el camino de reserva por tipo
+type reserveTicketRepo struct {
+	repository.TicketRepository
+	ticket    *entities.Ticket
+	reserved  []string
+	userIDs   []string
+	expiries  []time.Time
+	beginTxes int
+}
+
+func (r *reserveTicketRepo) GetByPublicID(_ context.Context, publicID string) (*entities.Ticket, error) {
+	if r.ticket == nil || r.ticket.PublicID != publicID {
+		return nil, repository.ErrTicketNotFound
+	}
+	copied := *r.ticket
+	return &copied, nil
+}
+
+func (r *reserveTicketRepo) ReserveTicket(_ context.Context, ticketPublicID, userPublicID string, expiresAt time.Time) error {
+	r.reserved = append(r.reserved, ticketPublicID)
+	r.userIDs = append(r.userIDs, userPublicID)
+	r.expiries = append(r.expiries, expiresAt)
+	r.ticket.Status = "reserved"
+	return nil
+}
+
+func (r *reserveTicketRepo) BeginTx(context.Context) (pgx.Tx, error) {
+	r.beginTxes++
+	return nil, errNoTx
+}
+
+type reservationEventRepo struct {
+	repository.EventRepository
+	allow bool
+}
+
+func (r reservationEventRepo) GetByID(context.Context, int64) (*entities.Event, error) {
+	return &entities.Event{ID: 1, AllowReservations: r.allow}, nil
+}
+
+func TestTicketServiceReserveExistingTicketGoesThroughRepository(t *testing.T) {
+	repo := &reserveTicketRepo{ticket: &entities.Ticket{PublicID: "tkt-1", EventID: 1, Status: "available"}}
+	s := NewTicketService(repo, nil, reservationEventRepo{allow: true}, nil, nil, 0)
+
+	expiresAt := time.Now().Add(5 * time.Minute)
+	ticket, err := s.ReserveTicket(context.Background(), &ticketdto.ReserveTicketRequest{
+		TicketID:  "tkt-1",
+		UserID:    "user-1",
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("ReserveTicket: %v", err)
+	}
+	if ticket.Status != "reserved" {
+		t.Errorf("status = %q, want reserved", ticket.Status)
+	}
+	if len(repo.reserved) != 1 || repo.reserved[0] != "tkt-1" || repo.userIDs[0] != "user-1" || !repo.expiries[0].Equal(expiresAt) {
+		t.Errorf("repository ReserveTicket calls = %v/%v/%v", repo.reserved, repo.userIDs, repo.expiries)
+	}
+	if repo.beginTxes != 0 {
+		t.Errorf("type reservation path ran %d times for an existing ticket", repo.beginTxes)
+	}
+}
+
+func TestTicketServiceReserveTicketDefaultsAndValidatesExpiry(t *testing.T) {
+	repo := &reserveTicketRepo{ticket: &entities.Ticket{PublicID: "tkt-1", EventID: 1, Status: "available"}}
+	s := NewTicketService(repo, nil, reservationEventRepo{allow: true}, nil, nil, 0)
+	ctx := context.Background()
+
+	_, err := s.ReserveTicket(ctx, &ticketdto.ReserveTicketRequest{
+		TicketID:  "tkt-1",
+		UserID:    "user-1",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if !errors.Is(err, ErrInvalidReservationExpiry) {
+		t.Errorf("past expiry: err = %v, want ErrInvalidReservationExpiry", err)
+	}
+
+	before := time.Now()
+	if _, err := s.ReserveTicket(ctx, &ticketdto.ReserveTicketRequest{TicketID: "tkt-1", UserID: "user-1"}); err != nil {
+		t.Fatalf("ReserveTicket without expiry: %v", err)
+	}
+	if got := repo.expiries[len(repo.expiries)-1]; got.Before(before.Add(defaultReservationTTL)) {
+		t.Errorf("default expiry = %v, want at least %v", got, before.Add(defaultReservationTTL))
+	}
+}
+
+func TestTicketServiceReserveExistingTicketRequiresUserAndReservations(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &reserveTicketRepo{ticket: &entities.Ticket{PublicID: "tkt-1", EventID: 1, Status: "available"}}
+	s := NewTicketService(repo, nil, reservationEventRepo{allow: true}, nil, nil, 0)
+	if _, err := s.ReserveTicket(ctx, &ticketdto.ReserveTicketRequest{TicketID: "tkt-1"}); err == nil {
+		t.Error("reservation without user succeeded")
+	}
+
+	s = NewTicketService(repo, nil, reservationEventRepo{allow: false}, nil, nil, 0)
+	if _, err := s.ReserveTicket(ctx, &ticketdto.ReserveTicketRequest{TicketID: "tkt-1", UserID: "user-1"}); err == nil {
+		t.Error("reservation succeeded for an event that does not allow reservations")
+	}
+	if len(repo.reserved) != 0 {
+		t.Errorf("repository ReserveTicket called %d times, want 0", len(repo.reserved))
+	}
+}
+
+func TestTicketServiceReserveTicketFallsBackToTicketType(t *testing.T) {
+	repo := &reserveTicketRepo{}
+	s := NewTicketService(repo, nil, reservationEventRepo{allow: true}, nil, nil, 0)
+
+	_, err := s.ReserveTicket(context.Background(), &ticketdto.ReserveTicketRequest{TicketID: "type-1", UserID: "user-1"})
+	if !errors.Is(err, errNoTx) {
+		t.Fatalf("err = %v, want the type reservation path to start a transaction", err)
+	}
+	if repo.beginTxes == 0 || len(repo.reserved) != 0 {
+		t.Errorf("beginTxes = %d, reserved = %v; want the type path only", repo.beginTxes, repo.reserved)
+	}
+}
+
+// transferTicketRepo registra las transferencias y devuelve err si está definido
+type transferTicketRepo struct {
+	repository.TicketRepository
+	err       error
+	transfers [][3]string
+}
+
+func (r *transferTicketRepo) TransferTicket(_ context.Context, ticketPublicID, fromCustomerPublicID, toCustomerPublicID string) error {
+	r.transfers = append(r.transfers, [3]string{ticketPublicID, fromCustomerPublicID, toCustomerPublicID})
+	return r.err
+}
+
+func (r *transferTicketRepo) GetByPublicID(_ context.Context, publicID string) (*entities.Ticket, error) {
+	return &entities.Ticket{PublicID: publicID, Status: "sold"}, nil
+}
+
+func TestTicketServiceTransferTicketUsesRepositoryTransaction(t *testing.T) {
+	ctx := context.Background()
+	repo := &transferTicketRepo{}
+	s := NewTicketService(repo, nil, nil, nil, nil, 0)
+
+	ticket, err := s.TransferTicket(ctx, &ticketdto.TransferTicketRequest{TicketID: "tkt", FromCustomerID: "from", ToCustomerID: "to"})
+	if err != nil {
+		t.Fatalf("TransferTicket: %v", err)
+	}
+	if ticket.PublicID != "tkt" || len(repo.transfers) != 1 || repo.transfers[0] != [3]string{"tkt", "from", "to"} {
+		t.Errorf("transfers = %v, ticket = %+v", repo.transfers, ticket)
+	}
+
+	// Sin remitente o hacia el mismo dueño no llega al repositorio
+	if _, err := s.TransferTicket(ctx, &ticketdto.TransferTicketRequest{TicketID: "tkt", ToCustomerID: "to"}); err == nil {
+		t.Error("transfer without sender succeeded")
+	}
+	if _, err := s.TransferTicket(ctx, &ticketdto.TransferTicketRequest{TicketID: "tkt", FromCustomerID: "Same", ToCustomerID: "same"}); !errors.Is(err, ErrSelfTransfer) {
+		t.Errorf("self transfer: err = %v, want ErrSelfTransfer", err)
+	}
+	if len(repo.transfers) != 1 {
+		t.Errorf("repository transfers = %d, want 1", len(repo.transfers))
+	}
+
+	// Los errores del repositorio se conservan para que el handler los mapee
+	for _, want := range []error{repository.ErrTicketNotFound, repository.ErrTicketOwnerMismatch, repository.ErrTicketNotAvailable} {
+		repo.err = want
+		if _, err := s.TransferTicket(ctx, &ticketdto.TransferTicketRequest{TicketID: "tkt", FromCustomerID: "from", ToCustomerID: "to"}); !errors.Is(err, want) {
+			t.Errorf("err = %v, want %v", err, want)
+		}
+	}
+}
+
+// checkInTicketRepo guarda un ticket vendido y registra los check-in
+type checkInTicketRepo struct {
+	repository.TicketRepository
+	ticket   *entities.Ticket
+	checkIns int
+}
+
+func (r *checkInTicketRepo) GetByPublicID(context.Context, string) (*entities.Ticket, error) {
+	copied := *r.ticket
+	return &copied, nil
+}
+
+func (r *checkInTicketRepo) GetByID(ctx context.Context, _ int64) (*entities.Ticket, error) {
+	return r.GetByPublicID(ctx, "")
+}
+
+func (r *checkInTicketRepo) CheckIn(_ context.Context, _ int64, method enums.CheckInMethod, _ string, _ *int64) error {
+	r.checkIns++
+	now := time.Now()
+	r.ticket.CheckedInAt = &now
+	r.ticket.CheckinMethod = (*string)(&method)
+	return nil
+}
+
+type checkInEventRepo struct {
+	repository.EventRepository
+	event *entities.Event
+}
+
+func (r checkInEventRepo) GetByID(context.Context, int64) (*entities.Event, error) {
+	return r.event, nil
+}
+
+func TestTicketServiceCheckInTicketHonorsEventWindow(t *testing.T) {
+	ctx := context.Background()
+
+	// El evento abre 3 horas antes; el default del servidor todavía no
+	startsAt := time.Now().Add(2 * time.Hour)
+	event := &entities.Event{ID: 1, StartsAt: startsAt, EndsAt: startsAt.Add(3 * time.Hour)}
+	repo := &checkInTicketRepo{ticket: &entities.Ticket{ID: 1, PublicID: "tkt", EventID: 1, Status: "sold"}}
+	s := NewTicketService(repo, nil, checkInEventRepo{event: event}, nil, nil, 0)
+	if err := s.SetCheckInWindow(30*time.Minute, time.Hour); err != nil {
+		t.Fatalf("SetCheckInWindow: %v", err)
+	}
+
+	if _, err := s.CheckInTicket(ctx, &ticketdto.CheckInTicketRequest{TicketID: "tkt"}); !errors.Is(err, ErrCheckInNotOpen) {
+		t.Fatalf("server window: err = %v, want ErrCheckInNotOpen", err)
+	}
+
+	event.Settings = &entities.EventSettings{CheckInWindowMinutes: 180}
+	ticket, err := s.CheckInTicket(ctx, &ticketdto.CheckInTicketRequest{TicketID: "tkt", Method: "nfc"})
+	if err != nil {
+		t.Fatalf("event window: %v", err)
+	}
+	if repo.checkIns != 1 || ticket.CheckedInAt == nil || ticket.CheckinMethod == nil || *ticket.CheckinMethod != "nfc" {
+		t.Errorf("check-ins = %d, ticket = %+v", repo.checkIns, ticket)
+	}
+}
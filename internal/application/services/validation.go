@@ -0,0 +1,23 @@
+// internal/application/services/validation.go
+package services
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// isDateRangeValid valida que endDate sea después de startDate
+func isDateRangeValid(start, end time.Time) bool {
+	return !end.Before(start)
+}
+
+// isValidUUID valida si un string es un UUID válido
+func isValidUUID(u string) bool {
+	if u == "" {
+		return false
+	}
+	pattern := `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`
+	match, _ := regexp.MatchString(pattern, strings.ToLower(u))
+	return match
+}
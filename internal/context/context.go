@@ -12,8 +12,10 @@ type contextKey string
 
 const (
 	UserIDKey    contextKey = "user_id"
+	UserRoleKey  contextKey = "user_role"
 	IPAddressKey contextKey = "ip_address"
 	UserAgentKey contextKey = "user_agent"
+	RequestIDKey contextKey = "request_id"
 )
 
 // AuditContext contiene información de auditoría
@@ -21,6 +23,7 @@ type AuditContext struct {
 	UserID    string
 	IPAddress string
 	UserAgent string
+	RequestID string
 	Metadata  map[string]interface{}
 }
 
@@ -51,6 +54,8 @@ func ExtractAuditContext(ctx context.Context) *AuditContext {
 		auditCtx.UserAgent = "osmi-server" // Default
 	}
 
+	auditCtx.RequestID = RequestIDFromContext(ctx)
+
 	return auditCtx
 }
 
@@ -59,6 +64,23 @@ func WithUserID(ctx context.Context, userID string) context.Context {
 	return context.WithValue(ctx, UserIDKey, userID)
 }
 
+// UserIDFromContext devuelve el usuario autenticado, o "" si la llamada es anónima
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(UserIDKey).(string)
+	return userID
+}
+
+// WithUserRole agrega el rol del usuario autenticado al contexto
+func WithUserRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, UserRoleKey, role)
+}
+
+// UserRoleFromContext devuelve el rol del usuario autenticado, o "" si no hay
+func UserRoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(UserRoleKey).(string)
+	return role
+}
+
 // WithIPAddress agrega IP Address al contexto
 func WithIPAddress(ctx context.Context, ip string) context.Context {
 	return context.WithValue(ctx, IPAddressKey, ip)
@@ -69,6 +91,17 @@ func WithUserAgent(ctx context.Context, userAgent string) context.Context {
 	return context.WithValue(ctx, UserAgentKey, userAgent)
 }
 
+// WithRequestID agrega el request id al contexto
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, requestID)
+}
+
+// RequestIDFromContext devuelve el request id del contexto, o "" si no hay
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+	return requestID
+}
+
 // ExtractFromHTTPRequest extrae información de auditoría de un HTTP request
 func ExtractFromHTTPRequest(r *http.Request) context.Context {
 	ctx := r.Context()
@@ -5,54 +5,27 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
+
+	"github.com/franciscozamorau/osmi-server/internal/config"
 )
 
 var Pool *pgxpool.Pool
 
-func init() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("No .env file found, using system environment")
-	}
-}
-
-// GetConnString devuelve la cadena de conexión (útil para sqlx)
-func GetConnString() string {
-	return getConnectionString()
-}
+// Replica es el pool opcional de la réplica de lectura; nil si no está configurada o no responde
+var Replica *pgxpool.Pool
 
 // Init inicializa la conexión a la base de datos usando pgxpool
-func Init() error {
-	connStr := getConnectionString()
-
-	config, err := pgxpool.ParseConfig(connStr)
+func Init(cfg config.DatabaseConfig) error {
+	poolConfig, err := newPoolConfig(cfg.URL, cfg)
 	if err != nil {
-		return fmt.Errorf("unable to parse connection string: %w", err)
+		return err
 	}
 
-	config.MaxConns = 25
-	config.MinConns = 5
-	config.MaxConnLifetime = 5 * time.Minute
-	config.MaxConnIdleTime = 2 * time.Minute
-	config.HealthCheckPeriod = 1 * time.Minute
-
-	// Configurar search_path por cada conexión
-	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
-		_, err := conn.Exec(ctx, "SET search_path TO ticketing, public")
-		if err != nil {
-			return fmt.Errorf("failed to set search_path: %w", err)
-		}
-		log.Println("✅ search_path configurado a ticketing, public")
-		return nil
-	}
-
-	Pool, err = pgxpool.NewWithConfig(context.Background(), config)
+	Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return fmt.Errorf("unable to create connection pool: %w", err)
 	}
@@ -64,44 +37,73 @@ func Init() error {
 		return fmt.Errorf("unable to ping database: %w", err)
 	}
 
-	log.Printf("✅ Database connected successfully (connections: %d)", config.MaxConns)
+	log.Printf("✅ Database connected successfully (connections: %d)", poolConfig.MaxConns)
+
+	if cfg.ReplicaURL != "" {
+		initReplica(cfg)
+	}
 	return nil
 }
 
-func getConnectionString() string {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn != "" {
-		return dsn
+// initReplica conecta la réplica de lectura. Es opcional: si falla se registra
+// y las lecturas siguen yendo al primario.
+func initReplica(cfg config.DatabaseConfig) {
+	poolConfig, err := newPoolConfig(cfg.ReplicaURL, cfg)
+	if err != nil {
+		log.Printf("⚠️ Read replica disabled: %v", err)
+		return
 	}
 
-	host := mustEnv("DB_HOST")
-	port := mustEnv("DB_PORT")
-	user := mustEnv("DB_USER")
-	password := mustEnv("DB_PASSWORD")
-	dbname := mustEnv("DB_NAME")
-	sslmode := getEnv("DB_SSLMODE", "disable")
+	replica, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		log.Printf("⚠️ Read replica disabled: unable to create pool: %v", err)
+		return
+	}
 
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		user, password, host, port, dbname, sslmode)
-}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-func mustEnv(key string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		log.Fatalf("❌ Missing required environment variable: %s", key)
+	if err := replica.Ping(ctx); err != nil {
+		// Se conserva el pool: pgxpool reconecta cuando la réplica vuelva y
+		// mientras tanto el repositorio cae al primario
+		log.Printf("⚠️ Read replica not responding, reads will fall back to primary: %v", err)
+	} else {
+		log.Println("✅ Read replica connected")
 	}
-	return value
+	Replica = replica
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// newPoolConfig arma la configuración del pool con los límites comunes
+func newPoolConfig(url string, cfg config.DatabaseConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse connection string: %w", err)
 	}
-	return defaultValue
+
+	poolConfig.MaxConns = int32(cfg.MaxOpenConns)
+	poolConfig.MinConns = int32(cfg.MaxIdleConns)
+	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
+	poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+
+	// Configurar search_path por cada conexión
+	poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, "SET search_path TO ticketing, public")
+		if err != nil {
+			return fmt.Errorf("failed to set search_path: %w", err)
+		}
+		log.Println("✅ search_path configurado a ticketing, public")
+		return nil
+	}
+
+	return poolConfig, nil
 }
 
 // Close cierra el pool de conexiones
 func Close() {
+	if Replica != nil {
+		Replica.Close()
+	}
 	if Pool != nil {
 		Pool.Close()
 		log.Println("✅ Database connection closed")
@@ -0,0 +1,111 @@
+// internal/database/migrate.go
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockID es la llave del advisory lock que serializa Migrate entre réplicas
+const migrationLockID int64 = 0x6f736d69 // "osmi"
+
+// Migrate aplica las migraciones embebidas pendientes sobre Pool.
+// Cada archivo corre en su propia transacción y su versión (el nombre del
+// archivo sin extensión) se registra en public.schema_migrations. Un advisory
+// lock de sesión evita que dos instancias migren a la vez.
+func Migrate(ctx context.Context) error {
+	if Pool == nil {
+		return fmt.Errorf("database pool is not initialized")
+	}
+	return migrate(ctx, Pool, migrationsFS)
+}
+
+// MigratePool aplica las migraciones embebidas sobre pool; lo usan los tests
+// de integración, que no pasan por Connect
+func MigratePool(ctx context.Context, pool *pgxpool.Pool) error {
+	return migrate(ctx, pool, migrationsFS)
+}
+
+func migrate(ctx context.Context, pool *pgxpool.Pool, migrations fs.FS) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS public.schema_migrations (
+			version    TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(files)
+
+	applied := 0
+	for _, file := range files {
+		version := strings.TrimSuffix(file[strings.LastIndex(file, "/")+1:], ".sql")
+
+		var exists bool
+		err := conn.QueryRow(ctx,
+			"SELECT EXISTS (SELECT 1 FROM public.schema_migrations WHERE version = $1)", version,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", version, err)
+		}
+		if exists {
+			continue
+		}
+
+		sql, err := fs.ReadFile(migrations, file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", version, err)
+		}
+
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %s: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, string(sql)); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to apply migration %s: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, "INSERT INTO public.schema_migrations (version) VALUES ($1)", version); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("failed to record migration %s: %w", version, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit migration %s: %w", version, err)
+		}
+
+		log.Printf("✅ Migration applied: %s", version)
+		applied++
+	}
+
+	if applied == 0 {
+		log.Println("✅ Database schema up to date")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool abre TEST_DATABASE_URL sin pasar por testsupport, que importa este paquete
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// scratchMigrations crea dos migraciones sobre un esquema propio del test. Los
+// CREATE no usan IF NOT EXISTS: aplicar una migración dos veces fallaría.
+func scratchMigrations(t *testing.T, pool *pgxpool.Pool) (fs.FS, []string) {
+	t.Helper()
+	suffix := uuid.NewString()[:8]
+	schema := "migrate_test_" + suffix
+	versions := []string{"9001_" + schema + "_a", "9002_" + schema + "_b"}
+
+	migrations := fstest.MapFS{
+		"migrations/" + versions[0] + ".sql": {Data: []byte(fmt.Sprintf(
+			"CREATE SCHEMA %[1]s; CREATE TABLE %[1]s.items (id BIGSERIAL PRIMARY KEY);", schema))},
+		"migrations/" + versions[1] + ".sql": {Data: []byte(fmt.Sprintf(
+			"ALTER TABLE %[1]s.items ADD COLUMN name TEXT NOT NULL;", schema))},
+	}
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		pool.Exec(ctx, "DROP SCHEMA IF EXISTS "+schema+" CASCADE")
+		pool.Exec(ctx, "DELETE FROM public.schema_migrations WHERE version = ANY($1)", versions)
+	})
+	return migrations, versions
+}
+
+func appliedCount(t *testing.T, pool *pgxpool.Pool, versions []string) int {
+	t.Helper()
+	var n int
+	err := pool.QueryRow(context.Background(),
+		"SELECT COUNT(*) FROM public.schema_migrations WHERE version = ANY($1)", versions,
+	).Scan(&n)
+	if err != nil {
+		t.Fatalf("failed to count applied migrations: %v", err)
+	}
+	return n
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+	migrations, versions := scratchMigrations(t, pool)
+
+	if err := migrate(ctx, pool, migrations); err != nil {
+		t.Fatalf("first migrate: %v", err)
+	}
+	if err := migrate(ctx, pool, migrations); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+	if n := appliedCount(t, pool, versions); n != len(versions) {
+		t.Errorf("recorded %d versions, want %d", n, len(versions))
+	}
+}
+
+func TestMigrateConcurrentRunsApplyOnce(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+	migrations, versions := scratchMigrations(t, pool)
+
+	const replicas = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, replicas)
+	for i := 0; i < replicas; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- migrate(ctx, pool, migrations)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent migrate: %v", err)
+		}
+	}
+	if n := appliedCount(t, pool, versions); n != len(versions) {
+		t.Errorf("recorded %d versions, want %d", n, len(versions))
+	}
+}
+
+func TestEmbeddedMigrationsAreOrderedAndUnique(t *testing.T) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		t.Fatalf("failed to list migrations: %v", err)
+	}
+	seen := map[string]string{}
+	for _, file := range files {
+		name := file[strings.LastIndex(file, "/")+1:]
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok || len(prefix) != 4 {
+			t.Errorf("%s: want a four-digit NNNN_ prefix", name)
+			continue
+		}
+		if other, dup := seen[prefix]; dup {
+			t.Errorf("%s and %s share version prefix %s", other, name, prefix)
+		}
+		seen[prefix] = name
+	}
+}
@@ -0,0 +1,4 @@
+-- Motivo de cancelación de eventos cancelados o eliminados
+
+ALTER TABLE ticketing.events
+    ADD COLUMN IF NOT EXISTS cancellation_reason TEXT;
@@ -0,0 +1,14 @@
+-- Historial de cambios de estado de tickets (anulaciones, reasignaciones, reservas)
+
+CREATE TABLE IF NOT EXISTS ticketing.ticket_status_history (
+    id          BIGSERIAL PRIMARY KEY,
+    ticket_id   BIGINT NOT NULL REFERENCES ticketing.tickets(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status   TEXT NOT NULL,
+    reason      TEXT,
+    changed_by  TEXT,
+    changed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_status_history_ticket
+    ON ticketing.ticket_status_history (ticket_id, changed_at);
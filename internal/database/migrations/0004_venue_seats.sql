@@ -0,0 +1,13 @@
+-- Butacas numeradas por recinto y asiento asignado a cada ticket
+
+CREATE TABLE IF NOT EXISTS ticketing.venue_seats (
+    id          BIGSERIAL PRIMARY KEY,
+    venue_id    BIGINT NOT NULL,
+    section     TEXT NOT NULL,
+    row_label   TEXT NOT NULL,
+    seat_number TEXT NOT NULL,
+    UNIQUE (venue_id, section, row_label, seat_number)
+);
+
+ALTER TABLE ticketing.tickets
+    ADD COLUMN IF NOT EXISTS seat_id BIGINT REFERENCES ticketing.venue_seats(id);
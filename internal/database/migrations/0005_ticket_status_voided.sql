@@ -0,0 +1,21 @@
+-- Estado 'voided' para tickets invalidados administrativamente (VoidTicket).
+-- El nombre del CHECK original del dominio no es estable entre entornos, así
+-- que se reemplazan todos sus CHECK por uno con la lista completa de estados.
+
+DO $$
+DECLARE
+    c RECORD;
+BEGIN
+    FOR c IN
+        SELECT conname
+        FROM pg_constraint
+        WHERE contypid = 'ticketing.ticket_status'::regtype
+          AND contype = 'c'
+    LOOP
+        EXECUTE format('ALTER DOMAIN ticketing.ticket_status DROP CONSTRAINT %I', c.conname);
+    END LOOP;
+END;
+$$;
+
+ALTER DOMAIN ticketing.ticket_status ADD CONSTRAINT ticket_status_check
+    CHECK (VALUE IN ('available', 'reserved', 'sold', 'checked_in', 'cancelled', 'refunded', 'expired', 'voided'));
@@ -0,0 +1,13 @@
+-- Historial de cambios de estado de eventos (publicar / despublicar)
+
+CREATE TABLE IF NOT EXISTS ticketing.event_status_history (
+    id          BIGSERIAL PRIMARY KEY,
+    event_id    BIGINT NOT NULL REFERENCES ticketing.events(id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status   TEXT NOT NULL,
+    changed_by  TEXT,
+    changed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_status_history_event
+    ON ticketing.event_status_history (event_id, changed_at);
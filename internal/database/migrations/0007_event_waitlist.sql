@@ -0,0 +1,14 @@
+-- Lista de espera para eventos agotados
+
+CREATE TABLE IF NOT EXISTS ticketing.event_waitlist (
+    id          BIGSERIAL PRIMARY KEY,
+    event_id    BIGINT NOT NULL REFERENCES ticketing.events(id) ON DELETE CASCADE,
+    customer_id BIGINT NOT NULL REFERENCES crm.customers(id) ON DELETE CASCADE,
+    joined_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    notified_at TIMESTAMPTZ,
+    UNIQUE (event_id, customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_waitlist_pending
+    ON ticketing.event_waitlist (event_id, joined_at, id)
+    WHERE notified_at IS NULL;
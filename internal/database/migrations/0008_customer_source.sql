@@ -0,0 +1,6 @@
+-- Canal de adquisición del cliente (web, referral, import, ...)
+
+ALTER TABLE crm.customers ADD COLUMN IF NOT EXISTS source VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_customers_created_source
+    ON crm.customers (created_at, source);
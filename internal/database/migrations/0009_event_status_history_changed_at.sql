@@ -0,0 +1,4 @@
+-- Lectura incremental del historial de estados (GetStatusChangesSince)
+
+CREATE INDEX IF NOT EXISTS idx_event_status_history_changed_at
+    ON ticketing.event_status_history (changed_at, id);
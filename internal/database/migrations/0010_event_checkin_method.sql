@@ -0,0 +1,6 @@
+-- settings.checkin_method usa el vocabulario de enums.CheckInMethod
+-- (qr, manual, nfc, code, rfid); qr_code se reescribe a qr.
+
+UPDATE ticketing.events
+SET settings = jsonb_set(settings, '{checkin_method}', to_jsonb('qr'::text))
+WHERE settings->>'checkin_method' = 'qr_code';
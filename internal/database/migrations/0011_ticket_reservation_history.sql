@@ -0,0 +1,27 @@
+-- Historial de reservas en ticket_status_history (GetReservationStats).
+-- Las reservas se crean y resuelven por varios caminos (ReserveTicket, checkout,
+-- compra, barrido de vencidas); el trigger registra todos en un solo lugar.
+
+CREATE OR REPLACE FUNCTION ticketing.log_ticket_reservation_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.status = 'reserved' THEN
+            INSERT INTO ticketing.ticket_status_history (ticket_id, from_status, to_status, reason)
+            VALUES (NEW.id, 'available', 'reserved', 'reservation');
+        END IF;
+    -- reserved -> voided ya lo registra VoidTicket
+    ELSIF NEW.status IS DISTINCT FROM OLD.status
+        AND (NEW.status = 'reserved'
+             OR (OLD.status = 'reserved' AND NEW.status IN ('sold', 'available', 'expired'))) THEN
+        INSERT INTO ticketing.ticket_status_history (ticket_id, from_status, to_status, reason)
+        VALUES (NEW.id, OLD.status, NEW.status, 'reservation');
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_ticket_reservation_history ON ticketing.tickets;
+CREATE TRIGGER trg_ticket_reservation_history
+    AFTER INSERT OR UPDATE OF status ON ticketing.tickets
+    FOR EACH ROW EXECUTE FUNCTION ticketing.log_ticket_reservation_change();
@@ -0,0 +1,30 @@
+-- Identificador público de pagos (FindByPublicID) y estados del flujo
+-- pending -> authorized -> captured -> refunded/failed. processing y completed
+-- se conservan para los pagos registrados con el flujo anterior.
+
+ALTER TABLE billing.payments
+    ADD COLUMN IF NOT EXISTS public_uuid UUID NOT NULL DEFAULT gen_random_uuid();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_public_uuid
+    ON billing.payments (public_uuid);
+
+-- Igual que en 0005: el nombre del CHECK original del dominio no es estable
+-- entre entornos, así que se reemplazan todos por uno con la lista completa.
+DO $$
+DECLARE
+    c RECORD;
+BEGIN
+    FOR c IN
+        SELECT conname
+        FROM pg_constraint
+        WHERE contypid = 'billing.payment_status'::regtype
+          AND contype = 'c'
+    LOOP
+        EXECUTE format('ALTER DOMAIN billing.payment_status DROP CONSTRAINT %I', c.conname);
+    END LOOP;
+END;
+$$;
+
+ALTER DOMAIN billing.payment_status ADD CONSTRAINT payment_status_check
+    CHECK (VALUE IN ('pending', 'authorized', 'captured', 'processing', 'completed',
+                     'failed', 'refunded', 'disputed', 'chargeback', 'expired'));
@@ -0,0 +1,26 @@
+-- Estado 'transferred' para tickets vendidos que cambiaron de titular
+-- (TransferTicket) y referencia al ticket del que proviene la titularidad.
+-- Igual que en 0005 se reemplazan todos los CHECK del dominio por uno con la
+-- lista completa de estados.
+
+DO $$
+DECLARE
+    c RECORD;
+BEGIN
+    FOR c IN
+        SELECT conname
+        FROM pg_constraint
+        WHERE contypid = 'ticketing.ticket_status'::regtype
+          AND contype = 'c'
+    LOOP
+        EXECUTE format('ALTER DOMAIN ticketing.ticket_status DROP CONSTRAINT %I', c.conname);
+    END LOOP;
+END;
+$$;
+
+ALTER DOMAIN ticketing.ticket_status ADD CONSTRAINT ticket_status_check
+    CHECK (VALUE IN ('available', 'reserved', 'sold', 'transferred', 'checked_in', 'cancelled',
+                     'refunded', 'expired', 'voided'));
+
+ALTER TABLE ticketing.tickets
+    ADD COLUMN IF NOT EXISTS transferred_from_ticket_id BIGINT REFERENCES ticketing.tickets (id);
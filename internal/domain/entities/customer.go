@@ -48,6 +48,7 @@ type Customer struct {
 
 	CustomerSegment string  `json:"customer_segment" db:"customer_segment"` // VARCHAR(50) con default 'new'
 	LifetimeValue   float64 `json:"lifetime_value" db:"lifetime_value"`     // DECIMAL(15,2)
+	Source          *string `json:"source,omitempty" db:"source"`           // Canal de adquisición
 
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
@@ -117,6 +118,19 @@ func (c *Customer) UpdateStats(orderAmount float64, ticketCount int, orderTime t
 	c.updateSegment()
 }
 
+// customerSegments son los segmentos que puede tener un cliente
+var customerSegments = map[string]bool{
+	"new":        true,
+	"occasional": true,
+	"regular":    true,
+	"vip":        true,
+}
+
+// IsValidCustomerSegment indica si segment es un segmento conocido
+func IsValidCustomerSegment(segment string) bool {
+	return customerSegments[segment]
+}
+
 // updateSegment actualiza el segmento del cliente basado en su actividad
 func (c *Customer) updateSegment() {
 	const (
@@ -143,6 +157,9 @@ func (c *Customer) updateSegment() {
 	}
 }
 
+// CommunicationPreferenceMarketing es la preferencia de consentimiento para comunicaciones de marketing
+const CommunicationPreferenceMarketing = "marketing"
+
 // GetCommunicationPreference obtiene una preferencia específica
 func (c *Customer) GetCommunicationPreference(key string) bool {
 	if c.CommunicationPreferences == nil {
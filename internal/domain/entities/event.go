@@ -2,7 +2,10 @@ package entities
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
 )
 
 // Event representa un evento en el sistema de ticketing
@@ -42,6 +45,8 @@ type Event struct {
 	IsFeatured bool   `json:"is_featured" db:"is_featured"`
 	IsFree     bool   `json:"is_free" db:"is_free"`
 
+	CancellationReason *string `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+
 	MaxAttendees *int `json:"max_attendees,omitempty" db:"max_attendees"`
 	MinAttendees int  `json:"min_attendees" db:"min_attendees"`
 
@@ -70,11 +75,55 @@ type Event struct {
 
 // EventSettings representa la configuración JSONB del evento
 type EventSettings struct {
-	AllowCancellations        bool   `json:"allow_cancellations"`
-	CancellationDeadlineHours int    `json:"cancellation_deadline_hours"`
-	AllowTransfers            bool   `json:"allow_transfers"`
-	RequireID                 bool   `json:"require_id"`
-	CheckinMethod             string `json:"checkin_method"` // qr_code, manual, rfid
+	AllowCancellations        bool                `json:"allow_cancellations"`
+	CancellationDeadlineHours int                 `json:"cancellation_deadline_hours"`
+	AllowTransfers            bool                `json:"allow_transfers"`
+	RequireID                 bool                `json:"require_id"`
+	CheckinMethod             enums.CheckInMethod `json:"checkin_method"`               // qr, manual, nfc, code, rfid
+	TicketCodePrefix          string              `json:"ticket_code_prefix,omitempty"` // vacío usa el default del servidor
+	AllowWaitlist             bool                `json:"allow_waitlist"`
+	ShowRemainingCount        bool                `json:"show_remaining_count"`
+	RefundPolicy              enums.RefundPolicy  `json:"refund_policy,omitempty"`
+	CheckInWindowMinutes      int                 `json:"check_in_window_minutes"` // minutos antes del inicio en que abre el check-in
+}
+
+// Validate verifica los campos tipo enum y los rangos de la configuración
+func (s EventSettings) Validate() error {
+	if s.RefundPolicy != "" && !s.RefundPolicy.IsValid() {
+		return fmt.Errorf("invalid refund policy: %q", s.RefundPolicy)
+	}
+	if s.CheckinMethod != "" && !s.CheckinMethod.IsValid() {
+		return fmt.Errorf("invalid checkin method: %q", s.CheckinMethod)
+	}
+	if s.CancellationDeadlineHours < 0 {
+		return fmt.Errorf("cancellation_deadline_hours cannot be negative")
+	}
+	if s.CheckInWindowMinutes < 0 {
+		return fmt.Errorf("check_in_window_minutes cannot be negative")
+	}
+	if s.TicketCodePrefix != "" {
+		if err := ValidateTicketCodePrefix(s.TicketCodePrefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxTicketCodePrefixLength es el largo máximo del prefijo de códigos de ticket
+const MaxTicketCodePrefixLength = 10
+
+// ValidateTicketCodePrefix exige un prefijo alfanumérico ASCII de 1 a
+// MaxTicketCodePrefixLength caracteres
+func ValidateTicketCodePrefix(prefix string) error {
+	if prefix == "" || len(prefix) > MaxTicketCodePrefixLength {
+		return fmt.Errorf("ticket code prefix must be 1-%d characters", MaxTicketCodePrefixLength)
+	}
+	for _, c := range prefix {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9') {
+			return fmt.Errorf("ticket code prefix must be alphanumeric: %q", prefix)
+		}
+	}
+	return nil
 }
 
 // ============================================================================
@@ -150,7 +199,11 @@ func GetDefaultSettings() EventSettings {
 		CancellationDeadlineHours: 24,
 		AllowTransfers:            true,
 		RequireID:                 false,
-		CheckinMethod:             "qr_code",
+		CheckinMethod:             enums.CheckInMethodQR,
+		AllowWaitlist:             false,
+		ShowRemainingCount:        false,
+		RefundPolicy:              enums.RefundPolicyModerate,
+		CheckInWindowMinutes:      60,
 	}
 }
 
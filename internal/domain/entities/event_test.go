@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+)
+
+func TestValidateTicketCodePrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		valid  bool
+	}{
+		{"ACME", true},
+		{"acme2026", true},
+		{"ABCDEFGHIJ", true},
+		{"", false},
+		{"ABCDEFGHIJK", false}, // más de MaxTicketCodePrefixLength
+		{"AC-ME", false},
+		{"ÁCME", false},
+		{"AC ME", false},
+	}
+	for _, tt := range tests {
+		err := ValidateTicketCodePrefix(tt.prefix)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateTicketCodePrefix(%q) = %v, want valid = %v", tt.prefix, err, tt.valid)
+		}
+	}
+
+	// En la configuración del evento vacío significa "usar el default"
+	if err := (EventSettings{}).Validate(); err != nil {
+		t.Errorf("settings without prefix: %v", err)
+	}
+	if err := (EventSettings{TicketCodePrefix: "AC-ME"}).Validate(); err == nil {
+		t.Error("settings with an invalid prefix passed validation")
+	}
+}
+
+func TestEventSettingsValidateCheckinMethod(t *testing.T) {
+	if err := GetDefaultSettings().Validate(); err != nil {
+		t.Errorf("default settings: %v", err)
+	}
+	for _, method := range enums.GetAllCheckInMethods() {
+		if err := (EventSettings{CheckinMethod: method}).Validate(); err != nil {
+			t.Errorf("settings with method %q: %v", method, err)
+		}
+	}
+	// qr_code es del vocabulario anterior y ya no es válido
+	for _, method := range []enums.CheckInMethod{"qr_code", "face"} {
+		if err := (EventSettings{CheckinMethod: method}).Validate(); err == nil {
+			t.Errorf("settings with method %q passed validation", method)
+		}
+	}
+}
@@ -8,8 +8,9 @@ import (
 // Payment representa un pago en el sistema
 // Mapea exactamente la tabla billing.payments
 type Payment struct {
-	ID      int64 `json:"id" db:"id"`
-	OrderID int64 `json:"order_id" db:"order_id"`
+	ID       int64  `json:"id" db:"id"`
+	PublicID string `json:"public_id" db:"public_uuid"`
+	OrderID  int64  `json:"order_id" db:"order_id"`
 
 	// CORREGIDO: SMALLINT en la BD -> int16 en Go
 	ProviderID            int16   `json:"provider_id" db:"provider_id"`
@@ -2,6 +2,8 @@
 package entities
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 )
@@ -20,7 +22,7 @@ type Ticket struct {
 	SecretHash string  `json:"-" db:"secret_hash"` // Nunca se expone en JSON
 	QRCodeData *string `json:"qr_code_data,omitempty" db:"qr_code_data"`
 
-	Status string `json:"status" db:"status"` // available, reserved, sold, checked_in, cancelled, refunded, expired
+	Status string `json:"status" db:"status"` // available, reserved, sold, transferred, checked_in, cancelled, refunded, expired, voided
 
 	FinalPrice float64 `json:"final_price" db:"final_price"`
 	Currency   string  `json:"currency" db:"currency"`
@@ -76,6 +78,11 @@ func (t *Ticket) IsSold() bool {
 	return t.Status == "sold"
 }
 
+// IsTransferred verifica si el ticket vendido cambió de titular
+func (t *Ticket) IsTransferred() bool {
+	return t.Status == "transferred"
+}
+
 // IsCheckedIn verifica si el ticket ha sido usado (check-in)
 func (t *Ticket) IsCheckedIn() bool {
 	return t.Status == "checked_in" || t.CheckedInAt != nil
@@ -98,27 +105,27 @@ func (t *Ticket) IsExpired() bool {
 
 // IsActive verifica si el ticket está activo (disponible para usar)
 func (t *Ticket) IsActive() bool {
-	return t.IsSold() && !t.IsCheckedIn() && !t.IsCancelled() && !t.IsRefunded() && !t.IsExpired()
+	return (t.IsSold() || t.IsTransferred()) && !t.IsCheckedIn() && !t.IsCancelled() && !t.IsRefunded() && !t.IsExpired()
 }
 
 // CanBeCheckedIn verifica si el ticket puede ser marcado como usado
 func (t *Ticket) CanBeCheckedIn() bool {
-	return t.IsSold() && !t.IsCheckedIn() && !t.IsCancelled() && !t.IsRefunded()
+	return (t.IsSold() || t.IsTransferred()) && !t.IsCheckedIn() && !t.IsCancelled() && !t.IsRefunded()
 }
 
 // CanBeCancelled verifica si el ticket puede ser cancelado
 func (t *Ticket) CanBeCancelled() bool {
-	return (t.IsAvailable() || t.IsReserved() || t.IsSold()) && !t.IsCheckedIn() && !t.IsCancelled() && !t.IsRefunded()
+	return (t.IsAvailable() || t.IsReserved() || t.IsSold() || t.IsTransferred()) && !t.IsCheckedIn() && !t.IsCancelled() && !t.IsRefunded()
 }
 
 // CanBeRefunded verifica si el ticket puede ser reembolsado
 func (t *Ticket) CanBeRefunded() bool {
-	return t.IsSold() && !t.IsCheckedIn() && !t.IsRefunded()
+	return (t.IsSold() || t.IsTransferred()) && !t.IsCheckedIn() && !t.IsRefunded()
 }
 
 // CanBeTransferred verifica si el ticket puede ser transferido
 func (t *Ticket) CanBeTransferred() bool {
-	return t.IsSold() && !t.IsCheckedIn() && !t.IsCancelled() && !t.IsRefunded()
+	return (t.IsSold() || t.IsTransferred()) && !t.IsCheckedIn() && !t.IsCancelled() && !t.IsRefunded()
 }
 
 // MarkAsSold marca el ticket como vendido
@@ -186,6 +193,7 @@ func (t *Ticket) MarkAsExpired() {
 // Transfer transfiere el ticket a otro cliente
 func (t *Ticket) Transfer(fromCustomerID int64, toCustomerID int64, transferToken string) {
 	now := time.Now()
+	t.Status = "transferred"
 	t.TransferredFrom = &fromCustomerID
 	t.CustomerID = &toCustomerID
 	t.TransferToken = &transferToken
@@ -217,6 +225,14 @@ func (t *Ticket) GetTimeUntilExpiry() time.Duration {
 	return t.ReservationExpiresAt.Sub(time.Now())
 }
 
+// NewTicketSecretHash genera el secret_hash de un ticket nuevo: 32 bytes
+// aleatorios en hexadecimal
+func NewTicketSecretHash() string {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return hex.EncodeToString(secret)
+}
+
 // Validate verifica que el ticket sea válido
 func (t *Ticket) Validate() error {
 	if t.TicketTypeID == 0 {
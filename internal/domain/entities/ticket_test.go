@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewTicketSecretHash(t *testing.T) {
+	first, second := NewTicketSecretHash(), NewTicketSecretHash()
+	if len(first) != 64 {
+		t.Fatalf("len = %d, want 64", len(first))
+	}
+	if _, err := hex.DecodeString(first); err != nil {
+		t.Errorf("secret %q is not hex: %v", first, err)
+	}
+	if first == second {
+		t.Error("two secrets are equal")
+	}
+}
@@ -0,0 +1,72 @@
+package enums
+
+import "strings"
+
+// CheckInMethod representa cómo se validó un ticket en el acceso al evento
+// Se guarda en ticketing.tickets.checkin_method
+type CheckInMethod string
+
+const (
+	// CheckInMethodQR - Escaneo del código QR del ticket
+	CheckInMethodQR CheckInMethod = "qr"
+	// CheckInMethodManual - Validación manual por el personal de acceso
+	CheckInMethodManual CheckInMethod = "manual"
+	// CheckInMethodNFC - Lectura NFC (pulsera o tarjeta)
+	CheckInMethodNFC CheckInMethod = "nfc"
+	// CheckInMethodCode - Captura del código alfanumérico del ticket
+	CheckInMethodCode CheckInMethod = "code"
+	// CheckInMethodRFID - Lectura RFID de largo alcance (pulsera UHF)
+	CheckInMethodRFID CheckInMethod = "rfid"
+)
+
+// DefaultCheckInMethod es el método usado cuando la solicitud no indica ninguno
+const DefaultCheckInMethod = CheckInMethodManual
+
+// IsValid verifica si el valor del enum es válido
+func (m CheckInMethod) IsValid() bool {
+	switch m {
+	case CheckInMethodQR, CheckInMethodManual, CheckInMethodNFC, CheckInMethodCode, CheckInMethodRFID:
+		return true
+	}
+	return false
+}
+
+// String devuelve la representación string del método
+func (m CheckInMethod) String() string {
+	return string(m)
+}
+
+// ParseCheckInMethod normaliza el método recibido. Vacío equivale a
+// DefaultCheckInMethod; un valor desconocido devuelve *InvalidCheckInMethodError.
+func ParseCheckInMethod(s string) (CheckInMethod, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return DefaultCheckInMethod, nil
+	}
+
+	method := CheckInMethod(s)
+	if !method.IsValid() {
+		return "", &InvalidCheckInMethodError{Method: s}
+	}
+	return method, nil
+}
+
+// GetAllCheckInMethods devuelve todos los métodos de check-in posibles
+func GetAllCheckInMethods() []CheckInMethod {
+	return []CheckInMethod{
+		CheckInMethodQR,
+		CheckInMethodManual,
+		CheckInMethodNFC,
+		CheckInMethodCode,
+		CheckInMethodRFID,
+	}
+}
+
+// InvalidCheckInMethodError error para valores inválidos
+type InvalidCheckInMethodError struct {
+	Method string
+}
+
+func (e *InvalidCheckInMethodError) Error() string {
+	return "invalid check-in method: " + e.Method
+}
@@ -0,0 +1,36 @@
+package enums
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCheckInMethod(t *testing.T) {
+	tests := []struct {
+		in   string
+		want CheckInMethod
+	}{
+		{"", DefaultCheckInMethod},
+		{"  ", DefaultCheckInMethod},
+		{"qr", CheckInMethodQR},
+		{" QR ", CheckInMethodQR},
+		{"manual", CheckInMethodManual},
+		{"nfc", CheckInMethodNFC},
+		{"code", CheckInMethodCode},
+		{"rfid", CheckInMethodRFID},
+	}
+	for _, tt := range tests {
+		got, err := ParseCheckInMethod(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseCheckInMethod(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"qr_code", "face"} {
+		_, err := ParseCheckInMethod(in)
+		var invalid *InvalidCheckInMethodError
+		if !errors.As(err, &invalid) || invalid.Method != in {
+			t.Errorf("ParseCheckInMethod(%q) err = %v, want InvalidCheckInMethodError", in, err)
+		}
+	}
+}
@@ -87,8 +87,10 @@ func (es EventStatus) CanTransitionTo(to EventStatus) bool {
 		return to == EventStatusPublished || to == EventStatusLive ||
 			to == EventStatusCancelled || to == EventStatusSoldOut
 	case EventStatusPublished:
+		// draft = despublicar
 		return to == EventStatusLive || to == EventStatusSoldOut ||
-			to == EventStatusCancelled || to == EventStatusCompleted
+			to == EventStatusCancelled || to == EventStatusCompleted ||
+			to == EventStatusDraft
 	case EventStatusLive:
 		return to == EventStatusCompleted || to == EventStatusCancelled ||
 			to == EventStatusSoldOut
@@ -114,7 +116,7 @@ func (es EventStatus) GetNextStatuses() []EventStatus {
 	case EventStatusScheduled:
 		return []EventStatus{EventStatusPublished, EventStatusLive, EventStatusCancelled, EventStatusSoldOut}
 	case EventStatusPublished:
-		return []EventStatus{EventStatusLive, EventStatusSoldOut, EventStatusCancelled, EventStatusCompleted}
+		return []EventStatus{EventStatusLive, EventStatusSoldOut, EventStatusCancelled, EventStatusCompleted, EventStatusDraft}
 	case EventStatusLive:
 		return []EventStatus{EventStatusCompleted, EventStatusCancelled, EventStatusSoldOut}
 	case EventStatusSoldOut:
@@ -7,9 +7,13 @@ type PaymentStatus string
 const (
 	// PaymentStatusPending - Pago pendiente de procesar
 	PaymentStatusPending PaymentStatus = "pending"
-	// PaymentStatusProcessing - Pago en proceso
+	// PaymentStatusAuthorized - Pago autorizado por el proveedor, pendiente de captura
+	PaymentStatusAuthorized PaymentStatus = "authorized"
+	// PaymentStatusCaptured - Pago capturado (cobrado)
+	PaymentStatusCaptured PaymentStatus = "captured"
+	// PaymentStatusProcessing - Pago en proceso (flujo anterior a authorized/captured)
 	PaymentStatusProcessing PaymentStatus = "processing"
-	// PaymentStatusCompleted - Pago completado exitosamente
+	// PaymentStatusCompleted - Pago completado exitosamente (flujo anterior a authorized/captured)
 	PaymentStatusCompleted PaymentStatus = "completed"
 	// PaymentStatusFailed - Pago fallido
 	PaymentStatusFailed PaymentStatus = "failed"
@@ -26,8 +30,8 @@ const (
 // IsValid verifica si el valor del enum es válido según el dominio de la BD
 func (ps PaymentStatus) IsValid() bool {
 	switch ps {
-	case PaymentStatusPending, PaymentStatusProcessing, PaymentStatusCompleted,
-		PaymentStatusFailed, PaymentStatusRefunded, PaymentStatusDisputed,
+	case PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusCaptured,
+		PaymentStatusProcessing, PaymentStatusCompleted, PaymentStatusFailed, PaymentStatusRefunded, PaymentStatusDisputed,
 		PaymentStatusChargeback, PaymentStatusExpired:
 		return true
 	}
@@ -36,12 +40,12 @@ func (ps PaymentStatus) IsValid() bool {
 
 // IsSuccessful indica si el pago fue exitoso
 func (ps PaymentStatus) IsSuccessful() bool {
-	return ps == PaymentStatusCompleted
+	return ps == PaymentStatusCaptured || ps == PaymentStatusCompleted
 }
 
 // IsPending indica si el pago está pendiente
 func (ps PaymentStatus) IsPending() bool {
-	return ps == PaymentStatusPending || ps == PaymentStatusProcessing
+	return ps == PaymentStatusPending || ps == PaymentStatusAuthorized || ps == PaymentStatusProcessing
 }
 
 // IsFailed indica si el pago falló
@@ -71,7 +75,7 @@ func (ps PaymentStatus) IsExpired() bool {
 
 // IsFinal indica si el pago está en un estado final
 func (ps PaymentStatus) IsFinal() bool {
-	return ps == PaymentStatusCompleted || ps == PaymentStatusFailed ||
+	return ps == PaymentStatusCaptured || ps == PaymentStatusCompleted || ps == PaymentStatusFailed ||
 		ps == PaymentStatusRefunded || ps == PaymentStatusExpired ||
 		ps == PaymentStatusDisputed || ps == PaymentStatusChargeback
 }
@@ -84,7 +88,7 @@ func (ps PaymentStatus) IsProblematic() bool {
 
 // CanRefund indica si el pago puede ser reembolsado
 func (ps PaymentStatus) CanRefund() bool {
-	return ps == PaymentStatusCompleted
+	return ps.IsSuccessful()
 }
 
 // CanRetry indica si se puede reintentar el pago
@@ -94,7 +98,7 @@ func (ps PaymentStatus) CanRetry() bool {
 
 // CanDispute indica si se puede disputar el pago
 func (ps PaymentStatus) CanDispute() bool {
-	return ps == PaymentStatusCompleted
+	return ps.IsSuccessful()
 }
 
 // String devuelve la representación string del estado
@@ -102,14 +106,18 @@ func (ps PaymentStatus) String() string {
 	return string(ps)
 }
 
-// PaymentFlow define las transiciones válidas entre estados
+// PaymentFlow define las transiciones válidas entre estados. El flujo es
+// pending -> authorized -> captured -> refunded/failed; processing y completed
+// se mantienen para que los pagos creados con el flujo anterior puedan cerrarse.
 var PaymentFlow = map[PaymentStatus][]PaymentStatus{
-	PaymentStatusPending:    {PaymentStatusProcessing, PaymentStatusFailed, PaymentStatusExpired},
+	PaymentStatusPending:    {PaymentStatusAuthorized, PaymentStatusProcessing, PaymentStatusFailed, PaymentStatusExpired},
+	PaymentStatusAuthorized: {PaymentStatusCaptured, PaymentStatusFailed, PaymentStatusExpired},
+	PaymentStatusCaptured:   {PaymentStatusRefunded, PaymentStatusFailed, PaymentStatusDisputed, PaymentStatusChargeback},
 	PaymentStatusProcessing: {PaymentStatusCompleted, PaymentStatusFailed, PaymentStatusDisputed, PaymentStatusChargeback},
 	PaymentStatusCompleted:  {PaymentStatusRefunded, PaymentStatusDisputed, PaymentStatusChargeback},
 	PaymentStatusFailed:     {PaymentStatusPending}, // Reintento
 	PaymentStatusRefunded:   {},
-	PaymentStatusDisputed:   {PaymentStatusCompleted, PaymentStatusChargeback, PaymentStatusRefunded},
+	PaymentStatusDisputed:   {PaymentStatusCaptured, PaymentStatusCompleted, PaymentStatusChargeback, PaymentStatusRefunded},
 	PaymentStatusChargeback: {PaymentStatusRefunded},
 	PaymentStatusExpired:    {},
 }
@@ -137,6 +145,8 @@ func CanTransitionPayment(from, to PaymentStatus) bool {
 func GetAllPaymentStatuses() []PaymentStatus {
 	return []PaymentStatus{
 		PaymentStatusPending,
+		PaymentStatusAuthorized,
+		PaymentStatusCaptured,
 		PaymentStatusProcessing,
 		PaymentStatusCompleted,
 		PaymentStatusFailed,
@@ -151,6 +161,7 @@ func GetAllPaymentStatuses() []PaymentStatus {
 func GetActivePaymentStatuses() []PaymentStatus {
 	return []PaymentStatus{
 		PaymentStatusPending,
+		PaymentStatusAuthorized,
 		PaymentStatusProcessing,
 	}
 }
@@ -158,6 +169,7 @@ func GetActivePaymentStatuses() []PaymentStatus {
 // GetFinalStatuses devuelve los estados finales
 func GetFinalPaymentStatuses() []PaymentStatus {
 	return []PaymentStatus{
+		PaymentStatusCaptured,
 		PaymentStatusCompleted,
 		PaymentStatusFailed,
 		PaymentStatusRefunded,
@@ -170,6 +182,7 @@ func GetFinalPaymentStatuses() []PaymentStatus {
 // GetSuccessfulStatuses devuelve los estados exitosos
 func GetSuccessfulPaymentStatuses() []PaymentStatus {
 	return []PaymentStatus{
+		PaymentStatusCaptured,
 		PaymentStatusCompleted,
 	}
 }
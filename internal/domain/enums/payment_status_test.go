@@ -0,0 +1,41 @@
+package enums
+
+import "testing"
+
+func TestCanTransitionPayment(t *testing.T) {
+	tests := []struct {
+		from, to PaymentStatus
+		want     bool
+	}{
+		{PaymentStatusPending, PaymentStatusAuthorized, true},
+		{PaymentStatusAuthorized, PaymentStatusCaptured, true},
+		{PaymentStatusCaptured, PaymentStatusRefunded, true},
+		{PaymentStatusCaptured, PaymentStatusFailed, true},
+		{PaymentStatusPending, PaymentStatusFailed, true},
+		{PaymentStatusAuthorized, PaymentStatusFailed, true},
+		// Pagos del flujo anterior
+		{PaymentStatusProcessing, PaymentStatusCompleted, true},
+		{PaymentStatusCompleted, PaymentStatusRefunded, true},
+
+		{PaymentStatusPending, PaymentStatusCaptured, false},
+		{PaymentStatusPending, PaymentStatusRefunded, false},
+		{PaymentStatusAuthorized, PaymentStatusRefunded, false},
+		{PaymentStatusRefunded, PaymentStatusCaptured, false},
+		{PaymentStatusCaptured, PaymentStatusAuthorized, false},
+		{PaymentStatusPending, PaymentStatus("settled"), false},
+	}
+	for _, tt := range tests {
+		if got := CanTransitionPayment(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionPayment(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestPaymentStatusCapturedIsSuccessful(t *testing.T) {
+	if !PaymentStatusCaptured.IsSuccessful() || !PaymentStatusCaptured.CanRefund() {
+		t.Error("captured should count as a successful, refundable payment")
+	}
+	if !PaymentStatusAuthorized.IsPending() || PaymentStatusAuthorized.IsSuccessful() {
+		t.Error("authorized should be pending, not successful")
+	}
+}
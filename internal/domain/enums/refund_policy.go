@@ -0,0 +1,29 @@
+package enums
+
+// RefundPolicy es la política de reembolso configurada en un evento
+type RefundPolicy string
+
+const (
+	// RefundPolicyNone - No se aceptan reembolsos
+	RefundPolicyNone RefundPolicy = "none"
+	// RefundPolicyFlexible - Reembolso completo hasta el inicio del evento
+	RefundPolicyFlexible RefundPolicy = "flexible"
+	// RefundPolicyModerate - Reembolso hasta la fecha límite de cancelación
+	RefundPolicyModerate RefundPolicy = "moderate"
+	// RefundPolicyStrict - Sólo reembolso si el evento se cancela
+	RefundPolicyStrict RefundPolicy = "strict"
+)
+
+// IsValid verifica si el valor del enum es válido
+func (p RefundPolicy) IsValid() bool {
+	switch p {
+	case RefundPolicyNone, RefundPolicyFlexible, RefundPolicyModerate, RefundPolicyStrict:
+		return true
+	}
+	return false
+}
+
+// String devuelve la representación string de la política
+func (p RefundPolicy) String() string {
+	return string(p)
+}
@@ -0,0 +1,56 @@
+package enums
+
+// SalesStatus representa el estado de la ventana de venta de un tipo de ticket
+type SalesStatus string
+
+const (
+	// SalesStatusNotStarted - La venta aún no ha comenzado
+	SalesStatusNotStarted SalesStatus = "not_started"
+	// SalesStatusActive - La venta está abierta y hay disponibilidad
+	SalesStatusActive SalesStatus = "active"
+	// SalesStatusEnded - La ventana de venta ya terminó
+	SalesStatusEnded SalesStatus = "ended"
+	// SalesStatusSoldOut - Dentro de la ventana pero sin disponibilidad
+	SalesStatusSoldOut SalesStatus = "sold_out"
+)
+
+// IsValid verifica si el valor del enum es válido
+func (s SalesStatus) IsValid() bool {
+	switch s {
+	case SalesStatusNotStarted, SalesStatusActive, SalesStatusEnded, SalesStatusSoldOut:
+		return true
+	}
+	return false
+}
+
+// String devuelve la representación string del estado
+func (s SalesStatus) String() string {
+	return string(s)
+}
+
+// PurchaseReason indica por qué un tipo de ticket puede o no comprarse
+type PurchaseReason string
+
+const (
+	// PurchaseReasonOK - Se puede comprar
+	PurchaseReasonOK PurchaseReason = "ok"
+	// PurchaseReasonInactive - El tipo de ticket está desactivado
+	PurchaseReasonInactive PurchaseReason = "inactive"
+	// PurchaseReasonSalesNotStarted - La venta aún no empieza
+	PurchaseReasonSalesNotStarted PurchaseReason = "sales_not_started"
+	// PurchaseReasonSalesEnded - La venta ya terminó
+	PurchaseReasonSalesEnded PurchaseReason = "sales_ended"
+	// PurchaseReasonSoldOut - No queda disponibilidad
+	PurchaseReasonSoldOut PurchaseReason = "sold_out"
+	// PurchaseReasonInsufficientQuantity - Hay disponibilidad pero no la suficiente
+	PurchaseReasonInsufficientQuantity PurchaseReason = "insufficient_quantity"
+	// PurchaseReasonBelowMinPerOrder - La cantidad es menor al mínimo por orden
+	PurchaseReasonBelowMinPerOrder PurchaseReason = "below_min_per_order"
+	// PurchaseReasonAboveMaxPerOrder - La cantidad supera el máximo por orden
+	PurchaseReasonAboveMaxPerOrder PurchaseReason = "above_max_per_order"
+)
+
+// String devuelve la representación string de la razón
+func (p PurchaseReason) String() string {
+	return string(p)
+}
@@ -0,0 +1,27 @@
+package enums
+
+// SeatStatus representa la disponibilidad de un asiento para un evento
+type SeatStatus string
+
+const (
+	// SeatStatusAvailable - Sin ticket activo asignado
+	SeatStatusAvailable SeatStatus = "available"
+	// SeatStatusHeld - Retenido por una reserva en curso
+	SeatStatusHeld SeatStatus = "held"
+	// SeatStatusSold - Vendido o ya usado
+	SeatStatusSold SeatStatus = "sold"
+)
+
+// IsValid verifica si el valor del enum es válido
+func (s SeatStatus) IsValid() bool {
+	switch s {
+	case SeatStatusAvailable, SeatStatusHeld, SeatStatusSold:
+		return true
+	}
+	return false
+}
+
+// String devuelve la representación string del estado
+func (s SeatStatus) String() string {
+	return string(s)
+}
@@ -19,6 +19,10 @@ const (
 	TicketStatusRefunded TicketStatus = "refunded"
 	// TicketStatusExpired - Ticket expirado
 	TicketStatusExpired TicketStatus = "expired"
+	// TicketStatusVoided - Ticket invalidado administrativamente (fraude, duplicado)
+	TicketStatusVoided TicketStatus = "voided"
+	// TicketStatusTransferred - Ticket vendido que cambió de titular (TransferTicket)
+	TicketStatusTransferred TicketStatus = "transferred"
 )
 
 // IsValid verifica si el valor del enum es válido
@@ -26,35 +30,54 @@ func (ts TicketStatus) IsValid() bool {
 	switch ts {
 	case TicketStatusAvailable, TicketStatusReserved, TicketStatusSold,
 		TicketStatusCheckedIn, TicketStatusCancelled, TicketStatusRefunded,
-		TicketStatusExpired:
+		TicketStatusExpired, TicketStatusVoided, TicketStatusTransferred:
 		return true
 	}
 	return false
 }
 
+// IsOwned verifica si el ticket pertenece a un cliente y sigue sin usar: vendido
+// o recibido por transferencia
+func (ts TicketStatus) IsOwned() bool {
+	return ts == TicketStatusSold || ts == TicketStatusTransferred
+}
+
 // CanCheckIn verifica si el ticket puede ser marcado como usado
 func (ts TicketStatus) CanCheckIn() bool {
-	return ts == TicketStatusSold
+	return ts.IsOwned()
 }
 
-// CanTransfer verifica si el ticket puede ser transferido
+// CanTransfer verifica si el ticket puede ser transferido; un ticket ya
+// transferido puede volver a transferirse
 func (ts TicketStatus) CanTransfer() bool {
-	return ts == TicketStatusSold
+	return ts.IsOwned()
 }
 
 // CanRefund verifica si el ticket puede ser reembolsado
 func (ts TicketStatus) CanRefund() bool {
-	return ts == TicketStatusSold
+	return ts.IsOwned()
 }
 
 // CanCancel verifica si el ticket puede ser cancelado
 func (ts TicketStatus) CanCancel() bool {
-	return ts == TicketStatusAvailable || ts == TicketStatusReserved || ts == TicketStatusSold
+	return ts == TicketStatusAvailable || ts == TicketStatusReserved || ts.IsOwned()
+}
+
+// CanVoid verifica si el ticket puede ser invalidado por un administrador;
+// aplica a cualquier estado salvo el ticket ya usado y los estados finales
+func (ts TicketStatus) CanVoid() bool {
+	return ts == TicketStatusAvailable || ts == TicketStatusReserved || ts.IsOwned()
+}
+
+// HoldsInventory verifica si el ticket ocupa inventario del tipo de ticket
+// (sold_quantity o reserved_quantity)
+func (ts TicketStatus) HoldsInventory() bool {
+	return ts == TicketStatusReserved || ts.IsOwned()
 }
 
 // IsActive verifica si el ticket está en un estado activo
 func (ts TicketStatus) IsActive() bool {
-	return ts == TicketStatusReserved || ts == TicketStatusSold || ts == TicketStatusCheckedIn
+	return ts == TicketStatusReserved || ts.IsOwned() || ts == TicketStatusCheckedIn
 }
 
 // String devuelve la representación string del estado
@@ -65,13 +88,15 @@ func (ts TicketStatus) String() string {
 // ValidStatusTransitions define las transiciones permitidas entre estados
 // Basado en la lógica de negocio del sistema
 var ValidStatusTransitions = map[TicketStatus][]TicketStatus{
-	TicketStatusAvailable: {TicketStatusReserved, TicketStatusSold, TicketStatusCancelled, TicketStatusExpired},
-	TicketStatusReserved:  {TicketStatusSold, TicketStatusAvailable, TicketStatusCancelled, TicketStatusExpired},
-	TicketStatusSold:      {TicketStatusCheckedIn, TicketStatusCancelled, TicketStatusRefunded},
-	TicketStatusCheckedIn: {},
-	TicketStatusCancelled: {},
-	TicketStatusRefunded:  {},
-	TicketStatusExpired:   {},
+	TicketStatusAvailable:   {TicketStatusReserved, TicketStatusSold, TicketStatusCancelled, TicketStatusExpired, TicketStatusVoided},
+	TicketStatusReserved:    {TicketStatusSold, TicketStatusAvailable, TicketStatusCancelled, TicketStatusExpired, TicketStatusVoided},
+	TicketStatusSold:        {TicketStatusCheckedIn, TicketStatusCancelled, TicketStatusRefunded, TicketStatusTransferred, TicketStatusVoided},
+	TicketStatusTransferred: {TicketStatusCheckedIn, TicketStatusCancelled, TicketStatusRefunded, TicketStatusTransferred, TicketStatusVoided},
+	TicketStatusCheckedIn:   {},
+	TicketStatusCancelled:   {},
+	TicketStatusRefunded:    {},
+	TicketStatusExpired:     {},
+	TicketStatusVoided:      {},
 }
 
 // CanTransitionTicket verifica si es posible transicionar de un estado a otro
@@ -103,6 +128,8 @@ func GetAllStatuses() []TicketStatus {
 		TicketStatusCancelled,
 		TicketStatusRefunded,
 		TicketStatusExpired,
+		TicketStatusVoided,
+		TicketStatusTransferred,
 	}
 }
 
@@ -111,6 +138,7 @@ func GetActiveStatuses() []TicketStatus {
 	return []TicketStatus{
 		TicketStatusReserved,
 		TicketStatusSold,
+		TicketStatusTransferred,
 		TicketStatusCheckedIn,
 	}
 }
@@ -122,5 +150,6 @@ func GetFinalStatuses() []TicketStatus {
 		TicketStatusCancelled,
 		TicketStatusRefunded,
 		TicketStatusExpired,
+		TicketStatusVoided,
 	}
 }
@@ -0,0 +1,66 @@
+package enums
+
+import "testing"
+
+func TestTicketStatusTransferredIsOwned(t *testing.T) {
+	tests := []struct {
+		status                                 TicketStatus
+		checkIn, transfer, refund, cancel, own bool
+	}{
+		{TicketStatusSold, true, true, true, true, true},
+		{TicketStatusTransferred, true, true, true, true, true},
+		{TicketStatusCheckedIn, false, false, false, false, false},
+		{TicketStatusRefunded, false, false, false, false, false},
+		{TicketStatusReserved, false, false, false, true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.CanCheckIn(); got != tt.checkIn {
+			t.Errorf("%s.CanCheckIn() = %v, want %v", tt.status, got, tt.checkIn)
+		}
+		if got := tt.status.CanTransfer(); got != tt.transfer {
+			t.Errorf("%s.CanTransfer() = %v, want %v", tt.status, got, tt.transfer)
+		}
+		if got := tt.status.CanRefund(); got != tt.refund {
+			t.Errorf("%s.CanRefund() = %v, want %v", tt.status, got, tt.refund)
+		}
+		if got := tt.status.CanCancel(); got != tt.cancel {
+			t.Errorf("%s.CanCancel() = %v, want %v", tt.status, got, tt.cancel)
+		}
+		if got := tt.status.IsOwned(); got != tt.own {
+			t.Errorf("%s.IsOwned() = %v, want %v", tt.status, got, tt.own)
+		}
+	}
+}
+
+func TestCanTransitionTicketTransferred(t *testing.T) {
+	tests := []struct {
+		from, to TicketStatus
+		want     bool
+	}{
+		{TicketStatusSold, TicketStatusTransferred, true},
+		{TicketStatusTransferred, TicketStatusTransferred, true},
+		{TicketStatusTransferred, TicketStatusCheckedIn, true},
+		{TicketStatusTransferred, TicketStatusRefunded, true},
+		{TicketStatusCheckedIn, TicketStatusTransferred, false},
+		{TicketStatusRefunded, TicketStatusTransferred, false},
+		{TicketStatusReserved, TicketStatusTransferred, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransitionTicket(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionTicket(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestTicketStatusCanVoid(t *testing.T) {
+	for _, status := range GetAllStatuses() {
+		want := status != TicketStatusCheckedIn && status != TicketStatusCancelled &&
+			status != TicketStatusRefunded && status != TicketStatusExpired && status != TicketStatusVoided
+		if got := status.CanVoid(); got != want {
+			t.Errorf("%s.CanVoid() = %v, want %v", status, got, want)
+		}
+	}
+	if TicketStatusAvailable.HoldsInventory() || !TicketStatusTransferred.HoldsInventory() {
+		t.Error("available should not hold inventory and transferred should")
+	}
+}
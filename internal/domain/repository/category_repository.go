@@ -45,6 +45,7 @@ type CategoryRepository interface {
 	Find(ctx context.Context, filter *CategoryFilter) ([]*entities.Category, int64, error)
 	GetByID(ctx context.Context, id int64) (*entities.Category, error)
 	GetByPublicID(ctx context.Context, publicID string) (*entities.Category, error)
+	GetActiveByPublicID(ctx context.Context, publicID string) (*entities.Category, error)
 	GetBySlug(ctx context.Context, slug string) (*entities.Category, error)
 	GetByEventID(ctx context.Context, eventID string, isActive *bool) ([]*entities.Category, error)
 
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"io"
 	"time"
 
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
 )
 
@@ -46,8 +48,11 @@ type CustomerFilter struct {
 	// Paginación y ordenamiento
 	Limit     int
 	Offset    int
-	SortBy    string // "created_at", "total_spent", "total_orders", "last_purchase_at"
+	SortBy    string // "created_at", "total_spent", "total_orders", "last_purchase_at", "lifetime_value"
 	SortOrder string // "asc", "desc"
+
+	// ConfirmAll permite operaciones masivas con un filtro vacío
+	ConfirmAll bool
 }
 
 // Errores específicos del repositorio
@@ -55,6 +60,15 @@ var (
 	ErrCustomerNotFound      = errors.New("customer not found")
 	ErrCustomerEmailExists   = errors.New("customer email already exists")
 	ErrCustomerAlreadyLinked = errors.New("customer already linked to a user")
+
+	ErrInvalidCustomerSegment = errors.New("invalid customer segment")
+	ErrEmptyCustomerFilter    = errors.New("empty customer filter requires ConfirmAll")
+	ErrInvalidGeoLevel        = errors.New("invalid geographic level")
+
+	ErrPhoneVerificationNotFound = errors.New("no pending phone verification")
+	ErrPhoneVerificationExpired  = errors.New("phone verification code expired")
+	ErrPhoneVerificationInvalid  = errors.New("invalid phone verification code")
+	ErrPhoneVerificationLocked   = errors.New("too many phone verification attempts")
 )
 
 type CustomerRepository interface {
@@ -63,6 +77,8 @@ type CustomerRepository interface {
 	Update(ctx context.Context, customer *entities.Customer) error
 	Delete(ctx context.Context, id int64) error
 	SoftDelete(ctx context.Context, publicID string) error
+	LinkToUser(ctx context.Context, customerID, userID int64) error
+	Anonymize(ctx context.Context, publicID string) error
 
 	// --- Operaciones de Lectura (Flexibles) ---
 	Find(ctx context.Context, filter *CustomerFilter) ([]*entities.Customer, int64, error)
@@ -70,6 +86,7 @@ type CustomerRepository interface {
 	// Atajos
 	GetByID(ctx context.Context, id int64) (*entities.Customer, error)
 	GetByPublicID(ctx context.Context, publicID string) (*entities.Customer, error)
+	GetActiveByPublicID(ctx context.Context, publicID string) (*entities.Customer, error)
 	GetByEmail(ctx context.Context, email string) (*entities.Customer, error)
 	GetByUserID(ctx context.Context, userID int64) (*entities.Customer, error)
 
@@ -81,14 +98,27 @@ type CustomerRepository interface {
 	UpdateStats(ctx context.Context, customerID int64, amount float64) error
 	UpdateLoyaltyPoints(ctx context.Context, customerID int64, points int32) error
 	SetVIP(ctx context.Context, customerID int64, isVIP bool) error
+	BulkSetVIP(ctx context.Context, minLifetimeValue float64) (int64, error)
+	BulkUpdateSegment(ctx context.Context, filter *CustomerFilter, segment string) (int64, error)
 
 	// --- Operaciones de Preferencias ---
 	UpdatePreferences(ctx context.Context, customerID int64, preferences map[string]interface{}) error
 	UpdateInvoiceSettings(ctx context.Context, customerID int64, requiresInvoice bool, taxID, taxName string) error
 
+	// --- Verificación de teléfono ---
+	CreatePhoneVerification(ctx context.Context, customerID int64) (string, error)
+	VerifyPhone(ctx context.Context, customerID int64, code string) error
+
 	// --- Estadísticas Agregadas ---
 	GetStats(ctx context.Context) (*CustomerStats, error)
 	GetVIPCustomers(ctx context.Context) ([]*entities.Customer, error)
+	GetAtRiskVIPs(ctx context.Context, dormantSince time.Time) ([]*entities.Customer, error)
+	GetCohortRetention(ctx context.Context, months int) ([]CohortRow, error)
+	ExportMarketingContacts(ctx context.Context, w io.Writer) error
+	FindDuplicates(ctx context.Context, pagination commondto.Pagination) ([]DuplicateGroup, error)
+	GetNewVsReturningStats(ctx context.Context, from, to time.Time) (*NewReturningStats, error)
+	GetAcquisitionSources(ctx context.Context, from, to time.Time) ([]SourceStat, error)
+	GetGeographicDistribution(ctx context.Context, level string, pagination commondto.Pagination) ([]GeoStat, error)
 }
 
 // CustomerStats representa estadísticas agregadas de clientes
@@ -108,3 +138,56 @@ type CountryStat struct {
 	Count   int64   `db:"count" json:"count"`     // ← Añadido tag db: para consistencia
 	Revenue float64 `db:"revenue" json:"revenue"` // ← Añadido tag db: para consistencia
 }
+
+// Niveles de agrupación de GetGeographicDistribution
+const (
+	GeoLevelCountry = "country"
+	GeoLevelState   = "state"
+	GeoLevelCity    = "city"
+)
+
+// GeoStat son clientes e ingresos de una ubicación. Según el nivel se llenan
+// Country; Country y State; o Country, State y City.
+type GeoStat struct {
+	Country string  `json:"country"`
+	State   string  `json:"state,omitempty"`
+	City    string  `json:"city,omitempty"`
+	Count   int64   `json:"count"`
+	Revenue float64 `json:"revenue"`
+}
+
+// CohortRow es una celda de la matriz de retención: clientes dados de alta en
+// Cohort que compraron MonthOffset meses después (0 = el mismo mes)
+type CohortRow struct {
+	Cohort          time.Time `json:"cohort"`
+	CohortSize      int64     `json:"cohort_size"`
+	MonthOffset     int       `json:"month_offset"`
+	ActiveCustomers int64     `json:"active_customers"`
+	RetentionRate   float64   `json:"retention_rate"` // porcentaje 0-100 de CohortSize
+}
+
+// DuplicateGroup agrupa clientes activos que comparten email o teléfono normalizado.
+// MatchType es "email" o "phone"; un cliente puede aparecer en un grupo de cada tipo.
+type DuplicateGroup struct {
+	MatchType   string   `json:"match_type"`
+	MatchValue  string   `json:"match_value"`
+	CustomerIDs []string `json:"customer_ids"`
+}
+
+// NewReturningStats separa las órdenes de un periodo entre clientes nuevos
+// (su primera orden cae en el periodo) y recurrentes (ya habían comprado antes)
+// SourceStat son los clientes nuevos y sus ingresos por canal de adquisición
+type SourceStat struct {
+	Source       string  `json:"source"`
+	NewCustomers int64   `json:"new_customers"`
+	Revenue      float64 `json:"revenue"`
+}
+
+type NewReturningStats struct {
+	NewCustomers       int64   `json:"new_customers"`
+	NewOrders          int64   `json:"new_orders"`
+	NewRevenue         float64 `json:"new_revenue"`
+	ReturningCustomers int64   `json:"returning_customers"`
+	ReturningOrders    int64   `json:"returning_orders"`
+	ReturningRevenue   float64 `json:"returning_revenue"`
+}
@@ -3,6 +3,17 @@ package repository
 import "errors"
 
 var (
-	ErrOrderNotFound   = errors.New("order not found")
-	ErrPaymentNotFound = errors.New("payment not found")
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrPaymentNotFound   = errors.New("payment not found")
+	ErrEventNotFound     = errors.New("event not found")
+	ErrOrganizerNotFound = errors.New("organizer not found")
+
+	ErrNotificationNotFound = errors.New("notification not found")
+
+	// ErrInvalidPaymentTransition indica un cambio de estado no permitido por enums.PaymentFlow
+	ErrInvalidPaymentTransition = errors.New("invalid payment status transition")
+
+	// ErrInactive indica que el registro existe pero está desactivado (soft delete),
+	// a diferencia de los Err*NotFound que indican que no existe
+	ErrInactive = errors.New("record is inactive")
 )
@@ -2,29 +2,251 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
 )
 
+// EventGlobalStats agrega métricas de todos los eventos.
+// TotalEvents, PublishedEvents y los campos Lifetime* no dependen del rango;
+// TotalTicketsSold y TotalRevenue se acotan por fecha de venta (sold_at)
+// cuando se indica un rango, y coinciden con los Lifetime* si no.
+type EventGlobalStats struct {
+	TotalEvents         int64      `json:"total_events"`
+	PublishedEvents     int64      `json:"published_events"`
+	LifetimeTicketsSold int64      `json:"lifetime_tickets_sold"`
+	LifetimeRevenue     float64    `json:"lifetime_revenue"`
+	TotalTicketsSold    int64      `json:"total_tickets_sold"`
+	TotalRevenue        float64    `json:"total_revenue"`
+	From                *time.Time `json:"from,omitempty"`
+	To                  *time.Time `json:"to,omitempty"`
+}
+
+// TagCount representa la frecuencia de un tag entre los eventos publicados
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// DemographicBucket es el conteo de asistentes en un grupo (país, segmento, vip)
+type DemographicBucket struct {
+	Key        string  `json:"key"`
+	Count      int64   `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// Demographics agrega atributos de los asistentes de un evento sin exponer datos personales.
+// Cada cliente cuenta una vez aunque tenga varios tickets.
+type Demographics struct {
+	EventID        int64               `json:"event_id"`
+	TotalAttendees int64               `json:"total_attendees"`
+	ByCountry      []DemographicBucket `json:"by_country"`
+	BySegment      []DemographicBucket `json:"by_segment"`
+	VIPCount       int64               `json:"vip_count"`
+	VIPPercentage  float64             `json:"vip_percentage"`
+}
+
+// HourlySales son las ventas de un evento en una hora del día (0-23)
+type HourlySales struct {
+	Hour        int     `json:"hour"`
+	TicketsSold int64   `json:"tickets_sold"`
+	Revenue     float64 `json:"revenue"`
+}
+
+// Forecast es la proyección de ocupación de un evento. ProjectedSellOutAt es nil
+// cuando no hubo ventas en la ventana (velocidad cero).
+type Forecast struct {
+	Capacity           int64      `json:"capacity"`
+	Sold               int64      `json:"sold"`
+	Occupancy          float64    `json:"occupancy"` // porcentaje 0-100, como el resto de las tasas
+	VelocityPerDay     float64    `json:"velocity_per_day"`
+	WindowDays         int        `json:"window_days"`
+	ProjectedSellOutAt *time.Time `json:"projected_sell_out_at,omitempty"`
+}
+
+// ReservationStats resume las reservas de un evento según ticket_status_history.
+// Abandoned son las que vencieron o se liberaron sin compra; Pending las que
+// siguen abiertas. ConversionRate (0-100) se calcula sobre las ya resueltas.
+type ReservationStats struct {
+	Made           int64   `json:"made"`
+	Converted      int64   `json:"converted"`
+	Abandoned      int64   `json:"abandoned"`
+	Pending        int64   `json:"pending"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+// ChannelStats separa las ventas emitidas desde back-office (ticket sin orden)
+// de las de autoservicio del cliente (ticket creado por un checkout con orden)
+type ChannelStats struct {
+	BackOfficeTickets int64   `json:"back_office_tickets"`
+	BackOfficeRevenue float64 `json:"back_office_revenue"`
+	GuestTickets      int64   `json:"guest_tickets"`
+	GuestRevenue      float64 `json:"guest_revenue"`
+}
+
+// PublishEvent es una publicación o despublicación registrada en el historial
+type PublishEvent struct {
+	Action     string    `json:"action"` // "publish" o "unpublish"
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      *string   `json:"actor,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// StatusChangeEvent es una transición de estado de un evento según el historial
+type StatusChangeEvent struct {
+	ID            int64     `json:"id"`
+	EventID       int64     `json:"event_id"`
+	EventPublicID string    `json:"event_public_id"`
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+// Cursor devuelve la posición de este cambio para pedir la página siguiente
+func (c StatusChangeEvent) Cursor() StatusChangeCursor {
+	return StatusChangeCursor{ChangedAt: c.ChangedAt, ID: c.ID}
+}
+
+// StatusChangeCursor es la posición (changed_at, id) del último cambio procesado;
+// el valor cero empieza desde el principio del historial
+type StatusChangeCursor struct {
+	ChangedAt time.Time `json:"changed_at"`
+	ID        int64     `json:"id"`
+}
+
+// SoldOutInfo es un evento que se agotó; TimeToSellOut va desde la última
+// publicación previa (o published_at) hasta el primer paso a sold_out
+type SoldOutInfo struct {
+	EventID       int64         `json:"event_id"`
+	EventPublicID string        `json:"event_public_id"`
+	Name          string        `json:"name"`
+	PublishedAt   time.Time     `json:"published_at"`
+	SoldOutAt     time.Time     `json:"sold_out_at"`
+	TimeToSellOut time.Duration `json:"time_to_sell_out"`
+}
+
+// WaitlistEntry es un cliente en la lista de espera de un evento
+type WaitlistEntry struct {
+	EventID    int64      `json:"event_id"`
+	CustomerID int64      `json:"customer_id"`
+	JoinedAt   time.Time  `json:"joined_at"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+}
+
+// DayLoad es la cantidad de eventos que ocurren (total o parcialmente) en un día
+type DayLoad struct {
+	Day        time.Time `json:"day"`
+	EventCount int64     `json:"event_count"`
+}
+
+// MonthCount es la cantidad de eventos que inician en un mes (1-12) del año
+type MonthCount struct {
+	Month      int   `json:"month"`
+	EventCount int64 `json:"event_count"`
+}
+
+// EventUtilization es la capacidad y venta de un evento dentro de un resumen de utilización
+type EventUtilization struct {
+	EventID        int64   `json:"event_id"`
+	PublicID       string  `json:"public_id"`
+	Name           string  `json:"name"`
+	Capacity       int64   `json:"capacity"`
+	Sold           int64   `json:"sold"`
+	UtilizationPct float64 `json:"utilization_pct"`
+}
+
+// UtilizationSummary agrega capacidad y ventas de los eventos publicados de un
+// organizador. Best y Worst son nil si ningún evento tiene capacidad.
+type UtilizationSummary struct {
+	TotalCapacity  int64             `json:"total_capacity"`
+	TotalSold      int64             `json:"total_sold"`
+	UtilizationPct float64           `json:"utilization_pct"`
+	Best           *EventUtilization `json:"best,omitempty"`
+	Worst          *EventUtilization `json:"worst,omitempty"`
+}
+
+// CategoryRevenue son las ventas de un tipo de ticket (categoría) de un evento
+type CategoryRevenue struct {
+	TicketTypeID int64   `json:"ticket_type_id"`
+	PublicID     string  `json:"public_id"`
+	Name         string  `json:"name"`
+	SoldCount    int64   `json:"sold_count"`
+	Revenue      float64 `json:"revenue"`
+}
+
+// EventDetail agrega el evento con sus tipos de ticket visibles y los beneficios de cada uno
+type EventDetail struct {
+	Event      *entities.Event        `json:"event"`
+	Categories []*entities.TicketType `json:"categories"`
+}
+
 type EventRepository interface {
 	// CRUD básico
 	Create(ctx context.Context, event *entities.Event) error
 	GetByID(ctx context.Context, id int64) (*entities.Event, error)
 	GetByPublicID(ctx context.Context, publicID string) (*entities.Event, error)
 	GetBySlug(ctx context.Context, slug string) (*entities.Event, error)
+	FindBySlugOrPublicID(ctx context.Context, identifier string) (*entities.Event, error)
+	GetEventDetail(ctx context.Context, publicID string) (*EventDetail, error)
+	GetRelatedEvents(ctx context.Context, eventID int64, limit int) ([]*entities.Event, error)
+	GetOccupancyForecast(ctx context.Context, eventID int64) (*Forecast, error)
+	GetReservationStats(ctx context.Context, eventID int64) (*ReservationStats, error)
+	GetSalesChannelBreakdown(ctx context.Context, eventID int64) (*ChannelStats, error)
+	Publish(ctx context.Context, id int64, publishedAt time.Time, actor string) error
+	Unpublish(ctx context.Context, id int64, actor string) error
+	GetPublishHistory(ctx context.Context, eventPublicID string) ([]PublishEvent, error)
+	GetStatusChangesSince(ctx context.Context, after StatusChangeCursor, limit int) ([]StatusChangeEvent, error)
+	GetSoldOutEvents(ctx context.Context, from, to time.Time) ([]SoldOutInfo, error)
+	GetBusiestDays(ctx context.Context, organizerPublicID string, limit int) ([]DayLoad, error)
+	GetMonthlyEventCounts(ctx context.Context, organizerPublicID string, year int) ([]MonthCount, error)
+	GetCapacityUtilization(ctx context.Context, organizerPublicID string) (*UtilizationSummary, error)
+	GetRevenueByCategory(ctx context.Context, eventID int64) ([]CategoryRevenue, error)
+	GetPriceRange(ctx context.Context, eventID int64) (min, max float64, err error)
+	GetSettings(ctx context.Context, publicID string) (entities.EventSettings, error)
+	UpdateSettings(ctx context.Context, publicID string, settings entities.EventSettings) error
+	JoinWaitlist(ctx context.Context, eventID, customerID int64) error
+	LeaveWaitlist(ctx context.Context, eventID, customerID int64) error
+	GetWaitlist(ctx context.Context, eventID int64) ([]WaitlistEntry, error)
+	NotifyNextWaitlisted(ctx context.Context, eventID int64) (*WaitlistEntry, error)
+	FindByIDsOrdered(ctx context.Context, ids []int64) ([]*entities.Event, error)
 	Update(ctx context.Context, event *entities.Event) error
 	Delete(ctx context.Context, id int64) error
+	Cancel(ctx context.Context, id int64, reason string) error
+	SoftDelete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	BulkUpdateStatus(ctx context.Context, eventIDs []int64, target string) (int64, []int64, error)
+	BulkCancelByOrganizer(ctx context.Context, organizerPublicID, reason string) (int64, error)
 
 	// Listados con filtros
 	List(ctx context.Context, filter map[string]interface{}, limit, offset int) ([]*entities.Event, int64, error)
 
 	// Búsquedas específicas (las que realmente usas)
 	ListByOrganizer(ctx context.Context, organizerID int64, limit, offset int) ([]*entities.Event, int64, error)
+	ListByCategory(ctx context.Context, categoryID int64, limit, offset int) ([]*entities.Event, int64, error)
+	ListByVenue(ctx context.Context, venueID int64, limit, offset int) ([]*entities.Event, int64, error)
 	ListUpcoming(ctx context.Context, limit int) ([]*entities.Event, error)
-	ListFeatured(ctx context.Context, limit int) ([]*entities.Event, error)
+	ListFeatured(ctx context.Context, pagination commondto.Pagination) ([]*entities.Event, int64, error)
+	GetEventsStartingBetween(ctx context.Context, from, to time.Time) ([]*entities.Event, error)
+	GetUpcomingCountByVenue(ctx context.Context, venueIDs []int64) (map[int64]int64, error)
+	GetStaleDrafts(ctx context.Context, olderThan time.Duration) ([]*entities.Event, error)
+	GetUpcomingForCategory(ctx context.Context, categoryPublicID string, limit int) ([]*entities.Event, error)
 
 	// Relaciones
 	GetEventCategories(ctx context.Context, eventID int64) ([]*entities.Category, error)
 	AddCategoryToEvent(ctx context.Context, eventID, categoryID int64, isPrimary bool) error
 	RemoveCategoryFromEvent(ctx context.Context, eventID, categoryID int64) error
+
+	// Estadísticas
+	GetGlobalStats(ctx context.Context, from, to *time.Time) (*EventGlobalStats, error)
+	GetTagCloud(ctx context.Context, limit int) ([]TagCount, error)
+	GetAttendeeDemographics(ctx context.Context, eventID int64) (*Demographics, error)
+	GetSalesByHour(ctx context.Context, eventID int64) ([]HourlySales, error)
+
+	// Contadores
+	IncrementShareCount(ctx context.Context, eventID int64) error
+	UpdateCounters(ctx context.Context, eventID int64, views, shares, favorites int64) error
+	RecountFavorites(ctx context.Context, eventID int64) error
+	RecountAllFavorites(ctx context.Context) (int64, error)
 }
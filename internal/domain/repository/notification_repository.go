@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	notificationdto "github.com/franciscozamorau/osmi-server/internal/api/dto/notification"
@@ -55,3 +56,18 @@ type NotificationRepository interface {
 	GetAverageDeliveryTime(ctx context.Context, channel string) (float64, error)
 	GetFailureReasons(ctx context.Context, period string) ([]*notificationdto.FailureReasonStats, error)
 }
+
+// NotificationOutbox es el subconjunto que usa el worker de envío.
+// ClaimDue marca como "sending" los mensajes listos para enviar y también
+// recupera los que quedaron en "sending" más de sendingTimeout (el worker cayó
+// a media entrega); cada mensaje devuelto trae el estado que tenía antes del
+// reclamo para que el worker sepa si debe consultar al proveedor antes de reenviar.
+type NotificationOutbox interface {
+	ClaimDue(ctx context.Context, limit int, sendingTimeout time.Duration) ([]*entities.Notification, error)
+	UpdateStatus(ctx context.Context, notificationID int64, status string) error
+	MarkAsSent(ctx context.Context, notificationID int64, sentAt string, providerMessageID string) error
+	MarkAsFailed(ctx context.Context, notificationID int64, errorMessage, errorCode string) error
+	IncrementAttempts(ctx context.Context, notificationID int64) error
+	SetNextRetry(ctx context.Context, notificationID int64, nextRetryAt string) error
+	AddErrorToHistory(ctx context.Context, notificationID int64, errorMessage, errorCode string) error
+}
@@ -22,6 +22,7 @@ type PaymentRepository interface {
 	// Búsquedas
 	List(ctx context.Context, filter paymentdto.PaymentFilter, pagination commondto.Pagination) ([]*entities.Payment, int64, error)
 	FindByOrder(ctx context.Context, orderID int64) ([]*entities.Payment, error)
+	ListByOrder(ctx context.Context, orderPublicID string) ([]*entities.Payment, error)
 	FindByCustomer(ctx context.Context, customerID int64, pagination commondto.Pagination) ([]*entities.Payment, int64, error)
 	FindByStatus(ctx context.Context, status string, pagination commondto.Pagination) ([]*entities.Payment, int64, error)
 	FindByProvider(ctx context.Context, providerID int64, pagination commondto.Pagination) ([]*entities.Payment, int64, error)
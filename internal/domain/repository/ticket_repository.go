@@ -58,6 +58,68 @@ type TicketStats struct {
 	AvgTicketPrice   float64 `json:"avg_ticket_price"`
 }
 
+// TicketWithDetails es un ticket con los datos de evento, tipo y categoría ya resueltos
+type TicketWithDetails struct {
+	*entities.Ticket
+	TicketTypeName string    `json:"ticket_type_name"`
+	EventPublicID  string    `json:"event_public_id"`
+	EventStartsAt  time.Time `json:"event_starts_at"`
+}
+
+// TransferLink representa un eslabón en la cadena de titulares de un ticket
+type TransferLink struct {
+	TicketPublicID string     `json:"ticket_public_id"`
+	CustomerID     *int64     `json:"customer_id,omitempty"`
+	TransferredAt  *time.Time `json:"transferred_at,omitempty"`
+}
+
+// SeatStatus es la disponibilidad de un asiento del venue para un evento
+type SeatStatus struct {
+	SeatID  int64            `json:"seat_id"`
+	Section string           `json:"section"`
+	Row     string           `json:"row"`
+	Number  string           `json:"number"`
+	Status  enums.SeatStatus `json:"status"`
+}
+
+// CheckInBucket es la cantidad de check-ins en un intervalo [Start, Start+bucket)
+type CheckInBucket struct {
+	Start    time.Time `json:"start"`
+	CheckIns int64     `json:"check_ins"`
+}
+
+// CurrencyRecognition son los ingresos por tickets de una moneda separados en
+// reconocidos (el evento ya terminó) y diferidos (el evento aún no termina)
+type CurrencyRecognition struct {
+	Currency          string  `json:"currency"`
+	Recognized        float64 `json:"recognized"`
+	Deferred          float64 `json:"deferred"`
+	RecognizedTickets int64   `json:"recognized_tickets"`
+	DeferredTickets   int64   `json:"deferred_tickets"`
+}
+
+// RecognitionReport es el reporte de reconocimiento de ingresos a la fecha AsOf
+type RecognitionReport struct {
+	AsOf       time.Time             `json:"as_of"`
+	ByCurrency []CurrencyRecognition `json:"by_currency"`
+}
+
+// DuplicateCode es un código repetido dentro de un evento y los tickets que lo usan
+type DuplicateCode struct {
+	EventID   int64   `json:"event_id"`
+	Code      string  `json:"code"`
+	Count     int64   `json:"count"`
+	TicketIDs []int64 `json:"ticket_ids"`
+}
+
+// GuestInfo es un invitado que recibe un ticket de cortesía; si no existe un
+// cliente con ese email se crea uno
+type GuestInfo struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Phone string `json:"phone,omitempty"`
+}
+
 // Errores específicos del repositorio
 var (
 	ErrTicketNotFound      = errors.New("ticket not found")
@@ -65,13 +127,17 @@ var (
 	ErrInvalidTicketStatus = errors.New("invalid ticket status transition")
 	ErrTicketNotAvailable  = errors.New("ticket not available for this operation")
 	ErrTicketDuplicateCode = errors.New("ticket code already exists")
+	ErrTicketTypeMismatch  = errors.New("ticket type belongs to a different event")
+	ErrTicketOwnerMismatch = errors.New("ticket does not belong to the given customer")
 )
 
 type TicketRepository interface {
 	// --- Operaciones de Escritura ---
 	Create(ctx context.Context, ticket *entities.Ticket) error
 	CreateBatch(ctx context.Context, tickets []*entities.Ticket) error
+	GenerateComplimentaryTickets(ctx context.Context, eventPublicID, categoryPublicID string, guests []GuestInfo) ([]string, error)
 	Update(ctx context.Context, ticket *entities.Ticket) error
+	SetQRCodeURL(ctx context.Context, ticketID int64, url string) error
 	Delete(ctx context.Context, id int64) error
 
 	// En TicketRepository interface
@@ -81,11 +147,13 @@ type TicketRepository interface {
 
 	// --- Operaciones de Lectura (Flexibles) ---
 	Find(ctx context.Context, filter *TicketFilter) ([]*entities.Ticket, int64, error)
+	ListTicketsPaged(ctx context.Context, filter *TicketFilter, cursor string, limit int) ([]*entities.Ticket, string, error)
 
 	// Atajos
 	GetByID(ctx context.Context, id int64) (*entities.Ticket, error)
 	GetByPublicID(ctx context.Context, publicID string) (*entities.Ticket, error)
 	GetByCode(ctx context.Context, code string) (*entities.Ticket, error)
+	GetTicketsWithDetailsByOrder(ctx context.Context, orderID int64) ([]*TicketWithDetails, error)
 
 	// --- Operaciones de Verificación ---
 	Exists(ctx context.Context, id int64) (bool, error)
@@ -93,17 +161,27 @@ type TicketRepository interface {
 
 	// --- Operaciones de Estado ---
 	UpdateStatus(ctx context.Context, ticketID int64, status enums.TicketStatus) error
-	CheckIn(ctx context.Context, ticketID int64, method, location string, checkedBy *int64) error
+	CheckIn(ctx context.Context, ticketID int64, method enums.CheckInMethod, location string, checkedBy *int64) error
 	Reserve(ctx context.Context, ticketID int64, reservedBy int64, expiresAt time.Time) error
+	ReserveTicket(ctx context.Context, ticketPublicID, userPublicID string, expiresAt time.Time) error
 	ReleaseReservation(ctx context.Context, ticketID int64) error
 	Transfer(ctx context.Context, ticketID int64, toCustomerID int64, transferToken string) error
+	TransferTicket(ctx context.Context, ticketPublicID, fromCustomerPublicID, toCustomerPublicID string) error
 	Cancel(ctx context.Context, ticketID int64) error
 	Refund(ctx context.Context, ticketID int64) error
+	VoidTicket(ctx context.Context, ticketPublicID, reason, actor string) error
+	ReassignCategory(ctx context.Context, ticketPublicID, newCategoryPublicID string) error
 
 	// --- Operaciones Específicas de Negocio ---
 	ValidateTicket(ctx context.Context, code, secretHash string) (*entities.Ticket, error)
 	GetEventStats(ctx context.Context, eventPublicID string) (*TicketStats, error)
 	GetReservedExpired(ctx context.Context) ([]*entities.Ticket, error)
+	GetRefundEligibleTickets(ctx context.Context, eventPublicID string) ([]*entities.Ticket, error)
+	GetTransferChain(ctx context.Context, ticketPublicID string) ([]TransferLink, error)
+	GetSeatMapAvailability(ctx context.Context, eventPublicID string) ([]SeatStatus, error)
+	GetCheckInTimeline(ctx context.Context, eventPublicID string, bucket time.Duration) ([]CheckInBucket, error)
+	FindDuplicateCodes(ctx context.Context) ([]DuplicateCode, error)
+	GetRevenueRecognition(ctx context.Context, asOf time.Time) (*RecognitionReport, error)
 
 	GetByPublicIDForUpdate(ctx context.Context, tx pgx.Tx, publicID string) (*entities.Ticket, error)
 }
@@ -3,13 +3,51 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	tickettypedto "github.com/franciscozamorau/osmi-server/internal/api/dto/ticket_type"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
 	"github.com/jackc/pgx/v5"
 )
 
+// ErrTicketTypeNotFound indica que el tipo de ticket (categoría) no existe
+var ErrTicketTypeNotFound = errors.New("ticket type not found")
+
+// ErrTicketTypeDuplicateName indica que ya existe un tipo de ticket con ese nombre en el evento
+var ErrTicketTypeDuplicateName = errors.New("ticket type name already exists for this event")
+
+// ErrNotEnoughTickets indica que el tipo de ticket no tiene cupo suficiente para la cantidad pedida
+var ErrNotEnoughTickets = errors.New("not enough tickets available")
+
+// SalesStatus describe el estado de venta de un tipo de ticket en un momento dado.
+// At es la fecha relevante: inicio de venta si no ha comenzado, fin de venta en
+// cualquier otro caso (nil si la venta no tiene fecha de cierre).
+// Remaining sólo se informa si el evento tiene ShowRemainingCount activo;
+// en caso contrario el cliente recibe únicamente Available.
+type SalesStatus struct {
+	Status    enums.SalesStatus `json:"status"`
+	At        *time.Time        `json:"at,omitempty"`
+	Available bool              `json:"available"`
+	Remaining *int              `json:"remaining,omitempty"`
+}
+
+// PurchaseCheck es el resultado estructurado de CheckPurchasability
+type PurchaseCheck struct {
+	Purchasable  bool                 `json:"purchasable"`
+	Reason       enums.PurchaseReason `json:"reason"`
+	Available    int                  `json:"available"`
+	MinPerOrder  int                  `json:"min_per_order"`
+	MaxPerOrder  int                  `json:"max_per_order"`
+	SaleStartsAt time.Time            `json:"sale_starts_at"`
+	SaleEndsAt   *time.Time           `json:"sale_ends_at,omitempty"`
+}
+
+// ErrPurchaseNotAllowed se devuelve junto con un PurchaseCheck no comprable
+var ErrPurchaseNotAllowed = errors.New("ticket type cannot be purchased")
+
 // TicketTypeRepository define operaciones para tipos de ticket
 type TicketTypeRepository interface {
 	// CRUD básico
@@ -20,6 +58,7 @@ type TicketTypeRepository interface {
 	Delete(ctx context.Context, id int64) error
 	SoftDelete(ctx context.Context, publicID string) error
 	SellTicketsDirect(ctx context.Context, ticketTypeID int64, quantity int) error
+	CloneToEvent(ctx context.Context, ticketTypePublicID, targetEventPublicID string) (string, error)
 
 	// Búsquedas
 	List(ctx context.Context, filter tickettypedto.TicketTypeFilter, pagination commondto.Pagination) ([]*entities.TicketType, int64, error)
@@ -36,10 +75,12 @@ type TicketTypeRepository interface {
 	CancelSoldTickets(ctx context.Context, ticketTypeID int64, quantity int) error
 	RefundTickets(ctx context.Context, ticketTypeID int64, quantity int) error
 	CheckAvailability(ctx context.Context, ticketTypeID int64, quantity int) (bool, error)
+	CheckPurchasability(ctx context.Context, ticketTypePublicID string, quantity int, now time.Time) (*PurchaseCheck, error)
 	GetAvailableQuantity(ctx context.Context, ticketTypeID int64) (int, error)
 	UpdateSaleDates(ctx context.Context, ticketTypeID int64, startsAt, endsAt string) error
 	UpdatePrice(ctx context.Context, ticketTypeID int64, price float64, currency string) error
 	UpdateStatus(ctx context.Context, ticketTypeID int64, active bool) error
+	GetSalesStatus(ctx context.Context, ticketTypePublicID string, now time.Time) (SalesStatus, error)
 
 	// Estadísticas
 	GetStats(ctx context.Context, ticketTypeID int64) (*tickettypedto.TicketTypeStatsResponse, error)
@@ -57,4 +98,6 @@ type TicketTypeRepository interface {
 
 	ReleaseExpiredReservations(ctx context.Context) (int64, error)
 	ReserveTicketWithLock(ctx context.Context, tx pgx.Tx, ticketTypeID int64, quantity int) error
+	SellTicketsWithLock(ctx context.Context, tx pgx.Tx, ticketTypeID int64, quantity int) error
+	ReserveTicketsBatchTx(ctx context.Context, tx pgx.Tx, quantities map[int64]int) error
 }
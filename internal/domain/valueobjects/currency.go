@@ -91,6 +91,16 @@ func GetDefaultCurrency() Currency {
 	return CurrencyMXN
 }
 
+// CurrencyOrDefault devuelve el código normalizado o la moneda por defecto si está
+// vacío o no es soportado (registros antiguos sin moneda)
+func CurrencyOrDefault(code string) string {
+	currency, err := NewCurrency(code)
+	if err != nil {
+		return GetDefaultCurrency().Code()
+	}
+	return currency.Code()
+}
+
 // GetAllCurrencies devuelve todas las monedas válidas
 func GetAllCurrencies() []Currency {
 	currencies := make([]Currency, 0, len(ValidCurrencies))
@@ -0,0 +1,20 @@
+package valueobjects
+
+import "testing"
+
+func TestCurrencyOrDefault(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"USD", "USD"},
+		{" eur ", "EUR"},
+		{"", "MXN"},
+		{"XXX", "MXN"},
+	}
+	for _, tt := range tests {
+		if got := CurrencyOrDefault(tt.code); got != tt.want {
+			t.Errorf("CurrencyOrDefault(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// internal/infrastructure/httpclient/client.go
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/config"
+)
+
+// ErrRetryable indica que la entrega falló por timeout, error de red o 5xx
+// y puede reintentarse
+var ErrRetryable = errors.New("retryable delivery failure")
+
+// New crea un *http.Client para webhooks y notificaciones a partir de la configuración.
+// Nunca usar http.DefaultClient: no tiene timeouts y una petición colgada deja la goroutine viva.
+func New(cfg config.HTTPClientConfig) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   cfg.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   cfg.ConnectTimeout,
+		ResponseHeaderTimeout: cfg.ReadTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.RequestTimeout,
+	}
+}
+
+// PostJSON envía un payload JSON y cierra siempre el body de la respuesta.
+// Timeouts, errores de red y respuestas 5xx/429 envuelven ErrRetryable para que
+// el dispatcher reprograme la entrega.
+func PostJSON(ctx context.Context, client *http.Client, url string, payload []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrRetryable, err)
+	}
+	defer resp.Body.Close()
+
+	// Drenar el body permite reutilizar la conexión
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests:
+		return resp.StatusCode, fmt.Errorf("%w: status %d", ErrRetryable, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return resp.StatusCode, fmt.Errorf("delivery rejected: status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,119 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/config"
+)
+
+func testConfig() config.HTTPClientConfig {
+	return config.HTTPClientConfig{
+		ConnectTimeout:      time.Second,
+		ReadTimeout:         100 * time.Millisecond,
+		RequestTimeout:      200 * time.Millisecond,
+		MaxIdleConns:        2,
+		MaxIdleConnsPerHost: 2,
+	}
+}
+
+// hangingServer nunca responde hasta que el cliente corta la conexión
+func hangingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		srv.Close()
+	})
+	return srv
+}
+
+func TestPostJSONTimeoutIsRetryable(t *testing.T) {
+	srv := hangingServer(t)
+	client := New(testConfig())
+
+	start := time.Now()
+	_, err := PostJSON(context.Background(), client, srv.URL, []byte(`{}`), nil)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrRetryable) {
+		t.Fatalf("error = %v, want ErrRetryable", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("hanging request took %v; the client timeout did not fire", elapsed)
+	}
+}
+
+func TestPostJSONContextDeadlineIsRetryable(t *testing.T) {
+	srv := hangingServer(t)
+	cfg := testConfig()
+	cfg.ReadTimeout = time.Minute
+	cfg.RequestTimeout = time.Minute
+	client := New(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := PostJSON(ctx, client, srv.URL, []byte(`{}`), nil); !errors.Is(err, ErrRetryable) {
+		t.Fatalf("error = %v, want ErrRetryable", err)
+	}
+}
+
+func TestPostJSONStatusClassification(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantErr   bool
+		retryable bool
+	}{
+		{"ok", http.StatusNoContent, false, false},
+		{"server error", http.StatusBadGateway, true, true},
+		{"too many requests", http.StatusTooManyRequests, true, true},
+		{"client error", http.StatusBadRequest, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotHeader, gotContentType string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotHeader = r.Header.Get("X-Signature")
+				gotContentType = r.Header.Get("Content-Type")
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			code, err := PostJSON(context.Background(), New(testConfig()), srv.URL, []byte(`{}`), map[string]string{"X-Signature": "abc"})
+			if code != tt.status {
+				t.Errorf("status = %d, want %d", code, tt.status)
+			}
+			if (err != nil) != tt.wantErr || errors.Is(err, ErrRetryable) != tt.retryable {
+				t.Errorf("error = %v, want error %v / retryable %v", err, tt.wantErr, tt.retryable)
+			}
+			if gotHeader != "abc" || gotContentType != "application/json" {
+				t.Errorf("headers = %q / %q", gotHeader, gotContentType)
+			}
+		})
+	}
+}
+
+func TestNewVerifiesTLSCertificates(t *testing.T) {
+	// El certificado autofirmado de httptest no es de confianza: la petición debe fallar
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	_, err := PostJSON(context.Background(), New(testConfig()), srv.URL, []byte(`{}`), nil)
+	if err == nil {
+		t.Fatal("request to a server with an untrusted certificate succeeded")
+	}
+}
@@ -1 +1,140 @@
 package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/httpclient"
+)
+
+// HTTPNotificationProvider entrega notificaciones a un proveedor HTTP genérico:
+// POST {baseURL}/messages con cabecera Idempotency-Key, y
+// GET {baseURL}/messages?idempotency_key=... para consultar un envío previo.
+// Ambos responden {"id": "<provider message id>"}; el GET responde 404 si no existe.
+type HTTPNotificationProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func NewHTTPNotificationProvider(client *http.Client, baseURL, apiKey string) *HTTPNotificationProvider {
+	return &HTTPNotificationProvider{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+	}
+}
+
+type providerMessage struct {
+	Channel  string  `json:"channel"`
+	Email    *string `json:"email,omitempty"`
+	Phone    *string `json:"phone,omitempty"`
+	Name     *string `json:"name,omitempty"`
+	Language string  `json:"language,omitempty"`
+	Subject  string  `json:"subject"`
+	Body     string  `json:"body"`
+}
+
+type providerMessageResponse struct {
+	ID string `json:"id"`
+}
+
+// Send envía la notificación; el proveedor deduplica por idempotencyKey
+func (p *HTTPNotificationProvider) Send(ctx context.Context, notification *entities.Notification, idempotencyKey string) (string, error) {
+	payload, err := json.Marshal(providerMessage{
+		Channel:  notification.Channel,
+		Email:    notification.RecipientEmail,
+		Phone:    notification.RecipientPhone,
+		Name:     notification.RecipientName,
+		Language: notification.RecipientLanguage,
+		Subject:  notification.Subject,
+		Body:     notification.Body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/messages", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	status, body, err := p.do(req)
+	if err != nil {
+		return "", err
+	}
+	if status >= 300 {
+		return "", fmt.Errorf("notification rejected: status %d", status)
+	}
+	return decodeMessageID(body)
+}
+
+// Lookup consulta si el proveedor ya aceptó un mensaje con esa llave
+func (p *HTTPNotificationProvider) Lookup(ctx context.Context, idempotencyKey string) (string, bool, error) {
+	endpoint := p.baseURL + "/messages?idempotency_key=" + url.QueryEscape(idempotencyKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	status, body, err := p.do(req)
+	if err != nil {
+		return "", false, err
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return "", false, nil
+	case status >= 300:
+		return "", false, fmt.Errorf("notification lookup rejected: status %d", status)
+	}
+
+	id, err := decodeMessageID(body)
+	if err != nil {
+		return "", false, err
+	}
+	return id, true, nil
+}
+
+// do ejecuta la petición con la misma clasificación de errores que
+// httpclient.PostJSON: red, 5xx y 429 envuelven httpclient.ErrRetryable
+func (p *HTTPNotificationProvider) do(req *http.Request) (int, []byte, error) {
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", httpclient.ErrRetryable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", httpclient.ErrRetryable, err)
+	}
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return resp.StatusCode, nil, fmt.Errorf("%w: status %d", httpclient.ErrRetryable, resp.StatusCode)
+	}
+	return resp.StatusCode, body, nil
+}
+
+func decodeMessageID(body []byte) (string, error) {
+	var resp providerMessageResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to decode provider response: %w", err)
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("provider response without message id")
+	}
+	return resp.ID, nil
+}
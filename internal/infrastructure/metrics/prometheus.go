@@ -0,0 +1,129 @@
+// internal/infrastructure/metrics/prometheus.go
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PoolStats son las conexiones del pool de base de datos en un instante
+type PoolStats struct {
+	Total int32
+	Idle  int32
+	Max   int32
+}
+
+// PoolStatsFunc devuelve las estadísticas del pool; ok es false mientras la
+// base de datos no esté inicializada
+type PoolStatsFunc func() (stats PoolStats, ok bool)
+
+// Handler expone los registros en formato de texto de Prometheus. Cualquiera de
+// los argumentos puede ser nil y esa sección se omite.
+func Handler(rpc *RPCRegistry, repos *Registry, pool PoolStatsFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WritePrometheus(w, rpc, repos, pool)
+	})
+}
+
+// WritePrometheus escribe las métricas en formato de exposición de Prometheus
+func WritePrometheus(out io.Writer, rpc *RPCRegistry, repos *Registry, pool PoolStatsFunc) error {
+	w := bufio.NewWriter(out)
+
+	if rpc != nil {
+		stats := rpc.Snapshot()
+
+		writeHeader(w, "osmi_grpc_requests_total", "counter", "Total de RPCs atendidos por método y código gRPC.")
+		for _, s := range stats {
+			fmt.Fprintf(w, "osmi_grpc_requests_total%s %d\n", labels("method", s.Method, "code", s.Code), s.Calls)
+		}
+
+		writeHeader(w, "osmi_grpc_request_duration_seconds", "histogram", "Latencia de los RPCs por método y código gRPC.")
+		for _, s := range stats {
+			writeHistogram(w, "osmi_grpc_request_duration_seconds", []string{"method", s.Method, "code", s.Code},
+				s.Bounds, s.Buckets, s.TotalTime, s.Calls)
+		}
+	}
+
+	if repos != nil {
+		stats := repos.Snapshot()
+
+		writeHeader(w, "osmi_repository_calls_total", "counter", "Llamadas a métodos de repositorio.")
+		for _, s := range stats {
+			fmt.Fprintf(w, "osmi_repository_calls_total%s %d\n", labels("repository", s.Repository, "method", s.Method), s.Calls)
+		}
+
+		writeHeader(w, "osmi_repository_errors_total", "counter", "Llamadas a métodos de repositorio que devolvieron error.")
+		for _, s := range stats {
+			fmt.Fprintf(w, "osmi_repository_errors_total%s %d\n", labels("repository", s.Repository, "method", s.Method), s.Errors)
+		}
+
+		writeHeader(w, "osmi_repository_call_duration_seconds", "histogram", "Latencia de los métodos de repositorio.")
+		for _, s := range stats {
+			writeHistogram(w, "osmi_repository_call_duration_seconds", []string{"repository", s.Repository, "method", s.Method},
+				s.Bounds, s.Buckets, s.TotalTime, s.Calls)
+		}
+	}
+
+	if pool != nil {
+		if stats, ok := pool(); ok {
+			writeHeader(w, "osmi_db_pool_total_connections", "gauge", "Conexiones abiertas en el pool.")
+			fmt.Fprintf(w, "osmi_db_pool_total_connections %d\n", stats.Total)
+			writeHeader(w, "osmi_db_pool_idle_connections", "gauge", "Conexiones ociosas en el pool.")
+			fmt.Fprintf(w, "osmi_db_pool_idle_connections %d\n", stats.Idle)
+			writeHeader(w, "osmi_db_pool_max_connections", "gauge", "Máximo de conexiones del pool.")
+			fmt.Fprintf(w, "osmi_db_pool_max_connections %d\n", stats.Max)
+		}
+	}
+
+	return w.Flush()
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeHistogram escribe buckets acumulados, _sum y _count. buckets trae los
+// conteos por intervalo (no acumulados) con la última posición para +Inf.
+func writeHistogram(w io.Writer, name string, kv []string, bounds []time.Duration, buckets []int64, total time.Duration, count int64) {
+	var cumulative int64
+	for i, bound := range bounds {
+		cumulative += buckets[i]
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(append(kv, "le", formatSeconds(bound))...), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(append(kv, "le", "+Inf")...), count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels(kv...), formatSeconds(total))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels(kv...), count)
+}
+
+// labels arma {k="v",...} a partir de pares clave/valor
+func labels(kv ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(kv); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(kv[i])
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(kv[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRPCRegistryObserveRPC(t *testing.T) {
+	r := NewRPCRegistry()
+	r.ObserveRPC("/osmi.OsmiService/GetEvent", "OK", time.Millisecond)
+	r.ObserveRPC("/osmi.OsmiService/GetEvent", "OK", 10*time.Second)
+	r.ObserveRPC("/osmi.OsmiService/GetEvent", "NotFound", time.Millisecond)
+	r.ObserveRPC("/osmi.OsmiService/CreateEvent", "OK", time.Millisecond)
+
+	snapshot := r.Snapshot()
+	if len(snapshot) != 3 {
+		t.Fatalf("snapshot has %d series, want 3", len(snapshot))
+	}
+	if snapshot[0].Method != "/osmi.OsmiService/CreateEvent" || snapshot[1].Code != "NotFound" || snapshot[2].Code != "OK" {
+		t.Errorf("snapshot order = %+v, want by method then code", snapshot)
+	}
+	ok := snapshot[2]
+	if ok.Calls != 2 || ok.Buckets[0] != 1 || ok.Buckets[len(DefaultLatencyBuckets)] != 1 {
+		t.Errorf("GetEvent OK = %+v, want one call in the first bucket and one over the last", ok)
+	}
+
+	// Snapshot devuelve copias
+	ok.Buckets[0] = 99
+	if again := r.Snapshot(); again[2].Buckets[0] != 1 {
+		t.Error("mutating the snapshot changed the registry")
+	}
+}
+
+func TestHandlerWritesPrometheusText(t *testing.T) {
+	rpc := NewRPCRegistry()
+	rpc.ObserveRPC(`/osmi.OsmiService/Get"Event`, "OK", 2*time.Millisecond)
+	repos := NewRegistry()
+	repos.ObserveRepositoryCall("event", "GetByID", time.Millisecond, true)
+	pool := func() (PoolStats, bool) { return PoolStats{Total: 4, Idle: 3, Max: 10}, true }
+
+	rec := httptest.NewRecorder()
+	Handler(rpc, repos, pool).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type = %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE osmi_grpc_requests_total counter\n",
+		`osmi_grpc_requests_total{method="/osmi.OsmiService/Get\"Event",code="OK"} 1` + "\n",
+		`osmi_grpc_request_duration_seconds_bucket{method="/osmi.OsmiService/Get\"Event",code="OK",le="0.001"} 0` + "\n",
+		`osmi_grpc_request_duration_seconds_bucket{method="/osmi.OsmiService/Get\"Event",code="OK",le="0.005"} 1` + "\n",
+		`osmi_grpc_request_duration_seconds_bucket{method="/osmi.OsmiService/Get\"Event",code="OK",le="+Inf"} 1` + "\n",
+		`osmi_grpc_request_duration_seconds_sum{method="/osmi.OsmiService/Get\"Event",code="OK"} 0.002` + "\n",
+		`osmi_repository_calls_total{repository="event",method="GetByID"} 1` + "\n",
+		`osmi_repository_errors_total{repository="event",method="GetByID"} 1` + "\n",
+		`osmi_repository_call_duration_seconds_count{repository="event",method="GetByID"} 1` + "\n",
+		"osmi_db_pool_total_connections 4\n",
+		"osmi_db_pool_idle_connections 3\n",
+		"osmi_db_pool_max_connections 10\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("exposition missing %q", want)
+		}
+	}
+}
+
+func TestHandlerOmitsMissingSections(t *testing.T) {
+	rec := httptest.NewRecorder()
+	notReady := func() (PoolStats, bool) { return PoolStats{}, false }
+	Handler(nil, nil, notReady).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if body := rec.Body.String(); body != "" {
+		t.Errorf("body = %q, want empty exposition before the pool exists", body)
+	}
+}
@@ -0,0 +1,118 @@
+// internal/infrastructure/metrics/registry.go
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets son los límites superiores (inclusive) del histograma de latencia
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// MethodStats son los contadores acumulados de un método de repositorio.
+// Buckets[i] cuenta las llamadas con latencia <= Bounds[i]; la última posición
+// cuenta las que superan el último límite.
+type MethodStats struct {
+	Repository string
+	Method     string
+	Calls      int64
+	Errors     int64
+	TotalTime  time.Duration
+	Bounds     []time.Duration
+	Buckets    []int64
+}
+
+type methodKey struct {
+	repository string
+	method     string
+}
+
+// Registry acumula llamadas y latencias por repositorio y método; es seguro
+// para uso concurrente
+type Registry struct {
+	mu      sync.Mutex
+	bounds  []time.Duration
+	methods map[methodKey]*MethodStats
+}
+
+// NewRegistry crea un registro con DefaultLatencyBuckets
+func NewRegistry() *Registry {
+	return &Registry{
+		bounds:  DefaultLatencyBuckets,
+		methods: make(map[methodKey]*MethodStats),
+	}
+}
+
+// ObserveRepositoryCall registra una llamada con su duración y si terminó en error
+func (r *Registry) ObserveRepositoryCall(repository, method string, elapsed time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := methodKey{repository: repository, method: method}
+	stats, ok := r.methods[key]
+	if !ok {
+		stats = &MethodStats{
+			Repository: repository,
+			Method:     method,
+			Bounds:     r.bounds,
+			Buckets:    make([]int64, len(r.bounds)+1),
+		}
+		r.methods[key] = stats
+	}
+
+	stats.Calls++
+	stats.TotalTime += elapsed
+	if failed {
+		stats.Errors++
+	}
+	bucket := sort.Search(len(r.bounds), func(i int) bool { return elapsed <= r.bounds[i] })
+	stats.Buckets[bucket]++
+}
+
+// Get devuelve una copia de los contadores de un método
+func (r *Registry) Get(repository, method string) (MethodStats, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.methods[methodKey{repository: repository, method: method}]
+	if !ok {
+		return MethodStats{}, false
+	}
+	return stats.copy(), true
+}
+
+// Snapshot devuelve una copia de todos los contadores ordenada por repositorio y método
+func (r *Registry) Snapshot() []MethodStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]MethodStats, 0, len(r.methods))
+	for _, stats := range r.methods {
+		out = append(out, stats.copy())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Repository != out[j].Repository {
+			return out[i].Repository < out[j].Repository
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+func (s *MethodStats) copy() MethodStats {
+	c := *s
+	c.Buckets = append([]int64(nil), s.Buckets...)
+	return c
+}
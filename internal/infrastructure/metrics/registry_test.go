@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegistryObserveRepositoryCall(t *testing.T) {
+	r := NewRegistry()
+	r.ObserveRepositoryCall("event", "GetByID", time.Millisecond, false)   // primer bucket (inclusive)
+	r.ObserveRepositoryCall("event", "GetByID", 30*time.Millisecond, true) // <= 50ms
+	r.ObserveRepositoryCall("event", "GetByID", 10*time.Second, false)     // sobre el último límite
+	r.ObserveRepositoryCall("customer", "GetByID", time.Millisecond, false)
+
+	stats, ok := r.Get("event", "GetByID")
+	if !ok {
+		t.Fatal("event.GetByID not recorded")
+	}
+	if stats.Calls != 3 || stats.Errors != 1 || stats.TotalTime != 10031*time.Millisecond {
+		t.Errorf("calls = %d, errors = %d, total = %s; want 3, 1, 10.031s", stats.Calls, stats.Errors, stats.TotalTime)
+	}
+	want := map[int]int64{0: 1, 4: 1, len(DefaultLatencyBuckets): 1}
+	for i, n := range stats.Buckets {
+		if n != want[i] {
+			t.Errorf("bucket %d = %d, want %d", i, n, want[i])
+		}
+	}
+
+	// Get devuelve una copia
+	stats.Buckets[0] = 99
+	if again, _ := r.Get("event", "GetByID"); again.Buckets[0] != 1 {
+		t.Error("mutating the returned stats changed the registry")
+	}
+
+	snapshot := r.Snapshot()
+	if len(snapshot) != 2 || snapshot[0].Repository != "customer" || snapshot[1].Repository != "event" {
+		t.Errorf("snapshot = %+v, want customer then event", snapshot)
+	}
+}
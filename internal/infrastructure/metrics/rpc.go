@@ -0,0 +1,83 @@
+// internal/infrastructure/metrics/rpc.go
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RPCStats son los contadores acumulados de un método gRPC para un código de
+// respuesta. Buckets sigue la misma convención que MethodStats.
+type RPCStats struct {
+	Method    string
+	Code      string
+	Calls     int64
+	TotalTime time.Duration
+	Bounds    []time.Duration
+	Buckets   []int64
+}
+
+type rpcKey struct {
+	method string
+	code   string
+}
+
+// RPCRegistry acumula llamadas y latencias por método gRPC y código de
+// respuesta; es seguro para uso concurrente
+type RPCRegistry struct {
+	mu     sync.Mutex
+	bounds []time.Duration
+	calls  map[rpcKey]*RPCStats
+}
+
+// NewRPCRegistry crea un registro con DefaultLatencyBuckets
+func NewRPCRegistry() *RPCRegistry {
+	return &RPCRegistry{
+		bounds: DefaultLatencyBuckets,
+		calls:  make(map[rpcKey]*RPCStats),
+	}
+}
+
+// ObserveRPC registra una llamada con su código de respuesta y duración
+func (r *RPCRegistry) ObserveRPC(method, code string, elapsed time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := rpcKey{method: method, code: code}
+	stats, ok := r.calls[key]
+	if !ok {
+		stats = &RPCStats{
+			Method:  method,
+			Code:    code,
+			Bounds:  r.bounds,
+			Buckets: make([]int64, len(r.bounds)+1),
+		}
+		r.calls[key] = stats
+	}
+
+	stats.Calls++
+	stats.TotalTime += elapsed
+	bucket := sort.Search(len(r.bounds), func(i int) bool { return elapsed <= r.bounds[i] })
+	stats.Buckets[bucket]++
+}
+
+// Snapshot devuelve una copia de todos los contadores ordenada por método y código
+func (r *RPCRegistry) Snapshot() []RPCStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]RPCStats, 0, len(r.calls))
+	for _, stats := range r.calls {
+		c := *stats
+		c.Buckets = append([]int64(nil), stats.Buckets...)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Method != out[j].Method {
+			return out[i].Method < out[j].Method
+		}
+		return out[i].Code < out[j].Code
+	})
+	return out
+}
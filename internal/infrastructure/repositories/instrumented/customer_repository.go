@@ -0,0 +1,189 @@
+// internal/infrastructure/repositories/instrumented/customer_repository.go
+package instrumented
+
+import (
+	"context"
+	"io"
+	"time"
+
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/metrics"
+)
+
+// CustomerRepository registra llamadas y latencia de cada método del repositorio
+// de clientes y delega en next
+type CustomerRepository struct {
+	recorder
+	next repository.CustomerRepository
+}
+
+var _ repository.CustomerRepository = (*CustomerRepository)(nil)
+
+func NewCustomerRepository(next repository.CustomerRepository, registry *metrics.Registry) *CustomerRepository {
+	return &CustomerRepository{
+		recorder: recorder{registry: registry, repository: "customer"},
+		next:     next,
+	}
+}
+
+func (r *CustomerRepository) Create(ctx context.Context, customer *entities.Customer) (err error) {
+	defer r.observe(ctx, "Create", time.Now(), &err)
+	return r.next.Create(ctx, customer)
+}
+
+func (r *CustomerRepository) Update(ctx context.Context, customer *entities.Customer) (err error) {
+	defer r.observe(ctx, "Update", time.Now(), &err)
+	return r.next.Update(ctx, customer)
+}
+
+func (r *CustomerRepository) Delete(ctx context.Context, id int64) (err error) {
+	defer r.observe(ctx, "Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *CustomerRepository) SoftDelete(ctx context.Context, publicID string) (err error) {
+	defer r.observe(ctx, "SoftDelete", time.Now(), &err)
+	return r.next.SoftDelete(ctx, publicID)
+}
+
+func (r *CustomerRepository) LinkToUser(ctx context.Context, customerID, userID int64) (err error) {
+	defer r.observe(ctx, "LinkToUser", time.Now(), &err)
+	return r.next.LinkToUser(ctx, customerID, userID)
+}
+
+func (r *CustomerRepository) Anonymize(ctx context.Context, publicID string) (err error) {
+	defer r.observe(ctx, "Anonymize", time.Now(), &err)
+	return r.next.Anonymize(ctx, publicID)
+}
+
+func (r *CustomerRepository) Find(ctx context.Context, filter *repository.CustomerFilter) (_ []*entities.Customer, _ int64, err error) {
+	defer r.observe(ctx, "Find", time.Now(), &err)
+	return r.next.Find(ctx, filter)
+}
+
+func (r *CustomerRepository) GetByID(ctx context.Context, id int64) (_ *entities.Customer, err error) {
+	defer r.observe(ctx, "GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *CustomerRepository) GetByPublicID(ctx context.Context, publicID string) (_ *entities.Customer, err error) {
+	defer r.observe(ctx, "GetByPublicID", time.Now(), &err)
+	return r.next.GetByPublicID(ctx, publicID)
+}
+
+func (r *CustomerRepository) GetActiveByPublicID(ctx context.Context, publicID string) (_ *entities.Customer, err error) {
+	defer r.observe(ctx, "GetActiveByPublicID", time.Now(), &err)
+	return r.next.GetActiveByPublicID(ctx, publicID)
+}
+
+func (r *CustomerRepository) GetByEmail(ctx context.Context, email string) (_ *entities.Customer, err error) {
+	defer r.observe(ctx, "GetByEmail", time.Now(), &err)
+	return r.next.GetByEmail(ctx, email)
+}
+
+func (r *CustomerRepository) GetByUserID(ctx context.Context, userID int64) (_ *entities.Customer, err error) {
+	defer r.observe(ctx, "GetByUserID", time.Now(), &err)
+	return r.next.GetByUserID(ctx, userID)
+}
+
+func (r *CustomerRepository) Exists(ctx context.Context, id int64) (_ bool, err error) {
+	defer r.observe(ctx, "Exists", time.Now(), &err)
+	return r.next.Exists(ctx, id)
+}
+
+func (r *CustomerRepository) ExistsByEmail(ctx context.Context, email string) (_ bool, err error) {
+	defer r.observe(ctx, "ExistsByEmail", time.Now(), &err)
+	return r.next.ExistsByEmail(ctx, email)
+}
+
+func (r *CustomerRepository) UpdateStats(ctx context.Context, customerID int64, amount float64) (err error) {
+	defer r.observe(ctx, "UpdateStats", time.Now(), &err)
+	return r.next.UpdateStats(ctx, customerID, amount)
+}
+
+func (r *CustomerRepository) UpdateLoyaltyPoints(ctx context.Context, customerID int64, points int32) (err error) {
+	defer r.observe(ctx, "UpdateLoyaltyPoints", time.Now(), &err)
+	return r.next.UpdateLoyaltyPoints(ctx, customerID, points)
+}
+
+func (r *CustomerRepository) SetVIP(ctx context.Context, customerID int64, isVIP bool) (err error) {
+	defer r.observe(ctx, "SetVIP", time.Now(), &err)
+	return r.next.SetVIP(ctx, customerID, isVIP)
+}
+
+func (r *CustomerRepository) BulkSetVIP(ctx context.Context, minLifetimeValue float64) (_ int64, err error) {
+	defer r.observe(ctx, "BulkSetVIP", time.Now(), &err)
+	return r.next.BulkSetVIP(ctx, minLifetimeValue)
+}
+
+func (r *CustomerRepository) BulkUpdateSegment(ctx context.Context, filter *repository.CustomerFilter, segment string) (_ int64, err error) {
+	defer r.observe(ctx, "BulkUpdateSegment", time.Now(), &err)
+	return r.next.BulkUpdateSegment(ctx, filter, segment)
+}
+
+func (r *CustomerRepository) UpdatePreferences(ctx context.Context, customerID int64, preferences map[string]interface{}) (err error) {
+	defer r.observe(ctx, "UpdatePreferences", time.Now(), &err)
+	return r.next.UpdatePreferences(ctx, customerID, preferences)
+}
+
+func (r *CustomerRepository) UpdateInvoiceSettings(ctx context.Context, customerID int64, requiresInvoice bool, taxID, taxName string) (err error) {
+	defer r.observe(ctx, "UpdateInvoiceSettings", time.Now(), &err)
+	return r.next.UpdateInvoiceSettings(ctx, customerID, requiresInvoice, taxID, taxName)
+}
+
+func (r *CustomerRepository) CreatePhoneVerification(ctx context.Context, customerID int64) (_ string, err error) {
+	defer r.observe(ctx, "CreatePhoneVerification", time.Now(), &err)
+	return r.next.CreatePhoneVerification(ctx, customerID)
+}
+
+func (r *CustomerRepository) VerifyPhone(ctx context.Context, customerID int64, code string) (err error) {
+	defer r.observe(ctx, "VerifyPhone", time.Now(), &err)
+	return r.next.VerifyPhone(ctx, customerID, code)
+}
+
+func (r *CustomerRepository) GetStats(ctx context.Context) (_ *repository.CustomerStats, err error) {
+	defer r.observe(ctx, "GetStats", time.Now(), &err)
+	return r.next.GetStats(ctx)
+}
+
+func (r *CustomerRepository) GetVIPCustomers(ctx context.Context) (_ []*entities.Customer, err error) {
+	defer r.observe(ctx, "GetVIPCustomers", time.Now(), &err)
+	return r.next.GetVIPCustomers(ctx)
+}
+
+func (r *CustomerRepository) GetAtRiskVIPs(ctx context.Context, dormantSince time.Time) (_ []*entities.Customer, err error) {
+	defer r.observe(ctx, "GetAtRiskVIPs", time.Now(), &err)
+	return r.next.GetAtRiskVIPs(ctx, dormantSince)
+}
+
+func (r *CustomerRepository) GetCohortRetention(ctx context.Context, months int) (_ []repository.CohortRow, err error) {
+	defer r.observe(ctx, "GetCohortRetention", time.Now(), &err)
+	return r.next.GetCohortRetention(ctx, months)
+}
+
+func (r *CustomerRepository) ExportMarketingContacts(ctx context.Context, w io.Writer) (err error) {
+	defer r.observe(ctx, "ExportMarketingContacts", time.Now(), &err)
+	return r.next.ExportMarketingContacts(ctx, w)
+}
+
+func (r *CustomerRepository) FindDuplicates(ctx context.Context, pagination commondto.Pagination) (_ []repository.DuplicateGroup, err error) {
+	defer r.observe(ctx, "FindDuplicates", time.Now(), &err)
+	return r.next.FindDuplicates(ctx, pagination)
+}
+
+func (r *CustomerRepository) GetNewVsReturningStats(ctx context.Context, from, to time.Time) (_ *repository.NewReturningStats, err error) {
+	defer r.observe(ctx, "GetNewVsReturningStats", time.Now(), &err)
+	return r.next.GetNewVsReturningStats(ctx, from, to)
+}
+
+func (r *CustomerRepository) GetAcquisitionSources(ctx context.Context, from, to time.Time) (_ []repository.SourceStat, err error) {
+	defer r.observe(ctx, "GetAcquisitionSources", time.Now(), &err)
+	return r.next.GetAcquisitionSources(ctx, from, to)
+}
+
+func (r *CustomerRepository) GetGeographicDistribution(ctx context.Context, level string, pagination commondto.Pagination) (_ []repository.GeoStat, err error) {
+	defer r.observe(ctx, "GetGeographicDistribution", time.Now(), &err)
+	return r.next.GetGeographicDistribution(ctx, level, pagination)
+}
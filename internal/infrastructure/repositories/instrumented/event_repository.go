@@ -0,0 +1,303 @@
+// internal/infrastructure/repositories/instrumented/event_repository.go
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/metrics"
+)
+
+// EventRepository registra llamadas y latencia de cada método del repositorio
+// de eventos y delega en next
+type EventRepository struct {
+	recorder
+	next repository.EventRepository
+}
+
+var _ repository.EventRepository = (*EventRepository)(nil)
+
+func NewEventRepository(next repository.EventRepository, registry *metrics.Registry) *EventRepository {
+	return &EventRepository{
+		recorder: recorder{registry: registry, repository: "event"},
+		next:     next,
+	}
+}
+
+func (r *EventRepository) Create(ctx context.Context, event *entities.Event) (err error) {
+	defer r.observe(ctx, "Create", time.Now(), &err)
+	return r.next.Create(ctx, event)
+}
+
+func (r *EventRepository) GetByID(ctx context.Context, id int64) (_ *entities.Event, err error) {
+	defer r.observe(ctx, "GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *EventRepository) GetByPublicID(ctx context.Context, publicID string) (_ *entities.Event, err error) {
+	defer r.observe(ctx, "GetByPublicID", time.Now(), &err)
+	return r.next.GetByPublicID(ctx, publicID)
+}
+
+func (r *EventRepository) GetBySlug(ctx context.Context, slug string) (_ *entities.Event, err error) {
+	defer r.observe(ctx, "GetBySlug", time.Now(), &err)
+	return r.next.GetBySlug(ctx, slug)
+}
+
+func (r *EventRepository) FindBySlugOrPublicID(ctx context.Context, identifier string) (_ *entities.Event, err error) {
+	defer r.observe(ctx, "FindBySlugOrPublicID", time.Now(), &err)
+	return r.next.FindBySlugOrPublicID(ctx, identifier)
+}
+
+func (r *EventRepository) GetEventDetail(ctx context.Context, publicID string) (_ *repository.EventDetail, err error) {
+	defer r.observe(ctx, "GetEventDetail", time.Now(), &err)
+	return r.next.GetEventDetail(ctx, publicID)
+}
+
+func (r *EventRepository) GetRelatedEvents(ctx context.Context, eventID int64, limit int) (_ []*entities.Event, err error) {
+	defer r.observe(ctx, "GetRelatedEvents", time.Now(), &err)
+	return r.next.GetRelatedEvents(ctx, eventID, limit)
+}
+
+func (r *EventRepository) GetOccupancyForecast(ctx context.Context, eventID int64) (_ *repository.Forecast, err error) {
+	defer r.observe(ctx, "GetOccupancyForecast", time.Now(), &err)
+	return r.next.GetOccupancyForecast(ctx, eventID)
+}
+
+func (r *EventRepository) GetReservationStats(ctx context.Context, eventID int64) (_ *repository.ReservationStats, err error) {
+	defer r.observe(ctx, "GetReservationStats", time.Now(), &err)
+	return r.next.GetReservationStats(ctx, eventID)
+}
+
+func (r *EventRepository) GetSalesChannelBreakdown(ctx context.Context, eventID int64) (_ *repository.ChannelStats, err error) {
+	defer r.observe(ctx, "GetSalesChannelBreakdown", time.Now(), &err)
+	return r.next.GetSalesChannelBreakdown(ctx, eventID)
+}
+
+func (r *EventRepository) Publish(ctx context.Context, id int64, publishedAt time.Time, actor string) (err error) {
+	defer r.observe(ctx, "Publish", time.Now(), &err)
+	return r.next.Publish(ctx, id, publishedAt, actor)
+}
+
+func (r *EventRepository) Unpublish(ctx context.Context, id int64, actor string) (err error) {
+	defer r.observe(ctx, "Unpublish", time.Now(), &err)
+	return r.next.Unpublish(ctx, id, actor)
+}
+
+func (r *EventRepository) GetPublishHistory(ctx context.Context, eventPublicID string) (_ []repository.PublishEvent, err error) {
+	defer r.observe(ctx, "GetPublishHistory", time.Now(), &err)
+	return r.next.GetPublishHistory(ctx, eventPublicID)
+}
+
+func (r *EventRepository) GetStatusChangesSince(ctx context.Context, after repository.StatusChangeCursor, limit int) (_ []repository.StatusChangeEvent, err error) {
+	defer r.observe(ctx, "GetStatusChangesSince", time.Now(), &err)
+	return r.next.GetStatusChangesSince(ctx, after, limit)
+}
+
+func (r *EventRepository) GetSoldOutEvents(ctx context.Context, from, to time.Time) (_ []repository.SoldOutInfo, err error) {
+	defer r.observe(ctx, "GetSoldOutEvents", time.Now(), &err)
+	return r.next.GetSoldOutEvents(ctx, from, to)
+}
+
+func (r *EventRepository) GetBusiestDays(ctx context.Context, organizerPublicID string, limit int) (_ []repository.DayLoad, err error) {
+	defer r.observe(ctx, "GetBusiestDays", time.Now(), &err)
+	return r.next.GetBusiestDays(ctx, organizerPublicID, limit)
+}
+
+func (r *EventRepository) GetMonthlyEventCounts(ctx context.Context, organizerPublicID string, year int) (_ []repository.MonthCount, err error) {
+	defer r.observe(ctx, "GetMonthlyEventCounts", time.Now(), &err)
+	return r.next.GetMonthlyEventCounts(ctx, organizerPublicID, year)
+}
+
+func (r *EventRepository) GetCapacityUtilization(ctx context.Context, organizerPublicID string) (_ *repository.UtilizationSummary, err error) {
+	defer r.observe(ctx, "GetCapacityUtilization", time.Now(), &err)
+	return r.next.GetCapacityUtilization(ctx, organizerPublicID)
+}
+
+func (r *EventRepository) GetRevenueByCategory(ctx context.Context, eventID int64) (_ []repository.CategoryRevenue, err error) {
+	defer r.observe(ctx, "GetRevenueByCategory", time.Now(), &err)
+	return r.next.GetRevenueByCategory(ctx, eventID)
+}
+
+func (r *EventRepository) GetPriceRange(ctx context.Context, eventID int64) (_ float64, _ float64, err error) {
+	defer r.observe(ctx, "GetPriceRange", time.Now(), &err)
+	return r.next.GetPriceRange(ctx, eventID)
+}
+
+func (r *EventRepository) GetSettings(ctx context.Context, publicID string) (_ entities.EventSettings, err error) {
+	defer r.observe(ctx, "GetSettings", time.Now(), &err)
+	return r.next.GetSettings(ctx, publicID)
+}
+
+func (r *EventRepository) UpdateSettings(ctx context.Context, publicID string, settings entities.EventSettings) (err error) {
+	defer r.observe(ctx, "UpdateSettings", time.Now(), &err)
+	return r.next.UpdateSettings(ctx, publicID, settings)
+}
+
+func (r *EventRepository) JoinWaitlist(ctx context.Context, eventID, customerID int64) (err error) {
+	defer r.observe(ctx, "JoinWaitlist", time.Now(), &err)
+	return r.next.JoinWaitlist(ctx, eventID, customerID)
+}
+
+func (r *EventRepository) LeaveWaitlist(ctx context.Context, eventID, customerID int64) (err error) {
+	defer r.observe(ctx, "LeaveWaitlist", time.Now(), &err)
+	return r.next.LeaveWaitlist(ctx, eventID, customerID)
+}
+
+func (r *EventRepository) GetWaitlist(ctx context.Context, eventID int64) (_ []repository.WaitlistEntry, err error) {
+	defer r.observe(ctx, "GetWaitlist", time.Now(), &err)
+	return r.next.GetWaitlist(ctx, eventID)
+}
+
+func (r *EventRepository) NotifyNextWaitlisted(ctx context.Context, eventID int64) (_ *repository.WaitlistEntry, err error) {
+	defer r.observe(ctx, "NotifyNextWaitlisted", time.Now(), &err)
+	return r.next.NotifyNextWaitlisted(ctx, eventID)
+}
+
+func (r *EventRepository) FindByIDsOrdered(ctx context.Context, ids []int64) (_ []*entities.Event, err error) {
+	defer r.observe(ctx, "FindByIDsOrdered", time.Now(), &err)
+	return r.next.FindByIDsOrdered(ctx, ids)
+}
+
+func (r *EventRepository) Update(ctx context.Context, event *entities.Event) (err error) {
+	defer r.observe(ctx, "Update", time.Now(), &err)
+	return r.next.Update(ctx, event)
+}
+
+func (r *EventRepository) Delete(ctx context.Context, id int64) (err error) {
+	defer r.observe(ctx, "Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *EventRepository) Cancel(ctx context.Context, id int64, reason string) (err error) {
+	defer r.observe(ctx, "Cancel", time.Now(), &err)
+	return r.next.Cancel(ctx, id, reason)
+}
+
+func (r *EventRepository) SoftDelete(ctx context.Context, id int64) (err error) {
+	defer r.observe(ctx, "SoftDelete", time.Now(), &err)
+	return r.next.SoftDelete(ctx, id)
+}
+
+func (r *EventRepository) Restore(ctx context.Context, id int64) (err error) {
+	defer r.observe(ctx, "Restore", time.Now(), &err)
+	return r.next.Restore(ctx, id)
+}
+
+func (r *EventRepository) BulkUpdateStatus(ctx context.Context, eventIDs []int64, target string) (_ int64, _ []int64, err error) {
+	defer r.observe(ctx, "BulkUpdateStatus", time.Now(), &err)
+	return r.next.BulkUpdateStatus(ctx, eventIDs, target)
+}
+
+func (r *EventRepository) BulkCancelByOrganizer(ctx context.Context, organizerPublicID, reason string) (_ int64, err error) {
+	defer r.observe(ctx, "BulkCancelByOrganizer", time.Now(), &err)
+	return r.next.BulkCancelByOrganizer(ctx, organizerPublicID, reason)
+}
+
+func (r *EventRepository) List(ctx context.Context, filter map[string]interface{}, limit, offset int) (_ []*entities.Event, _ int64, err error) {
+	defer r.observe(ctx, "List", time.Now(), &err)
+	return r.next.List(ctx, filter, limit, offset)
+}
+
+func (r *EventRepository) ListByOrganizer(ctx context.Context, organizerID int64, limit, offset int) (_ []*entities.Event, _ int64, err error) {
+	defer r.observe(ctx, "ListByOrganizer", time.Now(), &err)
+	return r.next.ListByOrganizer(ctx, organizerID, limit, offset)
+}
+
+func (r *EventRepository) ListByCategory(ctx context.Context, categoryID int64, limit, offset int) (_ []*entities.Event, _ int64, err error) {
+	defer r.observe(ctx, "ListByCategory", time.Now(), &err)
+	return r.next.ListByCategory(ctx, categoryID, limit, offset)
+}
+
+func (r *EventRepository) ListByVenue(ctx context.Context, venueID int64, limit, offset int) (_ []*entities.Event, _ int64, err error) {
+	defer r.observe(ctx, "ListByVenue", time.Now(), &err)
+	return r.next.ListByVenue(ctx, venueID, limit, offset)
+}
+
+func (r *EventRepository) ListUpcoming(ctx context.Context, limit int) (_ []*entities.Event, err error) {
+	defer r.observe(ctx, "ListUpcoming", time.Now(), &err)
+	return r.next.ListUpcoming(ctx, limit)
+}
+
+func (r *EventRepository) ListFeatured(ctx context.Context, pagination commondto.Pagination) (_ []*entities.Event, _ int64, err error) {
+	defer r.observe(ctx, "ListFeatured", time.Now(), &err)
+	return r.next.ListFeatured(ctx, pagination)
+}
+
+func (r *EventRepository) GetEventsStartingBetween(ctx context.Context, from, to time.Time) (_ []*entities.Event, err error) {
+	defer r.observe(ctx, "GetEventsStartingBetween", time.Now(), &err)
+	return r.next.GetEventsStartingBetween(ctx, from, to)
+}
+
+func (r *EventRepository) GetUpcomingCountByVenue(ctx context.Context, venueIDs []int64) (_ map[int64]int64, err error) {
+	defer r.observe(ctx, "GetUpcomingCountByVenue", time.Now(), &err)
+	return r.next.GetUpcomingCountByVenue(ctx, venueIDs)
+}
+
+func (r *EventRepository) GetStaleDrafts(ctx context.Context, olderThan time.Duration) (_ []*entities.Event, err error) {
+	defer r.observe(ctx, "GetStaleDrafts", time.Now(), &err)
+	return r.next.GetStaleDrafts(ctx, olderThan)
+}
+
+func (r *EventRepository) GetUpcomingForCategory(ctx context.Context, categoryPublicID string, limit int) (_ []*entities.Event, err error) {
+	defer r.observe(ctx, "GetUpcomingForCategory", time.Now(), &err)
+	return r.next.GetUpcomingForCategory(ctx, categoryPublicID, limit)
+}
+
+func (r *EventRepository) GetEventCategories(ctx context.Context, eventID int64) (_ []*entities.Category, err error) {
+	defer r.observe(ctx, "GetEventCategories", time.Now(), &err)
+	return r.next.GetEventCategories(ctx, eventID)
+}
+
+func (r *EventRepository) AddCategoryToEvent(ctx context.Context, eventID, categoryID int64, isPrimary bool) (err error) {
+	defer r.observe(ctx, "AddCategoryToEvent", time.Now(), &err)
+	return r.next.AddCategoryToEvent(ctx, eventID, categoryID, isPrimary)
+}
+
+func (r *EventRepository) RemoveCategoryFromEvent(ctx context.Context, eventID, categoryID int64) (err error) {
+	defer r.observe(ctx, "RemoveCategoryFromEvent", time.Now(), &err)
+	return r.next.RemoveCategoryFromEvent(ctx, eventID, categoryID)
+}
+
+func (r *EventRepository) GetGlobalStats(ctx context.Context, from, to *time.Time) (_ *repository.EventGlobalStats, err error) {
+	defer r.observe(ctx, "GetGlobalStats", time.Now(), &err)
+	return r.next.GetGlobalStats(ctx, from, to)
+}
+
+func (r *EventRepository) GetTagCloud(ctx context.Context, limit int) (_ []repository.TagCount, err error) {
+	defer r.observe(ctx, "GetTagCloud", time.Now(), &err)
+	return r.next.GetTagCloud(ctx, limit)
+}
+
+func (r *EventRepository) GetAttendeeDemographics(ctx context.Context, eventID int64) (_ *repository.Demographics, err error) {
+	defer r.observe(ctx, "GetAttendeeDemographics", time.Now(), &err)
+	return r.next.GetAttendeeDemographics(ctx, eventID)
+}
+
+func (r *EventRepository) GetSalesByHour(ctx context.Context, eventID int64) (_ []repository.HourlySales, err error) {
+	defer r.observe(ctx, "GetSalesByHour", time.Now(), &err)
+	return r.next.GetSalesByHour(ctx, eventID)
+}
+
+func (r *EventRepository) IncrementShareCount(ctx context.Context, eventID int64) (err error) {
+	defer r.observe(ctx, "IncrementShareCount", time.Now(), &err)
+	return r.next.IncrementShareCount(ctx, eventID)
+}
+
+func (r *EventRepository) UpdateCounters(ctx context.Context, eventID int64, views, shares, favorites int64) (err error) {
+	defer r.observe(ctx, "UpdateCounters", time.Now(), &err)
+	return r.next.UpdateCounters(ctx, eventID, views, shares, favorites)
+}
+
+func (r *EventRepository) RecountFavorites(ctx context.Context, eventID int64) (err error) {
+	defer r.observe(ctx, "RecountFavorites", time.Now(), &err)
+	return r.next.RecountFavorites(ctx, eventID)
+}
+
+func (r *EventRepository) RecountAllFavorites(ctx context.Context) (_ int64, err error) {
+	defer r.observe(ctx, "RecountAllFavorites", time.Now(), &err)
+	return r.next.RecountAllFavorites(ctx)
+}
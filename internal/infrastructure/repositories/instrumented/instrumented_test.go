@@ -0,0 +1,105 @@
+package instrumented
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/metrics"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/utils"
+)
+
+// stubEventRepo implementa sólo GetByID; el resto de la interfaz queda sin implementar
+type stubEventRepo struct {
+	repository.EventRepository
+}
+
+func (stubEventRepo) GetByID(_ context.Context, id int64) (*entities.Event, error) {
+	if id == 0 {
+		return nil, repository.ErrEventNotFound
+	}
+	return &entities.Event{ID: id}, nil
+}
+
+type stubCustomerRepo struct {
+	repository.CustomerRepository
+}
+
+func (stubCustomerRepo) GetByID(_ context.Context, id int64) (*entities.Customer, error) {
+	return &entities.Customer{ID: id}, nil
+}
+
+func TestEventRepositoryRecordsGetByID(t *testing.T) {
+	ctx := context.Background()
+	registry := metrics.NewRegistry()
+	repo := NewEventRepository(stubEventRepo{}, registry)
+
+	event, err := repo.GetByID(ctx, 7)
+	if err != nil || event.ID != 7 {
+		t.Fatalf("GetByID = %+v, %v; want the delegated event", event, err)
+	}
+	if _, err := repo.GetByID(ctx, 0); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Fatalf("GetByID(0) error = %v, want the delegated ErrEventNotFound", err)
+	}
+
+	stats, ok := registry.Get("event", "GetByID")
+	if !ok {
+		t.Fatal("no metric recorded for event.GetByID")
+	}
+	if stats.Calls != 2 || stats.Errors != 1 {
+		t.Errorf("calls = %d, errors = %d; want 2 and 1", stats.Calls, stats.Errors)
+	}
+	var observed int64
+	for _, n := range stats.Buckets {
+		observed += n
+	}
+	if observed != 2 {
+		t.Errorf("histogram holds %d observations, want 2", observed)
+	}
+}
+
+func TestCustomerRepositoryRecordsGetByID(t *testing.T) {
+	registry := metrics.NewRegistry()
+	repo := NewCustomerRepository(stubCustomerRepo{}, registry)
+
+	if _, err := repo.GetByID(context.Background(), 3); err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+
+	if stats, ok := registry.Get("customer", "GetByID"); !ok || stats.Calls != 1 || stats.Errors != 0 {
+		t.Errorf("customer.GetByID stats = %+v, %v; want one successful call", stats, ok)
+	}
+	if _, ok := registry.Get("event", "GetByID"); ok {
+		t.Error("customer call recorded under the event repository")
+	}
+}
+
+func TestRecorderLogsFailedCallsWithRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	repo := NewEventRepository(stubEventRepo{}, metrics.NewRegistry())
+	repo.SetLogger(utils.NewLogger("test").WithCallerInfo(false))
+	ctx := appctx.WithRequestID(context.Background(), "req-42")
+
+	// Una llamada rápida y exitosa no se loguea
+	if _, err := repo.GetByID(ctx, 7); err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("successful call logged: %q", buf.String())
+	}
+
+	repo.GetByID(ctx, 0)
+	if out := buf.String(); !strings.Contains(out, "Database GetByID on event") || !strings.Contains(out, "request_id=req-42") {
+		t.Errorf("failed call log = %q, want the method, repository and request id", out)
+	}
+}
@@ -0,0 +1,37 @@
+// internal/infrastructure/repositories/instrumented/recorder.go
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/metrics"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/utils"
+)
+
+// slowCallThreshold es la latencia a partir de la cual una llamada exitosa se loguea
+const slowCallThreshold = 500 * time.Millisecond
+
+// recorder es la parte común de los decoradores: cada método hace
+// defer r.observe(ctx, "Metodo", time.Now(), &err) y delega, sin tocar la
+// implementación de postgres
+type recorder struct {
+	registry   *metrics.Registry
+	repository string
+	logger     *utils.Logger
+}
+
+// SetLogger activa el log de llamadas fallidas o lentas, con el request id del
+// contexto para cruzarlas con el RPC que las originó
+func (r *recorder) SetLogger(logger *utils.Logger) {
+	r.logger = logger
+}
+
+func (r recorder) observe(ctx context.Context, method string, start time.Time, err *error) {
+	elapsed := time.Since(start)
+	r.registry.ObserveRepositoryCall(r.repository, method, elapsed, *err != nil)
+
+	if r.logger != nil && (*err != nil || elapsed >= slowCallThreshold) {
+		r.logger.DatabaseLoggerContext(ctx, method, r.repository, elapsed, 0, *err)
+	}
+}
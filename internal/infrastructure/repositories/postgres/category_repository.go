@@ -8,17 +8,17 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/repohelpers"
 )
 
 type CategoryRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewCategoryRepository(db *pgxpool.Pool) *CategoryRepository {
+func NewCategoryRepository(db DBTX) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
@@ -242,6 +242,19 @@ func (r *CategoryRepository) GetByPublicID(ctx context.Context, publicID string)
 	return categories[0], nil
 }
 
+// GetActiveByPublicID obtiene una categoría activa. Devuelve ErrCategoryNotFound si
+// no existe y ErrInactive si existe pero está desactivada.
+func (r *CategoryRepository) GetActiveByPublicID(ctx context.Context, publicID string) (*entities.Category, error) {
+	category, err := r.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if !category.IsActive {
+		return nil, fmt.Errorf("%w: category %s", repository.ErrInactive, publicID)
+	}
+	return category, nil
+}
+
 func (r *CategoryRepository) GetBySlug(ctx context.Context, slug string) (*entities.Category, error) {
 	filter := &repository.CategoryFilter{
 		Slug:  &slug,
@@ -269,9 +282,7 @@ func (r *CategoryRepository) GetByEventID(ctx context.Context, eventID string, i
 }
 
 func (r *CategoryRepository) Exists(ctx context.Context, id int64) (bool, error) {
-	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM ticketing.categories WHERE id = $1)`
-	err := r.db.QueryRow(ctx, query, id).Scan(&exists)
+	exists, err := repohelpers.Exists(ctx, r.db, "ticketing.categories", "id", id)
 	if err != nil {
 		return false, r.handleError(err, "failed to check category existence")
 	}
@@ -279,9 +290,7 @@ func (r *CategoryRepository) Exists(ctx context.Context, id int64) (bool, error)
 }
 
 func (r *CategoryRepository) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
-	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM ticketing.categories WHERE slug = $1)`
-	err := r.db.QueryRow(ctx, query, slug).Scan(&exists)
+	exists, err := repohelpers.Exists(ctx, r.db, "ticketing.categories", "slug", slug)
 	if err != nil {
 		return false, r.handleError(err, "failed to check slug existence")
 	}
@@ -0,0 +1,37 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+func TestCategoryRepositoryMissingVsInactive(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCategoryRepository(tx)
+
+	id := testsupport.SeedCategory(t, tx, "Retired")
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.categories SET is_active = false WHERE id = $1`, id); err != nil {
+		t.Fatalf("failed to deactivate category: %v", err)
+	}
+	publicID := testsupport.PublicID(t, tx, "ticketing.categories", id)
+
+	_, err := repo.GetActiveByPublicID(ctx, "00000000-0000-0000-0000-000000000000")
+	if !errors.Is(err, repository.ErrCategoryNotFound) {
+		t.Errorf("GetActiveByPublicID(missing) error = %v, want ErrCategoryNotFound", err)
+	}
+	_, err = repo.GetActiveByPublicID(ctx, publicID)
+	if !errors.Is(err, repository.ErrInactive) || errors.Is(err, repository.ErrCategoryNotFound) {
+		t.Errorf("GetActiveByPublicID(inactive) error = %v, want ErrInactive", err)
+	}
+
+	category, err := repo.GetByPublicID(ctx, publicID)
+	if err != nil || category.IsActive {
+		t.Errorf("GetByPublicID(inactive) = %+v, %v; want the inactive category", category, err)
+	}
+}
@@ -3,29 +3,47 @@ package postgres
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/repohelpers"
 )
 
 // CustomerRepository implementa la interfaz repository.CustomerRepository usando PostgreSQL
 type CustomerRepository struct {
-	db *pgxpool.Pool
+	db      DBTX
+	maxPage int
 }
 
 // NewCustomerRepository crea una nueva instancia del repositorio
-func NewCustomerRepository(db *pgxpool.Pool) *CustomerRepository {
+func NewCustomerRepository(db DBTX) *CustomerRepository {
 	return &CustomerRepository{
-		db: db,
+		db:      db,
+		maxPage: commondto.DefaultMaxPage,
+	}
+}
+
+// SetMaxPage fija la página más profunda que admiten los listados paginados por
+// offset. Valores no positivos se ignoran.
+func (r *CustomerRepository) SetMaxPage(page int) {
+	if page > 0 {
+		r.maxPage = page
 	}
 }
 
@@ -78,132 +96,7 @@ func (r *CustomerRepository) Find(ctx context.Context, filter *repository.Custom
 
 	countQuery := `SELECT COUNT(*) FROM crm.customers WHERE 1=1`
 
-	var conditions []string
-	args := pgx.NamedArgs{}
-	argPos := 1
-
-	if filter != nil {
-		// Filtros por ID
-		if len(filter.IDs) > 0 {
-			conditions = append(conditions, fmt.Sprintf("id = ANY(@id_%d)", argPos))
-			args[fmt.Sprintf("id_%d", argPos)] = filter.IDs
-			argPos++
-		}
-
-		if len(filter.PublicIDs) > 0 {
-			conditions = append(conditions, fmt.Sprintf("public_uuid = ANY(@public_%d)", argPos))
-			args[fmt.Sprintf("public_%d", argPos)] = filter.PublicIDs
-			argPos++
-		}
-
-		if filter.UserID != nil {
-			conditions = append(conditions, fmt.Sprintf("user_id = @user_%d", argPos))
-			args[fmt.Sprintf("user_%d", argPos)] = *filter.UserID
-			argPos++
-		}
-
-		if filter.Email != nil {
-			conditions = append(conditions, fmt.Sprintf("email = @email_%d", argPos))
-			args[fmt.Sprintf("email_%d", argPos)] = *filter.Email
-			argPos++
-		}
-
-		// Filtros de texto
-		if filter.SearchTerm != nil && *filter.SearchTerm != "" {
-			searchTerm := "%" + *filter.SearchTerm + "%"
-			conditions = append(conditions, fmt.Sprintf(
-				"(full_name ILIKE @search_%d OR email ILIKE @search_%d OR company_name ILIKE @search_%d OR tax_id ILIKE @search_%d)",
-				argPos, argPos, argPos, argPos,
-			))
-			args[fmt.Sprintf("search_%d", argPos)] = searchTerm
-			argPos++
-		}
-
-		if filter.FullName != nil {
-			conditions = append(conditions, fmt.Sprintf("full_name ILIKE @fullname_%d", argPos))
-			args[fmt.Sprintf("fullname_%d", argPos)] = "%" + *filter.FullName + "%"
-			argPos++
-		}
-
-		if filter.CompanyName != nil {
-			conditions = append(conditions, fmt.Sprintf("company_name ILIKE @company_%d", argPos))
-			args[fmt.Sprintf("company_%d", argPos)] = "%" + *filter.CompanyName + "%"
-			argPos++
-		}
-
-		if filter.Country != nil {
-			conditions = append(conditions, fmt.Sprintf("country = @country_%d", argPos))
-			args[fmt.Sprintf("country_%d", argPos)] = *filter.Country
-			argPos++
-		}
-
-		if filter.City != nil {
-			conditions = append(conditions, fmt.Sprintf("city ILIKE @city_%d", argPos))
-			args[fmt.Sprintf("city_%d", argPos)] = "%" + *filter.City + "%"
-			argPos++
-		}
-
-		if filter.IsActive != nil {
-			conditions = append(conditions, fmt.Sprintf("is_active = @active_%d", argPos))
-			args[fmt.Sprintf("active_%d", argPos)] = *filter.IsActive
-			argPos++
-		}
-
-		if filter.IsVIP != nil {
-			conditions = append(conditions, fmt.Sprintf("is_vip = @vip_%d", argPos))
-			args[fmt.Sprintf("vip_%d", argPos)] = *filter.IsVIP
-			argPos++
-		}
-
-		if filter.RequiresInvoice != nil {
-			conditions = append(conditions, fmt.Sprintf("requires_invoice = @invoice_%d", argPos))
-			args[fmt.Sprintf("invoice_%d", argPos)] = *filter.RequiresInvoice
-			argPos++
-		}
-
-		if filter.CustomerSegment != nil {
-			conditions = append(conditions, fmt.Sprintf("customer_segment = @segment_%d", argPos))
-			args[fmt.Sprintf("segment_%d", argPos)] = *filter.CustomerSegment
-			argPos++
-		}
-
-		// Filtros de fechas
-		if filter.CreatedFrom != nil {
-			conditions = append(conditions, fmt.Sprintf("created_at >= @created_from_%d", argPos))
-			args[fmt.Sprintf("created_from_%d", argPos)] = *filter.CreatedFrom
-			argPos++
-		}
-
-		if filter.CreatedTo != nil {
-			conditions = append(conditions, fmt.Sprintf("created_at <= @created_to_%d", argPos))
-			args[fmt.Sprintf("created_to_%d", argPos)] = *filter.CreatedTo
-			argPos++
-		}
-
-		if filter.LastPurchaseFrom != nil {
-			conditions = append(conditions, fmt.Sprintf("last_purchase_at >= @purchase_from_%d", argPos))
-			args[fmt.Sprintf("purchase_from_%d", argPos)] = *filter.LastPurchaseFrom
-			argPos++
-		}
-
-		if filter.LastPurchaseTo != nil {
-			conditions = append(conditions, fmt.Sprintf("last_purchase_at <= @purchase_to_%d", argPos))
-			args[fmt.Sprintf("purchase_to_%d", argPos)] = *filter.LastPurchaseTo
-			argPos++
-		}
-
-		if filter.MinTotalSpent != nil {
-			conditions = append(conditions, fmt.Sprintf("total_spent >= @min_spent_%d", argPos))
-			args[fmt.Sprintf("min_spent_%d", argPos)] = *filter.MinTotalSpent
-			argPos++
-		}
-
-		if filter.MaxTotalSpent != nil {
-			conditions = append(conditions, fmt.Sprintf("total_spent <= @max_spent_%d", argPos))
-			args[fmt.Sprintf("max_spent_%d", argPos)] = *filter.MaxTotalSpent
-			argPos++
-		}
-	}
+	conditions, args := buildCustomerConditions(filter)
 
 	// Unir condiciones
 	if len(conditions) > 0 {
@@ -230,6 +123,7 @@ func (r *CustomerRepository) Find(ctx context.Context, filter *repository.Custom
 				"total_orders":     true,
 				"last_purchase_at": true,
 				"full_name":        true,
+				"lifetime_value":   true,
 			}
 			if allowedSortColumns[filter.SortBy] {
 				sortBy = filter.SortBy
@@ -335,6 +229,143 @@ func (r *CustomerRepository) Find(ctx context.Context, filter *repository.Custom
 	return customers, total, nil
 }
 
+// buildCustomerConditions traduce un CustomerFilter en condiciones WHERE y sus
+// argumentos nombrados. Lo comparten Find y BulkUpdateSegment.
+func buildCustomerConditions(filter *repository.CustomerFilter) ([]string, pgx.NamedArgs) {
+	var conditions []string
+	args := pgx.NamedArgs{}
+	argPos := 1
+
+	if filter == nil {
+		return conditions, args
+	}
+
+	// Filtros por ID
+	if len(filter.IDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("id = ANY(@id_%d)", argPos))
+		args[fmt.Sprintf("id_%d", argPos)] = filter.IDs
+		argPos++
+	}
+
+	if len(filter.PublicIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("public_uuid = ANY(@public_%d)", argPos))
+		args[fmt.Sprintf("public_%d", argPos)] = filter.PublicIDs
+		argPos++
+	}
+
+	if filter.UserID != nil {
+		conditions = append(conditions, fmt.Sprintf("user_id = @user_%d", argPos))
+		args[fmt.Sprintf("user_%d", argPos)] = *filter.UserID
+		argPos++
+	}
+
+	if filter.Email != nil {
+		conditions = append(conditions, fmt.Sprintf("email = @email_%d", argPos))
+		args[fmt.Sprintf("email_%d", argPos)] = *filter.Email
+		argPos++
+	}
+
+	// Filtros de texto
+	if filter.SearchTerm != nil && *filter.SearchTerm != "" {
+		searchTerm := "%" + *filter.SearchTerm + "%"
+		conditions = append(conditions, fmt.Sprintf(
+			"(full_name ILIKE @search_%d OR email ILIKE @search_%d OR company_name ILIKE @search_%d OR tax_id ILIKE @search_%d)",
+			argPos, argPos, argPos, argPos,
+		))
+		args[fmt.Sprintf("search_%d", argPos)] = searchTerm
+		argPos++
+	}
+
+	if filter.FullName != nil {
+		conditions = append(conditions, fmt.Sprintf("full_name ILIKE @fullname_%d", argPos))
+		args[fmt.Sprintf("fullname_%d", argPos)] = "%" + *filter.FullName + "%"
+		argPos++
+	}
+
+	if filter.CompanyName != nil {
+		conditions = append(conditions, fmt.Sprintf("company_name ILIKE @company_%d", argPos))
+		args[fmt.Sprintf("company_%d", argPos)] = "%" + *filter.CompanyName + "%"
+		argPos++
+	}
+
+	if filter.Country != nil {
+		conditions = append(conditions, fmt.Sprintf("country = @country_%d", argPos))
+		args[fmt.Sprintf("country_%d", argPos)] = *filter.Country
+		argPos++
+	}
+
+	if filter.City != nil {
+		conditions = append(conditions, fmt.Sprintf("city ILIKE @city_%d", argPos))
+		args[fmt.Sprintf("city_%d", argPos)] = "%" + *filter.City + "%"
+		argPos++
+	}
+
+	if filter.IsActive != nil {
+		conditions = append(conditions, fmt.Sprintf("is_active = @active_%d", argPos))
+		args[fmt.Sprintf("active_%d", argPos)] = *filter.IsActive
+		argPos++
+	}
+
+	if filter.IsVIP != nil {
+		conditions = append(conditions, fmt.Sprintf("is_vip = @vip_%d", argPos))
+		args[fmt.Sprintf("vip_%d", argPos)] = *filter.IsVIP
+		argPos++
+	}
+
+	if filter.RequiresInvoice != nil {
+		conditions = append(conditions, fmt.Sprintf("requires_invoice = @invoice_%d", argPos))
+		args[fmt.Sprintf("invoice_%d", argPos)] = *filter.RequiresInvoice
+		argPos++
+	}
+
+	// Los segmentos se guardan en minúsculas: se normaliza la entrada para
+	// no envolver la columna en lower() y poder usar su índice
+	if filter.CustomerSegment != nil {
+		conditions = append(conditions, fmt.Sprintf("customer_segment = @segment_%d", argPos))
+		args[fmt.Sprintf("segment_%d", argPos)] = strings.ToLower(strings.TrimSpace(*filter.CustomerSegment))
+		argPos++
+	}
+
+	// Filtros de fechas
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= @created_from_%d", argPos))
+		args[fmt.Sprintf("created_from_%d", argPos)] = *filter.CreatedFrom
+		argPos++
+	}
+
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at <= @created_to_%d", argPos))
+		args[fmt.Sprintf("created_to_%d", argPos)] = *filter.CreatedTo
+		argPos++
+	}
+
+	if filter.LastPurchaseFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("last_purchase_at >= @purchase_from_%d", argPos))
+		args[fmt.Sprintf("purchase_from_%d", argPos)] = *filter.LastPurchaseFrom
+		argPos++
+	}
+
+	if filter.LastPurchaseTo != nil {
+		conditions = append(conditions, fmt.Sprintf("last_purchase_at <= @purchase_to_%d", argPos))
+		args[fmt.Sprintf("purchase_to_%d", argPos)] = *filter.LastPurchaseTo
+		argPos++
+	}
+
+	if filter.MinTotalSpent != nil {
+		conditions = append(conditions, fmt.Sprintf("total_spent >= @min_spent_%d", argPos))
+		args[fmt.Sprintf("min_spent_%d", argPos)] = *filter.MinTotalSpent
+		argPos++
+	}
+
+	if filter.MaxTotalSpent != nil {
+		conditions = append(conditions, fmt.Sprintf("total_spent <= @max_spent_%d", argPos))
+		args[fmt.Sprintf("max_spent_%d", argPos)] = *filter.MaxTotalSpent
+		argPos++
+	}
+
+	return conditions, args
+}
+
 // GetByID obtiene un cliente por su ID numérico
 func (r *CustomerRepository) GetByID(ctx context.Context, id int64) (*entities.Customer, error) {
 	filter := &repository.CustomerFilter{
@@ -423,7 +454,7 @@ func (r *CustomerRepository) Create(ctx context.Context, customer *entities.Cust
 			total_spent, total_orders, total_tickets, avg_order_value,
 			first_order_at, last_order_at, last_purchase_at,
 			is_active, is_vip, vip_since,
-			customer_segment, lifetime_value,
+			customer_segment, lifetime_value, source,
 			created_at, updated_at
 		) VALUES (
 			gen_random_uuid(), $1, $2, $3, $4,
@@ -433,7 +464,7 @@ func (r *CustomerRepository) Create(ctx context.Context, customer *entities.Cust
 			$17, $18, $19, $20,
 			$21, $22, $23,
 			$24, $25, $26,
-			$27, $28,
+			$27, $28, $29,
 			NOW(), NOW()
 		)
 		RETURNING id, public_uuid, created_at, updated_at
@@ -454,7 +485,7 @@ func (r *CustomerRepository) Create(ctx context.Context, customer *entities.Cust
 		customer.TotalSpent, customer.TotalOrders, customer.TotalTickets, customer.AvgOrderValue,
 		customer.FirstOrderAt, customer.LastOrderAt, customer.LastPurchaseAt,
 		customer.IsActive, customer.IsVIP, customer.VIPSince,
-		customer.CustomerSegment, customer.LifetimeValue,
+		customer.CustomerSegment, customer.LifetimeValue, customer.Source,
 	).Scan(&customer.ID, &customer.PublicID, &customer.CreatedAt, &customer.UpdatedAt)
 
 	if err != nil {
@@ -544,7 +575,91 @@ func (r *CustomerRepository) SoftDelete(ctx context.Context, publicID string) er
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return repository.ErrCustomerNotFound
+		return r.notFoundOrInactive(ctx, publicID)
+	}
+
+	return nil
+}
+
+// GetActiveByPublicID obtiene un cliente activo. Devuelve ErrCustomerNotFound si
+// no existe y ErrInactive si existe pero está desactivado.
+func (r *CustomerRepository) GetActiveByPublicID(ctx context.Context, publicID string) (*entities.Customer, error) {
+	customer, err := r.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if !customer.IsActive {
+		return nil, fmt.Errorf("%w: customer %s", repository.ErrInactive, publicID)
+	}
+	return customer, nil
+}
+
+// notFoundOrInactive elige el error cuando una operación sobre clientes activos no afectó filas
+func (r *CustomerRepository) notFoundOrInactive(ctx context.Context, publicID string) error {
+	found, active, err := repohelpers.ActiveState(ctx, r.db, "crm.customers", "public_uuid", publicID)
+	if err != nil {
+		return r.handleError(err, "failed to check customer state")
+	}
+	if found && !active {
+		return fmt.Errorf("%w: customer %s", repository.ErrInactive, publicID)
+	}
+	return repository.ErrCustomerNotFound
+}
+
+// Anonymize reemplaza los datos personales de un cliente por marcadores irreversibles
+// (derecho de supresión). Conserva id, estadísticas y pedidos, desactiva al cliente
+// y deja constancia en audit.data_changes. Es idempotente.
+func (r *CustomerRepository) Anonymize(ctx context.Context, publicID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// El email se deriva del public_uuid para que sea único y estable entre ejecuciones
+	query := `
+		UPDATE crm.customers
+		SET full_name = 'Anonymized Customer',
+			email = 'anonymized+' || public_uuid || '@anonymized.invalid',
+			phone = NULL,
+			company_name = NULL,
+			address_line1 = NULL,
+			address_line2 = NULL,
+			city = NULL,
+			state = NULL,
+			postal_code = NULL,
+			tax_id = NULL,
+			tax_id_type = NULL,
+			tax_name = NULL,
+			requires_invoice = false,
+			communication_preferences = '{}'::jsonb,
+			is_active = false,
+			updated_at = NOW()
+		WHERE public_uuid = $1
+		RETURNING id
+	`
+
+	var customerID int64
+	if err := tx.QueryRow(ctx, query, publicID).Scan(&customerID); err != nil {
+		return r.handleError(err, "failed to anonymize customer")
+	}
+
+	auditQuery := `
+		INSERT INTO audit.data_changes (table_name, record_id, operation, changed_fields, changed_at)
+		VALUES ('crm.customers', $1, 'UPDATE', $2, NOW())
+	`
+	changedFields := []string{
+		"full_name", "email", "phone", "company_name",
+		"address_line1", "address_line2", "city", "state", "postal_code",
+		"tax_id", "tax_id_type", "tax_name", "requires_invoice",
+		"communication_preferences", "is_active",
+	}
+	if _, err := tx.Exec(ctx, auditQuery, customerID, changedFields); err != nil {
+		return fmt.Errorf("failed to record anonymization audit: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
@@ -552,9 +667,7 @@ func (r *CustomerRepository) SoftDelete(ctx context.Context, publicID string) er
 
 // Exists verifica si existe un cliente con el ID dado
 func (r *CustomerRepository) Exists(ctx context.Context, id int64) (bool, error) {
-	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM crm.customers WHERE id = $1)`
-	err := r.db.QueryRow(ctx, query, id).Scan(&exists)
+	exists, err := repohelpers.Exists(ctx, r.db, "crm.customers", "id", id)
 	if err != nil {
 		return false, r.handleError(err, "failed to check customer existence")
 	}
@@ -563,9 +676,7 @@ func (r *CustomerRepository) Exists(ctx context.Context, id int64) (bool, error)
 
 // ExistsByEmail verifica si existe un cliente con el email dado
 func (r *CustomerRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM crm.customers WHERE email = $1)`
-	err := r.db.QueryRow(ctx, query, email).Scan(&exists)
+	exists, err := repohelpers.Exists(ctx, r.db, "crm.customers", "email", email)
 	if err != nil {
 		return false, r.handleError(err, "failed to check email existence")
 	}
@@ -625,6 +736,54 @@ func (r *CustomerRepository) SetVIP(ctx context.Context, customerID int64, isVIP
 	return nil
 }
 
+// BulkSetVIP marca como VIP a los clientes activos cuyo lifetime_value supera el umbral.
+// Los que ya son VIP no se tocan, así conservan su vip_since. Devuelve cuántos se promovieron.
+func (r *CustomerRepository) BulkSetVIP(ctx context.Context, minLifetimeValue float64) (int64, error) {
+	query := `
+		UPDATE crm.customers
+		SET is_vip = true,
+			vip_since = COALESCE(vip_since, NOW()),
+			updated_at = NOW()
+		WHERE is_active = true
+		  AND is_vip = false
+		  AND lifetime_value > $1
+	`
+	cmdTag, err := r.db.Exec(ctx, query, minLifetimeValue)
+	if err != nil {
+		return 0, r.handleError(err, "failed to bulk set VIP status")
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// BulkUpdateSegment asigna manualmente segment a todos los clientes que cumplen
+// filter y devuelve cuántos se actualizaron. Un filtro vacío se rechaza salvo que
+// filter.ConfirmAll sea true, para no reasignar a toda la base por accidente.
+// Limit, Offset y el ordenamiento del filtro se ignoran.
+func (r *CustomerRepository) BulkUpdateSegment(ctx context.Context, filter *repository.CustomerFilter, segment string) (int64, error) {
+	if !entities.IsValidCustomerSegment(segment) {
+		return 0, fmt.Errorf("%w: %q", repository.ErrInvalidCustomerSegment, segment)
+	}
+
+	conditions, args := buildCustomerConditions(filter)
+	if len(conditions) == 0 && (filter == nil || !filter.ConfirmAll) {
+		return 0, repository.ErrEmptyCustomerFilter
+	}
+
+	query := `UPDATE crm.customers SET customer_segment = @new_segment, updated_at = NOW() WHERE 1=1`
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+	args["new_segment"] = segment
+
+	cmdTag, err := r.db.Exec(ctx, query, args)
+	if err != nil {
+		return 0, r.handleError(err, "failed to bulk update customer segment")
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
 // UpdatePreferences actualiza las preferencias de comunicación del cliente
 func (r *CustomerRepository) UpdatePreferences(ctx context.Context, customerID int64, preferences map[string]interface{}) error {
 	prefsJSON, err := json.Marshal(preferences)
@@ -672,6 +831,148 @@ func (r *CustomerRepository) UpdateInvoiceSettings(ctx context.Context, customer
 	return nil
 }
 
+// ============================================================================
+// VERIFICACIÓN DE TELÉFONO
+// ============================================================================
+
+const (
+	phoneVerificationTTL         = 10 * time.Minute
+	phoneVerificationMaxAttempts = 5
+)
+
+// CreatePhoneVerification genera un código de 6 dígitos para el teléfono del cliente.
+// Sólo se guarda el hash; cualquier verificación pendiente anterior se descarta.
+// Devuelve el código en claro para enviarlo por SMS.
+func (r *CustomerRepository) CreatePhoneVerification(ctx context.Context, customerID int64) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var hasPhone bool
+	err = tx.QueryRow(ctx, `SELECT phone IS NOT NULL FROM crm.customers WHERE id = $1`, customerID).Scan(&hasPhone)
+	if err != nil {
+		return "", r.handleError(err, "failed to get customer phone")
+	}
+	if !hasPhone {
+		return "", fmt.Errorf("customer %d has no phone to verify", customerID)
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM crm.customer_phone_verifications
+		WHERE customer_id = $1 AND verified_at IS NULL
+	`, customerID)
+	if err != nil {
+		return "", r.handleError(err, "failed to discard previous phone verifications")
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO crm.customer_phone_verifications (customer_id, code_hash, expires_at, attempts, created_at)
+		VALUES ($1, $2, $3, 0, NOW())
+	`, customerID, hashVerificationCode(code), time.Now().Add(phoneVerificationTTL))
+	if err != nil {
+		return "", r.handleError(err, "failed to create phone verification")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return code, nil
+}
+
+// VerifyPhone valida el código y marca phone_verified en el cliente.
+// Cada intento fallido cuenta; al llegar al máximo la verificación queda bloqueada.
+func (r *CustomerRepository) VerifyPhone(ctx context.Context, customerID int64, code string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var verificationID int64
+	var codeHash string
+	var expiresAt time.Time
+	var attempts int
+
+	err = tx.QueryRow(ctx, `
+		SELECT id, code_hash, expires_at, attempts
+		FROM crm.customer_phone_verifications
+		WHERE customer_id = $1 AND verified_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+		FOR UPDATE
+	`, customerID).Scan(&verificationID, &codeHash, &expiresAt, &attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrPhoneVerificationNotFound
+		}
+		return r.handleError(err, "failed to get phone verification")
+	}
+
+	if attempts >= phoneVerificationMaxAttempts {
+		return repository.ErrPhoneVerificationLocked
+	}
+	if time.Now().After(expiresAt) {
+		return repository.ErrPhoneVerificationExpired
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashVerificationCode(code)), []byte(codeHash)) != 1 {
+		_, err = tx.Exec(ctx, `
+			UPDATE crm.customer_phone_verifications
+			SET attempts = attempts + 1
+			WHERE id = $1
+		`, verificationID)
+		if err != nil {
+			return r.handleError(err, "failed to record phone verification attempt")
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		if attempts+1 >= phoneVerificationMaxAttempts {
+			return repository.ErrPhoneVerificationLocked
+		}
+		return repository.ErrPhoneVerificationInvalid
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE crm.customer_phone_verifications
+		SET verified_at = NOW()
+		WHERE id = $1
+	`, verificationID)
+	if err != nil {
+		return r.handleError(err, "failed to complete phone verification")
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE crm.customers
+		SET phone_verified = true, updated_at = NOW()
+		WHERE id = $1
+	`, customerID)
+	if err != nil {
+		return r.handleError(err, "failed to mark phone as verified")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// hashVerificationCode devuelve el SHA-256 en hex de un código de verificación
+func hashVerificationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
 // GetStats obtiene estadísticas agregadas de clientes
 func (r *CustomerRepository) GetStats(ctx context.Context) (*repository.CustomerStats, error) {
 	query := `
@@ -717,7 +1018,7 @@ func (r *CustomerRepository) GetStats(ctx context.Context) (*repository.Customer
 	}
 	defer rows.Close()
 
-	var topCountries []repository.CountryStat
+	topCountries := []repository.CountryStat{}
 	for rows.Next() {
 		var cs repository.CountryStat
 		err = rows.Scan(&cs.Country, &cs.Count, &cs.Revenue)
@@ -748,7 +1049,450 @@ func (r *CustomerRepository) GetVIPCustomers(ctx context.Context) ([]*entities.C
 	return customers, nil
 }
 
+// maxAtRiskVIPs acota la lista de GetAtRiskVIPs; Find limita a 20 por defecto
+const maxAtRiskVIPs = 1000
+
+// GetAtRiskVIPs devuelve los VIP activos cuya última compra es anterior a
+// dormantSince, ordenados por lifetime_value descendente
+func (r *CustomerRepository) GetAtRiskVIPs(ctx context.Context, dormantSince time.Time) ([]*entities.Customer, error) {
+	filter := &repository.CustomerFilter{
+		IsVIP:          boolPtr(true),
+		IsActive:       boolPtr(true),
+		LastPurchaseTo: &dormantSince,
+		SortBy:         "lifetime_value",
+		SortOrder:      "DESC",
+		Limit:          maxAtRiskVIPs,
+	}
+
+	customers, _, err := r.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return customers, nil
+}
+
+// GetCohortRetention agrupa clientes por mes de alta y calcula, para cada mes
+// posterior, qué proporción hizo al menos una orden completada. Cubre los últimos
+// `months` cohortes; los meses que aún no han ocurrido no se incluyen.
+func (r *CustomerRepository) GetCohortRetention(ctx context.Context, months int) ([]repository.CohortRow, error) {
+	if months <= 0 {
+		months = 12
+	}
+
+	query := `
+		WITH cohorts AS (
+			SELECT id, date_trunc('month', created_at) AS cohort
+			FROM crm.customers
+			WHERE created_at >= date_trunc('month', NOW()) - make_interval(months => $1 - 1)
+		),
+		sizes AS (
+			SELECT cohort, COUNT(*) AS cohort_size
+			FROM cohorts
+			GROUP BY cohort
+		),
+		activity AS (
+			SELECT DISTINCT c.cohort, c.id,
+				((EXTRACT(YEAR FROM o.created_at) - EXTRACT(YEAR FROM c.cohort)) * 12
+				 + EXTRACT(MONTH FROM o.created_at) - EXTRACT(MONTH FROM c.cohort))::int AS month_offset
+			FROM cohorts c
+			JOIN billing.orders o ON o.customer_id = c.id
+			WHERE o.status = 'completed'
+		)
+		SELECT s.cohort, s.cohort_size, m.month_offset, COUNT(a.id) AS active_customers
+		FROM sizes s
+		CROSS JOIN generate_series(0, $1 - 1) AS m(month_offset)
+		LEFT JOIN activity a ON a.cohort = s.cohort AND a.month_offset = m.month_offset
+		WHERE s.cohort + make_interval(months => m.month_offset) <= date_trunc('month', NOW())
+		GROUP BY s.cohort, s.cohort_size, m.month_offset
+		ORDER BY s.cohort, m.month_offset
+	`
+
+	rows, err := r.db.Query(ctx, query, months)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get cohort retention")
+	}
+	defer rows.Close()
+
+	cohorts := []repository.CohortRow{}
+	for rows.Next() {
+		var row repository.CohortRow
+		if err := rows.Scan(&row.Cohort, &row.CohortSize, &row.MonthOffset, &row.ActiveCustomers); err != nil {
+			return nil, r.handleError(err, "failed to scan cohort row")
+		}
+		if row.CohortSize > 0 {
+			row.RetentionRate = float64(row.ActiveCustomers) / float64(row.CohortSize) * 100
+		}
+		cohorts = append(cohorts, row)
+	}
+
+	return cohorts, rows.Err()
+}
+
+// marketingExportBatchSize es cuántas filas se leen por FETCH del cursor
+const marketingExportBatchSize = 1000
+
+// ExportMarketingContacts escribe en w un CSV (nombre, email, segmento) con los
+// clientes activos que aceptaron marketing. Excluye anonimizados y a quien no dio
+// consentimiento explícito. Lee con un cursor para no cargar toda la tabla en memoria.
+func (r *CustomerRepository) ExportMarketingContacts(ctx context.Context, w io.Writer) error {
+	tx, err := beginReadOnly(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	declare := `
+		DECLARE marketing_contacts NO SCROLL CURSOR FOR
+		SELECT full_name, email, customer_segment
+		FROM crm.customers
+		WHERE is_active = true
+		  AND communication_preferences ->> $1 = 'true'
+		  AND email NOT LIKE 'anonymized+%@anonymized.invalid'
+		ORDER BY id
+	`
+	if _, err := tx.Exec(ctx, declare, entities.CommunicationPreferenceMarketing); err != nil {
+		return r.handleError(err, "failed to declare marketing export cursor")
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"full_name", "email", "customer_segment"}); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH %d FROM marketing_contacts", marketingExportBatchSize)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return r.handleError(err, "failed to fetch marketing contacts")
+		}
+
+		fetched := 0
+		for rows.Next() {
+			var fullName, email, segment string
+			if err := rows.Scan(&fullName, &email, &segment); err != nil {
+				rows.Close()
+				return r.handleError(err, "failed to scan marketing contact")
+			}
+			if err := writer.Write([]string{fullName, email, segment}); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to write csv row: %w", err)
+			}
+			fetched++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return r.handleError(err, "failed to iterate marketing contacts")
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to flush csv: %w", err)
+		}
+
+		if fetched < marketingExportBatchSize {
+			break
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
 // boolPtr es una función auxiliar para crear un puntero a bool
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+// FindDuplicates agrupa clientes activos con el mismo email (sin mayúsculas ni
+// espacios) o el mismo teléfono (sólo dígitos), para revisión y merge manual.
+// Sólo devuelve grupos de 2 o más, paginados por grupo.
+func (r *CustomerRepository) FindDuplicates(ctx context.Context, pagination commondto.Pagination) ([]repository.DuplicateGroup, error) {
+	pagination, err := pagination.Normalize(r.maxPage)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		WITH keys AS (
+			SELECT 'email' AS match_type, lower(trim(email)) AS match_value, public_uuid, id
+			FROM crm.customers
+			WHERE is_active = true AND email IS NOT NULL AND trim(email) <> ''
+			UNION ALL
+			SELECT 'phone', regexp_replace(phone, '\D', '', 'g'), public_uuid, id
+			FROM crm.customers
+			WHERE is_active = true AND phone IS NOT NULL
+			  AND regexp_replace(phone, '\D', '', 'g') <> ''
+		)
+		SELECT match_type, match_value, array_agg(public_uuid::text ORDER BY id)
+		FROM keys
+		GROUP BY match_type, match_value
+		HAVING COUNT(*) > 1
+		ORDER BY match_type, match_value
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Query(ctx, query, pagination.Limit(), pagination.Offset())
+	if err != nil {
+		return nil, r.handleError(err, "failed to find duplicate customers")
+	}
+	defer rows.Close()
+
+	groups := []repository.DuplicateGroup{}
+	for rows.Next() {
+		var g repository.DuplicateGroup
+		if err := rows.Scan(&g.MatchType, &g.MatchValue, &g.CustomerIDs); err != nil {
+			return nil, r.handleError(err, "failed to scan duplicate group")
+		}
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
+
+// GetNewVsReturningStats suma órdenes completadas e ingresos en [from, to) por
+// tipo de cliente: nuevo si su primera orden completada está dentro del rango,
+// recurrente si ya tenía una anterior
+func (r *CustomerRepository) GetNewVsReturningStats(ctx context.Context, from, to time.Time) (*repository.NewReturningStats, error) {
+	query := `
+		WITH first_orders AS (
+			SELECT customer_id, MIN(created_at) AS first_order_at
+			FROM billing.orders
+			WHERE status = 'completed' AND customer_id IS NOT NULL
+			GROUP BY customer_id
+		),
+		period_orders AS (
+			SELECT o.customer_id, o.total_amount, f.first_order_at >= $1 AS is_new
+			FROM billing.orders o
+			JOIN first_orders f ON f.customer_id = o.customer_id
+			WHERE o.status = 'completed'
+			  AND o.created_at >= $1
+			  AND o.created_at < $2
+		)
+		SELECT
+			COUNT(DISTINCT customer_id) FILTER (WHERE is_new),
+			COUNT(*) FILTER (WHERE is_new),
+			COALESCE(SUM(total_amount) FILTER (WHERE is_new), 0),
+			COUNT(DISTINCT customer_id) FILTER (WHERE NOT is_new),
+			COUNT(*) FILTER (WHERE NOT is_new),
+			COALESCE(SUM(total_amount) FILTER (WHERE NOT is_new), 0)
+		FROM period_orders
+	`
+
+	var stats repository.NewReturningStats
+	err := r.db.QueryRow(ctx, query, from, to).Scan(
+		&stats.NewCustomers, &stats.NewOrders, &stats.NewRevenue,
+		&stats.ReturningCustomers, &stats.ReturningOrders, &stats.ReturningRevenue,
+	)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get new vs returning stats")
+	}
+
+	return &stats, nil
+}
+
+// GetAcquisitionSources agrupa por canal de adquisición los clientes creados en
+// [from, to) y los ingresos de sus órdenes completadas. Los clientes sin source
+// se reportan como "unknown".
+func (r *CustomerRepository) GetAcquisitionSources(ctx context.Context, from, to time.Time) ([]repository.SourceStat, error) {
+	query := `
+		SELECT
+			COALESCE(NULLIF(c.source, ''), 'unknown') AS source,
+			COUNT(*) AS new_customers,
+			COALESCE(SUM(o.revenue), 0) AS revenue
+		FROM crm.customers c
+		LEFT JOIN (
+			SELECT customer_id, SUM(total_amount) AS revenue
+			FROM billing.orders
+			WHERE status = 'completed' AND customer_id IS NOT NULL
+			GROUP BY customer_id
+		) o ON o.customer_id = c.id
+		WHERE c.created_at >= $1
+		  AND c.created_at < $2
+		GROUP BY 1
+		ORDER BY new_customers DESC, source
+	`
+
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get acquisition sources")
+	}
+	defer rows.Close()
+
+	stats := []repository.SourceStat{}
+	for rows.Next() {
+		var s repository.SourceStat
+		if err := rows.Scan(&s.Source, &s.NewCustomers, &s.Revenue); err != nil {
+			return nil, r.handleError(err, "failed to scan acquisition source")
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// LinkToUser asocia un cliente invitado a un usuario registrado. Si el usuario ya
+// tiene un cliente, el invitado se fusiona en él: se suman estadísticas, se
+// combinan preferencias (prevalecen las del cliente existente), se reasignan
+// tickets y órdenes y el invitado queda desactivado.
+func (r *CustomerRepository) LinkToUser(ctx context.Context, customerID, userID int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Bloquear invitado y cliente existente del usuario en orden de id
+	rows, err := tx.Query(ctx, `
+		SELECT id, user_id
+		FROM crm.customers
+		WHERE id = $1 OR user_id = $2
+		ORDER BY id
+		FOR UPDATE
+	`, customerID, userID)
+	if err != nil {
+		return r.handleError(err, "failed to lock customers")
+	}
+
+	var guestFound bool
+	var guestUserID *int64
+	var existingID int64
+	for rows.Next() {
+		var id int64
+		var linkedUserID *int64
+		if err := rows.Scan(&id, &linkedUserID); err != nil {
+			rows.Close()
+			return r.handleError(err, "failed to scan customer")
+		}
+		if id == customerID {
+			guestFound = true
+			guestUserID = linkedUserID
+		} else {
+			existingID = id
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return r.handleError(err, "failed to read customers")
+	}
+
+	if !guestFound {
+		return repository.ErrCustomerNotFound
+	}
+	if guestUserID != nil {
+		if *guestUserID == userID {
+			return nil
+		}
+		return repository.ErrCustomerAlreadyLinked
+	}
+
+	if existingID == 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE crm.customers
+			SET user_id = $1, updated_at = NOW()
+			WHERE id = $2
+		`, userID, customerID)
+		if err != nil {
+			return r.handleError(err, "failed to link customer to user")
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE crm.customers e
+		SET communication_preferences = COALESCE(g.communication_preferences, '{}'::jsonb)
+				|| COALESCE(e.communication_preferences, '{}'::jsonb),
+			phone = COALESCE(e.phone, g.phone),
+			total_spent = e.total_spent + g.total_spent,
+			total_orders = e.total_orders + g.total_orders,
+			total_tickets = e.total_tickets + g.total_tickets,
+			avg_order_value = CASE WHEN e.total_orders + g.total_orders > 0
+				THEN (e.total_spent + g.total_spent) / (e.total_orders + g.total_orders)
+				ELSE 0 END,
+			lifetime_value = e.lifetime_value + g.lifetime_value,
+			first_order_at = LEAST(e.first_order_at, g.first_order_at),
+			last_order_at = GREATEST(e.last_order_at, g.last_order_at),
+			last_purchase_at = GREATEST(e.last_purchase_at, g.last_purchase_at),
+			is_vip = e.is_vip OR g.is_vip,
+			vip_since = LEAST(e.vip_since, g.vip_since),
+			updated_at = NOW()
+		FROM crm.customers g
+		WHERE e.id = $1 AND g.id = $2
+	`, existingID, customerID)
+	if err != nil {
+		return r.handleError(err, "failed to merge customer into existing customer")
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET customer_id = $1, updated_at = NOW() WHERE customer_id = $2`, existingID, customerID); err != nil {
+		return r.handleError(err, "failed to reassign tickets")
+	}
+	if _, err := tx.Exec(ctx, `UPDATE billing.orders SET customer_id = $1, updated_at = NOW() WHERE customer_id = $2`, existingID, customerID); err != nil {
+		return r.handleError(err, "failed to reassign orders")
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE crm.customers
+		SET is_active = false,
+			total_spent = 0, total_orders = 0, total_tickets = 0,
+			avg_order_value = 0, lifetime_value = 0,
+			updated_at = NOW()
+		WHERE id = $1
+	`, customerID)
+	if err != nil {
+		return r.handleError(err, "failed to deactivate merged customer")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// geoLevelColumns son las columnas de agrupación de cada nivel geográfico
+var geoLevelColumns = map[string]string{
+	repository.GeoLevelCountry: `COALESCE(country, 'Unknown'), '', ''`,
+	repository.GeoLevelState:   `COALESCE(country, 'Unknown'), COALESCE(state, 'Unknown'), ''`,
+	repository.GeoLevelCity:    `COALESCE(country, 'Unknown'), COALESCE(state, 'Unknown'), COALESCE(city, 'Unknown')`,
+}
+
+// GetGeographicDistribution cuenta clientes y suma total_spent por país,
+// estado o ciudad, de mayor a menor cantidad y sin el tope de GetStats
+func (r *CustomerRepository) GetGeographicDistribution(ctx context.Context, level string, pagination commondto.Pagination) ([]repository.GeoStat, error) {
+	columns, ok := geoLevelColumns[level]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", repository.ErrInvalidGeoLevel, level)
+	}
+
+	pagination, err := pagination.Normalize(r.maxPage)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, COUNT(*) AS count, COALESCE(SUM(total_spent), 0) AS revenue
+		FROM crm.customers
+		GROUP BY 1, 2, 3
+		ORDER BY count DESC, 1, 2, 3
+		LIMIT $1 OFFSET $2
+	`, columns)
+
+	rows, err := r.db.Query(ctx, query, pagination.Limit(), pagination.Offset())
+	if err != nil {
+		return nil, r.handleError(err, "failed to get geographic distribution")
+	}
+	defer rows.Close()
+
+	stats := []repository.GeoStat{}
+	for rows.Next() {
+		var gs repository.GeoStat
+		if err := rows.Scan(&gs.Country, &gs.State, &gs.City, &gs.Count, &gs.Revenue); err != nil {
+			return nil, r.handleError(err, "failed to scan geographic stat")
+		}
+		stats = append(stats, gs)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,968 @@
+package postgres_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+func TestCustomerRepositoryCreateAndGetByEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewCustomerRepository(testsupport.Tx(t))
+
+	customer := &entities.Customer{
+		FullName:                 "Ana López",
+		Email:                    "ana@example.com",
+		IsActive:                 true,
+		CustomerSegment:          "new",
+		CommunicationPreferences: map[string]interface{}{},
+	}
+	if err := repo.Create(ctx, customer); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if customer.ID == 0 || customer.PublicID == "" {
+		t.Fatalf("Create did not populate ids: %+v", customer)
+	}
+
+	got, err := repo.GetByEmail(ctx, "ana@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail: %v", err)
+	}
+	if got.ID != customer.ID || got.FullName != "Ana López" {
+		t.Errorf("GetByEmail = %+v, want customer %d", got, customer.ID)
+	}
+}
+
+func TestCustomerRepositoryGetByEmailNotFound(t *testing.T) {
+	repo := postgres.NewCustomerRepository(testsupport.Tx(t))
+
+	_, err := repo.GetByEmail(context.Background(), "nobody@example.com")
+	if !errors.Is(err, repository.ErrCustomerNotFound) {
+		t.Fatalf("GetByEmail error = %v, want ErrCustomerNotFound", err)
+	}
+}
+
+func TestCustomerRepositoryIsolatedPerTest(t *testing.T) {
+	// El cliente creado por otro test se revirtió con su transacción
+	tx := testsupport.Tx(t)
+	testsupport.SeedCustomer(t, tx, "Solo Aquí", "solo@example.com")
+
+	exists, err := postgres.NewCustomerRepository(tx).ExistsByEmail(context.Background(), "ana@example.com")
+	if err != nil {
+		t.Fatalf("ExistsByEmail: %v", err)
+	}
+	if exists {
+		t.Error("customer from another test leaked into this transaction")
+	}
+}
+
+func TestCustomerRepositoryAnonymizeScrubsPII(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	customerID := testsupport.SeedCustomer(t, tx, "Luis Pérez", "luis@example.com")
+	if _, err := tx.Exec(ctx, `
+		UPDATE crm.customers
+		SET phone = '+5215550000000', address_line1 = 'Calle 1', city = 'CDMX', tax_id = 'PEPL800101AAA'
+		WHERE id = $1
+	`, customerID); err != nil {
+		t.Fatalf("failed to set customer PII: %v", err)
+	}
+	if err := repo.UpdateStats(ctx, customerID, 450); err != nil {
+		t.Fatalf("UpdateStats: %v", err)
+	}
+	orderID := testsupport.SeedOrder(t, tx, customerID, "luis@example.com", 450, "completed")
+	publicID := testsupport.PublicID(t, tx, "crm.customers", customerID)
+
+	if err := repo.Anonymize(ctx, publicID); err != nil {
+		t.Fatalf("Anonymize: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, customerID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.FullName == "Luis Pérez" || got.Email == "luis@example.com" {
+		t.Errorf("name/email not scrubbed: %q %q", got.FullName, got.Email)
+	}
+	if got.Phone != nil || got.AddressLine1 != nil || got.City != nil || got.TaxID != nil {
+		t.Errorf("contact/tax fields not cleared: %+v", got)
+	}
+	if got.IsActive {
+		t.Error("anonymized customer should be inactive")
+	}
+	if got.TotalSpent != 450 || got.TotalOrders != 1 {
+		t.Errorf("stats = %.2f / %d orders, want 450 / 1", got.TotalSpent, got.TotalOrders)
+	}
+
+	var linkedCustomer int64
+	var orderTotal float64
+	if err := tx.QueryRow(ctx, `SELECT customer_id, total_amount FROM billing.orders WHERE id = $1`, orderID).Scan(&linkedCustomer, &orderTotal); err != nil {
+		t.Fatalf("failed to read order: %v", err)
+	}
+	if linkedCustomer != customerID || orderTotal != 450 {
+		t.Errorf("order = customer %d / %.2f, want %d / 450", linkedCustomer, orderTotal, customerID)
+	}
+
+	if _, err := repo.GetByEmail(ctx, "luis@example.com"); !errors.Is(err, repository.ErrCustomerNotFound) {
+		t.Errorf("GetByEmail(original) error = %v, want ErrCustomerNotFound", err)
+	}
+
+	var audits int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM audit.data_changes WHERE table_name = 'crm.customers' AND record_id = $1
+	`, customerID).Scan(&audits); err != nil {
+		t.Fatalf("failed to count audit rows: %v", err)
+	}
+	if audits == 0 {
+		t.Error("anonymization was not recorded in the audit trail")
+	}
+}
+
+func TestCustomerRepositoryAnonymizeTwice(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	// Dos clientes anonimizados no deben chocar en el índice único del email
+	first := testsupport.PublicID(t, tx, "crm.customers", testsupport.SeedCustomer(t, tx, "Uno", "uno@example.com"))
+	second := testsupport.PublicID(t, tx, "crm.customers", testsupport.SeedCustomer(t, tx, "Dos", "dos@example.com"))
+
+	for _, publicID := range []string{first, second, first} {
+		if err := repo.Anonymize(ctx, publicID); err != nil {
+			t.Fatalf("Anonymize(%s): %v", publicID, err)
+		}
+	}
+}
+
+func TestCustomerRepositoryAnonymizeNotFound(t *testing.T) {
+	repo := postgres.NewCustomerRepository(testsupport.Tx(t))
+
+	err := repo.Anonymize(context.Background(), "00000000-0000-0000-0000-000000000000")
+	if !errors.Is(err, repository.ErrCustomerNotFound) {
+		t.Fatalf("Anonymize error = %v, want ErrCustomerNotFound", err)
+	}
+}
+
+// seedCustomerWithPhone siembra un cliente con teléfono sin verificar
+func seedCustomerWithPhone(t *testing.T, db testsupport.DB, email string) int64 {
+	t.Helper()
+	customerID := testsupport.SeedCustomer(t, db, "Phone Owner", email)
+	if _, err := db.Exec(context.Background(), `UPDATE crm.customers SET phone = '+5215512345678' WHERE id = $1`, customerID); err != nil {
+		t.Fatalf("failed to set phone: %v", err)
+	}
+	return customerID
+}
+
+// wrongCode devuelve un código de 6 dígitos distinto de code
+func wrongCode(code string) string {
+	last := (code[5]-'0'+1)%10 + '0'
+	return code[:5] + string(last)
+}
+
+func phoneVerified(t *testing.T, db testsupport.DB, customerID int64) bool {
+	t.Helper()
+	var verified bool
+	if err := db.QueryRow(context.Background(), `SELECT phone_verified FROM crm.customers WHERE id = $1`, customerID).Scan(&verified); err != nil {
+		t.Fatalf("failed to read phone_verified: %v", err)
+	}
+	return verified
+}
+
+func TestCustomerRepositoryVerifyPhone(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+	customerID := seedCustomerWithPhone(t, tx, "verify@example.com")
+
+	code, err := repo.CreatePhoneVerification(ctx, customerID)
+	if err != nil {
+		t.Fatalf("CreatePhoneVerification: %v", err)
+	}
+	if len(code) != 6 {
+		t.Fatalf("code = %q, want 6 digits", code)
+	}
+
+	var stored string
+	if err := tx.QueryRow(ctx, `
+		SELECT code_hash FROM crm.customer_phone_verifications WHERE customer_id = $1
+	`, customerID).Scan(&stored); err != nil {
+		t.Fatalf("failed to read verification: %v", err)
+	}
+	if stored == code {
+		t.Error("verification code stored in clear text")
+	}
+
+	if err := repo.VerifyPhone(ctx, customerID, wrongCode(code)); !errors.Is(err, repository.ErrPhoneVerificationInvalid) {
+		t.Fatalf("VerifyPhone(wrong) error = %v, want ErrPhoneVerificationInvalid", err)
+	}
+	if err := repo.VerifyPhone(ctx, customerID, code); err != nil {
+		t.Fatalf("VerifyPhone: %v", err)
+	}
+	if !phoneVerified(t, tx, customerID) {
+		t.Error("phone_verified was not set")
+	}
+
+	if err := repo.VerifyPhone(ctx, customerID, code); !errors.Is(err, repository.ErrPhoneVerificationNotFound) {
+		t.Errorf("VerifyPhone(reused) error = %v, want ErrPhoneVerificationNotFound", err)
+	}
+}
+
+func TestCustomerRepositoryVerifyPhoneExpired(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+	customerID := seedCustomerWithPhone(t, tx, "expired@example.com")
+
+	code, err := repo.CreatePhoneVerification(ctx, customerID)
+	if err != nil {
+		t.Fatalf("CreatePhoneVerification: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE crm.customer_phone_verifications SET expires_at = NOW() - INTERVAL '1 minute' WHERE customer_id = $1
+	`, customerID); err != nil {
+		t.Fatalf("failed to expire verification: %v", err)
+	}
+
+	if err := repo.VerifyPhone(ctx, customerID, code); !errors.Is(err, repository.ErrPhoneVerificationExpired) {
+		t.Fatalf("VerifyPhone error = %v, want ErrPhoneVerificationExpired", err)
+	}
+	if phoneVerified(t, tx, customerID) {
+		t.Error("expired code must not verify the phone")
+	}
+}
+
+func TestCustomerRepositoryVerifyPhoneLockout(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+	customerID := seedCustomerWithPhone(t, tx, "locked@example.com")
+
+	code, err := repo.CreatePhoneVerification(ctx, customerID)
+	if err != nil {
+		t.Fatalf("CreatePhoneVerification: %v", err)
+	}
+
+	var lastErr error
+	for i := 0; i < 5; i++ {
+		lastErr = repo.VerifyPhone(ctx, customerID, wrongCode(code))
+	}
+	if !errors.Is(lastErr, repository.ErrPhoneVerificationLocked) {
+		t.Fatalf("fifth wrong attempt error = %v, want ErrPhoneVerificationLocked", lastErr)
+	}
+
+	// Ni el código correcto sirve una vez bloqueada
+	if err := repo.VerifyPhone(ctx, customerID, code); !errors.Is(err, repository.ErrPhoneVerificationLocked) {
+		t.Errorf("VerifyPhone(correct after lockout) error = %v, want ErrPhoneVerificationLocked", err)
+	}
+	if phoneVerified(t, tx, customerID) {
+		t.Error("locked verification must not verify the phone")
+	}
+
+	// Un código nuevo reinicia los intentos
+	newCode, err := repo.CreatePhoneVerification(ctx, customerID)
+	if err != nil {
+		t.Fatalf("CreatePhoneVerification: %v", err)
+	}
+	if err := repo.VerifyPhone(ctx, customerID, newCode); err != nil {
+		t.Errorf("VerifyPhone(new code): %v", err)
+	}
+}
+
+// seedSpender siembra un cliente con lifetime_value, actividad y estado VIP dados
+func seedSpender(t *testing.T, db testsupport.DB, email string, lifetimeValue float64, active, vip bool, vipSince *time.Time) int64 {
+	t.Helper()
+	customerID := testsupport.SeedCustomer(t, db, "Spender", email)
+	if _, err := db.Exec(context.Background(), `
+		UPDATE crm.customers SET lifetime_value = $1, is_active = $2, is_vip = $3, vip_since = $4 WHERE id = $5
+	`, lifetimeValue, active, vip, vipSince, customerID); err != nil {
+		t.Fatalf("failed to set customer spend: %v", err)
+	}
+	return customerID
+}
+
+func TestCustomerRepositoryBulkSetVIP(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	// Umbral muy alto para no tocar datos ajenos al test
+	const threshold = 90_000_000
+	since := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	qualifying := seedSpender(t, tx, "vip-above@example.com", threshold+1, true, false, nil)
+	atThreshold := seedSpender(t, tx, "vip-equal@example.com", threshold, true, false, nil)
+	inactive := seedSpender(t, tx, "vip-inactive@example.com", threshold+1, false, false, nil)
+	alreadyVIP := seedSpender(t, tx, "vip-already@example.com", threshold+1, true, true, &since)
+
+	promoted, err := repo.BulkSetVIP(ctx, threshold)
+	if err != nil {
+		t.Fatalf("BulkSetVIP: %v", err)
+	}
+	if promoted != 1 {
+		t.Errorf("promoted = %d, want 1", promoted)
+	}
+
+	vipState := func(id int64) (bool, *time.Time) {
+		t.Helper()
+		var vip bool
+		var vipSince *time.Time
+		if err := tx.QueryRow(ctx, `SELECT is_vip, vip_since FROM crm.customers WHERE id = $1`, id).Scan(&vip, &vipSince); err != nil {
+			t.Fatalf("failed to read vip state: %v", err)
+		}
+		return vip, vipSince
+	}
+
+	if vip, vipSince := vipState(qualifying); !vip || vipSince == nil {
+		t.Errorf("qualifying customer vip = %v / %v, want promoted with vip_since", vip, vipSince)
+	}
+	for _, id := range []int64{atThreshold, inactive} {
+		if vip, _ := vipState(id); vip {
+			t.Errorf("customer %d promoted, want untouched", id)
+		}
+	}
+	if _, vipSince := vipState(alreadyVIP); vipSince == nil || !vipSince.Equal(since) {
+		t.Errorf("existing VIP vip_since = %v, want %v", vipSince, since)
+	}
+}
+
+// atMonth fija created_at de una fila al día 3 del mes NOW() - monthsAgo
+func atMonth(t *testing.T, db testsupport.DB, table string, id int64, monthsAgo int) {
+	t.Helper()
+	query := `UPDATE ` + table + ` SET created_at = date_trunc('month', NOW()) - make_interval(months => $1) + INTERVAL '3 days' WHERE id = $2`
+	if _, err := db.Exec(context.Background(), query, monthsAgo, id); err != nil {
+		t.Fatalf("failed to set created_at on %s: %v", table, err)
+	}
+}
+
+func TestCustomerRepositoryGetCohortRetention(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	// El TRUNCATE se revierte con la transacción del test
+	if _, err := tx.Exec(ctx, `TRUNCATE crm.customers CASCADE`); err != nil {
+		t.Fatalf("failed to empty customers: %v", err)
+	}
+
+	// Cohorte de hace dos meses: A compra en el mes 0 y 1, B en el mes 1,
+	// C sólo tiene una orden pendiente y D nunca compra
+	customers := map[string]int64{}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		customers[name] = testsupport.SeedCustomer(t, tx, "Cohort "+name, "cohort-"+name+"@example.com")
+		atMonth(t, tx, "crm.customers", customers[name], 2)
+	}
+	orders := []struct {
+		customer  string
+		monthsAgo int
+		status    string
+	}{
+		{"a", 2, "completed"}, {"a", 1, "completed"}, {"b", 1, "completed"}, {"b", 1, "completed"}, {"c", 1, "pending"},
+	}
+	for _, o := range orders {
+		orderID := testsupport.SeedOrder(t, tx, customers[o.customer], "cohort-"+o.customer+"@example.com", 100, o.status)
+		atMonth(t, tx, "billing.orders", orderID, o.monthsAgo)
+	}
+
+	rows, err := repo.GetCohortRetention(ctx, 3)
+	if err != nil {
+		t.Fatalf("GetCohortRetention: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3 (one cohort, offsets 0-2): %+v", len(rows), rows)
+	}
+
+	want := []struct {
+		active int64
+		rate   float64
+	}{{1, 25}, {2, 50}, {0, 0}}
+	for i, w := range want {
+		row := rows[i]
+		if row.MonthOffset != i || row.CohortSize != 4 {
+			t.Errorf("row %d = offset %d / size %d, want offset %d / size 4", i, row.MonthOffset, row.CohortSize, i)
+		}
+		if row.ActiveCustomers != w.active || row.RetentionRate != w.rate {
+			t.Errorf("offset %d = %d active / %.1f%%, want %d / %.1f%%", i, row.ActiveCustomers, row.RetentionRate, w.active, w.rate)
+		}
+	}
+}
+
+func TestCustomerRepositoryFindSegmentIgnoresCase(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	vipID := testsupport.SeedCustomer(t, tx, "Segmento", "segment-vip@example.com")
+	if _, err := tx.Exec(ctx, `UPDATE crm.customers SET customer_segment = 'vip' WHERE id = $1`, vipID); err != nil {
+		t.Fatalf("failed to set segment: %v", err)
+	}
+
+	segment := " VIP "
+	search := "segment-vip@example.com"
+	customers, _, err := repo.Find(ctx, &repository.CustomerFilter{CustomerSegment: &segment, SearchTerm: &search, Limit: 10})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(customers) != 1 || customers[0].ID != vipID {
+		t.Errorf("Find(segment %q) = %d customers, want the vip customer %d", segment, len(customers), vipID)
+	}
+}
+
+func TestCustomerRepositoryExportMarketingContacts(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	// El TRUNCATE se revierte con la transacción del test
+	if _, err := tx.Exec(ctx, `TRUNCATE crm.customers CASCADE`); err != nil {
+		t.Fatalf("failed to empty customers: %v", err)
+	}
+
+	seed := func(name, email, prefs string, active bool) {
+		t.Helper()
+		customerID := testsupport.SeedCustomer(t, tx, name, email)
+		if _, err := tx.Exec(ctx, `
+			UPDATE crm.customers SET communication_preferences = $1::jsonb, is_active = $2, customer_segment = 'regular' WHERE id = $3
+		`, prefs, active, customerID); err != nil {
+			t.Fatalf("failed to set preferences: %v", err)
+		}
+	}
+	optIn := `{"marketing": true}`
+	seed(`López, Ana "La Jefa"`, "ana@example.com", optIn, true)
+	seed("Opted Out", "out@example.com", `{"marketing": false}`, true)
+	seed("No Answer", "silent@example.com", `{}`, true)
+	seed("Inactive", "inactive-mkt@example.com", optIn, false)
+	seed("Anonymized", "anonymized+abc@anonymized.invalid", optIn, true)
+
+	var buf bytes.Buffer
+	if err := repo.ExportMarketingContacts(ctx, &buf); err != nil {
+		t.Fatalf("ExportMarketingContacts: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"López, Ana ""La Jefa"""`) {
+		t.Errorf("csv = %q, want the name quoted and escaped", buf.String())
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"full_name", "email", "customer_segment"},
+		{`López, Ana "La Jefa"`, "ana@example.com", "regular"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %q, want %q", records, want)
+	}
+}
+
+func TestCustomerRepositoryFindDuplicates(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	// El TRUNCATE se revierte con la transacción del test
+	if _, err := tx.Exec(ctx, `TRUNCATE crm.customers CASCADE`); err != nil {
+		t.Fatalf("failed to empty customers: %v", err)
+	}
+
+	setPhone := func(id int64, phone string) {
+		t.Helper()
+		if _, err := tx.Exec(ctx, `UPDATE crm.customers SET phone = $1 WHERE id = $2`, phone, id); err != nil {
+			t.Fatalf("failed to set phone: %v", err)
+		}
+	}
+
+	exact1 := testsupport.SeedCustomer(t, tx, "Ana", "ana@example.com")
+	exact2 := testsupport.SeedCustomer(t, tx, "Ana Dup", "ana@example.com ")
+	// Mismo email con mayúsculas y espacios
+	normalized := testsupport.SeedCustomer(t, tx, "ANA", "  ANA@Example.com")
+	phone1 := testsupport.SeedCustomer(t, tx, "Luis", "luis@example.com")
+	phone2 := testsupport.SeedCustomer(t, tx, "Luis B", "luis.b@example.com")
+	setPhone(phone1, "+52 (55) 1234-5678")
+	setPhone(phone2, "+525512345678")
+	unique := testsupport.SeedCustomer(t, tx, "Única", "unica@example.com")
+	setPhone(unique, "+525599999999")
+	inactive := testsupport.SeedCustomer(t, tx, "Inactiva", "Luis@example.com")
+	if _, err := tx.Exec(ctx, `UPDATE crm.customers SET is_active = false WHERE id = $1`, inactive); err != nil {
+		t.Fatalf("failed to deactivate customer: %v", err)
+	}
+
+	groups, err := repo.FindDuplicates(ctx, commondto.NewPagination(1, 20))
+	if err != nil {
+		t.Fatalf("FindDuplicates: %v", err)
+	}
+
+	got := map[string][]string{}
+	for _, g := range groups {
+		got[g.MatchType+":"+g.MatchValue] = g.CustomerIDs
+	}
+	want := map[string][]string{
+		"email:ana@example.com": {
+			testsupport.PublicID(t, tx, "crm.customers", exact1),
+			testsupport.PublicID(t, tx, "crm.customers", exact2),
+			testsupport.PublicID(t, tx, "crm.customers", normalized),
+		},
+		"phone:525512345678": {
+			testsupport.PublicID(t, tx, "crm.customers", phone1),
+			testsupport.PublicID(t, tx, "crm.customers", phone2),
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("duplicate groups = %v, want %v", got, want)
+	}
+
+	page, err := repo.FindDuplicates(ctx, commondto.NewPagination(2, 1))
+	if err != nil {
+		t.Fatalf("FindDuplicates page 2: %v", err)
+	}
+	if len(page) != 1 || page[0].MatchType != "phone" {
+		t.Errorf("page 2 of size 1 = %+v, want the phone group", page)
+	}
+}
+
+func TestCustomerRepositoryGetNewVsReturningStats(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	// Ventana en el futuro para no mezclar órdenes de otros tests
+	from := time.Date(2091, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	order := func(customerID int64, total float64, status string, at time.Time) {
+		t.Helper()
+		id := testsupport.SeedOrder(t, tx, customerID, "nvr@example.com", total, status)
+		if _, err := tx.Exec(ctx, `UPDATE billing.orders SET created_at = $1 WHERE id = $2`, at, id); err != nil {
+			t.Fatalf("failed to date order: %v", err)
+		}
+	}
+
+	first := testsupport.SeedCustomer(t, tx, "First Timer", "nvr-first@example.com")
+	order(first, 100, "completed", from.AddDate(0, 0, 2))
+	order(first, 50, "completed", from.AddDate(0, 0, 10))
+
+	repeat := testsupport.SeedCustomer(t, tx, "Repeat", "nvr-repeat@example.com")
+	order(repeat, 999, "completed", from.AddDate(0, -1, 0))
+	order(repeat, 300, "completed", from.AddDate(0, 0, 5))
+
+	// Pendientes y fuera de la ventana no cuentan
+	other := testsupport.SeedCustomer(t, tx, "Other", "nvr-other@example.com")
+	order(other, 70, "pending", from.AddDate(0, 0, 3))
+	order(other, 80, "completed", to)
+
+	stats, err := repo.GetNewVsReturningStats(ctx, from, to)
+	if err != nil {
+		t.Fatalf("GetNewVsReturningStats: %v", err)
+	}
+
+	want := repository.NewReturningStats{
+		NewCustomers: 1, NewOrders: 2, NewRevenue: 150,
+		ReturningCustomers: 1, ReturningOrders: 1, ReturningRevenue: 300,
+	}
+	if *stats != want {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+}
+
+func TestCustomerRepositoryMissingVsInactive(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	id := testsupport.SeedCustomer(t, tx, "Soft Deleted", "soft-deleted@example.com")
+	publicID := testsupport.PublicID(t, tx, "crm.customers", id)
+	if err := repo.SoftDelete(ctx, publicID); err != nil {
+		t.Fatalf("SoftDelete: %v", err)
+	}
+	missing := "00000000-0000-0000-0000-000000000000"
+
+	if _, err := repo.GetActiveByPublicID(ctx, missing); !errors.Is(err, repository.ErrCustomerNotFound) {
+		t.Errorf("GetActiveByPublicID(missing) error = %v, want ErrCustomerNotFound", err)
+	}
+	_, err := repo.GetActiveByPublicID(ctx, publicID)
+	if !errors.Is(err, repository.ErrInactive) || errors.Is(err, repository.ErrCustomerNotFound) {
+		t.Errorf("GetActiveByPublicID(soft-deleted) error = %v, want ErrInactive", err)
+	}
+
+	if err := repo.SoftDelete(ctx, missing); !errors.Is(err, repository.ErrCustomerNotFound) {
+		t.Errorf("SoftDelete(missing) error = %v, want ErrCustomerNotFound", err)
+	}
+	if err := repo.SoftDelete(ctx, publicID); !errors.Is(err, repository.ErrInactive) {
+		t.Errorf("SoftDelete(soft-deleted) error = %v, want ErrInactive", err)
+	}
+
+	// GetByPublicID sigue devolviendo el registro desactivado
+	customer, err := repo.GetByPublicID(ctx, publicID)
+	if err != nil || customer.IsActive {
+		t.Errorf("GetByPublicID(soft-deleted) = %+v, %v; want the inactive customer", customer, err)
+	}
+}
+
+// seedUser inserta un usuario registrado mínimo y devuelve su id
+func seedUser(t *testing.T, db testsupport.DB, email string) int64 {
+	t.Helper()
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO auth.users (public_uuid, email, username, password_hash, is_active, created_at, updated_at)
+		VALUES (gen_random_uuid(), $1, $1, 'x', true, NOW(), NOW())
+		RETURNING id
+	`, email).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	return id
+}
+
+// setCustomerProfile fija preferencias y estadísticas de un cliente sembrado
+func setCustomerProfile(t *testing.T, db testsupport.DB, customerID int64, prefs string, spent float64, orders, tickets int) {
+	t.Helper()
+	_, err := db.Exec(context.Background(), `
+		UPDATE crm.customers
+		SET communication_preferences = $2::jsonb, total_spent = $3, total_orders = $4, total_tickets = $5
+		WHERE id = $1
+	`, customerID, prefs, spent, orders, tickets)
+	if err != nil {
+		t.Fatalf("failed to set customer profile: %v", err)
+	}
+}
+
+func TestCustomerRepositoryLinkToUserWithoutCustomer(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	guestID := testsupport.SeedCustomer(t, tx, "Guest", "link-guest@example.com")
+	userID := seedUser(t, tx, "link-user@example.com")
+
+	if err := repo.LinkToUser(ctx, guestID, userID); err != nil {
+		t.Fatalf("LinkToUser: %v", err)
+	}
+	guest, err := repo.GetByID(ctx, guestID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if guest.UserID == nil || *guest.UserID != userID || !guest.IsActive {
+		t.Errorf("guest user_id = %v, active = %v; want linked to %d and active", guest.UserID, guest.IsActive, userID)
+	}
+
+	// Volver a enlazar al mismo usuario no hace nada; a otro, es un conflicto
+	if err := repo.LinkToUser(ctx, guestID, userID); err != nil {
+		t.Errorf("second LinkToUser: %v", err)
+	}
+	otherUserID := seedUser(t, tx, "link-other@example.com")
+	if err := repo.LinkToUser(ctx, guestID, otherUserID); !errors.Is(err, repository.ErrCustomerAlreadyLinked) {
+		t.Errorf("LinkToUser to another user error = %v, want ErrCustomerAlreadyLinked", err)
+	}
+	if err := repo.LinkToUser(ctx, -1, userID); !errors.Is(err, repository.ErrCustomerNotFound) {
+		t.Errorf("LinkToUser(missing) error = %v, want ErrCustomerNotFound", err)
+	}
+}
+
+func TestCustomerRepositoryLinkToUserMergesIntoExistingCustomer(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	userID := seedUser(t, tx, "merge-user@example.com")
+	existingID := testsupport.SeedCustomer(t, tx, "Registered", "merge-registered@example.com")
+	if _, err := tx.Exec(ctx, `UPDATE crm.customers SET user_id = $1 WHERE id = $2`, userID, existingID); err != nil {
+		t.Fatalf("failed to link existing customer: %v", err)
+	}
+	setCustomerProfile(t, tx, existingID, `{"email": true, "sms": false}`, 300, 2, 3)
+
+	guestID := testsupport.SeedCustomer(t, tx, "Guest", "merge-guest@example.com")
+	setCustomerProfile(t, tx, guestID, `{"sms": true, "push": true}`, 100, 1, 2)
+	eventID := seedEvent(t, tx, "Merge")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 50, 10)
+	ticketID := testsupport.SeedTicket(t, tx, eventID, typeID, &guestID, "sold", 50)
+	orderID := testsupport.SeedOrder(t, tx, guestID, "merge-guest@example.com", 100, "completed")
+
+	if err := repo.LinkToUser(ctx, guestID, userID); err != nil {
+		t.Fatalf("LinkToUser: %v", err)
+	}
+
+	existing, err := repo.GetByID(ctx, existingID)
+	if err != nil {
+		t.Fatalf("GetByID(existing): %v", err)
+	}
+	if existing.TotalSpent != 400 || existing.TotalOrders != 3 || existing.TotalTickets != 5 {
+		t.Errorf("merged stats = %.2f / %d orders / %d tickets, want 400 / 3 / 5",
+			existing.TotalSpent, existing.TotalOrders, existing.TotalTickets)
+	}
+	// Las preferencias del cliente existente tienen prioridad
+	wantPrefs := map[string]interface{}{"email": true, "sms": false, "push": true}
+	if !reflect.DeepEqual(existing.CommunicationPreferences, wantPrefs) {
+		t.Errorf("merged preferences = %v, want %v", existing.CommunicationPreferences, wantPrefs)
+	}
+
+	guest, err := repo.GetByID(ctx, guestID)
+	if err != nil {
+		t.Fatalf("GetByID(guest): %v", err)
+	}
+	if guest.IsActive || guest.TotalSpent != 0 {
+		t.Errorf("guest active = %v, spent = %.2f; want deactivated with stats moved", guest.IsActive, guest.TotalSpent)
+	}
+
+	var ticketOwner, orderOwner int64
+	if err := tx.QueryRow(ctx, `SELECT customer_id FROM ticketing.tickets WHERE id = $1`, ticketID).Scan(&ticketOwner); err != nil {
+		t.Fatalf("failed to read ticket owner: %v", err)
+	}
+	if err := tx.QueryRow(ctx, `SELECT customer_id FROM billing.orders WHERE id = $1`, orderID).Scan(&orderOwner); err != nil {
+		t.Fatalf("failed to read order owner: %v", err)
+	}
+	if ticketOwner != existingID || orderOwner != existingID {
+		t.Errorf("ticket owner = %d, order owner = %d; want both moved to %d", ticketOwner, orderOwner, existingID)
+	}
+}
+
+func TestCustomerRepositoryGetAcquisitionSources(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	from := time.Date(2092, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	create := func(email, source string, createdAt time.Time) int64 {
+		t.Helper()
+		customer := &entities.Customer{
+			FullName:                 email,
+			Email:                    email,
+			IsActive:                 true,
+			CustomerSegment:          "new",
+			CommunicationPreferences: map[string]interface{}{},
+		}
+		if source != "" {
+			customer.Source = &source
+		}
+		if err := repo.Create(ctx, customer); err != nil {
+			t.Fatalf("Create(%s): %v", email, err)
+		}
+		if _, err := tx.Exec(ctx, `UPDATE crm.customers SET created_at = $1 WHERE id = $2`, createdAt, customer.ID); err != nil {
+			t.Fatalf("failed to set created_at: %v", err)
+		}
+		return customer.ID
+	}
+
+	web1 := create("acq-web1@example.com", "web", from.Add(time.Hour))
+	create("acq-web2@example.com", "web", from.AddDate(0, 0, 10))
+	referral := create("acq-referral@example.com", "referral", from.AddDate(0, 0, 5))
+	create("acq-import@example.com", "import", from.AddDate(0, 0, 20))
+	create("acq-none@example.com", "", from.AddDate(0, 0, 1))
+	// Fuera de la ventana [from, to)
+	create("acq-late@example.com", "web", to)
+
+	testsupport.SeedOrder(t, tx, web1, "acq-web1@example.com", 150, "completed")
+	testsupport.SeedOrder(t, tx, web1, "acq-web1@example.com", 999, "pending")
+	testsupport.SeedOrder(t, tx, referral, "acq-referral@example.com", 80, "completed")
+
+	var stored string
+	if err := tx.QueryRow(ctx, `SELECT source FROM crm.customers WHERE id = $1`, referral).Scan(&stored); err != nil || stored != "referral" {
+		t.Fatalf("stored source = %q, %v; want Create to write referral", stored, err)
+	}
+
+	stats, err := repo.GetAcquisitionSources(ctx, from, to)
+	if err != nil {
+		t.Fatalf("GetAcquisitionSources: %v", err)
+	}
+	want := []repository.SourceStat{
+		{Source: "web", NewCustomers: 2, Revenue: 150},
+		{Source: "import", NewCustomers: 1, Revenue: 0},
+		{Source: "referral", NewCustomers: 1, Revenue: 80},
+		{Source: "unknown", NewCustomers: 1, Revenue: 0},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("sources = %+v, want %+v", stats, want)
+	}
+}
+
+func TestCustomerRepositoryGetAtRiskVIPs(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	if _, err := tx.Exec(ctx, `TRUNCATE crm.customers CASCADE`); err != nil {
+		t.Fatalf("failed to truncate customers: %v", err)
+	}
+
+	threshold := time.Now().AddDate(0, -6, 0)
+	seed := func(email string, vip, active bool, lastPurchase time.Time, ltv float64) int64 {
+		t.Helper()
+		id := testsupport.SeedCustomer(t, tx, email, email)
+		_, err := tx.Exec(ctx, `
+			UPDATE crm.customers
+			SET is_vip = $2, is_active = $3, last_purchase_at = $4, lifetime_value = $5
+			WHERE id = $1
+		`, id, vip, active, lastPurchase, ltv)
+		if err != nil {
+			t.Fatalf("failed to set up customer: %v", err)
+		}
+		return id
+	}
+
+	dormantLow := seed("vip-dormant-low@example.com", true, true, threshold.AddDate(0, -1, 0), 500)
+	dormantHigh := seed("vip-dormant-high@example.com", true, true, threshold.AddDate(-1, 0, 0), 900)
+	seed("vip-active@example.com", true, true, time.Now().AddDate(0, 0, -3), 5000)
+	seed("regular-dormant@example.com", false, true, threshold.AddDate(0, -2, 0), 2000)
+	seed("vip-deactivated@example.com", true, false, threshold.AddDate(0, -2, 0), 3000)
+
+	customers, err := repo.GetAtRiskVIPs(ctx, threshold)
+	if err != nil {
+		t.Fatalf("GetAtRiskVIPs: %v", err)
+	}
+	var got []int64
+	for _, c := range customers {
+		got = append(got, c.ID)
+	}
+	if want := []int64{dormantHigh, dormantLow}; !reflect.DeepEqual(got, want) {
+		t.Errorf("at-risk VIPs = %v, want %v (dormant VIPs by lifetime value)", got, want)
+	}
+}
+
+func customerSegment(t *testing.T, db testsupport.DB, customerID int64) string {
+	t.Helper()
+	var segment string
+	if err := db.QueryRow(context.Background(), `SELECT COALESCE(customer_segment, '') FROM crm.customers WHERE id = $1`, customerID).Scan(&segment); err != nil {
+		t.Fatalf("failed to read segment: %v", err)
+	}
+	return segment
+}
+
+func TestCustomerRepositoryBulkUpdateSegment(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	first := testsupport.SeedCustomer(t, tx, "Bulk Uno", "bulk-segment-1@example.com")
+	second := testsupport.SeedCustomer(t, tx, "Bulk Dos", "bulk-segment-2@example.com")
+	outside := testsupport.SeedCustomer(t, tx, "Bulk Fuera", "bulk-segment-3@example.com")
+	before := customerSegment(t, tx, outside)
+
+	updated, err := repo.BulkUpdateSegment(ctx, &repository.CustomerFilter{IDs: []int64{first, second}}, "vip")
+	if err != nil {
+		t.Fatalf("BulkUpdateSegment: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("updated = %d, want 2", updated)
+	}
+	for _, id := range []int64{first, second} {
+		if got := customerSegment(t, tx, id); got != "vip" {
+			t.Errorf("customer %d segment = %q, want vip", id, got)
+		}
+	}
+	if got := customerSegment(t, tx, outside); got != before {
+		t.Errorf("customer outside the filter segment = %q, want %q", got, before)
+	}
+
+	if _, err := repo.BulkUpdateSegment(ctx, &repository.CustomerFilter{IDs: []int64{first}}, "platinum"); !errors.Is(err, repository.ErrInvalidCustomerSegment) {
+		t.Errorf("unknown segment: err = %v, want ErrInvalidCustomerSegment", err)
+	}
+}
+
+func TestCustomerRepositoryBulkUpdateSegmentRequiresConfirmAll(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	if _, err := tx.Exec(ctx, `TRUNCATE crm.customers CASCADE`); err != nil {
+		t.Fatalf("failed to clear customers: %v", err)
+	}
+	first := testsupport.SeedCustomer(t, tx, "Todos Uno", "bulk-all-1@example.com")
+	second := testsupport.SeedCustomer(t, tx, "Todos Dos", "bulk-all-2@example.com")
+
+	for name, filter := range map[string]*repository.CustomerFilter{
+		"nil filter":      nil,
+		"empty filter":    {},
+		"pagination only": {Limit: 10, SortBy: "created_at"},
+		"empty id list":   {IDs: []int64{}},
+	} {
+		updated, err := repo.BulkUpdateSegment(ctx, filter, "regular")
+		if !errors.Is(err, repository.ErrEmptyCustomerFilter) || updated != 0 {
+			t.Errorf("%s: updated = %d, err = %v, want ErrEmptyCustomerFilter", name, updated, err)
+		}
+	}
+	for _, id := range []int64{first, second} {
+		if got := customerSegment(t, tx, id); got == "regular" {
+			t.Errorf("customer %d updated without ConfirmAll", id)
+		}
+	}
+
+	updated, err := repo.BulkUpdateSegment(ctx, &repository.CustomerFilter{ConfirmAll: true}, "regular")
+	if err != nil {
+		t.Fatalf("BulkUpdateSegment(ConfirmAll): %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("ConfirmAll updated = %d, want 2", updated)
+	}
+}
+
+func TestCustomerRepositoryGetGeographicDistribution(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewCustomerRepository(tx)
+
+	if _, err := tx.Exec(ctx, `TRUNCATE crm.customers CASCADE`); err != nil {
+		t.Fatalf("failed to clear customers: %v", err)
+	}
+	seed := func(email string, country, state, city *string, spent float64) {
+		id := testsupport.SeedCustomer(t, tx, email, email)
+		if _, err := tx.Exec(ctx, `UPDATE crm.customers SET country = $2, state = $3, city = $4, total_spent = $5 WHERE id = $1`,
+			id, country, state, city, spent); err != nil {
+			t.Fatalf("failed to set location: %v", err)
+		}
+	}
+	mx, us := "MX", "US"
+	jal, cdmx, tx1 := "Jalisco", "CDMX", "Texas"
+	gdl, zap, cuau, aus := "Guadalajara", "Zapopan", "Cuauhtémoc", "Austin"
+	seed("geo1@example.com", &mx, &jal, &gdl, 100)
+	seed("geo2@example.com", &mx, &jal, &gdl, 50)
+	seed("geo3@example.com", &mx, &jal, &zap, 25)
+	seed("geo4@example.com", &mx, &cdmx, &cuau, 10)
+	seed("geo5@example.com", &us, &tx1, &aus, 300)
+	seed("geo7@example.com", &us, &tx1, &aus, 0)
+	seed("geo6@example.com", nil, nil, nil, 5)
+
+	countries, err := repo.GetGeographicDistribution(ctx, repository.GeoLevelCountry, commondto.Pagination{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("GetGeographicDistribution(country): %v", err)
+	}
+	wantCountries := []repository.GeoStat{
+		{Country: "MX", Count: 4, Revenue: 185},
+		{Country: "US", Count: 2, Revenue: 300},
+		{Country: "Unknown", Count: 1, Revenue: 5},
+	}
+	if !reflect.DeepEqual(countries, wantCountries) {
+		t.Errorf("countries = %+v, want %+v", countries, wantCountries)
+	}
+
+	cities, err := repo.GetGeographicDistribution(ctx, repository.GeoLevelCity, commondto.Pagination{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("GetGeographicDistribution(city): %v", err)
+	}
+	if len(cities) != 5 || cities[0] != (repository.GeoStat{Country: "MX", State: "Jalisco", City: "Guadalajara", Count: 2, Revenue: 150}) {
+		t.Errorf("cities = %+v, want Guadalajara first with 2 customers", cities)
+	}
+
+	// La paginación recorre todas las ubicaciones sin tope fijo
+	page2, err := repo.GetGeographicDistribution(ctx, repository.GeoLevelState, commondto.Pagination{Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatalf("GetGeographicDistribution(state, page 2): %v", err)
+	}
+	if len(page2) != 2 {
+		t.Errorf("state page 2 = %+v, want 2 of 4 states", page2)
+	}
+
+	if _, err := repo.GetGeographicDistribution(ctx, "planet", commondto.Pagination{Page: 1, PageSize: 10}); !errors.Is(err, repository.ErrInvalidGeoLevel) {
+		t.Errorf("invalid level: err = %v, want ErrInvalidGeoLevel", err)
+	}
+}
@@ -0,0 +1,30 @@
+// osmi/osmi-server/internal/infrastructure/repositories/postgres/db.go
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DBTX es lo que los repositorios usan de la base de datos. En producción es
+// el *pgxpool.Pool; en los tests de integración es un pgx.Tx que se revierte
+// al terminar, y los Begin de los repositorios se vuelven savepoints.
+type DBTX interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// beginReadOnly abre una transacción de sólo lectura. Dentro de otra
+// transacción (un pgx.Tx) se abre un savepoint sin cambiar el modo.
+func beginReadOnly(ctx context.Context, db DBTX) (pgx.Tx, error) {
+	if starter, ok := db.(interface {
+		BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	}); ok {
+		return starter.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	}
+	return db.Begin(ctx)
+}
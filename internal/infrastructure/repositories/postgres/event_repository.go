@@ -9,25 +9,65 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/repohelpers"
 )
 
 // EventRepository implementa la interfaz repository.EventRepository usando PostgreSQL
 type EventRepository struct {
-	db *pgxpool.Pool
+	db          DBTX
+	reader      *readRouter
+	shareBuffer *shareCountBuffer
+	maxPage     int
 }
 
 // NewEventRepository crea una nueva instancia del repositorio
-func NewEventRepository(db *pgxpool.Pool) *EventRepository {
+func NewEventRepository(db DBTX) *EventRepository {
 	return &EventRepository{
-		db: db,
+		db:      db,
+		reader:  &readRouter{primary: db},
+		maxPage: commondto.DefaultMaxPage,
 	}
 }
 
+// SetMaxPage fija la página más profunda que admiten los listados paginados por
+// offset. Valores no positivos se ignoran.
+func (r *EventRepository) SetMaxPage(page int) {
+	if page > 0 {
+		r.maxPage = page
+	}
+}
+
+// EnableReadReplica envía los listados a la réplica de lectura.
+// Si la réplica cae, las lecturas vuelven al primario automáticamente.
+func (r *EventRepository) EnableReadReplica(replica DBTX) {
+	r.reader = &readRouter{primary: r.db, replica: replica}
+}
+
+// EnableBufferedShareCount activa el conteo de compartidos en memoria con flush periódico.
+// Debe llamarse Close al apagar el servidor para no perder incrementos.
+func (r *EventRepository) EnableBufferedShareCount(flushInterval time.Duration) {
+	if r.shareBuffer != nil {
+		return
+	}
+	r.shareBuffer = newShareCountBuffer(r.db, flushInterval)
+}
+
+// Close persiste los incrementos pendientes del buffer de compartidos
+func (r *EventRepository) Close(ctx context.Context) error {
+	if r.shareBuffer == nil {
+		return nil
+	}
+	return r.shareBuffer.close(ctx)
+}
+
 // handleError mapea errores de PostgreSQL
 func (r *EventRepository) handleError(err error, context string) error {
 	if err == nil {
@@ -154,244 +194,124 @@ func (r *EventRepository) Create(ctx context.Context, event *entities.Event) err
 // GetByID obtiene evento por ID
 func (r *EventRepository) GetByID(ctx context.Context, id int64) (*entities.Event, error) {
 	query := `
-		SELECT 
-			id, public_uuid, organizer_id, primary_category_id, venue_id,
-			slug, name, short_description, description, event_type,
-			cover_image_url, banner_image_url, gallery_images,
-			timezone, starts_at, ends_at, doors_open_at, doors_close_at,
-			venue_name, address_full, city, state, country,
-			status, visibility, is_featured, is_free,
-			max_attendees, min_attendees, tags, age_restriction,
-			requires_approval, allow_reservations, reservation_duration_minutes,
-			view_count, favorite_count, share_count,
-			meta_title, meta_description, settings,
-			published_at, created_at, updated_at
+		SELECT ` + eventSelectColumns + `
 		FROM ticketing.events
 		WHERE id = $1
 	`
 
-	var event entities.Event
-	var galleryImagesJSON, tagsJSON, settingsJSON []byte
-	var organizerID, primaryCategoryID, venueID *int64
-	var coverImageURL, bannerImageURL, venueName, addressFull, city, state, country, metaTitle, metaDescription *string
-	var shortDescription, description, eventType *string
-	var doorsOpenAt, doorsCloseAt, publishedAt *time.Time
-
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&event.ID, &event.PublicID, &organizerID, &primaryCategoryID, &venueID,
-		&event.Slug, &event.Name, &shortDescription, &description, &eventType,
-		&coverImageURL, &bannerImageURL, &galleryImagesJSON,
-		&event.Timezone, &event.StartsAt, &event.EndsAt, &doorsOpenAt, &doorsCloseAt,
-		&venueName, &addressFull, &city, &state, &country,
-		&event.Status, &event.Visibility, &event.IsFeatured, &event.IsFree,
-		&event.MaxAttendees, &event.MinAttendees, &tagsJSON, &event.AgeRestriction,
-		&event.RequiresApproval, &event.AllowReservations, &event.ReservationDuration,
-		&event.ViewCount, &event.FavoriteCount, &event.ShareCount,
-		&metaTitle, &metaDescription, &settingsJSON,
-		&publishedAt, &event.CreatedAt, &event.UpdatedAt,
-	)
-
+	event, err := scanEvent(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("event not found: %d", id)
+			return nil, fmt.Errorf("%w: %d", repository.ErrEventNotFound, id)
 		}
 		return nil, r.handleError(err, "failed to get event by ID")
 	}
 
-	// Asignar campos NULL
-	event.OrganizerID = organizerID
-	event.PrimaryCategoryID = primaryCategoryID
-	event.VenueID = venueID
-	event.CoverImageURL = coverImageURL
-	event.BannerImageURL = bannerImageURL
-	event.VenueName = venueName
-	event.AddressFull = addressFull
-	event.City = city
-	event.State = state
-	event.Country = country
-	event.MetaTitle = metaTitle
-	event.MetaDescription = metaDescription
-	event.ShortDescription = shortDescription
-	event.Description = description
-	event.EventType = eventType
-	event.DoorsOpenAt = doorsOpenAt
-	event.DoorsCloseAt = doorsCloseAt
-	event.PublishedAt = publishedAt
-
-	// Deserializar JSON
-	if len(galleryImagesJSON) > 0 {
-		json.Unmarshal(galleryImagesJSON, &event.GalleryImages)
-	}
-	if len(tagsJSON) > 0 {
-		json.Unmarshal(tagsJSON, &event.Tags)
-	}
-	if len(settingsJSON) > 0 {
-		json.Unmarshal(settingsJSON, &event.Settings)
-	}
-
-	return &event, nil
+	return event, nil
 }
 
 // GetByPublicID obtiene evento por UUID
 func (r *EventRepository) GetByPublicID(ctx context.Context, publicID string) (*entities.Event, error) {
 	query := `
-		SELECT 
-			id, public_uuid, organizer_id, primary_category_id, venue_id,
-			slug, name, short_description, description, event_type,
-			cover_image_url, banner_image_url, gallery_images,
-			timezone, starts_at, ends_at, doors_open_at, doors_close_at,
-			venue_name, address_full, city, state, country,
-			status, visibility, is_featured, is_free,
-			max_attendees, min_attendees, tags, age_restriction,
-			requires_approval, allow_reservations, reservation_duration_minutes,
-			view_count, favorite_count, share_count,
-			meta_title, meta_description, settings,
-			published_at, created_at, updated_at
+		SELECT ` + eventSelectColumns + `
 		FROM ticketing.events
 		WHERE public_uuid = $1
 	`
 
-	var event entities.Event
-	var galleryImagesJSON, tagsJSON, settingsJSON []byte
-	var organizerID, primaryCategoryID, venueID *int64
-	var coverImageURL, bannerImageURL, venueName, addressFull, city, state, country, metaTitle, metaDescription *string
-	var shortDescription, description, eventType *string
-	var doorsOpenAt, doorsCloseAt, publishedAt *time.Time
-
-	err := r.db.QueryRow(ctx, query, publicID).Scan(
-		&event.ID, &event.PublicID, &organizerID, &primaryCategoryID, &venueID,
-		&event.Slug, &event.Name, &shortDescription, &description, &eventType,
-		&coverImageURL, &bannerImageURL, &galleryImagesJSON,
-		&event.Timezone, &event.StartsAt, &event.EndsAt, &doorsOpenAt, &doorsCloseAt,
-		&venueName, &addressFull, &city, &state, &country,
-		&event.Status, &event.Visibility, &event.IsFeatured, &event.IsFree,
-		&event.MaxAttendees, &event.MinAttendees, &tagsJSON, &event.AgeRestriction,
-		&event.RequiresApproval, &event.AllowReservations, &event.ReservationDuration,
-		&event.ViewCount, &event.FavoriteCount, &event.ShareCount,
-		&metaTitle, &metaDescription, &settingsJSON,
-		&publishedAt, &event.CreatedAt, &event.UpdatedAt,
-	)
-
+	event, err := scanEvent(r.db.QueryRow(ctx, query, publicID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("event not found: %s", publicID)
+			return nil, fmt.Errorf("%w: %s", repository.ErrEventNotFound, publicID)
 		}
 		return nil, r.handleError(err, "failed to get event by public ID")
 	}
 
-	// Asignar campos NULL
-	event.OrganizerID = organizerID
-	event.PrimaryCategoryID = primaryCategoryID
-	event.VenueID = venueID
-	event.CoverImageURL = coverImageURL
-	event.BannerImageURL = bannerImageURL
-	event.VenueName = venueName
-	event.AddressFull = addressFull
-	event.City = city
-	event.State = state
-	event.Country = country
-	event.MetaTitle = metaTitle
-	event.MetaDescription = metaDescription
-	event.ShortDescription = shortDescription
-	event.Description = description
-	event.EventType = eventType
-	event.DoorsOpenAt = doorsOpenAt
-	event.DoorsCloseAt = doorsCloseAt
-	event.PublishedAt = publishedAt
-
-	// Deserializar JSON
-	if len(galleryImagesJSON) > 0 {
-		json.Unmarshal(galleryImagesJSON, &event.GalleryImages)
-	}
-	if len(tagsJSON) > 0 {
-		json.Unmarshal(tagsJSON, &event.Tags)
-	}
-	if len(settingsJSON) > 0 {
-		json.Unmarshal(settingsJSON, &event.Settings)
-	}
-
-	return &event, nil
+	return event, nil
 }
 
 // GetBySlug obtiene evento por slug
 func (r *EventRepository) GetBySlug(ctx context.Context, slug string) (*entities.Event, error) {
 	query := `
-		SELECT 
-			id, public_uuid, organizer_id, primary_category_id, venue_id,
-			slug, name, short_description, description, event_type,
-			cover_image_url, banner_image_url, gallery_images,
-			timezone, starts_at, ends_at, doors_open_at, doors_close_at,
-			venue_name, address_full, city, state, country,
-			status, visibility, is_featured, is_free,
-			max_attendees, min_attendees, tags, age_restriction,
-			requires_approval, allow_reservations, reservation_duration_minutes,
-			view_count, favorite_count, share_count,
-			meta_title, meta_description, settings,
-			published_at, created_at, updated_at
+		SELECT ` + eventSelectColumns + `
 		FROM ticketing.events
 		WHERE slug = $1
 	`
 
-	var event entities.Event
-	var galleryImagesJSON, tagsJSON, settingsJSON []byte
-	var organizerID, primaryCategoryID, venueID *int64
-	var coverImageURL, bannerImageURL, venueName, addressFull, city, state, country, metaTitle, metaDescription *string
-	var shortDescription, description, eventType *string
-	var doorsOpenAt, doorsCloseAt, publishedAt *time.Time
-
-	err := r.db.QueryRow(ctx, query, slug).Scan(
-		&event.ID, &event.PublicID, &organizerID, &primaryCategoryID, &venueID,
-		&event.Slug, &event.Name, &shortDescription, &description, &eventType,
-		&coverImageURL, &bannerImageURL, &galleryImagesJSON,
-		&event.Timezone, &event.StartsAt, &event.EndsAt, &doorsOpenAt, &doorsCloseAt,
-		&venueName, &addressFull, &city, &state, &country,
-		&event.Status, &event.Visibility, &event.IsFeatured, &event.IsFree,
-		&event.MaxAttendees, &event.MinAttendees, &tagsJSON, &event.AgeRestriction,
-		&event.RequiresApproval, &event.AllowReservations, &event.ReservationDuration,
-		&event.ViewCount, &event.FavoriteCount, &event.ShareCount,
-		&metaTitle, &metaDescription, &settingsJSON,
-		&publishedAt, &event.CreatedAt, &event.UpdatedAt,
-	)
-
+	event, err := scanEvent(r.db.QueryRow(ctx, query, slug))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("event not found: %s", slug)
+			return nil, fmt.Errorf("%w: %s", repository.ErrEventNotFound, slug)
 		}
 		return nil, r.handleError(err, "failed to get event by slug")
 	}
 
-	// Asignar campos NULL
-	event.OrganizerID = organizerID
-	event.PrimaryCategoryID = primaryCategoryID
-	event.VenueID = venueID
-	event.CoverImageURL = coverImageURL
-	event.BannerImageURL = bannerImageURL
-	event.VenueName = venueName
-	event.AddressFull = addressFull
-	event.City = city
-	event.State = state
-	event.Country = country
-	event.MetaTitle = metaTitle
-	event.MetaDescription = metaDescription
-	event.ShortDescription = shortDescription
-	event.Description = description
-	event.EventType = eventType
-	event.DoorsOpenAt = doorsOpenAt
-	event.DoorsCloseAt = doorsCloseAt
-	event.PublishedAt = publishedAt
-
-	// Deserializar JSON
-	if len(galleryImagesJSON) > 0 {
-		json.Unmarshal(galleryImagesJSON, &event.GalleryImages)
+	return event, nil
+}
+
+// FindBySlugOrPublicID busca por public UUID si el identificador es un UUID válido
+// y por slug en otro caso. Si no existe devuelve repository.ErrEventNotFound.
+func (r *EventRepository) FindBySlugOrPublicID(ctx context.Context, identifier string) (*entities.Event, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return nil, repository.ErrEventNotFound
 	}
-	if len(tagsJSON) > 0 {
-		json.Unmarshal(tagsJSON, &event.Tags)
+
+	if _, err := uuid.Parse(identifier); err == nil {
+		return r.GetByPublicID(ctx, identifier)
 	}
-	if len(settingsJSON) > 0 {
-		json.Unmarshal(settingsJSON, &event.Settings)
+	return r.GetBySlug(ctx, identifier)
+}
+
+// GetEventDetail obtiene el evento y sus tipos de ticket activos y visibles con
+// sus beneficios en dos consultas, para la página de detalle
+func (r *EventRepository) GetEventDetail(ctx context.Context, publicID string) (*repository.EventDetail, error) {
+	event, err := r.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
 	}
 
-	return &event, nil
+	query := `
+		SELECT id, public_uuid, event_id, name, description, ticket_class,
+			base_price, currency, max_per_order, min_per_order,
+			sale_starts_at, sale_ends_at, benefits, access_type,
+			available_quantity, is_sold_out
+		FROM ticketing.ticket_types
+		WHERE event_id = $1
+		  AND is_active = true
+		  AND is_hidden = false
+		ORDER BY base_price, id
+	`
+
+	rows, err := r.db.Query(ctx, query, event.ID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get event ticket types")
+	}
+	defer rows.Close()
+
+	detail := &repository.EventDetail{
+		Event:      event,
+		Categories: []*entities.TicketType{},
+	}
+	for rows.Next() {
+		var tt entities.TicketType
+		var benefitsJSON []byte
+		err := rows.Scan(
+			&tt.ID, &tt.PublicID, &tt.EventID, &tt.Name, &tt.Description, &tt.TicketClass,
+			&tt.BasePrice, &tt.Currency, &tt.MaxPerOrder, &tt.MinPerOrder,
+			&tt.SaleStartsAt, &tt.SaleEndsAt, &benefitsJSON, &tt.AccessType,
+			&tt.AvailableQuantity, &tt.IsSoldOut,
+		)
+		if err != nil {
+			return nil, r.handleError(err, "failed to scan event ticket type")
+		}
+		tt.Benefits = []string{}
+		if len(benefitsJSON) > 0 {
+			json.Unmarshal(benefitsJSON, &tt.Benefits)
+		}
+		detail.Categories = append(detail.Categories, &tt)
+	}
+
+	return detail, rows.Err()
 }
 
 // Update actualiza evento
@@ -481,170 +401,557 @@ func (r *EventRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-// List devuelve eventos con filtros
-func (r *EventRepository) List(ctx context.Context, filter map[string]interface{}, limit, offset int) ([]*entities.Event, int64, error) {
-	where := []string{"1=1"}
-	args := pgx.NamedArgs{}
-	argPos := 1
+// DefaultSoftDeleteReason es la razón registrada cuando el organizador elimina un evento
+const DefaultSoftDeleteReason = "deleted by organizer"
 
-	if val, ok := filter["name"]; ok {
-		where = append(where, fmt.Sprintf("name ILIKE @name_%d", argPos))
-		args[fmt.Sprintf("name_%d", argPos)] = "%" + val.(string) + "%"
-		argPos++
+// Cancel cancela el evento y registra la razón
+func (r *EventRepository) Cancel(ctx context.Context, id int64, reason string) error {
+	// El cambio de estado se registra en event_status_history en la misma sentencia.
+	// Un evento completado o ya cancelado no se toca: su razón original se conserva.
+	query := `
+		WITH prev AS (
+			SELECT id, status FROM ticketing.events WHERE id = $2 FOR UPDATE
+		),
+		updated AS (
+			UPDATE ticketing.events e
+			SET status = 'cancelled',
+				cancellation_reason = $1,
+				updated_at = NOW()
+			FROM prev
+			WHERE e.id = prev.id
+			  AND prev.status NOT IN ('completed', 'cancelled')
+			RETURNING e.id, prev.status AS from_status
+		),
+		history AS (
+			INSERT INTO ticketing.event_status_history (event_id, from_status, to_status, changed_at)
+			SELECT id, from_status, 'cancelled', NOW()
+			FROM updated
+		)
+		SELECT prev.status, (SELECT COUNT(*) FROM updated) FROM prev
+	`
+
+	var current string
+	var affected int64
+	if err := r.db.QueryRow(ctx, query, reason, id).Scan(&current, &affected); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %d", repository.ErrEventNotFound, id)
+		}
+		return r.handleError(err, "failed to cancel event")
 	}
-	if val, ok := filter["organizer_id"]; ok {
-		where = append(where, fmt.Sprintf("organizer_id = @org_%d", argPos))
-		args[fmt.Sprintf("org_%d", argPos)] = val
-		argPos++
+
+	if affected == 0 {
+		return fmt.Errorf("cannot change event status from %s to %s", current, enums.EventStatusCancelled)
 	}
-	if val, ok := filter["status"]; ok {
-		where = append(where, fmt.Sprintf("status = @status_%d", argPos))
-		args[fmt.Sprintf("status_%d", argPos)] = val
-		argPos++
+
+	return nil
+}
+
+// SoftDelete oculta el evento cancelándolo con la razón por defecto
+func (r *EventRepository) SoftDelete(ctx context.Context, id int64) error {
+	return r.Cancel(ctx, id, DefaultSoftDeleteReason)
+}
+
+// Restore devuelve un evento cancelado a borrador y limpia la razón de cancelación
+func (r *EventRepository) Restore(ctx context.Context, id int64) error {
+	query := `
+		WITH restored AS (
+			UPDATE ticketing.events
+			SET status = 'draft',
+				cancellation_reason = NULL,
+				updated_at = NOW()
+			WHERE id = $1 AND status = 'cancelled'
+			RETURNING id
+		)
+		INSERT INTO ticketing.event_status_history (event_id, from_status, to_status, changed_at)
+		SELECT id, 'cancelled', 'draft', NOW()
+		FROM restored
+	`
+
+	cmdTag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return r.handleError(err, "failed to restore event")
 	}
-	if val, ok := filter["city"]; ok {
-		where = append(where, fmt.Sprintf("city = @city_%d", argPos))
-		args[fmt.Sprintf("city_%d", argPos)] = val
-		argPos++
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("cancelled event not found: %d", id)
 	}
-	if val, ok := filter["country"]; ok {
-		where = append(where, fmt.Sprintf("country = @country_%d", argPos))
-		args[fmt.Sprintf("country_%d", argPos)] = val
-		argPos++
+
+	return nil
+}
+
+// Publish publica un evento en borrador o programado y registra el cambio en el historial
+func (r *EventRepository) Publish(ctx context.Context, id int64, publishedAt time.Time, actor string) error {
+	return r.changePublishStatus(ctx, id, enums.EventStatusPublished, &publishedAt, actor)
+}
+
+// Unpublish regresa un evento publicado a borrador y registra el cambio en el historial
+func (r *EventRepository) Unpublish(ctx context.Context, id int64, actor string) error {
+	return r.changePublishStatus(ctx, id, enums.EventStatusDraft, nil, actor)
+}
+
+// changePublishStatus valida la transición con el evento bloqueado y escribe
+// estado e historial en la misma transacción
+func (r *EventRepository) changePublishStatus(ctx context.Context, id int64, target enums.EventStatus, publishedAt *time.Time, actor string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	if val, ok := filter["is_featured"]; ok {
-		where = append(where, fmt.Sprintf("is_featured = @featured_%d", argPos))
-		args[fmt.Sprintf("featured_%d", argPos)] = val
-		argPos++
+	defer tx.Rollback(ctx)
+
+	var current string
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM ticketing.events WHERE id = $1 FOR UPDATE
+	`, id).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %d", repository.ErrEventNotFound, id)
+		}
+		return r.handleError(err, "failed to lock event")
 	}
-	if val, ok := filter["is_free"]; ok {
-		where = append(where, fmt.Sprintf("is_free = @free_%d", argPos))
-		args[fmt.Sprintf("free_%d", argPos)] = val
-		argPos++
+
+	if !enums.EventStatus(current).CanTransitionTo(target) {
+		return fmt.Errorf("cannot change event status from %s to %s", current, target)
 	}
-	if val, ok := filter["date_from"]; ok {
-		where = append(where, fmt.Sprintf("starts_at >= @date_from_%d", argPos))
-		args[fmt.Sprintf("date_from_%d", argPos)] = val
-		argPos++
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ticketing.events
+		SET status = $1,
+			published_at = COALESCE($2, published_at),
+			updated_at = NOW()
+		WHERE id = $3
+	`, string(target), publishedAt, id)
+	if err != nil {
+		return r.handleError(err, "failed to update event status")
 	}
-	if val, ok := filter["date_to"]; ok {
-		where = append(where, fmt.Sprintf("ends_at <= @date_to_%d", argPos))
-		args[fmt.Sprintf("date_to_%d", argPos)] = val
-		argPos++
+
+	var changedBy *string
+	if actor != "" {
+		changedBy = &actor
 	}
-	if val, ok := filter["search"]; ok {
-		searchTerm := "%" + val.(string) + "%"
-		where = append(where, fmt.Sprintf("(name ILIKE @search_%d OR description ILIKE @search_%d)", argPos, argPos))
-		args[fmt.Sprintf("search_%d", argPos)] = searchTerm
-		argPos++
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ticketing.event_status_history (event_id, from_status, to_status, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, id, current, string(target), changedBy)
+	if err != nil {
+		return r.handleError(err, "failed to record event status history")
 	}
 
-	whereClause := strings.Join(where, " AND ")
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-	// Contar total
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM ticketing.events WHERE %s", whereClause)
-	var total int64
-	err := r.db.QueryRow(ctx, countQuery, args).Scan(&total)
+	return nil
+}
+
+// GetPublishHistory devuelve las publicaciones y despublicaciones del evento en orden cronológico
+func (r *EventRepository) GetPublishHistory(ctx context.Context, eventPublicID string) ([]repository.PublishEvent, error) {
+	query := `
+		SELECT
+			CASE WHEN h.to_status = 'published' THEN 'publish' ELSE 'unpublish' END,
+			h.from_status, h.to_status, h.changed_by, h.changed_at
+		FROM ticketing.event_status_history h
+		JOIN ticketing.events e ON e.id = h.event_id
+		WHERE e.public_uuid = $1
+		  AND (h.to_status = 'published' OR (h.from_status = 'published' AND h.to_status = 'draft'))
+		ORDER BY h.changed_at, h.id
+	`
+
+	rows, err := r.reader.query(ctx, query, eventPublicID)
 	if err != nil {
-		return nil, 0, r.handleError(err, "failed to count events")
+		return nil, r.handleError(err, "failed to get publish history")
 	}
+	defer rows.Close()
 
-	// Obtener datos
-	query := fmt.Sprintf(`
-		SELECT 
-			id, public_uuid, organizer_id, primary_category_id, venue_id,
-			slug, name, short_description, description, event_type,
-			cover_image_url, banner_image_url, gallery_images,
-			timezone, starts_at, ends_at, doors_open_at, doors_close_at,
-			venue_name, address_full, city, state, country,
-			status, visibility, is_featured, is_free,
-			max_attendees, min_attendees, tags, age_restriction,
-			requires_approval, allow_reservations, reservation_duration_minutes,
-			view_count, favorite_count, share_count,
-			meta_title, meta_description, settings,
-			published_at, created_at, updated_at
-		FROM ticketing.events 
-		WHERE %s
-		ORDER BY starts_at
-		LIMIT @limit OFFSET @offset
-	`, whereClause)
+	history := []repository.PublishEvent{}
+	for rows.Next() {
+		var pe repository.PublishEvent
+		if err := rows.Scan(&pe.Action, &pe.FromStatus, &pe.ToStatus, &pe.Actor, &pe.ChangedAt); err != nil {
+			return nil, r.handleError(err, "failed to scan publish history")
+		}
+		history = append(history, pe)
+	}
 
-	args["limit"] = limit
-	args["offset"] = offset
+	return history, rows.Err()
+}
+
+// statusChangeSafetyLag deja fuera los cambios más recientes. changed_at es el
+// NOW() de la transacción que escribió el cambio, así que una transacción larga
+// puede confirmar un cambio con changed_at anterior a otro que un consumidor ya
+// leyó; esperar este margen evita que el cursor lo salte.
+const statusChangeSafetyLag = 30 * time.Second
+
+// DefaultStatusChangesLimit es el tamaño de página de GetStatusChangesSince cuando no se indica uno
+const DefaultStatusChangesLimit = 500
+
+// GetStatusChangesSince devuelve hasta limit cambios de estado posteriores al
+// cursor after, en orden (changed_at, id). El consumidor reanuda pasando el
+// Cursor() del último cambio recibido; una página con menos de limit filas
+// indica que alcanzó el final. Los cambios de los últimos
+// statusChangeSafetyLag no se devuelven todavía.
+func (r *EventRepository) GetStatusChangesSince(ctx context.Context, after repository.StatusChangeCursor, limit int) ([]repository.StatusChangeEvent, error) {
+	if limit <= 0 {
+		limit = DefaultStatusChangesLimit
+	}
+
+	query := `
+		SELECT h.id, h.event_id, e.public_uuid, h.from_status, h.to_status, h.changed_at
+		FROM ticketing.event_status_history h
+		JOIN ticketing.events e ON e.id = h.event_id
+		WHERE (h.changed_at, h.id) > ($1, $2)
+		  AND h.changed_at < NOW() - make_interval(secs => $3)
+		ORDER BY h.changed_at, h.id
+		LIMIT $4
+	`
 
-	rows, err := r.db.Query(ctx, query, args)
+	rows, err := r.reader.query(ctx, query, after.ChangedAt, after.ID, statusChangeSafetyLag.Seconds(), limit)
 	if err != nil {
-		return nil, 0, r.handleError(err, "failed to list events")
+		return nil, r.handleError(err, "failed to get status changes")
 	}
 	defer rows.Close()
 
-	var events []*entities.Event
+	changes := []repository.StatusChangeEvent{}
 	for rows.Next() {
-		var event entities.Event
-		var galleryImagesJSON, tagsJSON, settingsJSON []byte
-		var organizerID, primaryCategoryID, venueID *int64
-		var coverImageURL, bannerImageURL, venueName, addressFull, city, state, country, metaTitle, metaDescription *string
-		var shortDescription, description, eventType *string
-		var doorsOpenAt, doorsCloseAt, publishedAt *time.Time
+		var c repository.StatusChangeEvent
+		if err := rows.Scan(&c.ID, &c.EventID, &c.EventPublicID, &c.FromStatus, &c.ToStatus, &c.ChangedAt); err != nil {
+			return nil, r.handleError(err, "failed to scan status change")
+		}
+		changes = append(changes, c)
+	}
 
-		err = rows.Scan(
-			&event.ID, &event.PublicID, &organizerID, &primaryCategoryID, &venueID,
-			&event.Slug, &event.Name, &shortDescription, &description, &eventType,
-			&coverImageURL, &bannerImageURL, &galleryImagesJSON,
-			&event.Timezone, &event.StartsAt, &event.EndsAt, &doorsOpenAt, &doorsCloseAt,
-			&venueName, &addressFull, &city, &state, &country,
-			&event.Status, &event.Visibility, &event.IsFeatured, &event.IsFree,
-			&event.MaxAttendees, &event.MinAttendees, &tagsJSON, &event.AgeRestriction,
-			&event.RequiresApproval, &event.AllowReservations, &event.ReservationDuration,
-			&event.ViewCount, &event.FavoriteCount, &event.ShareCount,
-			&metaTitle, &metaDescription, &settingsJSON,
-			&publishedAt, &event.CreatedAt, &event.UpdatedAt,
+	return changes, rows.Err()
+}
+
+// GetSoldOutEvents devuelve los eventos cuyo primer paso a sold_out cayó en
+// [from, to), ordenados del que se agotó más rápido al más lento. El inicio
+// es la última publicación anterior al agotamiento según el historial; si no
+// hay una se usa events.published_at y, sin ninguna de las dos, el evento se omite.
+func (r *EventRepository) GetSoldOutEvents(ctx context.Context, from, to time.Time) ([]repository.SoldOutInfo, error) {
+	query := `
+		WITH sold_out AS (
+			SELECT event_id, MIN(changed_at) AS sold_out_at
+			FROM ticketing.event_status_history
+			WHERE to_status = 'sold_out'
+			GROUP BY event_id
 		)
-		if err != nil {
-			return nil, 0, r.handleError(err, "failed to scan event row")
-		}
+		SELECT e.id, e.public_uuid, e.name, p.published_at, s.sold_out_at
+		FROM sold_out s
+		JOIN ticketing.events e ON e.id = s.event_id
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(
+				(SELECT MAX(h.changed_at)
+				 FROM ticketing.event_status_history h
+				 WHERE h.event_id = s.event_id
+				   AND h.to_status = 'published'
+				   AND h.changed_at <= s.sold_out_at),
+				e.published_at
+			) AS published_at
+		) p
+		WHERE s.sold_out_at >= $1 AND s.sold_out_at < $2
+		  AND p.published_at IS NOT NULL
+		ORDER BY s.sold_out_at - p.published_at, e.id
+	`
 
-		// Asignar campos NULL
-		event.OrganizerID = organizerID
-		event.PrimaryCategoryID = primaryCategoryID
-		event.VenueID = venueID
-		event.CoverImageURL = coverImageURL
-		event.BannerImageURL = bannerImageURL
-		event.VenueName = venueName
-		event.AddressFull = addressFull
-		event.City = city
-		event.State = state
-		event.Country = country
-		event.MetaTitle = metaTitle
-		event.MetaDescription = metaDescription
-		event.ShortDescription = shortDescription
-		event.Description = description
-		event.EventType = eventType
-		event.DoorsOpenAt = doorsOpenAt
-		event.DoorsCloseAt = doorsCloseAt
-		event.PublishedAt = publishedAt
+	rows, err := r.reader.query(ctx, query, from, to)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get sold out events")
+	}
+	defer rows.Close()
 
-		// Deserializar JSON
-		if len(galleryImagesJSON) > 0 {
-			json.Unmarshal(galleryImagesJSON, &event.GalleryImages)
-		}
-		if len(tagsJSON) > 0 {
-			json.Unmarshal(tagsJSON, &event.Tags)
-		}
-		if len(settingsJSON) > 0 {
-			json.Unmarshal(settingsJSON, &event.Settings)
+	events := []repository.SoldOutInfo{}
+	for rows.Next() {
+		var info repository.SoldOutInfo
+		if err := rows.Scan(&info.EventID, &info.EventPublicID, &info.Name, &info.PublishedAt, &info.SoldOutAt); err != nil {
+			return nil, r.handleError(err, "failed to scan sold out event")
 		}
-
-		events = append(events, &event)
+		info.TimeToSellOut = info.SoldOutAt.Sub(info.PublishedAt)
+		events = append(events, info)
 	}
 
-	return events, total, nil
+	return events, rows.Err()
 }
 
-// ListByOrganizer lista eventos de un organizador
-func (r *EventRepository) ListByOrganizer(ctx context.Context, organizerID int64, limit, offset int) ([]*entities.Event, int64, error) {
-	filter := map[string]interface{}{
-		"organizer_id": organizerID,
+// BulkUpdateStatus cambia el estado de varios eventos aplicando sólo transiciones válidas.
+// Devuelve cuántos cambiaron y los IDs omitidos (transición inválida o inexistentes).
+// Las filas se bloquean con ORDER BY id para evitar deadlocks con otros bulk updates.
+func (r *EventRepository) BulkUpdateStatus(ctx context.Context, eventIDs []int64, target string) (int64, []int64, error) {
+	targetStatus := enums.EventStatus(target)
+	if !targetStatus.IsValid() {
+		return 0, nil, &enums.InvalidEventStatusError{Status: target}
+	}
+	if len(eventIDs) == 0 {
+		return 0, nil, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, status
+		FROM ticketing.events
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`, eventIDs)
+	if err != nil {
+		return 0, nil, r.handleError(err, "failed to lock events")
+	}
+
+	current := make(map[int64]enums.EventStatus, len(eventIDs))
+	for rows.Next() {
+		var id int64
+		var status string
+		if err := rows.Scan(&id, &status); err != nil {
+			rows.Close()
+			return 0, nil, r.handleError(err, "failed to scan event status")
+		}
+		current[id] = enums.EventStatus(status)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, r.handleError(err, "failed to read event statuses")
+	}
+
+	var toUpdate []int64
+	var fromStatuses []string
+	skipped := []int64{}
+	for _, id := range eventIDs {
+		status, ok := current[id]
+		if !ok || !status.CanTransitionTo(targetStatus) {
+			skipped = append(skipped, id)
+			continue
+		}
+		toUpdate = append(toUpdate, id)
+		fromStatuses = append(fromStatuses, string(status))
+	}
+
+	var changed int64
+	if len(toUpdate) > 0 {
+		cmdTag, err := tx.Exec(ctx, `
+			UPDATE ticketing.events
+			SET status = $1, updated_at = NOW()
+			WHERE id = ANY($2)
+		`, target, toUpdate)
+		if err != nil {
+			return 0, nil, r.handleError(err, "failed to bulk update event status")
+		}
+		changed = cmdTag.RowsAffected()
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO ticketing.event_status_history (event_id, from_status, to_status, changed_at)
+			SELECT id, from_status, $3, NOW()
+			FROM unnest($1::bigint[], $2::text[]) AS t(id, from_status)
+		`, toUpdate, fromStatuses, target)
+		if err != nil {
+			return 0, nil, r.handleError(err, "failed to record event status history")
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return changed, skipped, nil
+}
+
+// BulkCancelByOrganizer cancela en una transacción todos los eventos del organizador
+// que no hayan terminado (cancelled, completed o archived se omiten), guarda la
+// razón y registra el cambio en event_status_history. Devuelve cuántos se cancelaron.
+func (r *EventRepository) BulkCancelByOrganizer(ctx context.Context, organizerPublicID, reason string) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var organizerID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM ticketing.organizers WHERE public_uuid = $1`, organizerPublicID).Scan(&organizerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%w: %s", repository.ErrOrganizerNotFound, organizerPublicID)
+		}
+		return 0, r.handleError(err, "failed to get organizer")
+	}
+
+	// Mismo orden de bloqueo que BulkUpdateStatus
+	query := `
+		WITH locked AS (
+			SELECT id, status
+			FROM ticketing.events
+			WHERE organizer_id = $1
+				AND status NOT IN ('cancelled', 'completed', 'archived')
+			ORDER BY id
+			FOR UPDATE
+		),
+		cancelled AS (
+			UPDATE ticketing.events e
+			SET status = 'cancelled',
+				cancellation_reason = $2,
+				updated_at = NOW()
+			FROM locked
+			WHERE e.id = locked.id
+			RETURNING e.id, locked.status AS from_status
+		)
+		INSERT INTO ticketing.event_status_history (event_id, from_status, to_status, changed_at)
+		SELECT id, from_status, 'cancelled', NOW()
+		FROM cancelled
+	`
+
+	cmdTag, err := tx.Exec(ctx, query, organizerID, reason)
+	if err != nil {
+		return 0, r.handleError(err, "failed to cancel organizer events")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// List devuelve eventos con filtros
+func (r *EventRepository) List(ctx context.Context, filter map[string]interface{}, limit, offset int) ([]*entities.Event, int64, error) {
+	limit, offset, err := commondto.NormalizeOffset(limit, offset, r.maxPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where := []string{"1=1"}
+	args := pgx.NamedArgs{}
+	argPos := 1
+
+	if val, ok := filter["name"]; ok {
+		where = append(where, fmt.Sprintf("name ILIKE @name_%d", argPos))
+		args[fmt.Sprintf("name_%d", argPos)] = "%" + val.(string) + "%"
+		argPos++
+	}
+	if val, ok := filter["organizer_id"]; ok {
+		where = append(where, fmt.Sprintf("organizer_id = @org_%d", argPos))
+		args[fmt.Sprintf("org_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["category_id"]; ok {
+		// Acepta el ID interno (int64) o el public_uuid de la categoría (string)
+		categoryExpr := fmt.Sprintf("@cat_%d", argPos)
+		if _, isUUID := val.(string); isUUID {
+			categoryExpr = fmt.Sprintf("(SELECT id FROM ticketing.categories WHERE public_uuid = @cat_%d)", argPos)
+		}
+		where = append(where, fmt.Sprintf(
+			"(primary_category_id = %[1]s OR EXISTS (SELECT 1 FROM ticketing.event_categories ec WHERE ec.event_id = ticketing.events.id AND ec.category_id = %[1]s))",
+			categoryExpr))
+		args[fmt.Sprintf("cat_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["venue_id"]; ok {
+		where = append(where, fmt.Sprintf("venue_id = @venue_%d", argPos))
+		args[fmt.Sprintf("venue_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["event_type"]; ok {
+		where = append(where, fmt.Sprintf("event_type = @event_type_%d", argPos))
+		args[fmt.Sprintf("event_type_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["status"]; ok {
+		where = append(where, fmt.Sprintf("status = lower(@status_%d)", argPos))
+		args[fmt.Sprintf("status_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["city"]; ok {
+		where = append(where, fmt.Sprintf("city = @city_%d", argPos))
+		args[fmt.Sprintf("city_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["country"]; ok {
+		where = append(where, fmt.Sprintf("country = @country_%d", argPos))
+		args[fmt.Sprintf("country_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["is_featured"]; ok {
+		where = append(where, fmt.Sprintf("is_featured = @featured_%d", argPos))
+		args[fmt.Sprintf("featured_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["is_free"]; ok {
+		where = append(where, fmt.Sprintf("is_free = @free_%d", argPos))
+		args[fmt.Sprintf("free_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["date_from"]; ok {
+		where = append(where, fmt.Sprintf("starts_at >= @date_from_%d", argPos))
+		args[fmt.Sprintf("date_from_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["date_to"]; ok {
+		where = append(where, fmt.Sprintf("ends_at <= @date_to_%d", argPos))
+		args[fmt.Sprintf("date_to_%d", argPos)] = val
+		argPos++
+	}
+	if val, ok := filter["search"]; ok {
+		searchTerm := "%" + val.(string) + "%"
+		where = append(where, fmt.Sprintf("(name ILIKE @search_%d OR description ILIKE @search_%d)", argPos, argPos))
+		args[fmt.Sprintf("search_%d", argPos)] = searchTerm
+		argPos++
+	}
+
+	whereClause := strings.Join(where, " AND ")
+
+	// Contar total
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM ticketing.events WHERE %s", whereClause)
+	var total int64
+	err = r.reader.queryRow(ctx, countQuery, args).Scan(&total)
+	if err != nil {
+		return nil, 0, r.handleError(err, "failed to count events")
+	}
+
+	// Obtener datos
+	query := fmt.Sprintf(`
+		SELECT `+eventSelectColumns+`
+		FROM ticketing.events
+		WHERE %s
+		ORDER BY starts_at, id
+		LIMIT @limit OFFSET @offset
+	`, whereClause)
+
+	args["limit"] = limit
+	args["offset"] = offset
+
+	rows, err := r.reader.query(ctx, query, args)
+	if err != nil {
+		return nil, 0, r.handleError(err, "failed to list events")
+	}
+	defer rows.Close()
+
+	events, err := scanEventRows(rows)
+	if err != nil {
+		return nil, 0, r.handleError(err, "failed to scan events")
+	}
+
+	return events, total, nil
+}
+
+// ListByOrganizer lista eventos de un organizador
+func (r *EventRepository) ListByOrganizer(ctx context.Context, organizerID int64, limit, offset int) ([]*entities.Event, int64, error) {
+	filter := map[string]interface{}{
+		"organizer_id": organizerID,
+	}
+	return r.List(ctx, filter, limit, offset)
+}
+
+// ListByCategory lista eventos de una categoría (principal o secundaria)
+func (r *EventRepository) ListByCategory(ctx context.Context, categoryID int64, limit, offset int) ([]*entities.Event, int64, error) {
+	filter := map[string]interface{}{
+		"category_id": categoryID,
+	}
+	return r.List(ctx, filter, limit, offset)
+}
+
+// ListByVenue lista eventos de un recinto
+func (r *EventRepository) ListByVenue(ctx context.Context, venueID int64, limit, offset int) ([]*entities.Event, int64, error) {
+	filter := map[string]interface{}{
+		"venue_id": venueID,
 	}
 	return r.List(ctx, filter, limit, offset)
 }
@@ -658,15 +965,119 @@ func (r *EventRepository) ListUpcoming(ctx context.Context, limit int) ([]*entit
 	return events, err
 }
 
-// ListFeatured lista eventos destacados
-func (r *EventRepository) ListFeatured(ctx context.Context, limit int) ([]*entities.Event, error) {
+// GetUpcomingForCategory lista eventos publicados y próximos de una categoría
+func (r *EventRepository) GetUpcomingForCategory(ctx context.Context, categoryPublicID string, limit int) ([]*entities.Event, error) {
+	var categoryID int64
+	err := r.db.QueryRow(ctx, `SELECT id FROM ticketing.categories WHERE public_uuid = $1`, categoryPublicID).Scan(&categoryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrCategoryNotFound
+		}
+		return nil, r.handleError(err, "failed to resolve category")
+	}
+
 	filter := map[string]interface{}{
-		"is_featured": true,
+		"category_id": categoryID,
+		"status":      string(enums.EventStatusPublished),
+		"date_from":   time.Now(),
 	}
 	events, _, err := r.List(ctx, filter, limit, 0)
 	return events, err
 }
 
+// GetEventsStartingBetween devuelve eventos publicados que empiezan en [from, to),
+// para que el worker de recordatorios encole notificaciones a los asistentes
+func (r *EventRepository) GetEventsStartingBetween(ctx context.Context, from, to time.Time) ([]*entities.Event, error) {
+	query := `
+		SELECT ` + eventSelectColumns + `
+		FROM ticketing.events
+		WHERE status = 'published'
+		  AND starts_at >= $1
+		  AND starts_at < $2
+		ORDER BY starts_at ASC
+	`
+
+	rows, err := r.reader.query(ctx, query, from, to)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get events starting between")
+	}
+	defer rows.Close()
+
+	return scanEventRows(rows)
+}
+
+// GetStaleDrafts devuelve eventos en borrador sin actualizar desde hace más de olderThan,
+// para sugerir al organizador publicarlos o eliminarlos
+func (r *EventRepository) GetStaleDrafts(ctx context.Context, olderThan time.Duration) ([]*entities.Event, error) {
+	query := `
+		SELECT ` + eventSelectColumns + `
+		FROM ticketing.events
+		WHERE status = 'draft'
+		  AND updated_at < $1
+		ORDER BY updated_at ASC
+	`
+
+	rows, err := r.reader.query(ctx, query, time.Now().Add(-olderThan))
+	if err != nil {
+		return nil, r.handleError(err, "failed to get stale drafts")
+	}
+	defer rows.Close()
+
+	return scanEventRows(rows)
+}
+
+// GetUpcomingCountByVenue cuenta los eventos publicados futuros de cada venue en una
+// sola consulta. Todos los venues pedidos aparecen en el mapa, con 0 si no tienen eventos.
+func (r *EventRepository) GetUpcomingCountByVenue(ctx context.Context, venueIDs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(venueIDs))
+	if len(venueIDs) == 0 {
+		return counts, nil
+	}
+	for _, id := range venueIDs {
+		counts[id] = 0
+	}
+
+	query := `
+		SELECT venue_id, COUNT(*)
+		FROM ticketing.events
+		WHERE venue_id = ANY($1)
+		  AND status = 'published'
+		  AND starts_at > NOW()
+		GROUP BY venue_id
+	`
+
+	rows, err := r.reader.query(ctx, query, venueIDs)
+	if err != nil {
+		return nil, r.handleError(err, "failed to count upcoming events by venue")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var venueID, count int64
+		if err := rows.Scan(&venueID, &count); err != nil {
+			return nil, r.handleError(err, "failed to scan venue event count")
+		}
+		counts[venueID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// ListFeatured lista con paginación los eventos destacados publicados y futuros
+func (r *EventRepository) ListFeatured(ctx context.Context, pagination commondto.Pagination) ([]*entities.Event, int64, error) {
+	pagination, err := pagination.Normalize(r.maxPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter := map[string]interface{}{
+		"is_featured": true,
+		"status":      string(enums.EventStatusPublished),
+		"date_from":   time.Now(),
+	}
+	return r.List(ctx, filter, pagination.Limit(), pagination.Offset())
+}
+
 // GetEventCategories obtiene categorías de un evento
 func (r *EventRepository) GetEventCategories(ctx context.Context, eventID int64) ([]*entities.Category, error) {
 	query := `
@@ -729,11 +1140,940 @@ func (r *EventRepository) RemoveCategoryFromEvent(ctx context.Context, eventID,
 
 // Exists verifica si existe un evento con el ID dado
 func (r *EventRepository) Exists(ctx context.Context, id int64) (bool, error) {
-	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM ticketing.events WHERE id = $1)`
-	err := r.db.QueryRow(ctx, query, id).Scan(&exists)
+	exists, err := repohelpers.Exists(ctx, r.db, "ticketing.events", "id", id)
 	if err != nil {
 		return false, r.handleError(err, "failed to check event existence")
 	}
 	return exists, nil
 }
+
+// IncrementShareCount incrementa el contador de compartidos de un evento.
+// Con el buffer activo el incremento se acumula y se persiste en el siguiente flush.
+func (r *EventRepository) IncrementShareCount(ctx context.Context, eventID int64) error {
+	if r.shareBuffer != nil {
+		r.shareBuffer.add(eventID, 1)
+		return nil
+	}
+
+	query := `UPDATE ticketing.events SET share_count = share_count + 1 WHERE id = $1`
+	cmdTag, err := r.db.Exec(ctx, query, eventID)
+	if err != nil {
+		return r.handleError(err, "failed to increment share count")
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("event not found: %d", eventID)
+	}
+	return nil
+}
+
+// UpdateCounters aplica deltas de vistas, compartidos y favoritos en un solo UPDATE.
+// Los deltas pueden ser negativos; los contadores nunca bajan de cero.
+func (r *EventRepository) UpdateCounters(ctx context.Context, eventID int64, views, shares, favorites int64) error {
+	if views == 0 && shares == 0 && favorites == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE ticketing.events
+		SET view_count = GREATEST(0, view_count + $1),
+			share_count = GREATEST(0, share_count + $2),
+			favorite_count = GREATEST(0, favorite_count + $3)
+		WHERE id = $4
+	`
+	cmdTag, err := r.db.Exec(ctx, query, views, shares, favorites, eventID)
+	if err != nil {
+		return r.handleError(err, "failed to update event counters")
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %d", repository.ErrEventNotFound, eventID)
+	}
+	return nil
+}
+
+// GetGlobalStats obtiene estadísticas globales de eventos.
+// from/to son opcionales y sólo acotan tickets vendidos e ingresos por sold_at.
+func (r *EventRepository) GetGlobalStats(ctx context.Context, from, to *time.Time) (*repository.EventGlobalStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM ticketing.events) AS total_events,
+			(SELECT COUNT(*) FROM ticketing.events WHERE status IN ('published', 'live')) AS published_events,
+			COUNT(t.id) AS lifetime_tickets_sold,
+			COALESCE(SUM(t.final_price), 0) AS lifetime_revenue,
+			COUNT(t.id) FILTER (
+				WHERE (@from::timestamptz IS NULL OR t.sold_at >= @from)
+				  AND (@to::timestamptz IS NULL OR t.sold_at < @to)
+			) AS total_tickets_sold,
+			COALESCE(SUM(t.final_price) FILTER (
+				WHERE (@from::timestamptz IS NULL OR t.sold_at >= @from)
+				  AND (@to::timestamptz IS NULL OR t.sold_at < @to)
+			), 0) AS total_revenue
+		FROM ticketing.tickets t
+		WHERE t.status IN ('sold', 'transferred', 'checked_in')
+	`
+
+	args := pgx.NamedArgs{
+		"from": from,
+		"to":   to,
+	}
+
+	stats := &repository.EventGlobalStats{From: from, To: to}
+	err := r.reader.queryRow(ctx, query, args).Scan(
+		&stats.TotalEvents,
+		&stats.PublishedEvents,
+		&stats.LifetimeTicketsSold,
+		&stats.LifetimeRevenue,
+		&stats.TotalTicketsSold,
+		&stats.TotalRevenue,
+	)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get global event stats")
+	}
+
+	return stats, nil
+}
+
+// GetTagCloud cuenta los tags de eventos publicados y devuelve los N más frecuentes
+func (r *EventRepository) GetTagCloud(ctx context.Context, limit int) ([]repository.TagCount, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		SELECT tag, COUNT(*) AS count
+		FROM ticketing.events e
+		CROSS JOIN LATERAL jsonb_array_elements_text(e.tags) AS tag
+		WHERE e.status IN ('published', 'live')
+		  AND e.tags IS NOT NULL
+		  AND jsonb_typeof(e.tags) = 'array'
+		GROUP BY tag
+		ORDER BY count DESC, tag ASC
+		LIMIT $1
+	`
+
+	rows, err := r.reader.query(ctx, query, limit)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get tag cloud")
+	}
+	defer rows.Close()
+
+	tags := []repository.TagCount{}
+	for rows.Next() {
+		var tc repository.TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, r.handleError(err, "failed to scan tag count")
+		}
+		tags = append(tags, tc)
+	}
+
+	return tags, rows.Err()
+}
+
+// GetAttendeeDemographics agrega país, segmento y proporción VIP de los clientes
+// con tickets vendidos o usados del evento
+func (r *EventRepository) GetAttendeeDemographics(ctx context.Context, eventID int64) (*repository.Demographics, error) {
+	query := `
+		WITH attendees AS (
+			SELECT DISTINCT c.id,
+				COALESCE(NULLIF(c.country, ''), 'unknown') AS country,
+				COALESCE(NULLIF(c.customer_segment, ''), 'unknown') AS segment,
+				c.is_vip
+			FROM ticketing.tickets t
+			JOIN crm.customers c ON c.id = t.customer_id
+			WHERE t.event_id = $1
+			  AND t.status IN ('sold', 'transferred', 'checked_in')
+		)
+		SELECT 'country' AS dimension, country AS bucket, COUNT(*) AS count
+		FROM attendees GROUP BY country
+		UNION ALL
+		SELECT 'segment', segment, COUNT(*)
+		FROM attendees GROUP BY segment
+		UNION ALL
+		SELECT 'vip', CASE WHEN is_vip THEN 'vip' ELSE 'regular' END, COUNT(*)
+		FROM attendees GROUP BY is_vip
+		ORDER BY dimension, count DESC, bucket
+	`
+
+	rows, err := r.reader.query(ctx, query, eventID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get attendee demographics")
+	}
+	defer rows.Close()
+
+	demographics := &repository.Demographics{
+		EventID:   eventID,
+		ByCountry: []repository.DemographicBucket{},
+		BySegment: []repository.DemographicBucket{},
+	}
+
+	for rows.Next() {
+		var dimension string
+		var bucket repository.DemographicBucket
+		if err := rows.Scan(&dimension, &bucket.Key, &bucket.Count); err != nil {
+			return nil, r.handleError(err, "failed to scan demographic bucket")
+		}
+
+		switch dimension {
+		case "country":
+			demographics.TotalAttendees += bucket.Count
+			demographics.ByCountry = append(demographics.ByCountry, bucket)
+		case "segment":
+			demographics.BySegment = append(demographics.BySegment, bucket)
+		case "vip":
+			if bucket.Key == "vip" {
+				demographics.VIPCount = bucket.Count
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, r.handleError(err, "failed to iterate demographic buckets")
+	}
+
+	if demographics.TotalAttendees > 0 {
+		total := float64(demographics.TotalAttendees)
+		for i := range demographics.ByCountry {
+			demographics.ByCountry[i].Percentage = float64(demographics.ByCountry[i].Count) / total * 100
+		}
+		for i := range demographics.BySegment {
+			demographics.BySegment[i].Percentage = float64(demographics.BySegment[i].Count) / total * 100
+		}
+		demographics.VIPPercentage = float64(demographics.VIPCount) / total * 100
+	}
+
+	return demographics, nil
+}
+
+// GetSalesByHour agrupa las ventas del evento por hora del día en la zona horaria
+// del evento. Siempre devuelve 24 entradas; las horas sin ventas van en cero.
+func (r *EventRepository) GetSalesByHour(ctx context.Context, eventID int64) ([]repository.HourlySales, error) {
+	query := `
+		WITH sales AS (
+			SELECT
+				EXTRACT(HOUR FROM t.created_at AT TIME ZONE COALESCE(e.timezone, 'UTC'))::int AS hour,
+				COUNT(*) AS tickets_sold,
+				COALESCE(SUM(t.final_price), 0) AS revenue
+			FROM ticketing.tickets t
+			JOIN ticketing.events e ON e.id = t.event_id
+			WHERE t.event_id = $1
+			  AND t.status IN ('sold', 'transferred', 'checked_in')
+			GROUP BY 1
+		)
+		SELECT h.hour, COALESCE(s.tickets_sold, 0), COALESCE(s.revenue, 0)
+		FROM generate_series(0, 23) AS h(hour)
+		LEFT JOIN sales s ON s.hour = h.hour
+		ORDER BY h.hour
+	`
+
+	rows, err := r.reader.query(ctx, query, eventID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get sales by hour")
+	}
+	defer rows.Close()
+
+	hours := make([]repository.HourlySales, 0, 24)
+	for rows.Next() {
+		var hs repository.HourlySales
+		if err := rows.Scan(&hs.Hour, &hs.TicketsSold, &hs.Revenue); err != nil {
+			return nil, r.handleError(err, "failed to scan hourly sales")
+		}
+		hours = append(hours, hs)
+	}
+
+	return hours, rows.Err()
+}
+
+// forecastWindowDays es la ventana usada para medir la velocidad de venta
+const forecastWindowDays = 7
+
+// GetOccupancyForecast calcula la ocupación actual, la velocidad de venta
+// (tickets/día en los últimos forecastWindowDays) y la fecha estimada de agotado
+func (r *EventRepository) GetOccupancyForecast(ctx context.Context, eventID int64) (*repository.Forecast, error) {
+	query := `
+		SELECT
+			COALESCE((
+				SELECT SUM(tt.total_quantity)
+				FROM ticketing.ticket_types tt
+				WHERE tt.event_id = e.id AND tt.is_active = true
+			), 0) AS capacity,
+			(
+				SELECT COUNT(*)
+				FROM ticketing.tickets t
+				WHERE t.event_id = e.id AND t.status IN ('sold', 'transferred', 'checked_in')
+			) AS sold,
+			(
+				SELECT COUNT(*)
+				FROM ticketing.tickets t
+				WHERE t.event_id = e.id
+				  AND t.status IN ('sold', 'transferred', 'checked_in')
+				  AND t.sold_at >= NOW() - make_interval(days => $2)
+			) AS recent_sold
+		FROM ticketing.events e
+		WHERE e.id = $1
+	`
+
+	forecast := &repository.Forecast{WindowDays: forecastWindowDays}
+	var recentSold int64
+	err := r.reader.queryRow(ctx, query, eventID, forecastWindowDays).Scan(
+		&forecast.Capacity, &forecast.Sold, &recentSold,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", repository.ErrEventNotFound, eventID)
+		}
+		return nil, r.handleError(err, "failed to get occupancy forecast")
+	}
+
+	if forecast.Capacity > 0 {
+		forecast.Occupancy = float64(forecast.Sold) / float64(forecast.Capacity) * 100
+	}
+	forecast.VelocityPerDay = float64(recentSold) / float64(forecastWindowDays)
+
+	if forecast.VelocityPerDay > 0 {
+		now := time.Now()
+		remaining := forecast.Capacity - forecast.Sold
+		projected := now
+		if remaining > 0 {
+			days := float64(remaining) / forecast.VelocityPerDay
+			projected = now.Add(time.Duration(days * float64(24*time.Hour)))
+		}
+		forecast.ProjectedSellOutAt = &projected
+	}
+
+	return forecast, nil
+}
+
+// GetReservationStats cuenta las reservas hechas, convertidas en venta y
+// abandonadas (vencidas o liberadas) de los tickets del evento. Se basa en las
+// filas que el trigger de reservas escribe en ticket_status_history.
+func (r *EventRepository) GetReservationStats(ctx context.Context, eventID int64) (*repository.ReservationStats, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE h.to_status = 'reserved') AS made,
+			COUNT(*) FILTER (WHERE h.from_status = 'reserved' AND h.to_status = 'sold') AS converted,
+			COUNT(*) FILTER (
+				WHERE h.from_status = 'reserved' AND h.to_status IN ('available', 'expired')
+			) AS abandoned
+		FROM ticketing.ticket_status_history h
+		JOIN ticketing.tickets t ON t.id = h.ticket_id
+		WHERE t.event_id = $1
+		  AND (h.from_status = 'reserved' OR h.to_status = 'reserved')
+	`
+
+	stats := &repository.ReservationStats{}
+	err := r.reader.queryRow(ctx, query, eventID).Scan(&stats.Made, &stats.Converted, &stats.Abandoned)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get reservation stats")
+	}
+
+	if pending := stats.Made - stats.Converted - stats.Abandoned; pending > 0 {
+		stats.Pending = pending
+	}
+	if resolved := stats.Converted + stats.Abandoned; resolved > 0 {
+		stats.ConversionRate = float64(stats.Converted) / float64(resolved) * 100
+	}
+
+	return stats, nil
+}
+
+// GetSalesChannelBreakdown cuenta tickets vendidos e ingresos por canal:
+// back-office (emisión directa, sin orden) o autoservicio (checkout con orden).
+// tickets no guarda el usuario que emitió, por eso el canal se deriva de order_id.
+func (r *EventRepository) GetSalesChannelBreakdown(ctx context.Context, eventID int64) (*repository.ChannelStats, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE order_id IS NULL),
+			COALESCE(SUM(final_price) FILTER (WHERE order_id IS NULL), 0),
+			COUNT(*) FILTER (WHERE order_id IS NOT NULL),
+			COALESCE(SUM(final_price) FILTER (WHERE order_id IS NOT NULL), 0)
+		FROM ticketing.tickets
+		WHERE event_id = $1
+		  AND status IN ('sold', 'transferred', 'checked_in')
+	`
+
+	var stats repository.ChannelStats
+	err := r.reader.queryRow(ctx, query, eventID).Scan(
+		&stats.BackOfficeTickets, &stats.BackOfficeRevenue,
+		&stats.GuestTickets, &stats.GuestRevenue,
+	)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get sales channel breakdown")
+	}
+
+	return &stats, nil
+}
+
+// GetBusiestDays devuelve los días con más eventos publicados simultáneos de un
+// organizador. Un evento cuenta en cada día calendario (en su zona horaria) que abarca.
+func (r *EventRepository) GetBusiestDays(ctx context.Context, organizerPublicID string, limit int) ([]repository.DayLoad, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	query := `
+		WITH event_days AS (
+			SELECT e.id, generate_series(
+				(e.starts_at AT TIME ZONE COALESCE(e.timezone, 'UTC'))::date,
+				(COALESCE(e.ends_at, e.starts_at) AT TIME ZONE COALESCE(e.timezone, 'UTC'))::date,
+				interval '1 day'
+			)::date AS day
+			FROM ticketing.events e
+			JOIN ticketing.organizers o ON o.id = e.organizer_id
+			WHERE o.public_uuid = $1
+			  AND e.status = 'published'
+		)
+		SELECT day, COUNT(DISTINCT id) AS event_count
+		FROM event_days
+		GROUP BY day
+		ORDER BY event_count DESC, day
+		LIMIT $2
+	`
+
+	rows, err := r.reader.query(ctx, query, organizerPublicID, limit)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get busiest days")
+	}
+	defer rows.Close()
+
+	days := []repository.DayLoad{}
+	for rows.Next() {
+		var d repository.DayLoad
+		if err := rows.Scan(&d.Day, &d.EventCount); err != nil {
+			return nil, r.handleError(err, "failed to scan day load")
+		}
+		days = append(days, d)
+	}
+
+	return days, rows.Err()
+}
+
+// GetMonthlyEventCounts cuenta los eventos del organizador que inician (por
+// starts_at en la zona horaria del evento) en cada mes de year. Siempre devuelve
+// 12 entradas; los meses sin eventos van en cero. Borradores y cancelados no cuentan.
+func (r *EventRepository) GetMonthlyEventCounts(ctx context.Context, organizerPublicID string, year int) ([]repository.MonthCount, error) {
+	query := `
+		WITH monthly AS (
+			SELECT
+				EXTRACT(MONTH FROM e.starts_at AT TIME ZONE COALESCE(e.timezone, 'UTC'))::int AS month,
+				COUNT(*) AS event_count
+			FROM ticketing.events e
+			JOIN ticketing.organizers o ON o.id = e.organizer_id
+			WHERE o.public_uuid = $1
+			  AND EXTRACT(YEAR FROM e.starts_at AT TIME ZONE COALESCE(e.timezone, 'UTC'))::int = $2
+			  AND e.status NOT IN ('draft', 'cancelled')
+			GROUP BY 1
+		)
+		SELECT m.month, COALESCE(mo.event_count, 0)
+		FROM generate_series(1, 12) AS m(month)
+		LEFT JOIN monthly mo ON mo.month = m.month
+		ORDER BY m.month
+	`
+
+	rows, err := r.reader.query(ctx, query, organizerPublicID, year)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get monthly event counts")
+	}
+	defer rows.Close()
+
+	months := make([]repository.MonthCount, 0, 12)
+	for rows.Next() {
+		var mc repository.MonthCount
+		if err := rows.Scan(&mc.Month, &mc.EventCount); err != nil {
+			return nil, r.handleError(err, "failed to scan monthly event count")
+		}
+		months = append(months, mc)
+	}
+
+	return months, rows.Err()
+}
+
+// GetCapacityUtilization resume capacidad (tipos de ticket activos) y vendidos
+// de los eventos publicados del organizador. Los eventos sin capacidad suman al
+// total pero no compiten por mejor/peor utilización.
+func (r *EventRepository) GetCapacityUtilization(ctx context.Context, organizerPublicID string) (*repository.UtilizationSummary, error) {
+	query := `
+		SELECT
+			e.id,
+			e.public_uuid,
+			e.name,
+			COALESCE((
+				SELECT SUM(tt.total_quantity)
+				FROM ticketing.ticket_types tt
+				WHERE tt.event_id = e.id AND tt.is_active = true
+			), 0) AS capacity,
+			(
+				SELECT COUNT(*)
+				FROM ticketing.tickets t
+				WHERE t.event_id = e.id AND t.status IN ('sold', 'transferred', 'checked_in')
+			) AS sold
+		FROM ticketing.events e
+		JOIN ticketing.organizers o ON o.id = e.organizer_id
+		WHERE o.public_uuid = $1
+		  AND e.status = 'published'
+		ORDER BY e.id
+	`
+
+	rows, err := r.reader.query(ctx, query, organizerPublicID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get capacity utilization")
+	}
+	defer rows.Close()
+
+	summary := &repository.UtilizationSummary{}
+	for rows.Next() {
+		var eu repository.EventUtilization
+		if err := rows.Scan(&eu.EventID, &eu.PublicID, &eu.Name, &eu.Capacity, &eu.Sold); err != nil {
+			return nil, r.handleError(err, "failed to scan event utilization")
+		}
+
+		summary.TotalCapacity += eu.Capacity
+		summary.TotalSold += eu.Sold
+		if eu.Capacity == 0 {
+			continue
+		}
+
+		eu.UtilizationPct = float64(eu.Sold) / float64(eu.Capacity) * 100
+		if summary.Best == nil || eu.UtilizationPct > summary.Best.UtilizationPct {
+			best := eu
+			summary.Best = &best
+		}
+		if summary.Worst == nil || eu.UtilizationPct < summary.Worst.UtilizationPct {
+			worst := eu
+			summary.Worst = &worst
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, r.handleError(err, "failed to read event utilization")
+	}
+
+	if summary.TotalCapacity > 0 {
+		summary.UtilizationPct = float64(summary.TotalSold) / float64(summary.TotalCapacity) * 100
+	}
+
+	return summary, nil
+}
+
+// GetRevenueByCategory devuelve vendidos e ingresos (suma de final_price de los
+// tickets vendidos, transferidos o usados) por tipo de ticket del evento, de mayor
+// a menor ingreso, incluyendo los que no vendieron
+func (r *EventRepository) GetRevenueByCategory(ctx context.Context, eventID int64) ([]repository.CategoryRevenue, error) {
+	query := `
+		SELECT tt.id, tt.public_uuid, tt.name,
+			COUNT(t.id) AS sold_count,
+			COALESCE(SUM(t.final_price), 0) AS revenue
+		FROM ticketing.ticket_types tt
+		LEFT JOIN ticketing.tickets t
+			ON t.ticket_type_id = tt.id
+			AND t.status IN ('sold', 'transferred', 'checked_in')
+		WHERE tt.event_id = $1
+		GROUP BY tt.id, tt.public_uuid, tt.name
+		ORDER BY revenue DESC, tt.name, tt.id
+	`
+
+	rows, err := r.reader.query(ctx, query, eventID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get revenue by category")
+	}
+	defer rows.Close()
+
+	categories := []repository.CategoryRevenue{}
+	for rows.Next() {
+		var c repository.CategoryRevenue
+		if err := rows.Scan(&c.TicketTypeID, &c.PublicID, &c.Name, &c.SoldCount, &c.Revenue); err != nil {
+			return nil, r.handleError(err, "failed to scan category revenue")
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetPriceRange devuelve el precio final (con service fee e impuestos, igual que
+// TicketType.GetFinalPrice) más bajo y más alto entre las categorías activas del
+// evento. Sin categorías activas devuelve ceros.
+func (r *EventRepository) GetPriceRange(ctx context.Context, eventID int64) (float64, float64, error) {
+	query := `
+		WITH prices AS (
+			SELECT (
+				base_price + CASE service_fee_type
+					WHEN 'percentage' THEN base_price * service_fee_value
+					WHEN 'fixed' THEN service_fee_value
+					ELSE 0
+				END
+			) * (1 + tax_rate) AS final_price
+			FROM ticketing.ticket_types
+			WHERE event_id = $1 AND is_active = true
+		)
+		SELECT COALESCE(MIN(final_price), 0), COALESCE(MAX(final_price), 0)
+		FROM prices
+	`
+
+	var min, max float64
+	if err := r.reader.queryRow(ctx, query, eventID).Scan(&min, &max); err != nil {
+		return 0, 0, r.handleError(err, "failed to get price range")
+	}
+
+	return min, max, nil
+}
+
+// GetSettings devuelve la configuración tipada del evento; si la columna está
+// vacía devuelve entities.GetDefaultSettings()
+func (r *EventRepository) GetSettings(ctx context.Context, publicID string) (entities.EventSettings, error) {
+	var settingsJSON []byte
+	err := r.reader.queryRow(ctx, `SELECT settings FROM ticketing.events WHERE public_uuid = $1`, publicID).Scan(&settingsJSON)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entities.EventSettings{}, fmt.Errorf("%w: %s", repository.ErrEventNotFound, publicID)
+		}
+		return entities.EventSettings{}, r.handleError(err, "failed to get event settings")
+	}
+
+	settings := entities.GetDefaultSettings()
+	if len(settingsJSON) > 0 && string(settingsJSON) != "null" {
+		if err := json.Unmarshal(settingsJSON, &settings); err != nil {
+			return entities.EventSettings{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+		}
+	}
+
+	return settings, nil
+}
+
+// UpdateSettings valida y reemplaza la configuración completa del evento
+func (r *EventRepository) UpdateSettings(ctx context.Context, publicID string, settings entities.EventSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	cmdTag, err := r.db.Exec(ctx, `
+		UPDATE ticketing.events
+		SET settings = $1, updated_at = NOW()
+		WHERE public_uuid = $2
+	`, settingsJSON, publicID)
+	if err != nil {
+		return r.handleError(err, "failed to update event settings")
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", repository.ErrEventNotFound, publicID)
+	}
+
+	return nil
+}
+
+// JoinWaitlist agrega al cliente a la lista de espera; si ya está no hace nada
+func (r *EventRepository) JoinWaitlist(ctx context.Context, eventID, customerID int64) error {
+	query := `
+		INSERT INTO ticketing.event_waitlist (event_id, customer_id, joined_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (event_id, customer_id) DO NOTHING
+	`
+
+	if _, err := r.db.Exec(ctx, query, eventID, customerID); err != nil {
+		return r.handleError(err, "failed to join waitlist")
+	}
+	return nil
+}
+
+// LeaveWaitlist quita al cliente de la lista de espera
+func (r *EventRepository) LeaveWaitlist(ctx context.Context, eventID, customerID int64) error {
+	query := `
+		DELETE FROM ticketing.event_waitlist
+		WHERE event_id = $1 AND customer_id = $2
+	`
+
+	if _, err := r.db.Exec(ctx, query, eventID, customerID); err != nil {
+		return r.handleError(err, "failed to leave waitlist")
+	}
+	return nil
+}
+
+// GetWaitlist devuelve la lista de espera en orden de llegada
+func (r *EventRepository) GetWaitlist(ctx context.Context, eventID int64) ([]repository.WaitlistEntry, error) {
+	query := `
+		SELECT event_id, customer_id, joined_at, notified_at
+		FROM ticketing.event_waitlist
+		WHERE event_id = $1
+		ORDER BY joined_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, eventID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get waitlist")
+	}
+	defer rows.Close()
+
+	entries := []repository.WaitlistEntry{}
+	for rows.Next() {
+		var e repository.WaitlistEntry
+		if err := rows.Scan(&e.EventID, &e.CustomerID, &e.JoinedAt, &e.NotifiedAt); err != nil {
+			return nil, r.handleError(err, "failed to scan waitlist entry")
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// NotifyNextWaitlisted encola un aviso para el primer cliente aún no notificado y
+// lo marca como notificado. Se llama cuando se libera inventario (p. ej. una
+// cancelación). Devuelve nil si no queda nadie en espera.
+func (r *EventRepository) NotifyNextWaitlisted(ctx context.Context, eventID int64) (*repository.WaitlistEntry, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var entryID int64
+	var entry repository.WaitlistEntry
+	var email, fullName, eventName string
+	err = tx.QueryRow(ctx, `
+		SELECT w.id, w.event_id, w.customer_id, w.joined_at, c.email, c.full_name, e.name
+		FROM ticketing.event_waitlist w
+		JOIN crm.customers c ON c.id = w.customer_id
+		JOIN ticketing.events e ON e.id = w.event_id
+		WHERE w.event_id = $1 AND w.notified_at IS NULL
+		ORDER BY w.joined_at, w.id
+		LIMIT 1
+		FOR UPDATE OF w SKIP LOCKED
+	`, eventID).Scan(&entryID, &entry.EventID, &entry.CustomerID, &entry.JoinedAt, &email, &fullName, &eventName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, r.handleError(err, "failed to get next waitlisted customer")
+	}
+
+	contextData, err := json.Marshal(map[string]interface{}{
+		"event_id":    entry.EventID,
+		"customer_id": entry.CustomerID,
+		"reason":      "waitlist_spot_available",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification context: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO notifications.messages (
+			recipient_email, recipient_name, recipient_language,
+			subject, body, channel, status, context_data,
+			scheduled_for, created_at, updated_at
+		) VALUES ($1, $2, 'es', $3, $4, 'email', 'pending', $5, NOW(), NOW(), NOW())
+	`, email, fullName,
+		fmt.Sprintf("Hay boletos disponibles para %s", eventName),
+		fmt.Sprintf("Hola %s, se liberaron boletos para %s. Entra pronto para asegurar tu lugar.", fullName, eventName),
+		contextData)
+	if err != nil {
+		return nil, r.handleError(err, "failed to enqueue waitlist notification")
+	}
+
+	var notifiedAt time.Time
+	err = tx.QueryRow(ctx, `
+		UPDATE ticketing.event_waitlist
+		SET notified_at = NOW()
+		WHERE id = $1
+		RETURNING notified_at
+	`, entryID).Scan(&notifiedAt)
+	if err != nil {
+		return nil, r.handleError(err, "failed to mark waitlist entry as notified")
+	}
+	entry.NotifiedAt = &notifiedAt
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// RecountFavorites recalcula favorite_count de un evento desde ticketing.event_favorites
+func (r *EventRepository) RecountFavorites(ctx context.Context, eventID int64) error {
+	query := `
+		UPDATE ticketing.events e
+		SET favorite_count = (
+				SELECT COUNT(*) FROM ticketing.event_favorites f WHERE f.event_id = e.id
+			),
+			updated_at = NOW()
+		WHERE e.id = $1
+	`
+	cmdTag, err := r.db.Exec(ctx, query, eventID)
+	if err != nil {
+		return r.handleError(err, "failed to recount favorites")
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return repository.ErrEventNotFound
+	}
+	return nil
+}
+
+// RecountAllFavorites corrige favorite_count en todos los eventos desincronizados.
+// Devuelve cuántos eventos se actualizaron.
+func (r *EventRepository) RecountAllFavorites(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE ticketing.events e
+		SET favorite_count = calc.real_count,
+			updated_at = NOW()
+		FROM (
+			SELECT ev.id, COUNT(f.event_id) AS real_count
+			FROM ticketing.events ev
+			LEFT JOIN ticketing.event_favorites f ON f.event_id = ev.id
+			GROUP BY ev.id
+		) calc
+		WHERE e.id = calc.id
+		  AND e.favorite_count IS DISTINCT FROM calc.real_count
+	`
+	cmdTag, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return 0, r.handleError(err, "failed to recount all favorites")
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+// FindByIDsOrdered obtiene eventos respetando el orden de ids (p. ej. ranking de búsqueda).
+// Los ids inexistentes se omiten.
+func (r *EventRepository) FindByIDsOrdered(ctx context.Context, ids []int64) ([]*entities.Event, error) {
+	if len(ids) == 0 {
+		return []*entities.Event{}, nil
+	}
+
+	query := `
+		SELECT ` + eventSelectColumns + `
+		FROM ticketing.events
+		WHERE id = ANY($1)
+		ORDER BY array_position($1, id)
+	`
+
+	rows, err := r.reader.query(ctx, query, ids)
+	if err != nil {
+		return nil, r.handleError(err, "failed to find events by ids")
+	}
+	defer rows.Close()
+
+	return scanEventRows(rows)
+}
+
+// GetRelatedEvents recomienda eventos próximos que comparten categoría principal,
+// organizador o tags con el evento dado. Se ordenan por número de atributos en
+// común (cada tag cuenta por separado) y después por popularidad.
+func (r *EventRepository) GetRelatedEvents(ctx context.Context, eventID int64, limit int) ([]*entities.Event, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	query := `
+		WITH src AS (
+			SELECT id AS src_id,
+				primary_category_id AS src_category_id,
+				organizer_id AS src_organizer_id,
+				COALESCE(tags, '[]'::jsonb) AS src_tags
+			FROM ticketing.events
+			WHERE id = $1
+		)
+		SELECT ` + eventSelectColumns + `
+		FROM ticketing.events
+		CROSS JOIN src
+		CROSS JOIN LATERAL (
+			SELECT
+				(primary_category_id IS NOT DISTINCT FROM src_category_id AND primary_category_id IS NOT NULL)::int
+				+ (organizer_id = src_organizer_id)::int
+				+ (
+					SELECT COUNT(*)
+					FROM jsonb_array_elements_text(COALESCE(tags, '[]'::jsonb)) t(tag)
+					WHERE src_tags ? t.tag
+				)::int AS shared
+		) score
+		WHERE id <> src_id
+		  AND status = 'published'
+		  AND starts_at > NOW()
+		  AND score.shared > 0
+		ORDER BY score.shared DESC, favorite_count DESC, view_count DESC, starts_at, id
+		LIMIT $2
+	`
+
+	rows, err := r.reader.query(ctx, query, eventID, limit)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get related events")
+	}
+	defer rows.Close()
+
+	return scanEventRows(rows)
+}
+
+// eventSelectColumns lista las columnas de ticketing.events en el orden de scanEventRows
+const eventSelectColumns = `
+			id, public_uuid, organizer_id, primary_category_id, venue_id,
+			slug, name, short_description, description, event_type,
+			cover_image_url, banner_image_url, gallery_images,
+			timezone, starts_at, ends_at, doors_open_at, doors_close_at,
+			venue_name, address_full, city, state, country,
+			status, visibility, is_featured, is_free,
+			max_attendees, min_attendees, tags, age_restriction,
+			requires_approval, allow_reservations, reservation_duration_minutes,
+			view_count, favorite_count, share_count,
+			meta_title, meta_description, settings,
+			published_at, created_at, updated_at,
+			cancellation_reason`
+
+// scanEvent escanea una fila seleccionada con eventSelectColumns y deserializa
+// las columnas JSON (gallery_images, tags, settings)
+func scanEvent(row pgx.Row) (*entities.Event, error) {
+	var event entities.Event
+	var galleryImagesJSON, tagsJSON, settingsJSON []byte
+
+	err := row.Scan(
+		&event.ID, &event.PublicID, &event.OrganizerID, &event.PrimaryCategoryID, &event.VenueID,
+		&event.Slug, &event.Name, &event.ShortDescription, &event.Description, &event.EventType,
+		&event.CoverImageURL, &event.BannerImageURL, &galleryImagesJSON,
+		&event.Timezone, &event.StartsAt, &event.EndsAt, &event.DoorsOpenAt, &event.DoorsCloseAt,
+		&event.VenueName, &event.AddressFull, &event.City, &event.State, &event.Country,
+		&event.Status, &event.Visibility, &event.IsFeatured, &event.IsFree,
+		&event.MaxAttendees, &event.MinAttendees, &tagsJSON, &event.AgeRestriction,
+		&event.RequiresApproval, &event.AllowReservations, &event.ReservationDuration,
+		&event.ViewCount, &event.FavoriteCount, &event.ShareCount,
+		&event.MetaTitle, &event.MetaDescription, &settingsJSON,
+		&event.PublishedAt, &event.CreatedAt, &event.UpdatedAt,
+		&event.CancellationReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(galleryImagesJSON) > 0 {
+		if err := json.Unmarshal(galleryImagesJSON, &event.GalleryImages); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal gallery_images: %w", err)
+		}
+	}
+	if len(tagsJSON) > 0 {
+		if err := json.Unmarshal(tagsJSON, &event.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+	}
+	if len(settingsJSON) > 0 {
+		if err := json.Unmarshal(settingsJSON, &event.Settings); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+		}
+	}
+
+	return &event, nil
+}
+
+// scanEventRows escanea filas seleccionadas con eventSelectColumns
+func scanEventRows(rows pgx.Rows) ([]*entities.Event, error) {
+	events := []*entities.Event{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event row: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate event rows: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,1881 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+// setSoldAt fija la fecha de venta de un ticket sembrado
+func setSoldAt(t *testing.T, db testsupport.DB, ticketID int64, soldAt time.Time) {
+	t.Helper()
+	if _, err := db.Exec(context.Background(), `UPDATE ticketing.tickets SET sold_at = $1 WHERE id = $2`, soldAt, ticketID); err != nil {
+		t.Fatalf("failed to set sold_at: %v", err)
+	}
+}
+
+func TestEventRepositoryGetGlobalStatsQuarterRange(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	before, err := repo.GetGlobalStats(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetGlobalStats: %v", err)
+	}
+
+	eventID := seedEvent(t, tx, "Festival")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	q1 := time.Date(2031, 2, 10, 12, 0, 0, 0, time.UTC)
+	q3 := time.Date(2031, 8, 10, 12, 0, 0, 0, time.UTC)
+	setSoldAt(t, tx, testsupport.SeedTicket(t, tx, eventID, typeID, nil, "sold", 100), q1)
+	setSoldAt(t, tx, testsupport.SeedTicket(t, tx, eventID, typeID, nil, "checked_in", 150), q1)
+	setSoldAt(t, tx, testsupport.SeedTicket(t, tx, eventID, typeID, nil, "sold", 400), q3)
+
+	from := time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2031, 4, 1, 0, 0, 0, 0, time.UTC)
+	quarter, err := repo.GetGlobalStats(ctx, &from, &to)
+	if err != nil {
+		t.Fatalf("GetGlobalStats: %v", err)
+	}
+
+	if quarter.TotalTicketsSold != 2 || quarter.TotalRevenue != 250 {
+		t.Errorf("quarter = %d tickets / %.2f, want 2 / 250", quarter.TotalTicketsSold, quarter.TotalRevenue)
+	}
+	if got := quarter.LifetimeRevenue - before.LifetimeRevenue; got != 650 {
+		t.Errorf("lifetime revenue grew by %.2f, want 650", got)
+	}
+	if quarter.TotalRevenue == quarter.LifetimeRevenue {
+		t.Error("quarter-scoped revenue should differ from the lifetime figure")
+	}
+}
+
+// setEventTags reemplaza las tags JSON de un evento; nil las deja en NULL
+func setEventTags(t *testing.T, db testsupport.DB, eventID int64, tags []string) {
+	t.Helper()
+	if _, err := db.Exec(context.Background(), `UPDATE ticketing.events SET tags = to_jsonb($1::text[]) WHERE id = $2`, tags, eventID); err != nil {
+		t.Fatalf("failed to set tags: %v", err)
+	}
+}
+
+func TestEventRepositoryGetTagCloudRanksByFrequency(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	// Prefijo propio para no depender de otros eventos de la base de pruebas
+	const p = "tagcloud-test-"
+	setEventTags(t, tx, seedEvent(t, tx, "Rock"), []string{p + "music", p + "rock", p + "outdoor"})
+	setEventTags(t, tx, seedEvent(t, tx, "Jazz"), []string{p + "music", p + "jazz"})
+	setEventTags(t, tx, seedEvent(t, tx, "Picnic"), []string{p + "music", p + "outdoor"})
+	setEventTags(t, tx, seedEvent(t, tx, "Untagged"), nil)
+
+	draftID := seedEvent(t, tx, "Draft")
+	setEventTags(t, tx, draftID, []string{p + "jazz", p + "jazz-draft"})
+	testsupport.SetEventStatus(t, tx, draftID, "draft")
+
+	cloud, err := repo.GetTagCloud(ctx, 1000)
+	if err != nil {
+		t.Fatalf("GetTagCloud: %v", err)
+	}
+
+	var got []string
+	counts := map[string]int64{}
+	for _, tc := range cloud {
+		if tag, ok := strings.CutPrefix(tc.Tag, p); ok {
+			got = append(got, tag)
+			counts[tag] = tc.Count
+		}
+	}
+
+	want := []string{"music", "outdoor", "jazz", "rock"}
+	if len(got) != len(want) {
+		t.Fatalf("tags = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("tags = %v, want %v", got, want)
+		}
+	}
+	if counts["music"] != 3 || counts["outdoor"] != 2 || counts["jazz"] != 1 {
+		t.Errorf("counts = %v, want music=3 outdoor=2 jazz=1", counts)
+	}
+}
+
+func TestEventRepositoryGetTagCloudLimit(t *testing.T) {
+	tx := testsupport.Tx(t)
+	setEventTags(t, tx, seedEvent(t, tx, "Limited"), []string{"limit-a", "limit-b", "limit-c"})
+
+	cloud, err := postgres.NewEventRepository(tx).GetTagCloud(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetTagCloud: %v", err)
+	}
+	if len(cloud) != 2 {
+		t.Errorf("GetTagCloud(2) returned %d tags, want 2", len(cloud))
+	}
+}
+
+func eventStatus(t *testing.T, db testsupport.DB, eventID int64) string {
+	t.Helper()
+	var status string
+	if err := db.QueryRow(context.Background(), `SELECT status FROM ticketing.events WHERE id = $1`, eventID).Scan(&status); err != nil {
+		t.Fatalf("failed to read event status: %v", err)
+	}
+	return status
+}
+
+func TestEventRepositoryBulkUpdateStatusSkipsIllegalTransitions(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	published := seedEvent(t, tx, "Published")
+	live := seedEvent(t, tx, "Live")
+	testsupport.SetEventStatus(t, tx, live, "live")
+	draft := seedEvent(t, tx, "Draft")
+	testsupport.SetEventStatus(t, tx, draft, "draft")
+	cancelled := seedEvent(t, tx, "Cancelled")
+	testsupport.SetEventStatus(t, tx, cancelled, "cancelled")
+	const missing = int64(-1)
+
+	changed, skipped, err := repo.BulkUpdateStatus(ctx, []int64{published, draft, live, missing, cancelled}, "completed")
+	if err != nil {
+		t.Fatalf("BulkUpdateStatus: %v", err)
+	}
+
+	if changed != 2 {
+		t.Errorf("changed = %d, want 2", changed)
+	}
+	wantSkipped := []int64{draft, missing, cancelled}
+	if len(skipped) != len(wantSkipped) {
+		t.Fatalf("skipped = %v, want %v", skipped, wantSkipped)
+	}
+	for i := range wantSkipped {
+		if skipped[i] != wantSkipped[i] {
+			t.Fatalf("skipped = %v, want %v", skipped, wantSkipped)
+		}
+	}
+
+	for id, want := range map[int64]string{published: "completed", live: "completed", draft: "draft", cancelled: "cancelled"} {
+		if got := eventStatus(t, tx, id); got != want {
+			t.Errorf("event %d status = %q, want %q", id, got, want)
+		}
+	}
+
+	var history int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM ticketing.event_status_history
+		WHERE event_id = ANY($1) AND to_status = 'completed'
+	`, []int64{published, live, draft, cancelled}).Scan(&history); err != nil {
+		t.Fatalf("failed to count history: %v", err)
+	}
+	if history != 2 {
+		t.Errorf("history rows = %d, want 2", history)
+	}
+}
+
+func TestEventRepositoryBulkUpdateStatusInvalidTarget(t *testing.T) {
+	tx := testsupport.Tx(t)
+	eventID := seedEvent(t, tx, "Invalid")
+
+	_, _, err := postgres.NewEventRepository(tx).BulkUpdateStatus(context.Background(), []int64{eventID}, "finished")
+	var invalid *enums.InvalidEventStatusError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("error = %v, want InvalidEventStatusError", err)
+	}
+	if got := eventStatus(t, tx, eventID); got != "published" {
+		t.Errorf("status = %q, want published", got)
+	}
+}
+
+// seedFavorites marca el evento como favorito de n clientes nuevos
+func seedFavorites(t *testing.T, db testsupport.DB, eventID int64, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		customerID := testsupport.SeedCustomer(t, db, "Fan", fmt.Sprintf("fan-%d-%d@example.com", eventID, i))
+		if _, err := db.Exec(context.Background(), `
+			INSERT INTO ticketing.event_favorites (event_id, customer_id) VALUES ($1, $2)
+		`, eventID, customerID); err != nil {
+			t.Fatalf("failed to seed favorite: %v", err)
+		}
+	}
+}
+
+func favoriteCount(t *testing.T, db testsupport.DB, eventID int64) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(context.Background(), `SELECT favorite_count FROM ticketing.events WHERE id = $1`, eventID).Scan(&count); err != nil {
+		t.Fatalf("failed to read favorite_count: %v", err)
+	}
+	return count
+}
+
+func setFavoriteCount(t *testing.T, db testsupport.DB, eventID int64, count int) {
+	t.Helper()
+	if _, err := db.Exec(context.Background(), `UPDATE ticketing.events SET favorite_count = $1 WHERE id = $2`, count, eventID); err != nil {
+		t.Fatalf("failed to set favorite_count: %v", err)
+	}
+}
+
+func TestEventRepositoryRecountFavoritesFixesDrift(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Favorito")
+	seedFavorites(t, tx, eventID, 3)
+	setFavoriteCount(t, tx, eventID, 42)
+
+	if err := repo.RecountFavorites(ctx, eventID); err != nil {
+		t.Fatalf("RecountFavorites: %v", err)
+	}
+	if got := favoriteCount(t, tx, eventID); got != 3 {
+		t.Errorf("favorite_count = %d, want 3", got)
+	}
+
+	if err := repo.RecountFavorites(ctx, -1); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Errorf("RecountFavorites(missing) error = %v, want ErrEventNotFound", err)
+	}
+}
+
+func TestEventRepositoryRecountAllFavorites(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	// Se corrige cualquier desfase previo para medir sólo lo que siembra el test
+	if _, err := repo.RecountAllFavorites(ctx); err != nil {
+		t.Fatalf("RecountAllFavorites: %v", err)
+	}
+
+	drifted := seedEvent(t, tx, "Drifted")
+	seedFavorites(t, tx, drifted, 2)
+	setFavoriteCount(t, tx, drifted, 0)
+	orphanCount := seedEvent(t, tx, "Orphan count")
+	setFavoriteCount(t, tx, orphanCount, 7)
+	inSync := seedEvent(t, tx, "In sync")
+	seedFavorites(t, tx, inSync, 1)
+	setFavoriteCount(t, tx, inSync, 1)
+
+	updated, err := repo.RecountAllFavorites(ctx)
+	if err != nil {
+		t.Fatalf("RecountAllFavorites: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("updated = %d, want 2", updated)
+	}
+	for id, want := range map[int64]int{drifted: 2, orphanCount: 0, inSync: 1} {
+		if got := favoriteCount(t, tx, id); got != want {
+			t.Errorf("event %d favorite_count = %d, want %d", id, got, want)
+		}
+	}
+}
+
+func TestEventRepositoryGetUpcomingForCategory(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	organizerID := testsupport.SeedOrganizer(t, tx, "Categorías")
+	categoryID := testsupport.SeedCategory(t, tx, "Teatro")
+	otherCategoryID := testsupport.SeedCategory(t, tx, "Deportes")
+	now := time.Now()
+
+	later := testsupport.SeedEvent(t, tx, organizerID, "Primary later", now.Add(10*24*time.Hour))
+	sooner := testsupport.SeedEvent(t, tx, organizerID, "Secondary sooner", now.Add(5*24*time.Hour))
+	draft := testsupport.SeedEvent(t, tx, organizerID, "Draft", now.Add(3*24*time.Hour))
+	testsupport.SetEventStatus(t, tx, draft, "draft")
+	past := testsupport.SeedEvent(t, tx, organizerID, "Past", now.Add(-5*24*time.Hour))
+	other := testsupport.SeedEvent(t, tx, organizerID, "Other category", now.Add(2*24*time.Hour))
+
+	for eventID, category := range map[int64]int64{later: categoryID, draft: categoryID, past: categoryID, other: otherCategoryID} {
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET primary_category_id = $1 WHERE id = $2`, category, eventID); err != nil {
+			t.Fatalf("failed to set primary category: %v", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO ticketing.event_categories (event_id, category_id, is_primary) VALUES ($1, $2, false)
+	`, sooner, categoryID); err != nil {
+		t.Fatalf("failed to link secondary category: %v", err)
+	}
+
+	events, err := repo.GetUpcomingForCategory(ctx, testsupport.PublicID(t, tx, "ticketing.categories", categoryID), 10)
+	if err != nil {
+		t.Fatalf("GetUpcomingForCategory: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != sooner || events[1].ID != later {
+		ids := make([]int64, len(events))
+		for i, e := range events {
+			ids[i] = e.ID
+		}
+		t.Errorf("events = %v, want [%d %d]", ids, sooner, later)
+	}
+}
+
+func TestEventRepositoryGetUpcomingForCategoryNotFound(t *testing.T) {
+	_, err := postgres.NewEventRepository(testsupport.Tx(t)).GetUpcomingForCategory(context.Background(), "00000000-0000-0000-0000-000000000000", 10)
+	if !errors.Is(err, repository.ErrCategoryNotFound) {
+		t.Fatalf("error = %v, want ErrCategoryNotFound", err)
+	}
+}
+
+func TestEventRepositoryFindByIDsOrdered(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	first := seedEvent(t, tx, "First")
+	second := seedEvent(t, tx, "Second")
+	third := seedEvent(t, tx, "Third")
+	const missing = int64(-42)
+
+	events, err := repo.FindByIDsOrdered(ctx, []int64{third, missing, first, second})
+	if err != nil {
+		t.Fatalf("FindByIDsOrdered: %v", err)
+	}
+
+	want := []int64{third, first, second}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, id := range want {
+		if events[i].ID != id {
+			t.Errorf("events[%d] = %d, want %d", i, events[i].ID, id)
+		}
+	}
+
+	empty, err := repo.FindByIDsOrdered(ctx, nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("FindByIDsOrdered(nil) = %d events, %v; want none", len(empty), err)
+	}
+}
+
+func TestEventRepositoryCancelStoresReasonAndRestoreClearsIt(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+	eventID := seedEvent(t, tx, "Lluvia")
+
+	if err := repo.Cancel(ctx, eventID, "tormenta"); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	event, err := repo.GetByID(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if event.Status != "cancelled" || event.CancellationReason == nil || *event.CancellationReason != "tormenta" {
+		t.Fatalf("after Cancel = %s / %v, want cancelled / tormenta", event.Status, event.CancellationReason)
+	}
+
+	if err := repo.Restore(ctx, eventID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	event, err = repo.GetByID(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if event.Status != "draft" || event.CancellationReason != nil {
+		t.Errorf("after Restore = %s / %v, want draft without reason", event.Status, event.CancellationReason)
+	}
+}
+
+func TestEventRepositorySoftDeleteUsesDefaultReason(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+	eventID := seedEvent(t, tx, "Borrado")
+
+	if err := repo.SoftDelete(ctx, eventID); err != nil {
+		t.Fatalf("SoftDelete: %v", err)
+	}
+	event, err := repo.GetByID(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if event.CancellationReason == nil || *event.CancellationReason != postgres.DefaultSoftDeleteReason {
+		t.Errorf("reason = %v, want %q", event.CancellationReason, postgres.DefaultSoftDeleteReason)
+	}
+}
+
+func TestEventRepositoryCancelGuardsFinalStatuses(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	completed := seedEvent(t, tx, "Completado")
+	testsupport.SetEventStatus(t, tx, completed, "completed")
+	if err := repo.Cancel(ctx, completed, "tarde"); err == nil {
+		t.Error("Cancel on a completed event should fail")
+	}
+	if got := eventStatus(t, tx, completed); got != "completed" {
+		t.Errorf("completed event status = %q, want completed", got)
+	}
+
+	cancelled := seedEvent(t, tx, "Cancelado")
+	if err := repo.Cancel(ctx, cancelled, "original"); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if err := repo.Cancel(ctx, cancelled, "otra"); err == nil {
+		t.Error("second Cancel should fail")
+	}
+	event, err := repo.GetByID(ctx, cancelled)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if event.CancellationReason == nil || *event.CancellationReason != "original" {
+		t.Errorf("reason = %v, want the original reason kept", event.CancellationReason)
+	}
+
+	if err := repo.Cancel(ctx, -1, "nada"); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Errorf("Cancel(missing) error = %v, want ErrEventNotFound", err)
+	}
+}
+
+// seedAttendee siembra un cliente con país, segmento y vip, y un ticket suyo en status
+func seedAttendee(t *testing.T, db testsupport.DB, eventID, typeID int64, email, country, segment string, vip bool, status string) int64 {
+	t.Helper()
+	customerID := testsupport.SeedCustomer(t, db, "Attendee", email)
+	if _, err := db.Exec(context.Background(), `
+		UPDATE crm.customers SET country = $1, customer_segment = $2, is_vip = $3 WHERE id = $4
+	`, country, segment, vip, customerID); err != nil {
+		t.Fatalf("failed to set attendee attributes: %v", err)
+	}
+	testsupport.SeedTicket(t, db, eventID, typeID, &customerID, status, 100)
+	return customerID
+}
+
+func TestEventRepositoryGetAttendeeDemographics(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Demografía")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 20)
+
+	mx := seedAttendee(t, tx, eventID, typeID, "mx1@example.com", "MX", "regular", true, "sold")
+	testsupport.SeedTicket(t, tx, eventID, typeID, &mx, "sold", 100) // un cliente con dos tickets cuenta una vez
+	seedAttendee(t, tx, eventID, typeID, "mx2@example.com", "MX", "new", false, "checked_in")
+	seedAttendee(t, tx, eventID, typeID, "us1@example.com", "US", "regular", false, "sold")
+	seedAttendee(t, tx, eventID, typeID, "co1@example.com", "CO", "vip", true, "sold")
+	// No cuentan: cancelados, reembolsados o reservas sin pagar
+	seedAttendee(t, tx, eventID, typeID, "ar1@example.com", "AR", "new", false, "cancelled")
+	seedAttendee(t, tx, eventID, typeID, "ar2@example.com", "AR", "new", false, "refunded")
+	seedAttendee(t, tx, eventID, typeID, "ar3@example.com", "AR", "new", false, "reserved")
+
+	demographics, err := repo.GetAttendeeDemographics(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetAttendeeDemographics: %v", err)
+	}
+
+	if demographics.TotalAttendees != 4 {
+		t.Fatalf("TotalAttendees = %d, want 4", demographics.TotalAttendees)
+	}
+	if demographics.VIPCount != 2 || demographics.VIPPercentage != 50 {
+		t.Errorf("vip = %d / %.1f%%, want 2 / 50%%", demographics.VIPCount, demographics.VIPPercentage)
+	}
+
+	countries := map[string]repository.DemographicBucket{}
+	for _, b := range demographics.ByCountry {
+		countries[b.Key] = b
+	}
+	if len(countries) != 3 || countries["MX"].Count != 2 || countries["MX"].Percentage != 50 || countries["US"].Count != 1 {
+		t.Errorf("by country = %+v, want MX=2 (50%%), US=1, CO=1", demographics.ByCountry)
+	}
+	if demographics.ByCountry[0].Key != "MX" {
+		t.Errorf("largest country bucket = %q, want MX first", demographics.ByCountry[0].Key)
+	}
+
+	segments := map[string]int64{}
+	for _, b := range demographics.BySegment {
+		segments[b.Key] = b.Count
+	}
+	if segments["regular"] != 2 || segments["new"] != 1 || segments["vip"] != 1 {
+		t.Errorf("by segment = %+v, want regular=2 new=1 vip=1", demographics.BySegment)
+	}
+}
+
+func TestEventRepositoryGetSalesByHour(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Mapa de calor")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 20)
+	customerID := testsupport.SeedCustomer(t, tx, "Buyer", "heatmap@example.com")
+
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	sales := []struct {
+		hour   int
+		status string
+	}{
+		{9, "sold"}, {9, "checked_in"}, {14, "sold"}, {21, "sold"},
+		{14, "cancelled"}, // no cuenta como venta
+	}
+	for _, s := range sales {
+		ticketID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, s.status, 100)
+		createdAt := day.Add(time.Duration(s.hour)*time.Hour + 30*time.Minute)
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET created_at = $1 WHERE id = $2`, createdAt, ticketID); err != nil {
+			t.Fatalf("failed to set created_at: %v", err)
+		}
+	}
+
+	hours, err := repo.GetSalesByHour(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetSalesByHour: %v", err)
+	}
+	if len(hours) != 24 {
+		t.Fatalf("got %d hours, want 24", len(hours))
+	}
+
+	want := map[int]int64{9: 2, 14: 1, 21: 1}
+	for i, h := range hours {
+		if h.Hour != i {
+			t.Errorf("hours[%d].Hour = %d, want %d", i, h.Hour, i)
+		}
+		if h.TicketsSold != want[i] || h.Revenue != float64(want[i]*100) {
+			t.Errorf("hour %d = %d tickets / %.2f, want %d / %.2f", i, h.TicketsSold, h.Revenue, want[i], float64(want[i]*100))
+		}
+	}
+}
+
+func TestEventRepositoryFindBySlugOrPublicID(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Identificador")
+	publicID := testsupport.PublicID(t, tx, "ticketing.events", eventID)
+	var slug string
+	if err := tx.QueryRow(ctx, `SELECT slug FROM ticketing.events WHERE id = $1`, eventID).Scan(&slug); err != nil {
+		t.Fatalf("failed to read slug: %v", err)
+	}
+
+	for _, identifier := range []string{publicID, slug, " " + slug + " "} {
+		event, err := repo.FindBySlugOrPublicID(ctx, identifier)
+		if err != nil {
+			t.Errorf("FindBySlugOrPublicID(%q): %v", identifier, err)
+			continue
+		}
+		if event.ID != eventID {
+			t.Errorf("FindBySlugOrPublicID(%q) = event %d, want %d", identifier, event.ID, eventID)
+		}
+	}
+
+	for _, identifier := range []string{"", "no-such-event-slug", "00000000-0000-0000-0000-000000000000"} {
+		if _, err := repo.FindBySlugOrPublicID(ctx, identifier); !errors.Is(err, repository.ErrEventNotFound) {
+			t.Errorf("FindBySlugOrPublicID(%q) error = %v, want ErrEventNotFound", identifier, err)
+		}
+	}
+}
+
+func TestEventRepositoryGetEventsStartingBetween(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	from := time.Date(2090, 6, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	organizerID := testsupport.SeedOrganizer(t, tx, "Recordatorios")
+
+	first := testsupport.SeedEvent(t, tx, organizerID, "Dentro temprano", from)
+	second := testsupport.SeedEvent(t, tx, organizerID, "Dentro tarde", to.Add(-time.Minute))
+	before := testsupport.SeedEvent(t, tx, organizerID, "Antes", from.Add(-time.Minute))
+	atEnd := testsupport.SeedEvent(t, tx, organizerID, "Al cierre", to)
+	draft := testsupport.SeedEvent(t, tx, organizerID, "Borrador", from.Add(time.Hour))
+	testsupport.SetEventStatus(t, tx, draft, "draft")
+	cancelled := testsupport.SeedEvent(t, tx, organizerID, "Cancelado", from.Add(2*time.Hour))
+	testsupport.SetEventStatus(t, tx, cancelled, "cancelled")
+
+	events, err := repo.GetEventsStartingBetween(ctx, from, to)
+	if err != nil {
+		t.Fatalf("GetEventsStartingBetween: %v", err)
+	}
+
+	var got []int64
+	excluded := map[int64]bool{before: true, atEnd: true, draft: true, cancelled: true}
+	for _, e := range events {
+		if excluded[e.ID] {
+			t.Errorf("event %q returned, want excluded", e.Name)
+		}
+		if e.ID == first || e.ID == second {
+			got = append(got, e.ID)
+		}
+	}
+	if len(got) != 2 || got[0] != first || got[1] != second {
+		t.Errorf("window events = %v, want [%d %d] ordered by start", got, first, second)
+	}
+}
+
+func TestEventRepositoryGetUpcomingCountByVenue(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	busy := testsupport.SeedVenue(t, tx, "Foro lleno")
+	quiet := testsupport.SeedVenue(t, tx, "Foro tranquilo")
+	empty := testsupport.SeedVenue(t, tx, "Foro vacío")
+
+	atVenue := func(name string, venueID int64, startsIn time.Duration, status string) {
+		t.Helper()
+		eventID := seedEvent(t, tx, name)
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET venue_id = $1, starts_at = NOW() + $2::interval, status = $3 WHERE id = $4`,
+			venueID, fmt.Sprintf("%d seconds", int(startsIn.Seconds())), status, eventID); err != nil {
+			t.Fatalf("failed to place event at venue: %v", err)
+		}
+	}
+	atVenue("Uno", busy, 24*time.Hour, "published")
+	atVenue("Dos", busy, 48*time.Hour, "published")
+	atVenue("Tres", quiet, 24*time.Hour, "published")
+	// No cuentan: pasados o sin publicar
+	atVenue("Pasado", quiet, -24*time.Hour, "published")
+	atVenue("Borrador", quiet, 24*time.Hour, "draft")
+	atVenue("Borrador vacío", empty, 24*time.Hour, "draft")
+
+	counts, err := repo.GetUpcomingCountByVenue(ctx, []int64{busy, quiet, empty})
+	if err != nil {
+		t.Fatalf("GetUpcomingCountByVenue: %v", err)
+	}
+
+	want := map[int64]int64{busy: 2, quiet: 1, empty: 0}
+	if len(counts) != len(want) {
+		t.Errorf("got %d venues, want %d", len(counts), len(want))
+	}
+	for venueID, n := range want {
+		if got, ok := counts[venueID]; !ok || got != n {
+			t.Errorf("venue %d count = %d (present %v), want %d", venueID, got, ok, n)
+		}
+	}
+
+	if counts, err := repo.GetUpcomingCountByVenue(ctx, nil); err != nil || len(counts) != 0 {
+		t.Errorf("GetUpcomingCountByVenue(nil) = %v, %v; want empty map", counts, err)
+	}
+}
+
+func TestEventRepositoryUpdateCountersClampsAtZero(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Contadores")
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET view_count = 10, share_count = 2, favorite_count = 1 WHERE id = $1`, eventID); err != nil {
+		t.Fatalf("failed to set counters: %v", err)
+	}
+
+	if err := repo.UpdateCounters(ctx, eventID, 5, -1, -3); err != nil {
+		t.Fatalf("UpdateCounters: %v", err)
+	}
+
+	var views, shares, favorites int64
+	if err := tx.QueryRow(ctx, `SELECT view_count, share_count, favorite_count FROM ticketing.events WHERE id = $1`, eventID).
+		Scan(&views, &shares, &favorites); err != nil {
+		t.Fatalf("failed to read counters: %v", err)
+	}
+	if views != 15 || shares != 1 || favorites != 0 {
+		t.Errorf("counters = %d / %d / %d, want 15 / 1 / 0", views, shares, favorites)
+	}
+
+	if err := repo.UpdateCounters(ctx, -eventID, 1, 0, 0); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Errorf("UpdateCounters(missing) error = %v, want ErrEventNotFound", err)
+	}
+	if err := repo.UpdateCounters(ctx, -eventID, 0, 0, 0); err != nil {
+		t.Errorf("UpdateCounters(no deltas) error = %v, want nil", err)
+	}
+}
+
+func TestEventRepositoryListFeaturedPages(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	// Sólo cuentan los destacados sembrados aquí; el UPDATE se revierte con el test
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET is_featured = false WHERE is_featured`); err != nil {
+		t.Fatalf("failed to unfeature events: %v", err)
+	}
+
+	feature := func(eventID int64) {
+		t.Helper()
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET is_featured = true WHERE id = $1`, eventID); err != nil {
+			t.Fatalf("failed to feature event: %v", err)
+		}
+	}
+	want := map[int64]bool{}
+	for i := 0; i < 5; i++ {
+		eventID := seedEvent(t, tx, fmt.Sprintf("Destacado %d", i))
+		feature(eventID)
+		want[eventID] = true
+	}
+	draft := seedEvent(t, tx, "Destacado borrador")
+	feature(draft)
+	testsupport.SetEventStatus(t, tx, draft, "draft")
+	past := testsupport.SeedEvent(t, tx, testsupport.SeedOrganizer(t, tx, "Pasado"), "Destacado pasado", time.Now().Add(-48*time.Hour))
+	feature(past)
+	seedEvent(t, tx, "Sin destacar")
+
+	seen := map[int64]bool{}
+	for page := 1; page <= 3; page++ {
+		events, total, err := repo.ListFeatured(ctx, commondto.Pagination{Page: page, PageSize: 2})
+		if err != nil {
+			t.Fatalf("ListFeatured(page %d): %v", page, err)
+		}
+		if total != 5 {
+			t.Errorf("page %d total = %d, want 5", page, total)
+		}
+		if wantLen := map[int]int{1: 2, 2: 2, 3: 1}[page]; len(events) != wantLen {
+			t.Errorf("page %d has %d events, want %d", page, len(events), wantLen)
+		}
+		for _, e := range events {
+			if !want[e.ID] || seen[e.ID] {
+				t.Errorf("page %d returned unexpected or repeated event %q", page, e.Name)
+			}
+			seen[e.ID] = true
+		}
+	}
+	if len(seen) != len(want) {
+		t.Errorf("paged through %d featured events, want %d", len(seen), len(want))
+	}
+}
+
+func TestEventRepositoryListMaxPage(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewEventRepository(testsupport.Tx(t))
+	repo.SetMaxPage(3)
+
+	if _, _, err := repo.ListFeatured(ctx, commondto.Pagination{Page: 3, PageSize: 10}); err != nil {
+		t.Errorf("ListFeatured(last allowed page): %v", err)
+	}
+	if _, _, err := repo.ListFeatured(ctx, commondto.Pagination{Page: 4, PageSize: 10}); !errors.Is(err, commondto.ErrValidation) {
+		t.Errorf("ListFeatured(page 4) error = %v, want ErrValidation", err)
+	}
+	if _, _, err := repo.List(ctx, map[string]interface{}{}, 10, 30); !errors.Is(err, commondto.ErrValidation) {
+		t.Errorf("List(offset 30) error = %v, want ErrValidation", err)
+	}
+	// limit 0 no desactiva el límite: se toma el tamaño de página por defecto
+	if _, _, err := repo.List(ctx, map[string]interface{}{}, 0, 1_000_000); !errors.Is(err, commondto.ErrValidation) {
+		t.Errorf("List(limit 0, deep offset) error = %v, want ErrValidation", err)
+	}
+}
+
+func TestEventRepositoryGetStaleDrafts(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	withUpdatedAt := func(name, status string, age time.Duration) int64 {
+		t.Helper()
+		eventID := seedEvent(t, tx, name)
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET status = $1, updated_at = $2 WHERE id = $3`,
+			status, time.Now().Add(-age), eventID); err != nil {
+			t.Fatalf("failed to age event: %v", err)
+		}
+		return eventID
+	}
+	stale := withUpdatedAt("Borrador viejo", "draft", 60*24*time.Hour)
+	recent := withUpdatedAt("Borrador reciente", "draft", time.Hour)
+	oldPublished := withUpdatedAt("Publicado viejo", "published", 60*24*time.Hour)
+
+	drafts, err := repo.GetStaleDrafts(ctx, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetStaleDrafts: %v", err)
+	}
+
+	found := false
+	for _, e := range drafts {
+		if e.Status != "draft" {
+			t.Errorf("event %q has status %s, want only drafts", e.Name, e.Status)
+		}
+		switch e.ID {
+		case stale:
+			found = true
+		case recent, oldPublished:
+			t.Errorf("event %q returned, want excluded", e.Name)
+		}
+	}
+	if !found {
+		t.Error("stale draft not returned")
+	}
+}
+
+func TestEventRepositoryGetEventDetail(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Detalle")
+	typeWith := func(name string, price float64, benefits string, active, hidden bool) int64 {
+		t.Helper()
+		typeID := testsupport.SeedTicketType(t, tx, eventID, name, price, 10)
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.ticket_types SET benefits = $1::jsonb, is_active = $2, is_hidden = $3 WHERE id = $4`,
+			benefits, active, hidden, typeID); err != nil {
+			t.Fatalf("failed to set ticket type details: %v", err)
+		}
+		return typeID
+	}
+	vip := typeWith("VIP", 900, `["Acceso preferente", "Bebida de cortesía"]`, true, false)
+	general := typeWith("General", 300, `[]`, true, false)
+	typeWith("Oculto", 100, `["Secreto"]`, true, true)
+	typeWith("Inactivo", 50, `["Viejo"]`, false, false)
+
+	detail, err := repo.GetEventDetail(ctx, testsupport.PublicID(t, tx, "ticketing.events", eventID))
+	if err != nil {
+		t.Fatalf("GetEventDetail: %v", err)
+	}
+	if detail.Event.ID != eventID {
+		t.Errorf("event = %d, want %d", detail.Event.ID, eventID)
+	}
+	if len(detail.Categories) != 2 {
+		t.Fatalf("got %d categories, want 2 (hidden and inactive excluded)", len(detail.Categories))
+	}
+
+	// Ordenadas por precio
+	if detail.Categories[0].ID != general || detail.Categories[1].ID != vip {
+		t.Errorf("categories = %d, %d; want general then vip", detail.Categories[0].ID, detail.Categories[1].ID)
+	}
+	if got := detail.Categories[1].Benefits; len(got) != 2 || got[0] != "Acceso preferente" || got[1] != "Bebida de cortesía" {
+		t.Errorf("vip benefits = %v", got)
+	}
+	if got := detail.Categories[0].Benefits; got == nil || len(got) != 0 {
+		t.Errorf("general benefits = %#v, want empty slice", got)
+	}
+
+	if _, err := repo.GetEventDetail(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Errorf("GetEventDetail(missing) error = %v, want ErrEventNotFound", err)
+	}
+}
+
+func TestEventRepositoryGetRelatedEvents(t *testing.T) {
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	const p = "related-test-"
+	startsAt := time.Now().Add(30 * 24 * time.Hour)
+	organizerID := testsupport.SeedOrganizer(t, tx, "Organizer Related")
+	sourceID := testsupport.SeedEvent(t, tx, organizerID, "Source", startsAt)
+	setEventTags(t, tx, sourceID, []string{p + "rock", p + "outdoor"})
+
+	// Mismo organizador y dos tags: 3 atributos en común
+	closest := testsupport.SeedEvent(t, tx, organizerID, "Closest", startsAt)
+	setEventTags(t, tx, closest, []string{p + "rock", p + "outdoor"})
+
+	// Una tag en común; la popularidad desempata
+	popular := seedEvent(t, tx, "Popular")
+	setEventTags(t, tx, popular, []string{p + "rock"})
+	setFavoriteCount(t, tx, popular, 50)
+	quiet := seedEvent(t, tx, "Quiet")
+	setEventTags(t, tx, quiet, []string{p + "outdoor"})
+
+	unrelated := seedEvent(t, tx, "Unrelated")
+	setEventTags(t, tx, unrelated, []string{p + "jazz"})
+	draft := testsupport.SeedEvent(t, tx, organizerID, "Draft", startsAt)
+	setEventTags(t, tx, draft, []string{p + "rock"})
+	testsupport.SetEventStatus(t, tx, draft, "draft")
+
+	events, err := repo.GetRelatedEvents(context.Background(), sourceID, 10)
+	if err != nil {
+		t.Fatalf("GetRelatedEvents: %v", err)
+	}
+
+	var got []int64
+	for _, e := range events {
+		got = append(got, e.ID)
+	}
+	want := []int64{closest, popular, quiet}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("related = %v, want %v (closest, popular, quiet)", got, want)
+	}
+
+	limited, err := repo.GetRelatedEvents(context.Background(), sourceID, 1)
+	if err != nil {
+		t.Fatalf("GetRelatedEvents(limit 1): %v", err)
+	}
+	if len(limited) != 1 || limited[0].ID != closest {
+		t.Errorf("GetRelatedEvents(limit 1) = %d events, want only the closest", len(limited))
+	}
+}
+
+func TestEventRepositoryGetOccupancyForecast(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	now := time.Now()
+	sell := func(eventID, typeID int64, n int, soldAt time.Time) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			setSoldAt(t, tx, testsupport.SeedTicket(t, tx, eventID, typeID, nil, "sold", 100), soldAt)
+		}
+	}
+
+	t.Run("projects from recent velocity", func(t *testing.T) {
+		eventID := seedEvent(t, tx, "Forecast")
+		typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 100)
+		// 14 ventas en la ventana de 7 días (2/día) y 6 antiguas que no cuentan
+		sell(eventID, typeID, 7, now.Add(-24*time.Hour))
+		sell(eventID, typeID, 7, now.Add(-5*24*time.Hour))
+		sell(eventID, typeID, 6, now.Add(-20*24*time.Hour))
+
+		forecast, err := repo.GetOccupancyForecast(ctx, eventID)
+		if err != nil {
+			t.Fatalf("GetOccupancyForecast: %v", err)
+		}
+		if forecast.Capacity != 100 || forecast.Sold != 20 || forecast.Occupancy != 20 {
+			t.Errorf("capacity/sold/occupancy = %d/%d/%.1f, want 100/20/20.0", forecast.Capacity, forecast.Sold, forecast.Occupancy)
+		}
+		if forecast.VelocityPerDay != 2 {
+			t.Errorf("velocity = %.2f, want 2", forecast.VelocityPerDay)
+		}
+		// 80 restantes a 2/día: 40 días
+		want := now.Add(40 * 24 * time.Hour)
+		if forecast.ProjectedSellOutAt == nil || forecast.ProjectedSellOutAt.Sub(want).Abs() > time.Minute {
+			t.Errorf("projected sell-out = %v, want about %v", forecast.ProjectedSellOutAt, want)
+		}
+	})
+
+	t.Run("zero velocity has no projection", func(t *testing.T) {
+		eventID := seedEvent(t, tx, "Stalled")
+		typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 50)
+		sell(eventID, typeID, 5, now.Add(-30*24*time.Hour))
+
+		forecast, err := repo.GetOccupancyForecast(ctx, eventID)
+		if err != nil {
+			t.Fatalf("GetOccupancyForecast: %v", err)
+		}
+		if forecast.VelocityPerDay != 0 || forecast.ProjectedSellOutAt != nil {
+			t.Errorf("velocity = %.2f, projection = %v; want 0 and nil", forecast.VelocityPerDay, forecast.ProjectedSellOutAt)
+		}
+		if forecast.Occupancy != 10 {
+			t.Errorf("occupancy = %.1f, want 10.0", forecast.Occupancy)
+		}
+	})
+
+	t.Run("sold out projects now", func(t *testing.T) {
+		eventID := seedEvent(t, tx, "Sold Out")
+		typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 3)
+		sell(eventID, typeID, 3, now.Add(-time.Hour))
+
+		forecast, err := repo.GetOccupancyForecast(ctx, eventID)
+		if err != nil {
+			t.Fatalf("GetOccupancyForecast: %v", err)
+		}
+		if forecast.Occupancy != 100 || forecast.ProjectedSellOutAt == nil || forecast.ProjectedSellOutAt.Sub(now).Abs() > time.Minute {
+			t.Errorf("occupancy = %.1f, projection = %v; want 100 and now", forecast.Occupancy, forecast.ProjectedSellOutAt)
+		}
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		if _, err := repo.GetOccupancyForecast(ctx, -1); !errors.Is(err, repository.ErrEventNotFound) {
+			t.Errorf("err = %v, want ErrEventNotFound", err)
+		}
+	})
+}
+
+func TestEventRepositoryGetSalesChannelBreakdown(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Channels")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 50)
+	customerID := testsupport.SeedCustomer(t, tx, "Buyer", "channels-buyer@example.com")
+	orderID := testsupport.SeedOrder(t, tx, customerID, "channels-buyer@example.com", 500, "completed")
+
+	// Back-office: emitidos sin orden
+	testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "sold", 100)
+	testsupport.SeedTicket(t, tx, eventID, typeID, nil, "checked_in", 150)
+	// Autoservicio: creados por el checkout de una orden
+	for _, price := range []float64{200, 300} {
+		ticketID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "sold", price)
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET order_id = $1 WHERE id = $2`, orderID, ticketID); err != nil {
+			t.Fatalf("failed to attach order: %v", err)
+		}
+	}
+	// Reservados y cancelados no son ventas
+	testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "reserved", 999)
+	testsupport.SeedTicket(t, tx, eventID, typeID, nil, "cancelled", 999)
+
+	stats, err := repo.GetSalesChannelBreakdown(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetSalesChannelBreakdown: %v", err)
+	}
+	if stats.BackOfficeTickets != 2 || stats.BackOfficeRevenue != 250 {
+		t.Errorf("back-office = %d tickets / %.2f, want 2 / 250", stats.BackOfficeTickets, stats.BackOfficeRevenue)
+	}
+	if stats.GuestTickets != 2 || stats.GuestRevenue != 500 {
+		t.Errorf("self-service = %d tickets / %.2f, want 2 / 500", stats.GuestTickets, stats.GuestRevenue)
+	}
+}
+
+func TestEventRepositoryGetPublishHistory(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Publish History")
+	testsupport.SetEventStatus(t, tx, eventID, "draft")
+	publicID := testsupport.PublicID(t, tx, "ticketing.events", eventID)
+
+	if err := repo.Publish(ctx, eventID, time.Now(), "user-a"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := repo.Unpublish(ctx, eventID, "user-b"); err != nil {
+		t.Fatalf("Unpublish: %v", err)
+	}
+	if err := repo.Publish(ctx, eventID, time.Now(), ""); err != nil {
+		t.Fatalf("second Publish: %v", err)
+	}
+	if got := eventStatus(t, tx, eventID); got != "published" {
+		t.Errorf("status = %s, want published", got)
+	}
+
+	history, err := repo.GetPublishHistory(ctx, publicID)
+	if err != nil {
+		t.Fatalf("GetPublishHistory: %v", err)
+	}
+
+	want := []struct{ action, from, to, actor string }{
+		{"publish", "draft", "published", "user-a"},
+		{"unpublish", "published", "draft", "user-b"},
+		{"publish", "draft", "published", ""},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("history has %d entries, want %d", len(history), len(want))
+	}
+	for i, w := range want {
+		h := history[i]
+		actor := ""
+		if h.Actor != nil {
+			actor = *h.Actor
+		}
+		if h.Action != w.action || h.FromStatus != w.from || h.ToStatus != w.to || actor != w.actor {
+			t.Errorf("entry %d = %s %s->%s by %q, want %s %s->%s by %q",
+				i, h.Action, h.FromStatus, h.ToStatus, actor, w.action, w.from, w.to, w.actor)
+		}
+	}
+
+	// Unpublish sobre un borrador no es una transición válida y no deja historial
+	testsupport.SetEventStatus(t, tx, eventID, "draft")
+	if err := repo.Unpublish(ctx, eventID, "user-c"); err == nil {
+		t.Error("Unpublish of a draft succeeded, want error")
+	}
+}
+
+// setJoinedAt fija la hora de llegada de una entrada de la lista de espera
+func setJoinedAt(t *testing.T, db testsupport.DB, eventID, customerID int64, joinedAt time.Time) {
+	t.Helper()
+	_, err := db.Exec(context.Background(), `
+		UPDATE ticketing.event_waitlist SET joined_at = $3 WHERE event_id = $1 AND customer_id = $2
+	`, eventID, customerID, joinedAt)
+	if err != nil {
+		t.Fatalf("failed to set joined_at: %v", err)
+	}
+}
+
+func TestEventRepositoryJoinWaitlistIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Waitlist Idempotent")
+	customerID := testsupport.SeedCustomer(t, tx, "Ana", "waitlist-ana@example.com")
+
+	for i := 0; i < 2; i++ {
+		if err := repo.JoinWaitlist(ctx, eventID, customerID); err != nil {
+			t.Fatalf("JoinWaitlist #%d: %v", i+1, err)
+		}
+	}
+
+	entries, err := repo.GetWaitlist(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetWaitlist: %v", err)
+	}
+	if len(entries) != 1 || entries[0].CustomerID != customerID {
+		t.Fatalf("waitlist = %+v, want a single entry for customer %d", entries, customerID)
+	}
+
+	if err := repo.LeaveWaitlist(ctx, eventID, customerID); err != nil {
+		t.Fatalf("LeaveWaitlist: %v", err)
+	}
+	if entries, _ := repo.GetWaitlist(ctx, eventID); len(entries) != 0 {
+		t.Errorf("waitlist after leaving = %+v, want empty", entries)
+	}
+}
+
+func TestEventRepositoryGetWaitlistOrdersByJoinTime(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Waitlist Order")
+	first := testsupport.SeedCustomer(t, tx, "First", "waitlist-first@example.com")
+	second := testsupport.SeedCustomer(t, tx, "Second", "waitlist-second@example.com")
+	third := testsupport.SeedCustomer(t, tx, "Third", "waitlist-third@example.com")
+
+	// Se insertan en otro orden que el de llegada: manda joined_at, no el id
+	base := time.Now().Add(-time.Hour)
+	for _, c := range []struct {
+		id  int64
+		off time.Duration
+	}{{third, 2 * time.Minute}, {first, 0}, {second, time.Minute}} {
+		if err := repo.JoinWaitlist(ctx, eventID, c.id); err != nil {
+			t.Fatalf("JoinWaitlist: %v", err)
+		}
+		setJoinedAt(t, tx, eventID, c.id, base.Add(c.off))
+	}
+
+	entries, err := repo.GetWaitlist(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetWaitlist: %v", err)
+	}
+	want := []int64{first, second, third}
+	if len(entries) != len(want) {
+		t.Fatalf("waitlist has %d entries, want %d", len(entries), len(want))
+	}
+	for i, id := range want {
+		if entries[i].CustomerID != id {
+			t.Errorf("entry %d = customer %d, want %d", i, entries[i].CustomerID, id)
+		}
+	}
+}
+
+func TestEventRepositoryNotifyNextWaitlisted(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Waitlist Notify")
+	early := testsupport.SeedCustomer(t, tx, "Early", "waitlist-early@example.com")
+	late := testsupport.SeedCustomer(t, tx, "Late", "waitlist-late@example.com")
+	base := time.Now().Add(-time.Hour)
+	for i, id := range []int64{late, early} {
+		if err := repo.JoinWaitlist(ctx, eventID, id); err != nil {
+			t.Fatalf("JoinWaitlist: %v", err)
+		}
+		setJoinedAt(t, tx, eventID, id, base.Add(-time.Duration(i)*time.Minute))
+	}
+
+	// Se libera un lugar: se avisa al primero en llegar
+	entry, err := repo.NotifyNextWaitlisted(ctx, eventID)
+	if err != nil {
+		t.Fatalf("NotifyNextWaitlisted: %v", err)
+	}
+	if entry == nil || entry.CustomerID != early || entry.NotifiedAt == nil {
+		t.Fatalf("notified = %+v, want customer %d stamped as notified", entry, early)
+	}
+
+	var queued int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM notifications.messages
+		WHERE recipient_email = 'waitlist-early@example.com' AND status = 'pending'
+		  AND (context_data->>'event_id')::bigint = $1
+	`, eventID).Scan(&queued)
+	if err != nil {
+		t.Fatalf("failed to count queued messages: %v", err)
+	}
+	if queued != 1 {
+		t.Errorf("queued %d messages for the earliest entry, want 1", queued)
+	}
+
+	// El siguiente aviso va al siguiente de la lista, no repite
+	entry, err = repo.NotifyNextWaitlisted(ctx, eventID)
+	if err != nil {
+		t.Fatalf("second NotifyNextWaitlisted: %v", err)
+	}
+	if entry == nil || entry.CustomerID != late {
+		t.Fatalf("second notified = %+v, want customer %d", entry, late)
+	}
+
+	entry, err = repo.NotifyNextWaitlisted(ctx, eventID)
+	if err != nil || entry != nil {
+		t.Errorf("NotifyNextWaitlisted with nobody left = %+v, %v; want nil, nil", entry, err)
+	}
+}
+
+func TestEventRepositoryGetBusiestDays(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	organizerID := testsupport.SeedOrganizer(t, tx, "Busy Organizer")
+	otherID := testsupport.SeedOrganizer(t, tx, "Other Organizer")
+	peak := time.Date(2090, 3, 10, 18, 0, 0, 0, time.UTC)
+	second := time.Date(2090, 3, 20, 18, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		testsupport.SeedEvent(t, tx, organizerID, fmt.Sprintf("Peak %d", i), peak.Add(time.Duration(i)*time.Hour))
+	}
+	testsupport.SeedEvent(t, tx, organizerID, "Second", second)
+	// Un festival de dos días también ocupa el día siguiente
+	festival := testsupport.SeedEvent(t, tx, organizerID, "Festival", second.Add(-24*time.Hour))
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET ends_at = $1 WHERE id = $2`, second.Add(2*time.Hour), festival); err != nil {
+		t.Fatalf("failed to extend festival: %v", err)
+	}
+	// Borradores y eventos de otro organizador no cuentan
+	for i := 0; i < 4; i++ {
+		draft := testsupport.SeedEvent(t, tx, organizerID, fmt.Sprintf("Draft %d", i), second)
+		testsupport.SetEventStatus(t, tx, draft, "draft")
+		testsupport.SeedEvent(t, tx, otherID, fmt.Sprintf("Other %d", i), second)
+	}
+
+	days, err := repo.GetBusiestDays(ctx, testsupport.PublicID(t, tx, "ticketing.organizers", organizerID), 2)
+	if err != nil {
+		t.Fatalf("GetBusiestDays: %v", err)
+	}
+
+	want := []struct {
+		day   string
+		count int64
+	}{{"2090-03-10", 3}, {"2090-03-20", 2}}
+	if len(days) != len(want) {
+		t.Fatalf("got %d days, want %d: %+v", len(days), len(want), days)
+	}
+	for i, w := range want {
+		if got := days[i].Day.Format("2006-01-02"); got != w.day || days[i].EventCount != w.count {
+			t.Errorf("day %d = %s with %d events, want %s with %d", i, got, days[i].EventCount, w.day, w.count)
+		}
+	}
+}
+
+func TestEventRepositoryGetRevenueByCategory(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Revenue")
+	vip := testsupport.SeedTicketType(t, tx, eventID, "VIP", 500, 20)
+	general := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 100)
+	balcony := testsupport.SeedTicketType(t, tx, eventID, "Balcony", 80, 50)
+	seed := func(eventID, typeID int64, status string, price float64, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			testsupport.SeedTicket(t, tx, eventID, typeID, nil, status, price)
+		}
+	}
+	// Se suma el final_price de cada ticket, no base_price * sold_quantity
+	seed(eventID, vip, "sold", 500, 2)
+	seed(eventID, vip, "checked_in", 450, 1) // 1450
+	seed(eventID, general, "sold", 100, 15)
+	seed(eventID, general, "transferred", 100, 3)
+	seed(eventID, general, "checked_in", 90, 2) // 2000
+	// Reservados, reembolsados y anulados no cuentan
+	seed(eventID, general, "reserved", 100, 5)
+	seed(eventID, vip, "refunded", 500, 4)
+	seed(eventID, vip, "voided", 500, 1)
+	// Los contadores del tipo no se usan
+	setTypeQuantities(t, tx, balcony, 10, 0)
+	// Balcony sin ventas también aparece
+
+	// Otro evento no se mezcla
+	otherID := seedEvent(t, tx, "Revenue Other")
+	seed(otherID, testsupport.SeedTicketType(t, tx, otherID, "General", 1000, 100), "sold", 1000, 5)
+
+	rows, err := repo.GetRevenueByCategory(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetRevenueByCategory: %v", err)
+	}
+
+	want := []struct {
+		id      int64
+		sold    int64
+		revenue float64
+	}{{general, 20, 2000}, {vip, 3, 1450}, {balcony, 0, 0}}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d categories, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i, w := range want {
+		if rows[i].TicketTypeID != w.id || rows[i].SoldCount != w.sold || rows[i].Revenue != w.revenue {
+			t.Errorf("row %d = %+v, want type %d with %d sold / %.2f", i, rows[i], w.id, w.sold, w.revenue)
+		}
+	}
+}
+
+func TestEventRepositoryBulkCancelByOrganizer(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	organizerID := testsupport.SeedOrganizer(t, tx, "Suspended Organizer")
+	startsAt := time.Now().Add(30 * 24 * time.Hour)
+	seeded := map[string]int64{}
+	for _, status := range []string{"draft", "published", "sold_out", "cancelled", "completed"} {
+		id := testsupport.SeedEvent(t, tx, organizerID, "Suspended "+status, startsAt)
+		testsupport.SetEventStatus(t, tx, id, status)
+		seeded[status] = id
+	}
+	otherEvent := seedEvent(t, tx, "Unrelated")
+
+	count, err := repo.BulkCancelByOrganizer(ctx, testsupport.PublicID(t, tx, "ticketing.organizers", organizerID), "organizer suspended")
+	if err != nil {
+		t.Fatalf("BulkCancelByOrganizer: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("cancelled %d events, want 3", count)
+	}
+
+	for status, id := range seeded {
+		want := "cancelled"
+		if status == "completed" {
+			want = "completed"
+		}
+		if got := eventStatus(t, tx, id); got != want {
+			t.Errorf("%s event status = %s, want %s", status, got, want)
+		}
+
+		var reason *string
+		var history int
+		err := tx.QueryRow(ctx, `
+			SELECT e.cancellation_reason,
+				(SELECT COUNT(*) FROM ticketing.event_status_history h WHERE h.event_id = e.id AND h.to_status = 'cancelled')
+			FROM ticketing.events e WHERE e.id = $1
+		`, id).Scan(&reason, &history)
+		if err != nil {
+			t.Fatalf("failed to read cancellation of %s event: %v", status, err)
+		}
+		// Los ya cancelados o terminados se saltan: ni motivo nuevo ni historial
+		eligible := status != "cancelled" && status != "completed"
+		if eligible && (reason == nil || *reason != "organizer suspended" || history != 1) {
+			t.Errorf("%s event reason = %v, history = %d; want the reason and one history row", status, reason, history)
+		}
+		if !eligible && history != 0 {
+			t.Errorf("%s event got %d cancellation history rows, want 0", status, history)
+		}
+	}
+	if got := eventStatus(t, tx, otherEvent); got != "published" {
+		t.Errorf("other organizer's event status = %s, want published", got)
+	}
+
+	_, err = repo.BulkCancelByOrganizer(ctx, "00000000-0000-0000-0000-000000000000", "x")
+	if !errors.Is(err, repository.ErrOrganizerNotFound) {
+		t.Errorf("missing organizer error = %v, want ErrOrganizerNotFound", err)
+	}
+}
+
+// seedStatusChange inserta un cambio de estado con changed_at explícito y devuelve su id
+func seedStatusChange(t *testing.T, db testsupport.DB, eventID int64, from, to string, changedAt time.Time) int64 {
+	t.Helper()
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO ticketing.event_status_history (event_id, from_status, to_status, changed_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, eventID, from, to, changedAt).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed status change: %v", err)
+	}
+	return id
+}
+
+func TestEventRepositoryGetStatusChangesSincePagesByCursor(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventA := seedEvent(t, tx, "Status Feed A")
+	eventB := seedEvent(t, tx, "Status Feed B")
+	base := time.Date(1990, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	before := seedStatusChange(t, tx, eventA, "draft", "published", base.Add(-time.Hour))
+	// Dos cambios con el mismo changed_at: el id desempata y ninguno se pierde entre páginas
+	want := []int64{
+		seedStatusChange(t, tx, eventA, "published", "sold_out", base),
+		seedStatusChange(t, tx, eventB, "draft", "published", base),
+		seedStatusChange(t, tx, eventB, "published", "cancelled", base.Add(time.Minute)),
+		seedStatusChange(t, tx, eventA, "sold_out", "completed", base.Add(2*time.Minute)),
+	}
+	// Un cambio recién escrito queda dentro del margen de seguridad
+	recent := seedStatusChange(t, tx, eventA, "completed", "archived", time.Now())
+
+	cursor := repository.StatusChangeCursor{ChangedAt: base.Add(-time.Hour), ID: before}
+	var got []int64
+	for page := 0; page < 10; page++ {
+		changes, err := repo.GetStatusChangesSince(ctx, cursor, 3)
+		if err != nil {
+			t.Fatalf("GetStatusChangesSince: %v", err)
+		}
+		for _, c := range changes {
+			if c.EventID != eventA && c.EventID != eventB {
+				continue
+			}
+			if c.ID == recent {
+				t.Errorf("change %d inside the safety lag was returned", c.ID)
+			}
+			got = append(got, c.ID)
+		}
+		if len(changes) < 3 {
+			break
+		}
+		cursor = changes[len(changes)-1].Cursor()
+	}
+
+	if len(got) < len(want) {
+		t.Fatalf("got changes %v, want %v first", got, want)
+	}
+	for i, id := range want {
+		if got[i] != id {
+			t.Errorf("change %d = %d, want %d (full feed %v)", i, got[i], id, got)
+		}
+	}
+	for _, id := range got {
+		if id == before {
+			t.Errorf("change %d at the cursor was returned again", before)
+		}
+	}
+
+	first, err := repo.GetStatusChangesSince(ctx, repository.StatusChangeCursor{ChangedAt: base.Add(-time.Hour), ID: before}, 1)
+	if err != nil {
+		t.Fatalf("GetStatusChangesSince(limit 1): %v", err)
+	}
+	if len(first) != 1 || first[0].ID != want[0] || first[0].FromStatus != "published" || first[0].ToStatus != "sold_out" {
+		t.Errorf("first page = %+v, want only change %d published->sold_out", first, want[0])
+	}
+}
+
+func TestEventRepositoryGetPriceRange(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Price Range")
+	testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	vip := testsupport.SeedTicketType(t, tx, eventID, "VIP", 200, 10)
+	early := testsupport.SeedTicketType(t, tx, eventID, "Early", 80, 10)
+	retired := testsupport.SeedTicketType(t, tx, eventID, "Retired", 5, 10)
+	for _, q := range []struct {
+		sql string
+		id  int64
+	}{
+		// VIP: (200 + 20 fijo) * 1.16 = 255.2
+		{`UPDATE ticketing.ticket_types SET service_fee_type = 'fixed', service_fee_value = 20, tax_rate = 0.16 WHERE id = $1`, vip},
+		// Early: (80 + 80 * 0.1) * 1 = 88
+		{`UPDATE ticketing.ticket_types SET service_fee_type = 'percentage', service_fee_value = 0.1 WHERE id = $1`, early},
+		// Las categorías inactivas no cuentan aunque sean las más baratas
+		{`UPDATE ticketing.ticket_types SET is_active = false WHERE id = $1`, retired},
+	} {
+		if _, err := tx.Exec(ctx, q.sql, q.id); err != nil {
+			t.Fatalf("failed to configure ticket type: %v", err)
+		}
+	}
+
+	min, max, err := repo.GetPriceRange(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetPriceRange: %v", err)
+	}
+	if math.Abs(min-88) > 0.001 || math.Abs(max-255.2) > 0.001 {
+		t.Errorf("price range = %.2f-%.2f, want 88.00-255.20", min, max)
+	}
+
+	emptyID := seedEvent(t, tx, "No Prices")
+	min, max, err = repo.GetPriceRange(ctx, emptyID)
+	if err != nil || min != 0 || max != 0 {
+		t.Errorf("price range without categories = %.2f-%.2f, %v; want zeros", min, max, err)
+	}
+}
+
+func TestEventRepositorySettingsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Settings")
+	publicID := testsupport.PublicID(t, tx, "ticketing.events", eventID)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET settings = '{}'::jsonb WHERE id = $1`, eventID); err != nil {
+		t.Fatalf("failed to clear settings: %v", err)
+	}
+
+	// Sin configuración guardada se devuelven los valores por defecto
+	got, err := repo.GetSettings(ctx, publicID)
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if !reflect.DeepEqual(got, entities.GetDefaultSettings()) {
+		t.Errorf("settings without a stored value = %+v, want the defaults", got)
+	}
+
+	settings := entities.GetDefaultSettings()
+	settings.AllowWaitlist = true
+	settings.ShowRemainingCount = true
+	settings.RefundPolicy = enums.RefundPolicyStrict
+	settings.CheckInWindowMinutes = 90
+	settings.TicketCodePrefix = "ACME"
+	if err := repo.UpdateSettings(ctx, publicID, settings); err != nil {
+		t.Fatalf("UpdateSettings: %v", err)
+	}
+	got, err = repo.GetSettings(ctx, publicID)
+	if err != nil {
+		t.Fatalf("GetSettings after update: %v", err)
+	}
+	if !reflect.DeepEqual(got, settings) {
+		t.Errorf("round-tripped settings = %+v, want %+v", got, settings)
+	}
+
+	invalid := settings
+	invalid.RefundPolicy = "whenever"
+	if err := repo.UpdateSettings(ctx, publicID, invalid); err == nil {
+		t.Error("UpdateSettings with an invalid refund policy succeeded")
+	}
+	if got, _ := repo.GetSettings(ctx, publicID); got.RefundPolicy != enums.RefundPolicyStrict {
+		t.Errorf("refund policy after rejected update = %q, want strict", got.RefundPolicy)
+	}
+
+	missing := "00000000-0000-0000-0000-000000000000"
+	if err := repo.UpdateSettings(ctx, missing, settings); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Errorf("UpdateSettings(missing) error = %v, want ErrEventNotFound", err)
+	}
+	if _, err := repo.GetSettings(ctx, missing); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Errorf("GetSettings(missing) error = %v, want ErrEventNotFound", err)
+	}
+}
+
+func TestEventRepositoryScansJSONColumns(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "JSON Columns")
+	setEventTags(t, tx, eventID, []string{"jazz", "outdoor"})
+	_, err := tx.Exec(ctx, `
+		UPDATE ticketing.events
+		SET gallery_images = '["https://cdn.example/a.jpg", "https://cdn.example/b.jpg"]'::jsonb,
+			settings = '{"allow_waitlist": true, "check_in_window_minutes": 45, "refund_policy": "strict"}'::jsonb
+		WHERE id = $1
+	`, eventID)
+	if err != nil {
+		t.Fatalf("failed to set JSON columns: %v", err)
+	}
+
+	byID, err := repo.GetByID(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	byPublicID, err := repo.GetByPublicID(ctx, byID.PublicID)
+	if err != nil {
+		t.Fatalf("GetByPublicID: %v", err)
+	}
+
+	for name, event := range map[string]*entities.Event{"GetByID": byID, "GetByPublicID": byPublicID} {
+		if event.Tags == nil || !reflect.DeepEqual(*event.Tags, []string{"jazz", "outdoor"}) {
+			t.Errorf("%s tags = %v, want [jazz outdoor]", name, event.Tags)
+		}
+		wantGallery := []string{"https://cdn.example/a.jpg", "https://cdn.example/b.jpg"}
+		if event.GalleryImages == nil || !reflect.DeepEqual(*event.GalleryImages, wantGallery) {
+			t.Errorf("%s gallery = %v, want %v", name, event.GalleryImages, wantGallery)
+		}
+		if event.Settings == nil || !event.Settings.AllowWaitlist || event.Settings.CheckInWindowMinutes != 45 ||
+			event.Settings.RefundPolicy != enums.RefundPolicyStrict {
+			t.Errorf("%s settings = %+v, want the stored settings", name, event.Settings)
+		}
+		if event.Name != "JSON Columns" || event.Status != "published" {
+			t.Errorf("%s scanned name/status = %q/%q, columns out of order", name, event.Name, event.Status)
+		}
+	}
+}
+
+func eventIDs(events []*entities.Event) []int64 {
+	ids := make([]int64, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestEventRepositoryScopedListingsIsolateByID(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	startsAt := time.Now().Add(30 * 24 * time.Hour)
+	orgA := testsupport.SeedOrganizer(t, tx, "Organizer Aislado A")
+	orgB := testsupport.SeedOrganizer(t, tx, "Organizer Aislado B")
+	venueA := testsupport.SeedVenue(t, tx, "Recinto Aislado A")
+	venueB := testsupport.SeedVenue(t, tx, "Recinto Aislado B")
+	catA := testsupport.SeedCategory(t, tx, "Categoria Aislada A")
+	catB := testsupport.SeedCategory(t, tx, "Categoria Aislada B")
+
+	eventA := testsupport.SeedEvent(t, tx, orgA, "Evento A", startsAt)
+	eventB := testsupport.SeedEvent(t, tx, orgB, "Evento B", startsAt)
+	for _, e := range []struct{ event, venue, category int64 }{{eventA, venueA, catA}, {eventB, venueB, catB}} {
+		if _, err := tx.Exec(ctx, `
+			UPDATE ticketing.events SET venue_id = $1, primary_category_id = $2 WHERE id = $3
+		`, e.venue, e.category, e.event); err != nil {
+			t.Fatalf("failed to assign venue and category: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		list func() ([]*entities.Event, int64, error)
+		want int64
+	}{
+		{"organizer A", func() ([]*entities.Event, int64, error) { return repo.ListByOrganizer(ctx, orgA, 50, 0) }, eventA},
+		{"organizer B", func() ([]*entities.Event, int64, error) { return repo.ListByOrganizer(ctx, orgB, 50, 0) }, eventB},
+		{"venue A", func() ([]*entities.Event, int64, error) { return repo.ListByVenue(ctx, venueA, 50, 0) }, eventA},
+		{"venue B", func() ([]*entities.Event, int64, error) { return repo.ListByVenue(ctx, venueB, 50, 0) }, eventB},
+		{"category A", func() ([]*entities.Event, int64, error) { return repo.ListByCategory(ctx, catA, 50, 0) }, eventA},
+		{"category B", func() ([]*entities.Event, int64, error) { return repo.ListByCategory(ctx, catB, 50, 0) }, eventB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, total, err := tt.list()
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if got := eventIDs(events); total != 1 || !reflect.DeepEqual(got, []int64{tt.want}) {
+				t.Errorf("events = %v (total %d), want only %d", got, total, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventRepositoryGetMonthlyEventCounts(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	organizerID := testsupport.SeedOrganizer(t, tx, "Monthly Organizer")
+	otherID := testsupport.SeedOrganizer(t, tx, "Monthly Other")
+	at := func(month time.Month, day int) time.Time { return time.Date(2091, month, day, 20, 0, 0, 0, time.UTC) }
+
+	testsupport.SeedEvent(t, tx, organizerID, "Enero 1", at(time.January, 5))
+	testsupport.SeedEvent(t, tx, organizerID, "Enero 2", at(time.January, 25))
+	testsupport.SeedEvent(t, tx, organizerID, "Marzo", at(time.March, 12))
+	testsupport.SeedEvent(t, tx, organizerID, "Diciembre", at(time.December, 31))
+	// Fuera del año, de otro organizador, borradores y cancelados no cuentan
+	testsupport.SeedEvent(t, tx, organizerID, "Año anterior", time.Date(2090, time.December, 15, 20, 0, 0, 0, time.UTC))
+	testsupport.SeedEvent(t, tx, otherID, "Otro organizador", at(time.February, 1))
+	testsupport.SetEventStatus(t, tx, testsupport.SeedEvent(t, tx, organizerID, "Borrador", at(time.April, 1)), "draft")
+	testsupport.SetEventStatus(t, tx, testsupport.SeedEvent(t, tx, organizerID, "Cancelado", at(time.May, 1)), "cancelled")
+	// 1 de febrero 03:00 UTC sigue siendo enero en la zona horaria del evento
+	localJanuary := testsupport.SeedEvent(t, tx, organizerID, "Enero local", time.Date(2091, time.February, 1, 3, 0, 0, 0, time.UTC))
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET timezone = 'America/Mexico_City' WHERE id = $1`, localJanuary); err != nil {
+		t.Fatalf("failed to set timezone: %v", err)
+	}
+
+	months, err := repo.GetMonthlyEventCounts(ctx, testsupport.PublicID(t, tx, "ticketing.organizers", organizerID), 2091)
+	if err != nil {
+		t.Fatalf("GetMonthlyEventCounts: %v", err)
+	}
+	want := map[int]int64{1: 3, 3: 1, 12: 1}
+	if len(months) != 12 {
+		t.Fatalf("got %d months, want 12", len(months))
+	}
+	for i, mc := range months {
+		if mc.Month != i+1 {
+			t.Errorf("entry %d month = %d, want %d", i, mc.Month, i+1)
+		}
+		if mc.EventCount != want[i+1] {
+			t.Errorf("month %d count = %d, want %d", i+1, mc.EventCount, want[i+1])
+		}
+	}
+}
+
+func TestEventRepositoryGetCapacityUtilization(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	organizerID := testsupport.SeedOrganizer(t, tx, "Utilization Organizer")
+	startsAt := time.Date(2091, time.June, 1, 20, 0, 0, 0, time.UTC)
+	sell := func(eventID, typeID int64, n int) {
+		for i := 0; i < n; i++ {
+			testsupport.SeedTicket(t, tx, eventID, typeID, nil, "sold", 100)
+		}
+	}
+
+	// 8 de 10 vendidos: 80%
+	busy := testsupport.SeedEvent(t, tx, organizerID, "Casi lleno", startsAt)
+	sell(busy, testsupport.SeedTicketType(t, tx, busy, "General", 100, 10), 8)
+
+	// 5 de 20 vendidos entre dos tipos: 25%; el tipo inactivo no suma capacidad
+	quiet := testsupport.SeedEvent(t, tx, organizerID, "Tranquilo", startsAt)
+	sell(quiet, testsupport.SeedTicketType(t, tx, quiet, "General", 100, 15), 4)
+	sell(quiet, testsupport.SeedTicketType(t, tx, quiet, "VIP", 300, 5), 1)
+	inactive := testsupport.SeedTicketType(t, tx, quiet, "Retirado", 50, 100)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.ticket_types SET is_active = false WHERE id = $1`, inactive); err != nil {
+		t.Fatalf("failed to deactivate ticket type: %v", err)
+	}
+
+	// Sin capacidad: suma al total pero no compite por mejor/peor
+	testsupport.SeedEvent(t, tx, organizerID, "Sin boletos", startsAt)
+
+	// Los borradores no cuentan
+	draft := testsupport.SeedEvent(t, tx, organizerID, "Borrador", startsAt)
+	sell(draft, testsupport.SeedTicketType(t, tx, draft, "General", 100, 10), 10)
+	testsupport.SetEventStatus(t, tx, draft, "draft")
+
+	summary, err := repo.GetCapacityUtilization(ctx, testsupport.PublicID(t, tx, "ticketing.organizers", organizerID))
+	if err != nil {
+		t.Fatalf("GetCapacityUtilization: %v", err)
+	}
+	if summary.TotalCapacity != 30 || summary.TotalSold != 13 {
+		t.Errorf("totals = %d capacity, %d sold; want 30, 13", summary.TotalCapacity, summary.TotalSold)
+	}
+	if want := 13.0 / 30.0 * 100; math.Abs(summary.UtilizationPct-want) > 0.001 {
+		t.Errorf("utilization = %.3f, want %.3f", summary.UtilizationPct, want)
+	}
+	if summary.Best == nil || summary.Best.EventID != busy || summary.Best.UtilizationPct != 80 {
+		t.Errorf("best = %+v, want event %d at 80%%", summary.Best, busy)
+	}
+	if summary.Worst == nil || summary.Worst.EventID != quiet || summary.Worst.UtilizationPct != 25 {
+		t.Errorf("worst = %+v, want event %d at 25%%", summary.Worst, quiet)
+	}
+
+	// Un organizador sin capacidad no divide entre cero
+	emptyID := testsupport.SeedOrganizer(t, tx, "Utilization Empty")
+	testsupport.SeedEvent(t, tx, emptyID, "Sin boletos", startsAt)
+	empty, err := repo.GetCapacityUtilization(ctx, testsupport.PublicID(t, tx, "ticketing.organizers", emptyID))
+	if err != nil {
+		t.Fatalf("GetCapacityUtilization (empty): %v", err)
+	}
+	if empty.TotalCapacity != 0 || empty.UtilizationPct != 0 || empty.Best != nil || empty.Worst != nil {
+		t.Errorf("empty summary = %+v, want zero values", empty)
+	}
+}
+
+func TestEventRepositoryGetReservationStats(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	eventID := seedEvent(t, tx, "Reservas")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 20)
+	reserved := make([]int64, 6)
+	for i := range reserved {
+		reserved[i] = testsupport.SeedTicket(t, tx, eventID, typeID, nil, "reserved", 100)
+	}
+	// 3 compradas, 1 vencida, 1 liberada y 1 abierta
+	resolve := func(id int64, status string) {
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET status = $2 WHERE id = $1`, id, status); err != nil {
+			t.Fatalf("failed to move ticket to %s: %v", status, err)
+		}
+	}
+	resolve(reserved[0], "sold")
+	resolve(reserved[1], "sold")
+	resolve(reserved[2], "sold")
+	resolve(reserved[3], "expired")
+	resolve(reserved[4], "available")
+	// Una venta directa no es una reserva
+	testsupport.SeedTicket(t, tx, eventID, typeID, nil, "sold", 100)
+	// Las reservas de otro evento no cuentan
+	otherID := seedEvent(t, tx, "Otras reservas")
+	testsupport.SeedTicket(t, tx, otherID, testsupport.SeedTicketType(t, tx, otherID, "General", 100, 5), nil, "reserved", 100)
+
+	stats, err := repo.GetReservationStats(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetReservationStats: %v", err)
+	}
+	want := repository.ReservationStats{Made: 6, Converted: 3, Abandoned: 2, Pending: 1, ConversionRate: 60}
+	if *stats != want {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+
+	// Sin reservas resueltas la tasa queda en cero
+	stats, err = repo.GetReservationStats(ctx, otherID)
+	if err != nil {
+		t.Fatalf("GetReservationStats (pending only): %v", err)
+	}
+	if stats.Made != 1 || stats.Pending != 1 || stats.ConversionRate != 0 {
+		t.Errorf("pending-only stats = %+v, want 1 made, 1 pending, rate 0", *stats)
+	}
+}
+
+func TestEventRepositoryListFiltersByEventType(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	organizerID := testsupport.SeedOrganizer(t, tx, "Event Type Organizer")
+	startsAt := time.Date(2091, time.July, 1, 20, 0, 0, 0, time.UTC)
+	concert := testsupport.SeedEvent(t, tx, organizerID, "Concierto", startsAt)
+	conference := testsupport.SeedEvent(t, tx, organizerID, "Congreso", startsAt)
+	for id, eventType := range map[int64]string{concert: "concert", conference: "conference"} {
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET event_type = $2 WHERE id = $1`, id, eventType); err != nil {
+			t.Fatalf("failed to set event type: %v", err)
+		}
+	}
+
+	events, _, err := repo.List(ctx, map[string]interface{}{"organizer_id": organizerID, "event_type": "concert"}, 10, 0)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := eventIDs(events); len(got) != 1 || got[0] != concert {
+		t.Errorf("events = %v, want only %d", got, concert)
+	}
+}
+
+func TestEventRepositoryGetSoldOutEventsOrdersByTimeToSellOut(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewEventRepository(tx)
+
+	base := time.Date(2091, 3, 1, 12, 0, 0, 0, time.UTC)
+	from, to := base, base.Add(30*24*time.Hour)
+
+	slow := seedEvent(t, tx, "Sold Out Slow")
+	seedStatusChange(t, tx, slow, "draft", "published", base)
+	seedStatusChange(t, tx, slow, "published", "sold_out", base.Add(3*time.Hour))
+
+	fast := seedEvent(t, tx, "Sold Out Fast")
+	seedStatusChange(t, tx, fast, "draft", "published", base)
+	seedStatusChange(t, tx, fast, "published", "sold_out", base.Add(time.Hour))
+	// Solo cuenta el primer agotamiento
+	seedStatusChange(t, tx, fast, "sold_out", "published", base.Add(2*time.Hour))
+	seedStatusChange(t, tx, fast, "published", "sold_out", base.Add(10*time.Hour))
+
+	// Se mide desde la última publicación anterior al agotamiento
+	republished := seedEvent(t, tx, "Sold Out Republished")
+	seedStatusChange(t, tx, republished, "draft", "published", base.Add(-10*24*time.Hour))
+	seedStatusChange(t, tx, republished, "published", "draft", base.Add(-9*24*time.Hour))
+	seedStatusChange(t, tx, republished, "draft", "published", base.Add(2*time.Hour))
+	seedStatusChange(t, tx, republished, "published", "sold_out", base.Add(4*time.Hour))
+
+	// Sin historial de publicación se usa events.published_at
+	fallback := seedEvent(t, tx, "Sold Out Fallback")
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET published_at = $1 WHERE id = $2`, base, fallback); err != nil {
+		t.Fatalf("failed to set published_at: %v", err)
+	}
+	seedStatusChange(t, tx, fallback, "published", "sold_out", base.Add(30*time.Minute))
+
+	outside := seedEvent(t, tx, "Sold Out Outside")
+	seedStatusChange(t, tx, outside, "draft", "published", base.Add(-48*time.Hour))
+	seedStatusChange(t, tx, outside, "published", "sold_out", base.Add(-time.Hour))
+
+	events, err := repo.GetSoldOutEvents(ctx, from, to)
+	if err != nil {
+		t.Fatalf("GetSoldOutEvents: %v", err)
+	}
+
+	seeded := map[int64]bool{slow: true, fast: true, republished: true, fallback: true, outside: true}
+	var got []int64
+	took := map[int64]time.Duration{}
+	for _, e := range events {
+		if seeded[e.EventID] {
+			got = append(got, e.EventID)
+			took[e.EventID] = e.TimeToSellOut
+		}
+	}
+	want := []int64{fallback, fast, republished, slow}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("events = %v, want %v", got, want)
+			break
+		}
+	}
+	for id, d := range map[int64]time.Duration{fallback: 30 * time.Minute, fast: time.Hour, republished: 2 * time.Hour, slow: 3 * time.Hour} {
+		if took[id] != d {
+			t.Errorf("event %d time to sell out = %v, want %v", id, took[id], d)
+		}
+	}
+}
@@ -18,6 +18,7 @@ type QueryBuilder struct {
 	distinct   bool
 	limit      int
 	offset     int
+	seekColumn string
 }
 
 func NewQueryBuilder(baseQuery string) *QueryBuilder {
@@ -90,6 +91,24 @@ func (qb *QueryBuilder) WhereIn(field string, values []interface{}) *QueryBuilde
 	return qb
 }
 
+// WhereInLower añade WHERE IN sin distinguir mayúsculas: lower(campo) IN (lower($n), ...)
+func (qb *QueryBuilder) WhereInLower(field string, values []interface{}) *QueryBuilder {
+	if len(values) == 0 {
+		return qb.WhereRaw("1 = 0")
+	}
+
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = fmt.Sprintf("lower($%d)", qb.argCounter)
+		qb.args = append(qb.args, values[i])
+		qb.argCounter++
+	}
+
+	condition := fmt.Sprintf("lower(%s) IN (%s)", field, strings.Join(placeholders, ", "))
+	qb.conditions = append(qb.conditions, condition)
+	return qb
+}
+
 // WhereLike añade condición WHERE LIKE
 func (qb *QueryBuilder) WhereLike(field, value string, caseSensitive bool) *QueryBuilder {
 	operator := "LIKE"
@@ -170,6 +189,34 @@ func (qb *QueryBuilder) Offset(offset int) *QueryBuilder {
 	return qb
 }
 
+// SeekAfter activa paginación por keyset sobre column: añade "column > ?" con
+// value y ordena por column ascendente. Con value nil (primera página) no se
+// añade el predicado. column debe ser única (p. ej. el id): si tiene valores
+// repetidos, las filas que comparten el valor del cursor con la última fila
+// devuelta se saltan en la página siguiente.
+func (qb *QueryBuilder) SeekAfter(column string, value interface{}) *QueryBuilder {
+	qb.seekColumn = column
+	if value != nil {
+		qb.Where(column+" > ?", value)
+	}
+	return qb.OrderBy(column, false)
+}
+
+// BuildSeek construye la query de una página por keyset. Pide una fila más que
+// Limit para saber si hay otra página (ver SeekPage) e ignora Offset. Sin
+// SeekAfter equivale a Build.
+func (qb *QueryBuilder) BuildSeek() (string, []interface{}) {
+	if qb.seekColumn == "" {
+		return qb.Build()
+	}
+	page := qb.Clone()
+	page.offset = -1
+	if page.limit >= 0 {
+		page.limit++
+	}
+	return page.Build()
+}
+
 // Build construye la query completa
 func (qb *QueryBuilder) Build() (string, []interface{}) {
 	var query strings.Builder
@@ -213,41 +260,49 @@ func (qb *QueryBuilder) Build() (string, []interface{}) {
 	return query.String(), qb.args
 }
 
-// BuildCount construye query COUNT
+// Clone devuelve una copia independiente del builder: modificar la copia (más
+// filtros, orden, paginación) no afecta al original. Permite armar los filtros
+// base una vez y derivar de ellos la query paginada y la de conteo.
+func (qb *QueryBuilder) Clone() *QueryBuilder {
+	clone := &QueryBuilder{
+		args:       append([]interface{}(nil), qb.args...),
+		argCounter: qb.argCounter,
+		conditions: append([]string(nil), qb.conditions...),
+		joins:      append([]string(nil), qb.joins...),
+		orderBy:    append([]string(nil), qb.orderBy...),
+		groupBy:    append([]string(nil), qb.groupBy...),
+		having:     append([]string(nil), qb.having...),
+		distinct:   qb.distinct,
+		limit:      qb.limit,
+		offset:     qb.offset,
+		seekColumn: qb.seekColumn,
+	}
+	// strings.Builder no se puede copiar por valor
+	clone.query.WriteString(qb.query.String())
+	return clone
+}
+
+// BuildCount construye query COUNT con los mismos JOINs y filtros que Build,
+// sin ORDER BY, LIMIT ni OFFSET. No modifica el builder.
 func (qb *QueryBuilder) BuildCount() (string, []interface{}) {
-	queryStr := qb.query.String()
+	base := qb.Clone()
+	base.orderBy = nil
+	base.limit = -1
+	base.offset = -1
+	queryStr, args := base.Build()
+
+	// Con agrupación el conteo debe hacerse sobre las filas agrupadas
+	if len(qb.groupBy) > 0 || len(qb.having) > 0 || qb.distinct {
+		return "SELECT COUNT(*) FROM (" + queryStr + ") AS count_query", args
+	}
 
 	// Encontrar la posición de FROM
 	fromIndex := strings.Index(strings.ToUpper(queryStr), " FROM ")
 	if fromIndex == -1 {
-		return "SELECT COUNT(*) FROM (" + queryStr + ") AS count_query", qb.args
+		return "SELECT COUNT(*) FROM (" + queryStr + ") AS count_query", args
 	}
 
-	// Construir query COUNT
-	countQuery := "SELECT COUNT(*) " + queryStr[fromIndex:]
-
-	// Quitar ORDER BY, LIMIT, OFFSET para COUNT
-	countQuery = removeClause(countQuery, "ORDER BY")
-	countQuery = removeClause(countQuery, "LIMIT")
-	countQuery = removeClause(countQuery, "OFFSET")
-
-	return countQuery, qb.args
-}
-
-// removeClause remueve una cláusula de la query
-func removeClause(query, clause string) string {
-	upperQuery := strings.ToUpper(query)
-	upperClause := strings.ToUpper(clause)
-
-	if idx := strings.Index(upperQuery, " "+upperClause); idx != -1 {
-		// Encontrar el final de la cláusula
-		endIdx := strings.Index(upperQuery[idx+1:], " ")
-		if endIdx == -1 {
-			return query[:idx]
-		}
-		return query[:idx] + query[idx+endIdx+2:]
-	}
-	return query
+	return "SELECT COUNT(*)" + queryStr[fromIndex:], args
 }
 
 // Reset resetea el builder
@@ -263,4 +318,5 @@ func (qb *QueryBuilder) Reset() {
 	qb.distinct = false
 	qb.limit = -1
 	qb.offset = -1
+	qb.seekColumn = ""
 }
@@ -0,0 +1,188 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhereInLower(t *testing.T) {
+	qb := NewQueryBuilder("SELECT id FROM ticketing.events").
+		Where("organizer_id = ?", 7).
+		WhereInLower("status", []interface{}{"PUBLISHED", "Draft"})
+
+	sql, args := qb.Build()
+
+	wantSQL := "SELECT id FROM ticketing.events WHERE organizer_id = $1 AND lower(status) IN (lower($2), lower($3))"
+	if sql != wantSQL {
+		t.Errorf("sql = %q, want %q", sql, wantSQL)
+	}
+	if want := []interface{}{7, "PUBLISHED", "Draft"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestWhereInLowerEmptyMatchesNothing(t *testing.T) {
+	sql, args := NewQueryBuilder("SELECT id FROM ticketing.events").WhereInLower("status", nil).Build()
+
+	if sql != "SELECT id FROM ticketing.events WHERE 1 = 0" || len(args) != 0 {
+		t.Errorf("sql = %q / args %v, want an always-false condition", sql, args)
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	base := NewQueryBuilder("SELECT e.id FROM ticketing.events e").
+		Join("JOIN ticketing.venues v ON v.id = e.venue_id").
+		Where("e.organizer_id = ?", 7)
+	wantSQL, wantArgs := base.Build()
+
+	clone := base.Clone().
+		Join("JOIN ticketing.categories c ON c.id = e.category_id").
+		Where("e.status = ?", "published").
+		GroupBy("e.id").
+		OrderBy("e.id", true).
+		Limit(10).
+		Offset(20)
+	clone.Build()
+
+	sql, args := base.Build()
+	if sql != wantSQL {
+		t.Errorf("original sql changed by clone: %q, want %q", sql, wantSQL)
+	}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("original args changed by clone: %v, want %v", args, wantArgs)
+	}
+
+	// Añadir al original tampoco afecta a la copia ya hecha
+	base.Where("e.is_public = ?", true)
+	cloneSQL, cloneArgs := clone.Build()
+	wantClone := "SELECT e.id FROM ticketing.events e JOIN ticketing.venues v ON v.id = e.venue_id " +
+		"JOIN ticketing.categories c ON c.id = e.category_id WHERE e.organizer_id = $1 AND e.status = $2 " +
+		"GROUP BY e.id ORDER BY e.id DESC LIMIT 10 OFFSET 20"
+	if cloneSQL != wantClone {
+		t.Errorf("clone sql = %q, want %q", cloneSQL, wantClone)
+	}
+	if want := []interface{}{7, "published"}; !reflect.DeepEqual(cloneArgs, want) {
+		t.Errorf("clone args = %v, want %v", cloneArgs, want)
+	}
+}
+
+func TestCountAndPageShareFilters(t *testing.T) {
+	base := NewQueryBuilder("SELECT e.id, e.name FROM ticketing.events e").
+		Join("JOIN ticketing.venues v ON v.id = e.venue_id").
+		Where("v.city = ?", "Lima").
+		WhereLike("e.name", "rock", false)
+
+	pageSQL, pageArgs := base.Clone().OrderBy("e.starts_at", false).Limit(20).Offset(40).Build()
+	countSQL, countArgs := base.BuildCount()
+
+	wantPage := "SELECT e.id, e.name FROM ticketing.events e JOIN ticketing.venues v ON v.id = e.venue_id " +
+		"WHERE v.city = $1 AND e.name ILIKE $2 ORDER BY e.starts_at ASC LIMIT 20 OFFSET 40"
+	if pageSQL != wantPage {
+		t.Errorf("page sql = %q, want %q", pageSQL, wantPage)
+	}
+	wantCount := "SELECT COUNT(*) FROM ticketing.events e JOIN ticketing.venues v ON v.id = e.venue_id " +
+		"WHERE v.city = $1 AND e.name ILIKE $2"
+	if countSQL != wantCount {
+		t.Errorf("count sql = %q, want %q", countSQL, wantCount)
+	}
+	if !reflect.DeepEqual(pageArgs, countArgs) {
+		t.Errorf("page args %v and count args %v differ", pageArgs, countArgs)
+	}
+}
+
+func TestBuildCountDoesNotMutateBuilder(t *testing.T) {
+	qb := NewQueryBuilder("SELECT id FROM ticketing.events").
+		Where("status = ?", "published").
+		OrderBy("id", false).
+		Limit(5)
+	want, _ := qb.Build()
+
+	qb.BuildCount()
+
+	if got, _ := qb.Build(); got != want {
+		t.Errorf("BuildCount changed the builder: %q, want %q", got, want)
+	}
+}
+
+func TestBuildCountWrapsGroupedAndDistinct(t *testing.T) {
+	tests := []struct {
+		name string
+		qb   *QueryBuilder
+		want string
+	}{
+		{
+			"group by",
+			NewQueryBuilder("SELECT e.organizer_id, COUNT(*) FROM ticketing.events e").
+				Where("e.status = ?", "published").
+				GroupBy("e.organizer_id").
+				Having("COUNT(*) > ?", 2).
+				OrderBy("e.organizer_id", false),
+			"SELECT COUNT(*) FROM (SELECT e.organizer_id, COUNT(*) FROM ticketing.events e " +
+				"WHERE e.status = $1 GROUP BY e.organizer_id HAVING COUNT(*) > $2) AS count_query",
+		},
+		{
+			"distinct",
+			NewQueryBuilder("SELECT DISTINCT e.id FROM ticketing.events e").
+				Join("JOIN ticketing.ticket_types tt ON tt.event_id = e.id").
+				Distinct().
+				Limit(10),
+			"SELECT COUNT(*) FROM (SELECT DISTINCT e.id FROM ticketing.events e " +
+				"JOIN ticketing.ticket_types tt ON tt.event_id = e.id) AS count_query",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := tt.qb.BuildCount(); got != tt.want {
+				t.Errorf("count sql = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeekAfterFirstPageHasNoPredicate(t *testing.T) {
+	qb := NewQueryBuilder("SELECT id FROM ticketing.tickets t").
+		Where("t.event_id = ?", 3).
+		SeekAfter("t.id", nil).
+		Limit(20).
+		Offset(40)
+
+	sql, args := qb.BuildSeek()
+
+	wantSQL := "SELECT id FROM ticketing.tickets t WHERE t.event_id = $1 ORDER BY t.id ASC LIMIT 21"
+	if sql != wantSQL {
+		t.Errorf("sql = %q, want %q", sql, wantSQL)
+	}
+	if want := []interface{}{3}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestSeekAfterAddsKeysetPredicate(t *testing.T) {
+	qb := NewQueryBuilder("SELECT id FROM ticketing.tickets t").
+		Where("t.event_id = ?", 3).
+		SeekAfter("t.id", int64(99)).
+		Limit(20)
+
+	sql, args := qb.BuildSeek()
+
+	wantSQL := "SELECT id FROM ticketing.tickets t WHERE t.event_id = $1 AND t.id > $2 ORDER BY t.id ASC LIMIT 21"
+	if sql != wantSQL {
+		t.Errorf("sql = %q, want %q", sql, wantSQL)
+	}
+	if want := []interface{}{3, int64(99)}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+	// BuildSeek no modifica el builder
+	if plain, _ := qb.Build(); plain != "SELECT id FROM ticketing.tickets t WHERE t.event_id = $1 AND t.id > $2 ORDER BY t.id ASC LIMIT 20" {
+		t.Errorf("Build after BuildSeek = %q", plain)
+	}
+}
+
+func TestBuildSeekWithoutSeekAfterMatchesBuild(t *testing.T) {
+	qb := NewQueryBuilder("SELECT id FROM ticketing.tickets").Limit(10).Offset(5)
+	seek, _ := qb.BuildSeek()
+	plain, _ := qb.Build()
+	if seek != plain {
+		t.Errorf("BuildSeek = %q, want %q", seek, plain)
+	}
+}
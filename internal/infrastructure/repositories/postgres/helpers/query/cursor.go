@@ -0,0 +1,47 @@
+// internal/infrastructure/repositories/postgres/helpers/query/cursor.go
+package query
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+)
+
+// ErrInvalidCursor indica que el cursor recibido no fue emitido por SeekPage
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// EncodeCursor convierte el valor de la columna de keyset en un cursor opaco
+func EncodeCursor(value int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(value, 10)))
+}
+
+// DecodeCursor revierte EncodeCursor. Un cursor vacío es la primera página y
+// devuelve nil, listo para pasarse a SeekAfter.
+func DecodeCursor(cursor string) (interface{}, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	value, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return value, nil
+}
+
+// SeekPage recorta la fila extra pedida por BuildSeek y devuelve el cursor de la
+// siguiente página: el valor de keyset de la última fila devuelta, o "" si no
+// hay más filas.
+func SeekPage[T any](items []T, limit int, key func(T) int64) ([]T, string) {
+	if limit < 0 || len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	if len(items) == 0 {
+		return items, ""
+	}
+	return items, EncodeCursor(key(items[len(items)-1]))
+}
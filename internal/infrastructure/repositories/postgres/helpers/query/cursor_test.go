@@ -0,0 +1,42 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, v := range []int64{0, 1, 987654321} {
+		got, err := DecodeCursor(EncodeCursor(v))
+		if err != nil {
+			t.Fatalf("DecodeCursor(%d): %v", v, err)
+		}
+		if got != v {
+			t.Errorf("round trip = %v, want %d", got, v)
+		}
+	}
+
+	if got, err := DecodeCursor(""); got != nil || err != nil {
+		t.Errorf("empty cursor = %v, %v; want nil, nil", got, err)
+	}
+	for _, bad := range []string{"%%%", EncodeCursor(1) + "!", "YWJj"} {
+		if _, err := DecodeCursor(bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) err = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestSeekPage(t *testing.T) {
+	key := func(v int64) int64 { return v }
+
+	items, next := SeekPage([]int64{4, 7, 9}, 2, key)
+	if !reflect.DeepEqual(items, []int64{4, 7}) || next != EncodeCursor(7) {
+		t.Errorf("full page = %v, %q; want [4 7] with cursor of 7", items, next)
+	}
+
+	items, next = SeekPage([]int64{4, 7}, 2, key)
+	if !reflect.DeepEqual(items, []int64{4, 7}) || next != "" {
+		t.Errorf("last page = %v, %q; want [4 7] without cursor", items, next)
+	}
+}
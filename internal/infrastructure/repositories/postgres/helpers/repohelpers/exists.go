@@ -0,0 +1,78 @@
+// internal/infrastructure/repositories/postgres/helpers/repohelpers/exists.go
+package repohelpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Querier es lo que usan los helpers: un *pgxpool.Pool o un pgx.Tx
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ErrIdentifierNotAllowed indica que la tabla o columna no está en la lista permitida
+var ErrIdentifierNotAllowed = errors.New("table or column not allowed")
+
+// existsAllowlist define las tablas y columnas que se pueden consultar con Exists.
+// Los identificadores se interpolan en el SQL, por eso nunca deben venir del usuario.
+var existsAllowlist = map[string]map[string]bool{
+	"ticketing.events": {
+		"id":          true,
+		"public_uuid": true,
+		"slug":        true,
+	},
+	"crm.customers": {
+		"id":          true,
+		"public_uuid": true,
+		"email":       true,
+	},
+	"ticketing.categories": {
+		"id":          true,
+		"public_uuid": true,
+		"slug":        true,
+	},
+}
+
+// Exists verifica si hay al menos una fila en table con column = value
+func Exists(ctx context.Context, db Querier, table, column string, value interface{}) (bool, error) {
+	columns, ok := existsAllowlist[table]
+	if !ok || !columns[column] {
+		return false, fmt.Errorf("%w: %s.%s", ErrIdentifierNotAllowed, table, column)
+	}
+
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE %s = $1)`, table, column)
+	if err := db.QueryRow(ctx, query, value).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// activeTables son las tablas del allowlist que tienen columna is_active
+var activeTables = map[string]bool{
+	"crm.customers":        true,
+	"ticketing.categories": true,
+}
+
+// ActiveState indica si existe una fila con column = value y si está activa,
+// para distinguir un registro inexistente de uno desactivado
+func ActiveState(ctx context.Context, db Querier, table, column string, value interface{}) (found bool, active bool, err error) {
+	columns, ok := existsAllowlist[table]
+	if !ok || !columns[column] || !activeTables[table] {
+		return false, false, fmt.Errorf("%w: %s.%s", ErrIdentifierNotAllowed, table, column)
+	}
+
+	query := fmt.Sprintf(`SELECT is_active FROM %s WHERE %s = $1 LIMIT 1`, table, column)
+	err = db.QueryRow(ctx, query, value).Scan(&active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, active, nil
+}
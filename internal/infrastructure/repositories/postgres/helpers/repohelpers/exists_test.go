@@ -0,0 +1,66 @@
+package repohelpers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/repohelpers"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+func TestExistsRejectsUnlistedIdentifiers(t *testing.T) {
+	tests := []struct {
+		table  string
+		column string
+	}{
+		{"auth.users", "email"},
+		{"crm.customers", "password_hash"},
+		{"crm.customers; DROP TABLE crm.customers", "id"},
+		{"ticketing.events", "id = id OR 1"},
+	}
+	for _, tt := range tests {
+		// Sin base de datos: el allowlist debe rechazar antes de consultar
+		_, err := repohelpers.Exists(context.Background(), nil, tt.table, tt.column, 1)
+		if !errors.Is(err, repohelpers.ErrIdentifierNotAllowed) {
+			t.Errorf("Exists(%q, %q) error = %v, want ErrIdentifierNotAllowed", tt.table, tt.column, err)
+		}
+	}
+
+	if _, _, err := repohelpers.ActiveState(context.Background(), nil, "ticketing.events", "id", 1); !errors.Is(err, repohelpers.ErrIdentifierNotAllowed) {
+		t.Errorf("ActiveState on a table without is_active error = %v, want ErrIdentifierNotAllowed", err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	customerID := testsupport.SeedCustomer(t, tx, "Exists", "exists@example.com")
+
+	found, err := repohelpers.Exists(ctx, tx, "crm.customers", "email", "exists@example.com")
+	if err != nil || !found {
+		t.Errorf("Exists(existing email) = %v, %v; want true", found, err)
+	}
+	found, err = repohelpers.Exists(ctx, tx, "crm.customers", "id", -customerID)
+	if err != nil || found {
+		t.Errorf("Exists(missing id) = %v, %v; want false", found, err)
+	}
+}
+
+func TestActiveState(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	customerID := testsupport.SeedCustomer(t, tx, "Inactive", "inactive@example.com")
+	if _, err := tx.Exec(ctx, `UPDATE crm.customers SET is_active = false WHERE id = $1`, customerID); err != nil {
+		t.Fatalf("failed to deactivate customer: %v", err)
+	}
+
+	found, active, err := repohelpers.ActiveState(ctx, tx, "crm.customers", "id", customerID)
+	if err != nil || !found || active {
+		t.Errorf("ActiveState(inactive) = %v/%v, %v; want found and inactive", found, active, err)
+	}
+	found, _, err = repohelpers.ActiveState(ctx, tx, "crm.customers", "id", -customerID)
+	if err != nil || found {
+		t.Errorf("ActiveState(missing) found = %v, %v; want not found", found, err)
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -8,6 +9,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
 )
 
 // LogLevel representa el nivel de log
@@ -232,12 +235,9 @@ func (l *Logger) getCallerInfo() string {
 	return fmt.Sprintf("%s:%d", file, frame.Line)
 }
 
-// mergeFields combina múltiples mapas de fields
+// mergeFields combina múltiples mapas de fields. Siempre devuelve un mapa
+// inicializado: los loggers especializados le agregan claves aunque no reciban fields.
 func mergeFields(fields ...map[string]interface{}) map[string]interface{} {
-	if len(fields) == 0 {
-		return nil
-	}
-
 	result := make(map[string]interface{})
 	for _, fieldMap := range fields {
 		for key, value := range fieldMap {
@@ -322,6 +322,16 @@ func (l *Logger) DatabaseLogger(operation, table string, duration time.Duration,
 	}
 }
 
+// DatabaseLoggerContext es DatabaseLogger con el request id del contexto en los
+// campos, para cruzar las consultas con el log del RPC que las originó
+func (l *Logger) DatabaseLoggerContext(ctx context.Context, operation, table string, duration time.Duration, rowsAffected int64, err error, fields ...map[string]interface{}) {
+	allFields := mergeFields(fields...)
+	if requestID := appctx.RequestIDFromContext(ctx); requestID != "" {
+		allFields["request_id"] = requestID
+	}
+	l.DatabaseLogger(operation, table, duration, rowsAffected, err, allFields)
+}
+
 // BusinessLogger log de operaciones de negocio
 func (l *Logger) BusinessLogger(operation, entity string, entityID interface{}, success bool, fields ...map[string]interface{}) {
 	allFields := mergeFields(fields...)
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	appctx "github.com/franciscozamorau/osmi-server/internal/context"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestDatabaseLoggerContextWithoutFields(t *testing.T) {
+	logs := captureLog(t)
+	logger := NewLogger("test").WithCallerInfo(false)
+	ctx := appctx.WithRequestID(context.Background(), "req-123")
+
+	// Sin fields el mapa debe inicializarse en vez de entrar en pánico
+	logger.DatabaseLoggerContext(ctx, "GetByID", "event", 5*time.Millisecond, 0, errors.New("boom"))
+	logger.DatabaseLogger("List", "event", time.Millisecond, 3, nil)
+
+	out := logs.String()
+	for _, want := range []string{"Database GetByID on event", "request_id=req-123", "error=boom", "Database List on event", "rows_affected=3"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDatabaseLoggerContextWithoutRequestID(t *testing.T) {
+	logs := captureLog(t)
+	NewLogger("test").WithCallerInfo(false).DatabaseLoggerContext(context.Background(), "GetByID", "event", time.Millisecond, 1, nil)
+
+	if out := logs.String(); !strings.Contains(out, "Database GetByID on event") || strings.Contains(out, "request_id") {
+		t.Errorf("log output = %q, want the entry without request_id", out)
+	}
+}
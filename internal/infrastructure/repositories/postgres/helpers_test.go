@@ -0,0 +1,27 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// seedEvent crea un organizador y un evento publicado dentro de 30 días
+func seedEvent(t *testing.T, db testsupport.DB, name string) int64 {
+	t.Helper()
+	organizerID := testsupport.SeedOrganizer(t, db, "Organizer "+name)
+	return testsupport.SeedEvent(t, db, organizerID, name, time.Now().Add(30*24*time.Hour))
+}
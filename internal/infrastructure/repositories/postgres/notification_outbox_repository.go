@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+)
+
+// NotificationOutboxRepository implementa repository.NotificationOutbox sobre
+// notifications.messages
+type NotificationOutboxRepository struct {
+	db DBTX
+}
+
+func NewNotificationOutboxRepository(db DBTX) *NotificationOutboxRepository {
+	return &NotificationOutboxRepository{db: db}
+}
+
+// ClaimDue reclama hasta limit mensajes pendientes (o con reintento vencido) y los
+// que llevan más de sendingTimeout en "sending". SKIP LOCKED permite varios workers;
+// al pasar a "sending" se renueva updated_at, que es el reloj del timeout.
+func (r *NotificationOutboxRepository) ClaimDue(ctx context.Context, limit int, sendingTimeout time.Duration) ([]*entities.Notification, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		WITH due AS (
+			SELECT id, status AS previous_status
+			FROM notifications.messages
+			WHERE (
+					status IN ('pending', 'retrying')
+					AND scheduled_for <= NOW()
+					AND (next_retry_at IS NULL OR next_retry_at <= NOW())
+				)
+				OR (
+					status = 'sending'
+					AND updated_at < NOW() - make_interval(secs => $2)
+				)
+			ORDER BY scheduled_for, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notifications.messages m
+		SET status = 'sending',
+			updated_at = NOW()
+		FROM due
+		WHERE m.id = due.id
+		RETURNING m.id, m.template_id, m.recipient_email, m.recipient_phone, m.recipient_name,
+			m.recipient_user_id, m.recipient_language, m.subject, m.body, m.channel,
+			due.previous_status, m.attempts, m.max_attempts, m.next_retry_at, m.retry_delay,
+			m.backoff_factor, m.provider_message_id, m.scheduled_for, m.created_at, m.updated_at
+	`
+
+	rows, err := r.db.Query(ctx, query, limit, sendingTimeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*entities.Notification
+	for rows.Next() {
+		var n entities.Notification
+		if err := rows.Scan(
+			&n.ID, &n.TemplateID, &n.RecipientEmail, &n.RecipientPhone, &n.RecipientName,
+			&n.RecipientUserID, &n.RecipientLanguage, &n.Subject, &n.Body, &n.Channel,
+			&n.Status, &n.Attempts, &n.MaxAttempts, &n.NextRetryAt, &n.RetryDelay,
+			&n.BackoffFactor, &n.ProviderMessageID, &n.ScheduledFor, &n.CreatedAt, &n.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, &n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read claimed notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// UpdateStatus cambia el estado de un mensaje
+func (r *NotificationOutboxRepository) UpdateStatus(ctx context.Context, notificationID int64, status string) error {
+	if !enums.NotificationStatus(status).IsValid() {
+		return fmt.Errorf("invalid notification status: %s", status)
+	}
+	return r.exec(ctx, `
+		UPDATE notifications.messages
+		SET status = $2, updated_at = NOW()
+		WHERE id = $1
+	`, notificationID, status)
+}
+
+// MarkAsSent registra el id del proveedor; sentAt va en RFC3339
+func (r *NotificationOutboxRepository) MarkAsSent(ctx context.Context, notificationID int64, sentAt string, providerMessageID string) error {
+	return r.exec(ctx, `
+		UPDATE notifications.messages
+		SET status = 'sent', sent_at = $2, provider_message_id = $3,
+			next_retry_at = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, notificationID, sentAt, providerMessageID)
+}
+
+// MarkAsFailed deja el mensaje en "failed" de forma definitiva
+func (r *NotificationOutboxRepository) MarkAsFailed(ctx context.Context, notificationID int64, errorMessage, errorCode string) error {
+	return r.exec(ctx, `
+		UPDATE notifications.messages
+		SET status = 'failed', last_error = $2, error_code = $3,
+			next_retry_at = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, notificationID, errorMessage, errorCode)
+}
+
+// IncrementAttempts suma un intento de envío
+func (r *NotificationOutboxRepository) IncrementAttempts(ctx context.Context, notificationID int64) error {
+	return r.exec(ctx, `
+		UPDATE notifications.messages
+		SET attempts = attempts + 1, updated_at = NOW()
+		WHERE id = $1
+	`, notificationID)
+}
+
+// SetNextRetry devuelve el mensaje a la cola como "retrying" a partir de nextRetryAt (RFC3339)
+func (r *NotificationOutboxRepository) SetNextRetry(ctx context.Context, notificationID int64, nextRetryAt string) error {
+	return r.exec(ctx, `
+		UPDATE notifications.messages
+		SET status = 'retrying', next_retry_at = $2, updated_at = NOW()
+		WHERE id = $1
+	`, notificationID, nextRetryAt)
+}
+
+// AddErrorToHistory agrega el error a error_history y lo deja como last_error
+func (r *NotificationOutboxRepository) AddErrorToHistory(ctx context.Context, notificationID int64, errorMessage, errorCode string) error {
+	entry, err := json.Marshal([]map[string]interface{}{{
+		"error":      errorMessage,
+		"error_code": errorCode,
+		"at":         time.Now().UTC().Format(time.RFC3339),
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to encode error history: %w", err)
+	}
+
+	return r.exec(ctx, `
+		UPDATE notifications.messages
+		SET error_history = COALESCE(error_history, '[]'::jsonb) || $2::jsonb,
+			last_error = $3, error_code = $4, updated_at = NOW()
+		WHERE id = $1
+	`, notificationID, string(entry), errorMessage, errorCode)
+}
+
+func (r *NotificationOutboxRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update notification: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return repository.ErrNotificationNotFound
+	}
+	return nil
+}
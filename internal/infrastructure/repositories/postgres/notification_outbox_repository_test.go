@@ -0,0 +1,76 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+// seedMessage inserta un mensaje programado en 1970 para que ClaimDue lo tome
+// antes que cualquier otro de la base de pruebas
+func seedMessage(t *testing.T, db testsupport.DB, status string, updatedAt time.Time, nextRetryAt *time.Time) int64 {
+	t.Helper()
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO notifications.messages (
+			recipient_email, recipient_language, subject, body, channel, status,
+			attempts, max_attempts, retry_delay, backoff_factor, next_retry_at,
+			scheduled_for, created_at, updated_at
+		) VALUES ('fan@example.com', 'es', 'Hola', 'Cuerpo', 'email', $1, 0, 3, 60, 2, $2,
+			'1970-01-01', $3, $3)
+		RETURNING id
+	`, status, nextRetryAt, updatedAt).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed notification: %v", err)
+	}
+	return id
+}
+
+func TestNotificationOutboxRepositoryClaimDue(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewNotificationOutboxRepository(tx)
+
+	now := time.Now()
+	pending := seedMessage(t, tx, "pending", now, nil)
+	retryDue := seedMessage(t, tx, "retrying", now, timePtr(now.Add(-time.Minute)))
+	stuck := seedMessage(t, tx, "sending", now.Add(-time.Hour), nil)
+	seedMessage(t, tx, "retrying", now, timePtr(now.Add(time.Hour)))
+	seedMessage(t, tx, "sending", now, nil)
+	seedMessage(t, tx, "sent", now.Add(-time.Hour), nil)
+
+	claimed, err := repo.ClaimDue(ctx, 100, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDue: %v", err)
+	}
+
+	previous := map[int64]string{}
+	for _, n := range claimed {
+		previous[n.ID] = n.Status
+	}
+	want := map[int64]string{pending: "pending", retryDue: "retrying", stuck: "sending"}
+	for id, status := range want {
+		if previous[id] != status {
+			t.Errorf("message %d claimed with previous status %q, want %q", id, previous[id], status)
+		}
+	}
+	for id := range previous {
+		if _, ok := want[id]; !ok && id > pending {
+			t.Errorf("message %d claimed, want it skipped", id)
+		}
+	}
+
+	// Ya reclamados: quedan en "sending" con el timeout renovado
+	again, err := repo.ClaimDue(ctx, 100, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("second ClaimDue: %v", err)
+	}
+	for _, n := range again {
+		if _, ok := want[n.ID]; ok {
+			t.Errorf("message %d claimed twice", n.ID)
+		}
+	}
+}
@@ -3,21 +3,123 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	orderdto "github.com/franciscozamorau/osmi-server/internal/api/dto/order"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type OrderRepository struct {
-	db *pgxpool.Pool
+	db      DBTX
+	maxPage int
 }
 
-func NewOrderRepository(db *pgxpool.Pool) *OrderRepository {
-	return &OrderRepository{db: db}
+func NewOrderRepository(db DBTX) *OrderRepository {
+	return &OrderRepository{
+		db:      db,
+		maxPage: commondto.DefaultMaxPage,
+	}
+}
+
+// SetMaxPage fija la página más profunda que admiten los listados paginados por
+// offset. Valores no positivos se ignoran.
+func (r *OrderRepository) SetMaxPage(page int) {
+	if page > 0 {
+		r.maxPage = page
+	}
+}
+
+// handleError traduce errores de pgx a errores del dominio
+func (r *OrderRepository) handleError(err error, context string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrOrderNotFound
+	}
+	return fmt.Errorf("%s: %w", context, err)
+}
+
+// orderSelectColumns lista las columnas de billing.orders (alias o) en el orden de scanOrder
+const orderSelectColumns = `
+	o.id, o.public_uuid, o.customer_id, o.customer_email, o.customer_name, o.customer_phone,
+	o.subtotal, o.tax_amount, o.service_fee_amount, o.discount_amount, o.total_amount, o.currency,
+	o.payment_status, o.status, o.order_type, o.is_reservation, o.reservation_expires_at,
+	o.payment_method, o.payment_provider_id,
+	o.invoice_required, o.invoice_generated, o.invoice_number,
+	o.promotion_code, o.promotion_id, o.metadata, o.notes,
+	o.ip_address, o.user_agent,
+	o.expires_at, o.paid_at, o.cancelled_at, o.refunded_at,
+	o.created_at, o.updated_at`
+
+// scanOrder escanea una fila seleccionada con orderSelectColumns
+func scanOrder(row pgx.Row) (*entities.Order, error) {
+	var order entities.Order
+	err := row.Scan(
+		&order.ID, &order.PublicID, &order.CustomerID, &order.CustomerEmail, &order.CustomerName, &order.CustomerPhone,
+		&order.Subtotal, &order.TaxAmount, &order.ServiceFeeAmount, &order.DiscountAmount, &order.TotalAmount, &order.Currency,
+		&order.PaymentStatus, &order.Status, &order.OrderType, &order.IsReservation, &order.ReservationExpiresAt,
+		&order.PaymentMethod, &order.PaymentProviderID,
+		&order.InvoiceRequired, &order.InvoiceGenerated, &order.InvoiceNumber,
+		&order.PromotionCode, &order.PromotionID, &order.Metadata, &order.Notes,
+		&order.IPAddress, &order.UserAgent,
+		&order.ExpiresAt, &order.PaidAt, &order.CancelledAt, &order.RefundedAt,
+		&order.CreatedAt, &order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// buildOrderConditions traduce un OrderFilter en condiciones WHERE sobre billing.orders (alias o)
+func buildOrderConditions(filter orderdto.OrderFilter) ([]string, pgx.NamedArgs) {
+	conditions := []string{"1=1"}
+	args := pgx.NamedArgs{}
+
+	if filter.CustomerID != "" {
+		conditions = append(conditions, "o.customer_id = (SELECT id FROM crm.customers WHERE public_uuid = @customer_id)")
+		args["customer_id"] = filter.CustomerID
+	}
+	if filter.CustomerEmail != "" {
+		conditions = append(conditions, "lower(o.customer_email) = lower(@customer_email)")
+		args["customer_email"] = filter.CustomerEmail
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "o.status = lower(@status)")
+		args["status"] = filter.Status
+	}
+	if filter.OrderType != "" {
+		conditions = append(conditions, "o.order_type = @order_type")
+		args["order_type"] = filter.OrderType
+	}
+	if filter.DateFrom != "" {
+		conditions = append(conditions, "o.created_at >= @date_from::date")
+		args["date_from"] = filter.DateFrom
+	}
+	if filter.DateTo != "" {
+		// DateTo es inclusivo: incluye todo el día indicado
+		conditions = append(conditions, "o.created_at < @date_to::date + 1")
+		args["date_to"] = filter.DateTo
+	}
+	if filter.MinAmount > 0 {
+		conditions = append(conditions, "o.total_amount >= @min_amount")
+		args["min_amount"] = filter.MinAmount
+	}
+	if filter.MaxAmount > 0 {
+		conditions = append(conditions, "o.total_amount <= @max_amount")
+		args["max_amount"] = filter.MaxAmount
+	}
+	if filter.HasInvoice != nil {
+		conditions = append(conditions, "o.invoice_generated = @has_invoice")
+		args["has_invoice"] = *filter.HasInvoice
+	}
+
+	return conditions, args
 }
 
 // ============================================================================
@@ -60,29 +162,21 @@ func (r *OrderRepository) Create(ctx context.Context, order *entities.Order) err
 		order.IPAddress, order.UserAgent,
 		order.ExpiresAt, order.PaidAt, order.CancelledAt, order.RefundedAt,
 	).Scan(&order.ID, &order.PublicID, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return r.handleError(err, "failed to create order")
+	}
 
-	return err
+	return nil
 }
 
 func (r *OrderRepository) GetByPublicID(ctx context.Context, publicID string) (*entities.Order, error) {
-	query := `
-		SELECT id, public_uuid, customer_id, status, total_amount, currency,
-			payment_method, created_at, updated_at
-		FROM billing.orders
-		WHERE public_uuid = $1
-	`
-
-	var order entities.Order
-	err := r.db.QueryRow(ctx, query, publicID).Scan(
-		&order.ID, &order.PublicID, &order.CustomerID, &order.Status,
-		&order.TotalAmount, &order.Currency, &order.PaymentMethod,
-		&order.CreatedAt, &order.UpdatedAt,
-	)
+	query := `SELECT ` + orderSelectColumns + ` FROM billing.orders o WHERE o.public_uuid = $1`
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, repository.ErrOrderNotFound
+	order, err := scanOrder(r.db.QueryRow(ctx, query, publicID))
+	if err != nil {
+		return nil, r.handleError(err, "failed to get order")
 	}
-	return &order, err
+	return order, nil
 }
 
 func (r *OrderRepository) GetByCustomerID(ctx context.Context, customerID int64) ([]*entities.Order, error) {
@@ -118,8 +212,14 @@ func (r *OrderRepository) GetByCustomerID(ctx context.Context, customerID int64)
 
 func (r *OrderRepository) UpdateStatus(ctx context.Context, orderID int64, status string) error {
 	query := `UPDATE billing.orders SET status = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.db.Exec(ctx, query, status, orderID)
-	return err
+	cmdTag, err := r.db.Exec(ctx, query, status, orderID)
+	if err != nil {
+		return r.handleError(err, "failed to update order status")
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return repository.ErrOrderNotFound
+	}
+	return nil
 }
 
 func (r *OrderRepository) AddItem(ctx context.Context, item *entities.OrderItem) error {
@@ -168,7 +268,13 @@ func (r *OrderRepository) GetItems(ctx context.Context, orderID int64) ([]*entit
 // ============================================================================
 
 func (r *OrderRepository) FindByID(ctx context.Context, id int64) (*entities.Order, error) {
-	return nil, repository.ErrOrderNotFound
+	query := `SELECT ` + orderSelectColumns + ` FROM billing.orders o WHERE o.id = $1`
+
+	order, err := scanOrder(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, r.handleError(err, "failed to get order")
+	}
+	return order, nil
 }
 
 func (r *OrderRepository) FindByPublicID(ctx context.Context, publicID string) (*entities.Order, error) {
@@ -192,8 +298,48 @@ func (r *OrderRepository) Delete(ctx context.Context, id int64) error {
 	return err
 }
 
+// List devuelve las órdenes que cumplen filter, de la más reciente a la más antigua
 func (r *OrderRepository) List(ctx context.Context, filter orderdto.OrderFilter, pagination commondto.Pagination) ([]*entities.Order, int64, error) {
-	return nil, 0, nil
+	pagination, err := pagination.Normalize(r.maxPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions, args := buildOrderConditions(filter)
+	whereClause := strings.Join(conditions, " AND ")
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM billing.orders o WHERE ` + whereClause
+	if err := r.db.QueryRow(ctx, countQuery, args).Scan(&total); err != nil {
+		return nil, 0, r.handleError(err, "failed to count orders")
+	}
+
+	query := `
+		SELECT ` + orderSelectColumns + `
+		FROM billing.orders o
+		WHERE ` + whereClause + `
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT @limit OFFSET @offset
+	`
+	args["limit"] = pagination.Limit()
+	args["offset"] = pagination.Offset()
+
+	rows, err := r.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, 0, r.handleError(err, "failed to list orders")
+	}
+	defer rows.Close()
+
+	orders := []*entities.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, 0, r.handleError(err, "failed to scan order")
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, total, rows.Err()
 }
 
 func (r *OrderRepository) FindByCustomer(ctx context.Context, customerID int64, pagination commondto.Pagination) ([]*entities.Order, int64, error) {
@@ -247,8 +393,33 @@ func (r *OrderRepository) UpdateOrderItems(ctx context.Context, orderID int64, i
 	return nil
 }
 
+// CalculateTotals recalcula los totales de una orden a partir de sus items: el
+// subtotal es la suma de order_items y el total suma impuestos y cargo por
+// servicio y resta el descuento guardados en la orden
 func (r *OrderRepository) CalculateTotals(ctx context.Context, orderID int64) (*orderdto.OrderTotals, error) {
-	return nil, nil
+	query := `
+		SELECT
+			COALESCE(SUM(oi.total_price), 0) AS subtotal,
+			o.tax_amount, o.service_fee_amount, o.discount_amount
+		FROM billing.orders o
+		LEFT JOIN billing.order_items oi ON oi.order_id = o.id
+		WHERE o.id = $1
+		GROUP BY o.id
+	`
+
+	var totals orderdto.OrderTotals
+	err := r.db.QueryRow(ctx, query, orderID).Scan(
+		&totals.Subtotal, &totals.TaxAmount, &totals.ServiceFee, &totals.DiscountAmount,
+	)
+	if err != nil {
+		return nil, r.handleError(err, "failed to calculate order totals")
+	}
+
+	totals.TotalAmount = totals.Subtotal + totals.TaxAmount + totals.ServiceFee - totals.DiscountAmount
+	if totals.TotalAmount < 0 {
+		totals.TotalAmount = 0
+	}
+	return &totals, nil
 }
 
 func (r *OrderRepository) ApplyPromotion(ctx context.Context, orderID int64, promotionCode string) error {
@@ -267,8 +438,68 @@ func (r *OrderRepository) CancelInvoice(ctx context.Context, orderID int64) erro
 	return nil
 }
 
+// maxTopPromotionCodes es cuántos códigos de promoción devuelve GetStats
+const maxTopPromotionCodes = 5
+
+// GetStats agrega las órdenes que cumplen filter. Los ingresos y el valor
+// promedio sólo consideran órdenes completadas; la tasa de conversión es
+// completadas / total y la de reservas, reservas / total (ambas en porcentaje).
 func (r *OrderRepository) GetStats(ctx context.Context, filter orderdto.OrderFilter) (*orderdto.OrderStatsResponse, error) {
-	return nil, nil
+	conditions, args := buildOrderConditions(filter)
+	whereClause := strings.Join(conditions, " AND ")
+
+	query := `
+		SELECT
+			COUNT(*) AS total_orders,
+			COUNT(*) FILTER (WHERE o.status = 'completed') AS completed_orders,
+			COUNT(*) FILTER (WHERE o.status IN ('pending', 'processing')) AS pending_orders,
+			COUNT(*) FILTER (WHERE o.status = 'failed') AS failed_orders,
+			COALESCE(SUM(o.total_amount) FILTER (WHERE o.status = 'completed'), 0) AS total_revenue,
+			COALESCE(AVG(o.total_amount) FILTER (WHERE o.status = 'completed'), 0) AS avg_order_value,
+			COUNT(*) FILTER (WHERE o.is_reservation) AS reservations
+		FROM billing.orders o
+		WHERE ` + whereClause
+
+	var stats orderdto.OrderStatsResponse
+	var reservations int
+	err := r.db.QueryRow(ctx, query, args).Scan(
+		&stats.TotalOrders, &stats.CompletedOrders, &stats.PendingOrders, &stats.FailedOrders,
+		&stats.TotalRevenue, &stats.AvgOrderValue, &reservations,
+	)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get order stats")
+	}
+
+	if stats.TotalOrders > 0 {
+		stats.ConversionRate = float64(stats.CompletedOrders) / float64(stats.TotalOrders) * 100
+		stats.ReservationRate = float64(reservations) / float64(stats.TotalOrders) * 100
+	}
+
+	promoQuery := `
+		SELECT o.promotion_code, COUNT(*) AS usage_count, COALESCE(SUM(o.discount_amount), 0) AS total_discount
+		FROM billing.orders o
+		WHERE ` + whereClause + ` AND o.promotion_code IS NOT NULL
+		GROUP BY o.promotion_code
+		ORDER BY usage_count DESC, o.promotion_code
+		LIMIT @top_promotions
+	`
+	args["top_promotions"] = maxTopPromotionCodes
+
+	rows, err := r.db.Query(ctx, promoQuery, args)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get promotion stats")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var promo orderdto.PromotionStats
+		if err := rows.Scan(&promo.Code, &promo.UsageCount, &promo.TotalDiscount); err != nil {
+			return nil, r.handleError(err, "failed to scan promotion stats")
+		}
+		stats.TopPromotionCodes = append(stats.TopPromotionCodes, promo)
+	}
+
+	return &stats, rows.Err()
 }
 
 func (r *OrderRepository) GetCustomerOrderStats(ctx context.Context, customerID int64) (*orderdto.CustomerOrderStats, error) {
@@ -0,0 +1,200 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
+	orderdto "github.com/franciscozamorau/osmi-server/internal/api/dto/order"
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+func TestOrderRepositoryCreateAndFindByPublicID(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewOrderRepository(tx)
+
+	customerID := testsupport.SeedCustomer(t, tx, "Comprador", "order-create@example.com")
+	promo := "VERANO"
+	order := &entities.Order{
+		CustomerID:     &customerID,
+		CustomerEmail:  "order-create@example.com",
+		Subtotal:       200,
+		TaxAmount:      32,
+		DiscountAmount: 20,
+		TotalAmount:    212,
+		Currency:       "MXN",
+		Status:         "pending",
+		OrderType:      "ticket",
+		PromotionCode:  &promo,
+		Metadata:       map[string]interface{}{"channel": "web"},
+	}
+	if err := repo.Create(ctx, order); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if order.ID == 0 || order.PublicID == "" {
+		t.Fatalf("Create did not return id/public id: %+v", order)
+	}
+
+	got, err := repo.FindByPublicID(ctx, order.PublicID)
+	if err != nil {
+		t.Fatalf("FindByPublicID: %v", err)
+	}
+	if got.ID != order.ID || got.TotalAmount != 212 || got.Status != "pending" ||
+		got.PromotionCode == nil || *got.PromotionCode != "VERANO" || got.Metadata["channel"] != "web" {
+		t.Errorf("FindByPublicID = %+v, want the created order", got)
+	}
+
+	if _, err := repo.FindByPublicID(ctx, uuid.NewString()); !errors.Is(err, repository.ErrOrderNotFound) {
+		t.Errorf("missing order: err = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestOrderRepositoryListFiltersAndPaginates(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewOrderRepository(tx)
+
+	email := uuid.NewString() + "@orders.example.com"
+	customerID := testsupport.SeedCustomer(t, tx, "Listado", email)
+	var completed []int64
+	for i := 0; i < 3; i++ {
+		completed = append(completed, testsupport.SeedOrder(t, tx, customerID, email, 100, "completed"))
+	}
+	testsupport.SeedOrder(t, tx, customerID, email, 50, "pending")
+	testsupport.SeedOrder(t, tx, testsupport.SeedCustomer(t, tx, "Otro", "order-list-other@example.com"), "order-list-other@example.com", 100, "completed")
+
+	filter := orderdto.OrderFilter{CustomerEmail: email, Status: "completed"}
+	firstPage, total, err := repo.List(ctx, filter, commondto.Pagination{Page: 1, PageSize: 2})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	secondPage, _, err := repo.List(ctx, filter, commondto.Pagination{Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatalf("List page 2: %v", err)
+	}
+
+	if total != 3 || len(firstPage) != 2 || len(secondPage) != 1 {
+		t.Fatalf("total = %d, pages = %d+%d, want 3 split 2+1", total, len(firstPage), len(secondPage))
+	}
+	// Misma created_at dentro de la transacción: desempata el id descendente
+	got := []int64{firstPage[0].ID, firstPage[1].ID, secondPage[0].ID}
+	want := []int64{completed[2], completed[1], completed[0]}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("order %d = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestOrderRepositoryUpdateStatus(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewOrderRepository(tx)
+
+	customerID := testsupport.SeedCustomer(t, tx, "Estado", "order-status@example.com")
+	orderID := testsupport.SeedOrder(t, tx, customerID, "order-status@example.com", 100, "pending")
+
+	if err := repo.UpdateStatus(ctx, orderID, "completed"); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	order, err := repo.FindByID(ctx, orderID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if order.Status != "completed" {
+		t.Errorf("status = %q, want completed", order.Status)
+	}
+
+	if err := repo.UpdateStatus(ctx, -1, "completed"); !errors.Is(err, repository.ErrOrderNotFound) {
+		t.Errorf("missing order: err = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestOrderRepositoryCalculateTotalsFromItems(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewOrderRepository(tx)
+
+	eventID := seedEvent(t, tx, "Totales")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 150, 10)
+	customerID := testsupport.SeedCustomer(t, tx, "Totales", "order-totals@example.com")
+	orderID := testsupport.SeedOrder(t, tx, customerID, "order-totals@example.com", 0, "pending")
+	if _, err := tx.Exec(ctx, `
+		UPDATE billing.orders SET tax_amount = 48, service_fee_amount = 10, discount_amount = 25 WHERE id = $1
+	`, orderID); err != nil {
+		t.Fatalf("failed to set order charges: %v", err)
+	}
+	for _, price := range []float64{150, 150} {
+		ticketID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "reserved", price)
+		item := &entities.OrderItem{OrderID: orderID, TicketTypeID: typeID, TicketID: ticketID, Quantity: 1, UnitPrice: price, TotalPrice: price}
+		if err := repo.AddItem(ctx, item); err != nil {
+			t.Fatalf("AddItem: %v", err)
+		}
+	}
+
+	totals, err := repo.CalculateTotals(ctx, orderID)
+	if err != nil {
+		t.Fatalf("CalculateTotals: %v", err)
+	}
+	want := orderdto.OrderTotals{Subtotal: 300, TaxAmount: 48, ServiceFee: 10, DiscountAmount: 25, TotalAmount: 333}
+	if *totals != want {
+		t.Errorf("totals = %+v, want %+v", *totals, want)
+	}
+
+	// Una orden sin items sólo suma sus cargos
+	emptyID := testsupport.SeedOrder(t, tx, customerID, "order-totals@example.com", 0, "pending")
+	totals, err = repo.CalculateTotals(ctx, emptyID)
+	if err != nil {
+		t.Fatalf("CalculateTotals(empty): %v", err)
+	}
+	if totals.Subtotal != 0 || totals.TotalAmount != 0 {
+		t.Errorf("empty order totals = %+v, want zeros", *totals)
+	}
+
+	if _, err := repo.CalculateTotals(ctx, -1); !errors.Is(err, repository.ErrOrderNotFound) {
+		t.Errorf("missing order: err = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestOrderRepositoryGetStats(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewOrderRepository(tx)
+
+	email := uuid.NewString() + "@orders.example.com"
+	customerID := testsupport.SeedCustomer(t, tx, "Stats", email)
+	testsupport.SeedOrder(t, tx, customerID, email, 100, "completed")
+	promoted := testsupport.SeedOrder(t, tx, customerID, email, 300, "completed")
+	testsupport.SeedOrder(t, tx, customerID, email, 80, "pending")
+	reservation := testsupport.SeedOrder(t, tx, customerID, email, 50, "failed")
+	if _, err := tx.Exec(ctx, `UPDATE billing.orders SET promotion_code = 'VIP10', discount_amount = 30 WHERE id = $1`, promoted); err != nil {
+		t.Fatalf("failed to set promotion: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE billing.orders SET is_reservation = true WHERE id = $1`, reservation); err != nil {
+		t.Fatalf("failed to mark reservation: %v", err)
+	}
+
+	stats, err := repo.GetStats(ctx, orderdto.OrderFilter{CustomerEmail: email})
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.TotalOrders != 4 || stats.CompletedOrders != 2 || stats.PendingOrders != 1 || stats.FailedOrders != 1 {
+		t.Errorf("counts = %+v, want 4 total, 2 completed, 1 pending, 1 failed", stats)
+	}
+	if stats.TotalRevenue != 400 || stats.AvgOrderValue != 200 {
+		t.Errorf("revenue/avg = %v/%v, want 400/200", stats.TotalRevenue, stats.AvgOrderValue)
+	}
+	if stats.ConversionRate != 50 || stats.ReservationRate != 25 {
+		t.Errorf("conversion/reservation rate = %v/%v, want 50/25", stats.ConversionRate, stats.ReservationRate)
+	}
+	if len(stats.TopPromotionCodes) != 1 || stats.TopPromotionCodes[0].Code != "VIP10" || stats.TopPromotionCodes[0].TotalDiscount != 30 {
+		t.Errorf("top promotions = %+v, want VIP10 with 30 discount", stats.TopPromotionCodes)
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	organizerdto "github.com/franciscozamorau/osmi-server/internal/api/dto/organizer"
@@ -19,13 +18,23 @@ import (
 
 // OrganizerRepository implementa la interfaz repository.OrganizerRepository
 type OrganizerRepository struct {
-	db *pgxpool.Pool
+	db      DBTX
+	maxPage int
 }
 
 // NewOrganizerRepository crea una nueva instancia
-func NewOrganizerRepository(db *pgxpool.Pool) *OrganizerRepository {
+func NewOrganizerRepository(db DBTX) *OrganizerRepository {
 	return &OrganizerRepository{
-		db: db,
+		db:      db,
+		maxPage: commondto.DefaultMaxPage,
+	}
+}
+
+// SetMaxPage fija la página más profunda que admiten los listados paginados por
+// offset. Valores no positivos se ignoran.
+func (r *OrganizerRepository) SetMaxPage(page int) {
+	if page > 0 {
+		r.maxPage = page
 	}
 }
 
@@ -389,6 +398,11 @@ func (r *OrganizerRepository) Exists(ctx context.Context, id int64) (bool, error
 
 // List lista organizadores con filtros
 func (r *OrganizerRepository) List(ctx context.Context, filter organizerdto.OrganizerFilter, pagination commondto.Pagination) ([]*entities.Organizer, int64, error) {
+	pagination, err := pagination.Normalize(r.maxPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	where := []string{"1=1"}
 	args := pgx.NamedArgs{}
 	argPos := 1
@@ -425,7 +439,7 @@ func (r *OrganizerRepository) List(ctx context.Context, filter organizerdto.Orga
 	// Contar total
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM ticketing.organizers WHERE %s", whereClause)
 	var total int64
-	err := r.db.QueryRow(ctx, countQuery, args).Scan(&total)
+	err = r.db.QueryRow(ctx, countQuery, args).Scan(&total)
 	if err != nil {
 		return nil, 0, r.handleError(err, "failed to count organizers")
 	}
@@ -3,21 +3,23 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	paymentdto "github.com/franciscozamorau/osmi-server/internal/api/dto/payment"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PaymentRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewPaymentRepository(db *pgxpool.Pool) *PaymentRepository {
+func NewPaymentRepository(db DBTX) *PaymentRepository {
 	return &PaymentRepository{db: db}
 }
 
@@ -31,128 +33,83 @@ func (r *PaymentRepository) Create(ctx context.Context, payment *entities.Paymen
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW()
 		)
-		RETURNING id, created_at, updated_at
+		RETURNING id, public_uuid, created_at, updated_at
 	`
 
 	err := r.db.QueryRow(ctx, query,
 		payment.OrderID, payment.ProviderID, payment.Amount, payment.Currency, payment.ExchangeRate,
 		payment.Status, payment.PaymentMethod, payment.Attempts, payment.MaxAttempts,
 		payment.IPAddress, payment.UserAgent,
-	).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+	).Scan(&payment.ID, &payment.PublicID, &payment.CreatedAt, &payment.UpdatedAt)
 
 	return err
 }
 
 // FindByID obtiene un pago por ID
 func (r *PaymentRepository) FindByID(ctx context.Context, id int64) (*entities.Payment, error) {
-	query := `
-		SELECT id, order_id, provider_id, provider_transaction_id, provider_session_id,
-			amount, currency, exchange_rate, status, payment_method, payment_method_details,
-			attempts, max_attempts, next_retry_at, last_error, error_code,
-			ip_address, user_agent, processed_at, refunded_at, cancelled_at,
-			created_at, updated_at
-		FROM billing.payments
-		WHERE id = $1
-	`
-
-	var p entities.Payment
-	var providerTransactionID, providerSessionID, lastError, errorCode *string
-	var paymentMethodDetails map[string]interface{}
-	var nextRetryAt, processedAt, refundedAt, cancelledAt *time.Time
-
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&p.ID, &p.OrderID, &p.ProviderID, &providerTransactionID, &providerSessionID,
-		&p.Amount, &p.Currency, &p.ExchangeRate, &p.Status, &p.PaymentMethod, &paymentMethodDetails,
-		&p.Attempts, &p.MaxAttempts, &nextRetryAt, &lastError, &errorCode,
-		&p.IPAddress, &p.UserAgent, &processedAt, &refundedAt, &cancelledAt,
-		&p.CreatedAt, &p.UpdatedAt,
-	)
+	query := `SELECT ` + paymentSelectColumns + ` FROM billing.payments p WHERE p.id = $1`
 
+	payment, err := scanPayment(r.db.QueryRow(ctx, query, id))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, repository.ErrPaymentNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	p.ProviderTransactionID = providerTransactionID
-	p.ProviderSessionID = providerSessionID
-	p.PaymentMethodDetails = &paymentMethodDetails
-	p.NextRetryAt = nextRetryAt
-	p.LastError = lastError
-	p.ErrorCode = errorCode
-	p.ProcessedAt = processedAt
-	p.RefundedAt = refundedAt
-	p.CancelledAt = cancelledAt
-
-	return &p, nil
+	return payment, nil
 }
 
 // FindByOrderID obtiene pagos de una orden
 func (r *PaymentRepository) FindByOrderID(ctx context.Context, orderID int64) ([]*entities.Payment, error) {
-	query := `
-		SELECT id, order_id, provider_id, provider_transaction_id, provider_session_id,
-			amount, currency, exchange_rate, status, payment_method, payment_method_details,
-			attempts, max_attempts, next_retry_at, last_error, error_code,
-			ip_address, user_agent, processed_at, refunded_at, cancelled_at,
-			created_at, updated_at
-		FROM billing.payments
-		WHERE order_id = $1
-		ORDER BY created_at DESC
-	`
+	return r.FindByOrder(ctx, orderID)
+}
 
-	rows, err := r.db.Query(ctx, query, orderID)
+// UpdateStatus actualiza el estado de un pago validando la transición contra
+// enums.PaymentFlow con la fila bloqueada. Repetir el estado actual sólo
+// actualiza los datos del proveedor (transaction_id, session_id, last_error,
+// error_code). Al capturarse se registra processed_at (o providerData
+// "processed_at") y al reembolsarse refunded_at.
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, paymentID int64, status string, providerData map[string]interface{}) error {
+	target := enums.PaymentStatus(strings.ToLower(status))
+	if !target.IsValid() {
+		return &enums.InvalidPaymentStatusError{Status: status}
+	}
+
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback(ctx)
 
-	var payments []*entities.Payment
-	for rows.Next() {
-		var p entities.Payment
-		var providerTransactionID, providerSessionID, lastError, errorCode *string
-		var paymentMethodDetails map[string]interface{}
-		var nextRetryAt, processedAt, refundedAt, cancelledAt *time.Time
-
-		err = rows.Scan(
-			&p.ID, &p.OrderID, &p.ProviderID, &providerTransactionID, &providerSessionID,
-			&p.Amount, &p.Currency, &p.ExchangeRate, &p.Status, &p.PaymentMethod, &paymentMethodDetails,
-			&p.Attempts, &p.MaxAttempts, &nextRetryAt, &lastError, &errorCode,
-			&p.IPAddress, &p.UserAgent, &processedAt, &refundedAt, &cancelledAt,
-			&p.CreatedAt, &p.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+	var current string
+	err = tx.QueryRow(ctx, `SELECT status FROM billing.payments WHERE id = $1 FOR UPDATE`, paymentID).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrPaymentNotFound
 		}
-
-		p.ProviderTransactionID = providerTransactionID
-		p.ProviderSessionID = providerSessionID
-		p.PaymentMethodDetails = &paymentMethodDetails
-		p.NextRetryAt = nextRetryAt
-		p.LastError = lastError
-		p.ErrorCode = errorCode
-		p.ProcessedAt = processedAt
-		p.RefundedAt = refundedAt
-		p.CancelledAt = cancelledAt
-
-		payments = append(payments, &p)
+		return fmt.Errorf("failed to get payment status: %w", err)
 	}
 
-	return payments, nil
-}
+	from := enums.PaymentStatus(current)
+	if from != target && !enums.CanTransitionPayment(from, target) {
+		return fmt.Errorf("%w: %s -> %s", repository.ErrInvalidPaymentTransition, from, target)
+	}
 
-// UpdateStatus actualiza el estado de un pago
-func (r *PaymentRepository) UpdateStatus(ctx context.Context, paymentID int64, status string, providerData map[string]interface{}) error {
 	query := `
 		UPDATE billing.payments
 		SET status = $1,
 		    provider_transaction_id = COALESCE($2, provider_transaction_id),
 		    provider_session_id = COALESCE($3, provider_session_id),
+		    last_error = COALESCE($4, last_error),
+		    error_code = COALESCE($5, error_code),
+		    processed_at = CASE WHEN $1 IN ('captured', 'completed') THEN COALESCE(processed_at, $6, NOW()) ELSE processed_at END,
+		    refunded_at = CASE WHEN $1 = 'refunded' THEN COALESCE(refunded_at, NOW()) ELSE refunded_at END,
 		    updated_at = NOW()
-		WHERE id = $4
+		WHERE id = $7
 	`
 
-	var transactionID, sessionID *string
+	var transactionID, sessionID, lastError, errorCode *string
+	var processedAt *time.Time
 	if providerData != nil {
 		if tid, ok := providerData["transaction_id"].(string); ok {
 			transactionID = &tid
@@ -160,33 +117,48 @@ func (r *PaymentRepository) UpdateStatus(ctx context.Context, paymentID int64, s
 		if sid, ok := providerData["session_id"].(string); ok {
 			sessionID = &sid
 		}
+		if msg, ok := providerData["last_error"].(string); ok && msg != "" {
+			lastError = &msg
+		}
+		if code, ok := providerData["error_code"].(string); ok && code != "" {
+			errorCode = &code
+		}
+		if at, ok := providerData["processed_at"].(time.Time); ok {
+			processedAt = &at
+		}
 	}
 
-	_, err := r.db.Exec(ctx, query, status, transactionID, sessionID, paymentID)
-	return err
+	if _, err := tx.Exec(ctx, query, string(target), transactionID, sessionID, lastError, errorCode, processedAt, paymentID); err != nil {
+		return fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
-// Update actualiza un pago completo
+// Update actualiza los datos de un pago salvo su estado, que sólo cambia con
+// UpdateStatus para que toda transición pase por enums.PaymentFlow
 func (r *PaymentRepository) Update(ctx context.Context, payment *entities.Payment) error {
 	query := `
 		UPDATE billing.payments SET
 			provider_transaction_id = $1,
 			provider_session_id = $2,
-			status = $3,
-			attempts = $4,
-			next_retry_at = $5,
-			last_error = $6,
-			error_code = $7,
-			processed_at = $8,
-			refunded_at = $9,
-			cancelled_at = $10,
+			attempts = $3,
+			next_retry_at = $4,
+			last_error = $5,
+			error_code = $6,
+			processed_at = $7,
+			refunded_at = $8,
+			cancelled_at = $9,
 			updated_at = NOW()
-		WHERE id = $11
+		WHERE id = $10
 	`
 
 	_, err := r.db.Exec(ctx, query,
 		payment.ProviderTransactionID, payment.ProviderSessionID,
-		payment.Status, payment.Attempts, payment.NextRetryAt,
+		payment.Attempts, payment.NextRetryAt,
 		payment.LastError, payment.ErrorCode,
 		payment.ProcessedAt, payment.RefundedAt, payment.CancelledAt,
 		payment.ID,
@@ -197,60 +169,26 @@ func (r *PaymentRepository) Update(ctx context.Context, payment *entities.Paymen
 
 // FindByTransactionID obtiene un pago por transaction_id del proveedor
 func (r *PaymentRepository) FindByTransactionID(ctx context.Context, transactionID string) (*entities.Payment, error) {
-	query := `
-		SELECT id, order_id, provider_id, provider_transaction_id, provider_session_id,
-			amount, currency, exchange_rate, status, payment_method, payment_method_details,
-			attempts, max_attempts, next_retry_at, last_error, error_code,
-			ip_address, user_agent, processed_at, refunded_at, cancelled_at,
-			created_at, updated_at
-		FROM billing.payments
-		WHERE provider_transaction_id = $1
-	`
-
-	var p entities.Payment
-	var providerSessionID, lastError, errorCode *string
-	var paymentMethodDetails map[string]interface{}
-	var nextRetryAt, processedAt, refundedAt, cancelledAt *time.Time
-
-	err := r.db.QueryRow(ctx, query, transactionID).Scan(
-		&p.ID, &p.OrderID, &p.ProviderID, &p.ProviderTransactionID, &providerSessionID,
-		&p.Amount, &p.Currency, &p.ExchangeRate, &p.Status, &p.PaymentMethod, &paymentMethodDetails,
-		&p.Attempts, &p.MaxAttempts, &nextRetryAt, &lastError, &errorCode,
-		&p.IPAddress, &p.UserAgent, &processedAt, &refundedAt, &cancelledAt,
-		&p.CreatedAt, &p.UpdatedAt,
-	)
+	query := `SELECT ` + paymentSelectColumns + ` FROM billing.payments p WHERE p.provider_transaction_id = $1`
 
+	payment, err := scanPayment(r.db.QueryRow(ctx, query, transactionID))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, repository.ErrPaymentNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	p.ProviderSessionID = providerSessionID
-	p.PaymentMethodDetails = &paymentMethodDetails
-	p.NextRetryAt = nextRetryAt
-	p.LastError = lastError
-	p.ErrorCode = errorCode
-	p.ProcessedAt = processedAt
-	p.RefundedAt = refundedAt
-	p.CancelledAt = cancelledAt
-
-	return &p, nil
+	return payment, nil
 }
 
-// FindByOrder obtiene todos los pagos de una orden
+// FindByOrder obtiene todos los pagos de una orden, del más reciente al más antiguo
 func (r *PaymentRepository) FindByOrder(ctx context.Context, orderID int64) ([]*entities.Payment, error) {
 	query := `
-        SELECT id, order_id, provider_id, provider_transaction_id, provider_session_id,
-            amount, currency, exchange_rate, status, payment_method, payment_method_details,
-            attempts, max_attempts, next_retry_at, last_error, error_code,
-            ip_address, user_agent, processed_at, refunded_at, cancelled_at,
-            created_at, updated_at
-        FROM billing.payments
-        WHERE order_id = $1
-        ORDER BY created_at DESC
-    `
+		SELECT ` + paymentSelectColumns + `
+		FROM billing.payments p
+		WHERE p.order_id = $1
+		ORDER BY p.created_at DESC, p.id DESC
+	`
 
 	rows, err := r.db.Query(ctx, query, orderID)
 	if err != nil {
@@ -258,38 +196,36 @@ func (r *PaymentRepository) FindByOrder(ctx context.Context, orderID int64) ([]*
 	}
 	defer rows.Close()
 
-	var payments []*entities.Payment
-	for rows.Next() {
-		var p entities.Payment
-		var providerTransactionID, providerSessionID, lastError, errorCode *string
-		var paymentMethodDetails map[string]interface{}
-		var nextRetryAt, processedAt, refundedAt, cancelledAt *time.Time
-
-		err = rows.Scan(
-			&p.ID, &p.OrderID, &p.ProviderID, &providerTransactionID, &providerSessionID,
-			&p.Amount, &p.Currency, &p.ExchangeRate, &p.Status, &p.PaymentMethod, &paymentMethodDetails,
-			&p.Attempts, &p.MaxAttempts, &nextRetryAt, &lastError, &errorCode,
-			&p.IPAddress, &p.UserAgent, &processedAt, &refundedAt, &cancelledAt,
-			&p.CreatedAt, &p.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
+	return scanPaymentRows(rows)
+}
 
-		p.ProviderTransactionID = providerTransactionID
-		p.ProviderSessionID = providerSessionID
-		p.PaymentMethodDetails = &paymentMethodDetails
-		p.NextRetryAt = nextRetryAt
-		p.LastError = lastError
-		p.ErrorCode = errorCode
-		p.ProcessedAt = processedAt
-		p.RefundedAt = refundedAt
-		p.CancelledAt = cancelledAt
-
-		payments = append(payments, &p)
+// FindByPublicID obtiene un pago por su public_uuid
+func (r *PaymentRepository) FindByPublicID(ctx context.Context, publicID string) (*entities.Payment, error) {
+	query := `SELECT ` + paymentSelectColumns + ` FROM billing.payments p WHERE p.public_uuid = $1`
+
+	payment, err := scanPayment(r.db.QueryRow(ctx, query, publicID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrPaymentNotFound
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	return payment, nil
+}
 
-	return payments, nil
+// ListByOrder lista los pagos de la orden con public_uuid orderPublicID, del más
+// reciente al más antiguo. Una orden inexistente devuelve ErrOrderNotFound.
+func (r *PaymentRepository) ListByOrder(ctx context.Context, orderPublicID string) ([]*entities.Payment, error) {
+	var orderID int64
+	err := r.db.QueryRow(ctx, `SELECT id FROM billing.orders WHERE public_uuid = $1`, orderPublicID).Scan(&orderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	return r.FindByOrder(ctx, orderID)
 }
 
 // ============================================================================
@@ -301,11 +237,6 @@ func (r *PaymentRepository) Delete(ctx context.Context, id int64) error {
 	return err
 }
 
-func (r *PaymentRepository) FindByPublicID(ctx context.Context, publicID string) (*entities.Payment, error) {
-	// Si tu tabla no tiene public_uuid, puedes omitir este método o implementarlo
-	return nil, repository.ErrPaymentNotFound
-}
-
 func (r *PaymentRepository) List(ctx context.Context, filter paymentdto.PaymentFilter, pagination commondto.Pagination) ([]*entities.Payment, int64, error) {
 	return nil, 0, nil
 }
@@ -330,16 +261,32 @@ func (r *PaymentRepository) FindPendingPayments(ctx context.Context) ([]*entitie
 	return nil, nil
 }
 
+// MarkAsProcessed pasa el pago a captured vía UpdateStatus. processedAt
+// (RFC3339) es opcional; vacío registra el momento actual.
 func (r *PaymentRepository) MarkAsProcessed(ctx context.Context, paymentID int64, processedAt string) error {
-	return nil
+	var providerData map[string]interface{}
+	if processedAt != "" {
+		at, err := time.Parse(time.RFC3339, processedAt)
+		if err != nil {
+			return fmt.Errorf("invalid processed_at %q: %w", processedAt, err)
+		}
+		providerData = map[string]interface{}{"processed_at": at}
+	}
+	return r.UpdateStatus(ctx, paymentID, string(enums.PaymentStatusCaptured), providerData)
 }
 
+// MarkAsRefunded pasa el pago a refunded vía UpdateStatus. El reembolso
+// refundID vive en billing.refunds, que ya referencia al pago.
 func (r *PaymentRepository) MarkAsRefunded(ctx context.Context, paymentID int64, refundID int64) error {
-	return nil
+	return r.UpdateStatus(ctx, paymentID, string(enums.PaymentStatusRefunded), nil)
 }
 
+// MarkAsFailed pasa el pago a failed vía UpdateStatus y guarda el error del proveedor
 func (r *PaymentRepository) MarkAsFailed(ctx context.Context, paymentID int64, errorMessage string, errorCode string) error {
-	return nil
+	return r.UpdateStatus(ctx, paymentID, string(enums.PaymentStatusFailed), map[string]interface{}{
+		"last_error": errorMessage,
+		"error_code": errorCode,
+	})
 }
 
 func (r *PaymentRepository) IncrementAttempts(ctx context.Context, paymentID int64) error {
@@ -358,8 +305,73 @@ func (r *PaymentRepository) UpdatePaymentMethod(ctx context.Context, paymentID i
 	return nil
 }
 
+// GetStats agrega los pagos que cumplen filter. El volumen y el promedio sólo
+// consideran pagos cobrados (captured o completed); la tasa de éxito es
+// cobrados / total en porcentaje.
 func (r *PaymentRepository) GetStats(ctx context.Context, filter paymentdto.PaymentFilter) (*paymentdto.PaymentStatsResponse, error) {
-	return nil, nil
+	conditions := []string{"1=1"}
+	args := pgx.NamedArgs{}
+
+	if filter.OrderID != "" {
+		conditions = append(conditions, "p.order_id = (SELECT id FROM billing.orders WHERE public_uuid = @order_id)")
+		args["order_id"] = filter.OrderID
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "p.status = lower(@status)")
+		args["status"] = filter.Status
+	}
+	if filter.PaymentMethod != "" {
+		conditions = append(conditions, "p.payment_method = @payment_method")
+		args["payment_method"] = filter.PaymentMethod
+	}
+	if filter.PaymentProvider != "" {
+		conditions = append(conditions, "p.provider_id = (SELECT id FROM billing.payment_providers WHERE code = @provider)")
+		args["provider"] = filter.PaymentProvider
+	}
+	if filter.DateFrom != "" {
+		conditions = append(conditions, "p.created_at >= @date_from::date")
+		args["date_from"] = filter.DateFrom
+	}
+	if filter.DateTo != "" {
+		conditions = append(conditions, "p.created_at < @date_to::date + 1")
+		args["date_to"] = filter.DateTo
+	}
+	if filter.MinAmount > 0 {
+		conditions = append(conditions, "p.amount >= @min_amount")
+		args["min_amount"] = filter.MinAmount
+	}
+	if filter.MaxAmount > 0 {
+		conditions = append(conditions, "p.amount <= @max_amount")
+		args["max_amount"] = filter.MaxAmount
+	}
+	if filter.Attempts > 0 {
+		conditions = append(conditions, "p.attempts >= @attempts")
+		args["attempts"] = filter.Attempts
+	}
+
+	query := `
+		SELECT
+			COUNT(*) AS total_payments,
+			COUNT(*) FILTER (WHERE p.status IN ('captured', 'completed')) AS successful_payments,
+			COUNT(*) FILTER (WHERE p.status IN ('failed', 'expired')) AS failed_payments,
+			COALESCE(SUM(p.amount) FILTER (WHERE p.status IN ('captured', 'completed')), 0) AS total_volume,
+			COALESCE(AVG(p.amount) FILTER (WHERE p.status IN ('captured', 'completed')), 0) AS avg_payment_value
+		FROM billing.payments p
+		WHERE ` + strings.Join(conditions, " AND ")
+
+	var stats paymentdto.PaymentStatsResponse
+	err := r.db.QueryRow(ctx, query, args).Scan(
+		&stats.TotalPayments, &stats.SuccessfulPayments, &stats.FailedPayments,
+		&stats.TotalVolume, &stats.AvgPaymentValue,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment stats: %w", err)
+	}
+
+	if stats.TotalPayments > 0 {
+		stats.SuccessRate = float64(stats.SuccessfulPayments) / float64(stats.TotalPayments) * 100
+	}
+	return &stats, nil
 }
 
 func (r *PaymentRepository) GetProviderStats(ctx context.Context, providerID int64) (*paymentdto.ProviderStats, error) {
@@ -381,3 +393,43 @@ func (r *PaymentRepository) GetAverageProcessingTime(ctx context.Context) (float
 func (r *PaymentRepository) GetTotalProcessedAmount(ctx context.Context, currency string) (float64, error) {
 	return 0, nil
 }
+
+// paymentSelectColumns lista las columnas de billing.payments (alias p) en el orden de scanPayment
+const paymentSelectColumns = `
+	p.id, p.public_uuid, p.order_id, p.provider_id, p.provider_transaction_id, p.provider_session_id,
+	p.amount, p.currency, p.exchange_rate, p.status, p.payment_method, p.payment_method_details,
+	p.attempts, p.max_attempts, p.next_retry_at, p.last_error, p.error_code,
+	p.ip_address, p.user_agent, p.processed_at, p.refunded_at, p.cancelled_at,
+	p.created_at, p.updated_at`
+
+// scanPayment escanea una fila seleccionada con paymentSelectColumns
+func scanPayment(row pgx.Row) (*entities.Payment, error) {
+	var p entities.Payment
+	err := row.Scan(
+		&p.ID, &p.PublicID, &p.OrderID, &p.ProviderID, &p.ProviderTransactionID, &p.ProviderSessionID,
+		&p.Amount, &p.Currency, &p.ExchangeRate, &p.Status, &p.PaymentMethod, &p.PaymentMethodDetails,
+		&p.Attempts, &p.MaxAttempts, &p.NextRetryAt, &p.LastError, &p.ErrorCode,
+		&p.IPAddress, &p.UserAgent, &p.ProcessedAt, &p.RefundedAt, &p.CancelledAt,
+		&p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// scanPaymentRows escanea todas las filas seleccionadas con paymentSelectColumns
+func scanPaymentRows(rows pgx.Rows) ([]*entities.Payment, error) {
+	var payments []*entities.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment row: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate payments: %w", err)
+	}
+	return payments, nil
+}
@@ -0,0 +1,227 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+// seedPayment crea un pago pendiente de una orden nueva con el primer proveedor configurado
+func seedPayment(t *testing.T, db testsupport.DB, repo *postgres.PaymentRepository) *entities.Payment {
+	t.Helper()
+	ctx := context.Background()
+
+	var providerID int16
+	err := db.QueryRow(ctx, `SELECT id FROM billing.payment_providers ORDER BY id LIMIT 1`).Scan(&providerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		t.Skip("no payment providers configured in the test database")
+	}
+	if err != nil {
+		t.Fatalf("failed to get payment provider: %v", err)
+	}
+
+	customerID := testsupport.SeedCustomer(t, db, "Pagador", "payment-"+uuid.NewString()[:8]+"@example.com")
+	orderID := testsupport.SeedOrder(t, db, customerID, "payment@example.com", 500, "pending")
+	payment := &entities.Payment{
+		OrderID:      orderID,
+		ProviderID:   providerID,
+		Amount:       500,
+		Currency:     "MXN",
+		ExchangeRate: 1,
+		Status:       "pending",
+		MaxAttempts:  3,
+	}
+	if err := repo.Create(ctx, payment); err != nil {
+		t.Fatalf("failed to create payment: %v", err)
+	}
+	return payment
+}
+
+func TestPaymentRepositoryUpdateStatusFollowsFlow(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewPaymentRepository(tx)
+	payment := seedPayment(t, tx, repo)
+
+	// pending -> captured se salta la autorización
+	if err := repo.UpdateStatus(ctx, payment.ID, "captured", nil); !errors.Is(err, repository.ErrInvalidPaymentTransition) {
+		t.Errorf("pending -> captured: err = %v, want ErrInvalidPaymentTransition", err)
+	}
+	if err := repo.UpdateStatus(ctx, payment.ID, "authorized", map[string]interface{}{"transaction_id": "pi_123"}); err != nil {
+		t.Fatalf("pending -> authorized: %v", err)
+	}
+	if err := repo.UpdateStatus(ctx, payment.ID, "refunded", nil); !errors.Is(err, repository.ErrInvalidPaymentTransition) {
+		t.Errorf("authorized -> refunded: err = %v, want ErrInvalidPaymentTransition", err)
+	}
+	if err := repo.UpdateStatus(ctx, payment.ID, "captured", nil); err != nil {
+		t.Fatalf("authorized -> captured: %v", err)
+	}
+	// Repetir el estado actual no es una transición inválida
+	if err := repo.UpdateStatus(ctx, payment.ID, "captured", nil); err != nil {
+		t.Errorf("captured -> captured: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, payment.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.Status != "captured" || got.ProcessedAt == nil {
+		t.Errorf("status = %q, processed_at = %v; want captured with processed_at", got.Status, got.ProcessedAt)
+	}
+	if got.ProviderTransactionID == nil || *got.ProviderTransactionID != "pi_123" {
+		t.Errorf("provider transaction = %v, want pi_123", got.ProviderTransactionID)
+	}
+
+	if err := repo.UpdateStatus(ctx, payment.ID, "refunded", nil); err != nil {
+		t.Fatalf("captured -> refunded: %v", err)
+	}
+	if err := repo.UpdateStatus(ctx, payment.ID, "captured", nil); !errors.Is(err, repository.ErrInvalidPaymentTransition) {
+		t.Errorf("refunded -> captured: err = %v, want ErrInvalidPaymentTransition", err)
+	}
+
+	var invalid *enums.InvalidPaymentStatusError
+	if err := repo.UpdateStatus(ctx, payment.ID, "settled", nil); !errors.As(err, &invalid) {
+		t.Errorf("unknown status: err = %v, want InvalidPaymentStatusError", err)
+	}
+	if err := repo.UpdateStatus(ctx, -1, "authorized", nil); !errors.Is(err, repository.ErrPaymentNotFound) {
+		t.Errorf("missing payment: err = %v, want ErrPaymentNotFound", err)
+	}
+}
+
+func TestPaymentRepositoryMarkHelpersUseFlow(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewPaymentRepository(tx)
+
+	// Un pago pendiente no puede reembolsarse ni darse por cobrado directamente
+	pending := seedPayment(t, tx, repo)
+	if err := repo.MarkAsRefunded(ctx, pending.ID, 1); !errors.Is(err, repository.ErrInvalidPaymentTransition) {
+		t.Errorf("MarkAsRefunded(pending): err = %v, want ErrInvalidPaymentTransition", err)
+	}
+	if err := repo.MarkAsProcessed(ctx, pending.ID, ""); !errors.Is(err, repository.ErrInvalidPaymentTransition) {
+		t.Errorf("MarkAsProcessed(pending): err = %v, want ErrInvalidPaymentTransition", err)
+	}
+	if err := repo.MarkAsFailed(ctx, pending.ID, "card declined", "card_declined"); err != nil {
+		t.Fatalf("MarkAsFailed: %v", err)
+	}
+	failed, err := repo.FindByID(ctx, pending.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if failed.Status != "failed" || failed.LastError == nil || *failed.LastError != "card declined" ||
+		failed.ErrorCode == nil || *failed.ErrorCode != "card_declined" {
+		t.Errorf("failed payment = %q %v %v, want failed with the provider error", failed.Status, failed.LastError, failed.ErrorCode)
+	}
+
+	authorized := seedPayment(t, tx, repo)
+	if err := repo.UpdateStatus(ctx, authorized.ID, "authorized", nil); err != nil {
+		t.Fatalf("pending -> authorized: %v", err)
+	}
+	processedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := repo.MarkAsProcessed(ctx, authorized.ID, processedAt.Format(time.RFC3339)); err != nil {
+		t.Fatalf("MarkAsProcessed: %v", err)
+	}
+	if err := repo.MarkAsRefunded(ctx, authorized.ID, 1); err != nil {
+		t.Fatalf("MarkAsRefunded: %v", err)
+	}
+	refunded, err := repo.FindByID(ctx, authorized.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if refunded.Status != "refunded" || refunded.RefundedAt == nil {
+		t.Errorf("status = %q, refunded_at = %v; want refunded with refunded_at", refunded.Status, refunded.RefundedAt)
+	}
+	if refunded.ProcessedAt == nil || !refunded.ProcessedAt.Equal(processedAt) {
+		t.Errorf("processed_at = %v, want %v", refunded.ProcessedAt, processedAt)
+	}
+
+	if err := repo.MarkAsProcessed(ctx, authorized.ID, "yesterday"); err == nil {
+		t.Error("MarkAsProcessed accepted a processed_at that is not RFC3339")
+	}
+}
+
+func TestPaymentRepositoryFindByPublicIDAndListByOrder(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewPaymentRepository(tx)
+	payment := seedPayment(t, tx, repo)
+
+	if payment.PublicID == "" {
+		t.Fatal("Create did not return the payment public id")
+	}
+	got, err := repo.FindByPublicID(ctx, payment.PublicID)
+	if err != nil {
+		t.Fatalf("FindByPublicID: %v", err)
+	}
+	if got.ID != payment.ID || got.OrderID != payment.OrderID || got.Status != "pending" {
+		t.Errorf("FindByPublicID = %+v, want payment %d of order %d", got, payment.ID, payment.OrderID)
+	}
+	if _, err := repo.FindByPublicID(ctx, uuid.NewString()); !errors.Is(err, repository.ErrPaymentNotFound) {
+		t.Errorf("unknown public id: err = %v, want ErrPaymentNotFound", err)
+	}
+
+	// Un segundo intento de la misma orden aparece primero
+	retry := &entities.Payment{
+		OrderID:      payment.OrderID,
+		ProviderID:   payment.ProviderID,
+		Amount:       payment.Amount,
+		Currency:     payment.Currency,
+		ExchangeRate: 1,
+		Status:       "pending",
+		MaxAttempts:  3,
+	}
+	if err := repo.Create(ctx, retry); err != nil {
+		t.Fatalf("Create retry: %v", err)
+	}
+
+	var orderPublicID string
+	if err := tx.QueryRow(ctx, `SELECT public_uuid FROM billing.orders WHERE id = $1`, payment.OrderID).Scan(&orderPublicID); err != nil {
+		t.Fatalf("failed to get order public id: %v", err)
+	}
+	payments, err := repo.ListByOrder(ctx, orderPublicID)
+	if err != nil {
+		t.Fatalf("ListByOrder: %v", err)
+	}
+	if len(payments) != 2 || payments[0].ID != retry.ID || payments[1].ID != payment.ID {
+		t.Errorf("ListByOrder returned %d payments, want [%d %d] newest first", len(payments), retry.ID, payment.ID)
+	}
+	if _, err := repo.ListByOrder(ctx, uuid.NewString()); !errors.Is(err, repository.ErrOrderNotFound) {
+		t.Errorf("unknown order: err = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestPaymentRepositoryUpdateDoesNotChangeStatus(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewPaymentRepository(tx)
+	payment := seedPayment(t, tx, repo)
+
+	lastError := "card declined"
+	payment.Status = "refunded"
+	payment.Attempts = 2
+	payment.LastError = &lastError
+	if err := repo.Update(ctx, payment); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, payment.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.Status != "pending" {
+		t.Errorf("status = %q, want pending: Update must not bypass the transition check", got.Status)
+	}
+	if got.Attempts != 2 || got.LastError == nil || *got.LastError != lastError {
+		t.Errorf("attempts/last_error = %d/%v, want 2/%q", got.Attempts, got.LastError, lastError)
+	}
+}
@@ -0,0 +1,98 @@
+// osmi/osmi-server/internal/infrastructure/repositories/postgres/read_router.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// readRouter envía las lecturas a la réplica si existe. Si la réplica falla a
+// nivel de conexión reintenta una vez contra el primario. Las escrituras nunca
+// pasan por aquí: siguen usando el pool primario directamente.
+type readRouter struct {
+	primary DBTX
+	replica DBTX
+}
+
+// query ejecuta una lectura con fallback al primario
+func (rr *readRouter) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if rr.replica == nil {
+		return rr.primary.Query(ctx, sql, args...)
+	}
+
+	rows, err := rr.replica.Query(ctx, sql, args...)
+	if err == nil {
+		return rows, nil
+	}
+	if ctx.Err() != nil || !isConnectionError(err) {
+		return nil, err
+	}
+
+	log.Printf("⚠️ Read replica unavailable, falling back to primary: %v", err)
+	return rr.primary.Query(ctx, sql, args...)
+}
+
+// queryRow es el equivalente de QueryRow con el mismo fallback
+func (rr *readRouter) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := rr.query(ctx, sql, args...)
+	return &routedRow{rows: rows, err: err}
+}
+
+// routedRow adapta pgx.Rows a pgx.Row
+type routedRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *routedRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
+
+// isConnectionError distingue fallos de conexión (réplica caída, red, reinicio)
+// de errores de la consulta, que no deben reintentarse
+func isConnectionError(err error) bool {
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 08xxx connection_exception, 57P01 admin_shutdown, 57P02 crash_shutdown, 57P03 cannot_connect_now
+		switch {
+		case len(pgErr.Code) == 5 && pgErr.Code[:2] == "08":
+			return true
+		case pgErr.Code == "57P01", pgErr.Code == "57P02", pgErr.Code == "57P03":
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || pgconn.SafeToRetry(err)
+}
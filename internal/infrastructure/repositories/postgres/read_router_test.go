@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// stubQuerier cuenta las consultas y devuelve err en cada una
+type stubQuerier struct {
+	DBTX
+
+	err   error
+	calls int
+}
+
+func (db *stubQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.calls++
+	return nil, db.err
+}
+
+// captureLog redirige el log estándar durante el test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestReadRouterFallsBackToPrimaryOnConnectionError(t *testing.T) {
+	logs := captureLog(t)
+	replicaDown := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	replica := &stubQuerier{err: replicaDown}
+	primary := &stubQuerier{}
+	router := &readRouter{primary: primary, replica: replica}
+
+	if _, err := router.query(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if replica.calls != 1 || primary.calls != 1 {
+		t.Errorf("calls = replica %d / primary %d, want 1 / 1", replica.calls, primary.calls)
+	}
+	if !strings.Contains(logs.String(), "falling back to primary") {
+		t.Errorf("log = %q, want the fallback logged", logs.String())
+	}
+}
+
+func TestReadRouterDoesNotRetryQueryErrors(t *testing.T) {
+	queryErr := &pgconn.PgError{Code: "42P01", Message: "relation does not exist"}
+	replica := &stubQuerier{err: queryErr}
+	primary := &stubQuerier{}
+	router := &readRouter{primary: primary, replica: replica}
+
+	if _, err := router.query(context.Background(), "SELECT 1"); !errors.Is(err, queryErr) {
+		t.Errorf("error = %v, want the replica error", err)
+	}
+	if primary.calls != 0 {
+		t.Errorf("primary calls = %d, want 0", primary.calls)
+	}
+}
+
+func TestReadRouterWithoutReplicaUsesPrimary(t *testing.T) {
+	primary := &stubQuerier{}
+	router := &readRouter{primary: primary}
+
+	if _, err := router.query(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary calls = %d, want 1", primary.calls)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"network", &net.OpError{Op: "read", Err: errors.New("reset")}, true},
+		{"undefined table", &pgconn.PgError{Code: "42P01"}, false},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := isConnectionError(tt.err); got != tt.want {
+			t.Errorf("isConnectionError(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
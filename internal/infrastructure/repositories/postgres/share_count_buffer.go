@@ -0,0 +1,110 @@
+// osmi/osmi-server/internal/infrastructure/repositories/postgres/share_count_buffer.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// shareCountBuffer acumula incrementos de share_count en memoria y los
+// persiste periódicamente con un único UPDATE por evento
+type shareCountBuffer struct {
+	db       DBTX
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[int64]int
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// newShareCountBuffer crea el buffer e inicia el flush periódico
+func newShareCountBuffer(db DBTX, interval time.Duration) *shareCountBuffer {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	b := &shareCountBuffer{
+		db:       db,
+		interval: interval,
+		pending:  make(map[int64]int),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go b.run()
+	return b
+}
+
+// add acumula un incremento para el evento
+func (b *shareCountBuffer) add(eventID int64, delta int) {
+	b.mu.Lock()
+	b.pending[eventID] += delta
+	b.mu.Unlock()
+}
+
+// run ejecuta el flush en cada intervalo hasta que se cierre el buffer
+func (b *shareCountBuffer) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), b.interval)
+			if err := b.flush(ctx); err != nil {
+				log.Printf("⚠️ Error flushing share counts: %v", err)
+			}
+			cancel()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// flush persiste los incrementos acumulados. Los que fallan se devuelven al
+// buffer para reintentarlos en el siguiente flush.
+func (b *shareCountBuffer) flush(ctx context.Context) error {
+	b.mu.Lock()
+	if len(b.pending) == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	batch := b.pending
+	b.pending = make(map[int64]int)
+	b.mu.Unlock()
+
+	query := `
+		UPDATE ticketing.events
+		SET share_count = share_count + $1
+		WHERE id = $2
+	`
+
+	var firstErr error
+	for eventID, delta := range batch {
+		if _, err := b.db.Exec(ctx, query, delta, eventID); err != nil {
+			b.add(eventID, delta)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to flush share count for event %d: %w", eventID, err)
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// close detiene el flush periódico y persiste lo pendiente
+func (b *shareCountBuffer) close(ctx context.Context) error {
+	b.once.Do(func() {
+		close(b.stop)
+	})
+	<-b.done
+	return b.flush(ctx)
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// recordingDB acumula los deltas de share_count que recibe por Exec
+type recordingDB struct {
+	DBTX
+
+	mu     sync.Mutex
+	counts map[int64]int
+	fail   bool
+}
+
+func newRecordingDB() *recordingDB {
+	return &recordingDB{counts: make(map[int64]int)}
+}
+
+func (db *recordingDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.fail {
+		return pgconn.CommandTag{}, errors.New("connection refused")
+	}
+	db.counts[args[1].(int64)] += args[0].(int)
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (db *recordingDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (db *recordingDB) count(eventID int64) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.counts[eventID]
+}
+
+func (db *recordingDB) setFail(fail bool) {
+	db.mu.Lock()
+	db.fail = fail
+	db.mu.Unlock()
+}
+
+func TestShareCountBufferFlushesPeriodically(t *testing.T) {
+	db := newRecordingDB()
+	buffer := newShareCountBuffer(db, 10*time.Millisecond)
+	defer buffer.close(context.Background())
+
+	for i := 0; i < 3; i++ {
+		buffer.add(1, 1)
+	}
+	buffer.add(2, 1)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for db.count(1) != 3 || db.count(2) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("increments not persisted: event 1 = %d, event 2 = %d", db.count(1), db.count(2))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestShareCountBufferFlushesOnClose(t *testing.T) {
+	db := newRecordingDB()
+	buffer := newShareCountBuffer(db, time.Hour)
+
+	buffer.add(7, 1)
+	buffer.add(7, 1)
+	if got := db.count(7); got != 0 {
+		t.Fatalf("increments persisted before flush: %d", got)
+	}
+
+	if err := buffer.close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := db.count(7); got != 2 {
+		t.Errorf("share count after close = %d, want 2", got)
+	}
+}
+
+func TestShareCountBufferRequeuesFailedFlush(t *testing.T) {
+	db := newRecordingDB()
+	buffer := newShareCountBuffer(db, time.Hour)
+
+	buffer.add(3, 4)
+	db.setFail(true)
+	if err := buffer.flush(context.Background()); err == nil {
+		t.Fatal("flush error = nil, want failure")
+	}
+
+	db.setFail(false)
+	if err := buffer.close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := db.count(3); got != 4 {
+		t.Errorf("share count after retry = %d, want 4", got)
+	}
+}
@@ -0,0 +1,87 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	orderdto "github.com/franciscozamorau/osmi-server/internal/api/dto/order"
+	paymentdto "github.com/franciscozamorau/osmi-server/internal/api/dto/payment"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+// Los GetStats deben devolver ceros, no errores de scan por NULL, cuando no hay datos
+
+func TestCustomerRepositoryGetStatsEmpty(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+
+	// El TRUNCATE se revierte con la transacción del test
+	if _, err := tx.Exec(ctx, `TRUNCATE crm.customers CASCADE`); err != nil {
+		t.Fatalf("failed to empty customers: %v", err)
+	}
+
+	stats, err := postgres.NewCustomerRepository(tx).GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.TotalCustomers != 0 || stats.TotalRevenue != 0 || stats.AvgLifetimeValue != 0 {
+		t.Errorf("stats = %+v, want zeros", stats)
+	}
+}
+
+func TestOrderRepositoryGetStatsEmpty(t *testing.T) {
+	filter := orderdto.OrderFilter{CustomerEmail: uuid.NewString() + "@nobody.invalid"}
+
+	stats, err := postgres.NewOrderRepository(testsupport.Tx(t)).GetStats(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.TotalOrders != 0 || stats.TotalRevenue != 0 || stats.AvgOrderValue != 0 || stats.ConversionRate != 0 {
+		t.Errorf("stats = %+v, want zeros", stats)
+	}
+}
+
+func TestPaymentRepositoryGetStatsEmpty(t *testing.T) {
+	filter := paymentdto.PaymentFilter{OrderID: uuid.NewString()}
+
+	stats, err := postgres.NewPaymentRepository(testsupport.Tx(t)).GetStats(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.TotalPayments != 0 || stats.TotalVolume != 0 || stats.AvgPaymentValue != 0 || stats.SuccessRate != 0 {
+		t.Errorf("stats = %+v, want zeros", stats)
+	}
+}
+
+func TestEventScopedStatsEmpty(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	eventID := seedEvent(t, tx, "Sin ventas")
+
+	typeStats, err := postgres.NewTicketTypeRepository(tx).GetEventTicketStats(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetEventTicketStats: %v", err)
+	}
+	if typeStats.TotalQuantity != 0 || typeStats.Revenue != 0 || typeStats.SellThroughRate != 0 {
+		t.Errorf("ticket type stats = %+v, want zeros", typeStats)
+	}
+
+	ticketStats, err := postgres.NewTicketRepository(tx).GetEventStats(ctx, testsupport.PublicID(t, tx, "ticketing.events", eventID))
+	if err != nil {
+		t.Fatalf("GetEventStats: %v", err)
+	}
+	if ticketStats.TotalTickets != 0 || ticketStats.TotalRevenue != 0 || ticketStats.AvgTicketPrice != 0 {
+		t.Errorf("ticket stats = %+v, want zeros", ticketStats)
+	}
+
+	reservations, err := postgres.NewEventRepository(tx).GetReservationStats(ctx, eventID)
+	if err != nil {
+		t.Fatalf("GetReservationStats: %v", err)
+	}
+	if reservations.Made != 0 || reservations.ConversionRate != 0 {
+		t.Errorf("reservation stats = %+v, want zeros", reservations)
+	}
+}
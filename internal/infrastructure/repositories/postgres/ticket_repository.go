@@ -8,22 +8,25 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
 	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/domain/valueobjects"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres/helpers/query"
 )
 
 // TicketRepository implementa la interfaz repository.TicketRepository usando PostgreSQL
 type TicketRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 // NewTicketRepository crea una nueva instancia del repositorio
-func NewTicketRepository(db *pgxpool.Pool) *TicketRepository {
+func NewTicketRepository(db DBTX) *TicketRepository {
 	return &TicketRepository{
 		db: db,
 	}
@@ -59,12 +62,155 @@ func (r *TicketRepository) handleError(err error, context string) error {
 	return fmt.Errorf("%s: %w", context, err)
 }
 
+// ReassignCategory mueve un ticket a otro tipo de ticket (categoría) del mismo
+// evento, p. ej. upgrade o downgrade por soporte. Traslada el inventario que
+// ocupa el ticket, recalcula precio final e impuestos con el nuevo tipo (igual
+// que al crear el ticket) y registra el cambio.
+func (r *TicketRepository) ReassignCategory(ctx context.Context, ticketPublicID, newCategoryPublicID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ticketID, oldTypeID, eventID int64
+	var currentStatus string
+	err = tx.QueryRow(ctx, `
+		SELECT id, ticket_type_id, event_id, status
+		FROM ticketing.tickets
+		WHERE public_uuid = $1
+		FOR UPDATE
+	`, ticketPublicID).Scan(&ticketID, &oldTypeID, &eventID, &currentStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrTicketNotFound
+		}
+		return r.handleError(err, "failed to get ticket for reassignment")
+	}
+
+	status := enums.TicketStatus(currentStatus)
+	if !status.IsOwned() && status != enums.TicketStatusReserved {
+		return repository.ErrTicketNotAvailable
+	}
+
+	// Precio, cargo y tarifa se recalculan igual que al crear el ticket
+	var newType entities.TicketType
+	err = tx.QueryRow(ctx, `
+		SELECT id, event_id, base_price, currency, COALESCE(tax_rate, 0),
+			COALESCE(service_fee_type, ''), COALESCE(service_fee_value, 0)
+		FROM ticketing.ticket_types
+		WHERE public_uuid = $1 AND is_active = true
+	`, newCategoryPublicID).Scan(
+		&newType.ID, &newType.EventID, &newType.BasePrice, &newType.Currency, &newType.TaxRate,
+		&newType.ServiceFeeType, &newType.ServiceFeeValue,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrTicketTypeNotFound
+		}
+		return r.handleError(err, "failed to get target ticket type")
+	}
+	newTypeID, newEventID := newType.ID, newType.EventID
+	if newEventID != eventID {
+		return repository.ErrTicketTypeMismatch
+	}
+	if newTypeID == oldTypeID {
+		return nil
+	}
+
+	// Ambos tipos se bloquean en orden de id para no producir deadlocks
+	rows, err := tx.Query(ctx, `
+		SELECT id, (total_quantity - sold_quantity - reserved_quantity)
+		FROM ticketing.ticket_types
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`, []int64{oldTypeID, newTypeID})
+	if err != nil {
+		return r.handleError(err, "failed to lock ticket types")
+	}
+	var newAvailable int
+	for rows.Next() {
+		var id int64
+		var available int
+		if err := rows.Scan(&id, &available); err != nil {
+			rows.Close()
+			return r.handleError(err, "failed to scan ticket type availability")
+		}
+		if id == newTypeID {
+			newAvailable = available
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return r.handleError(err, "failed to read ticket type availability")
+	}
+	if newAvailable < 1 {
+		return repository.ErrTicketNotAvailable
+	}
+
+	column := "sold_quantity"
+	if status == enums.TicketStatusReserved {
+		column = "reserved_quantity"
+	}
+	inventoryQuery := `
+		UPDATE ticketing.ticket_types
+		SET ` + column + ` = GREATEST(0, ` + column + ` + $2),
+			updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, inventoryQuery, oldTypeID, -1); err != nil {
+		return r.handleError(err, "failed to release inventory from old ticket type")
+	}
+	if _, err := tx.Exec(ctx, inventoryQuery, newTypeID, 1); err != nil {
+		return r.handleError(err, "failed to take inventory from new ticket type")
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE ticketing.ticket_types
+		SET available_quantity = total_quantity - sold_quantity - reserved_quantity,
+			is_sold_out = (total_quantity - sold_quantity - reserved_quantity) <= 0
+		WHERE id = ANY($1)
+	`, []int64{oldTypeID, newTypeID})
+	if err != nil {
+		return r.handleError(err, "failed to refresh ticket type availability")
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ticketing.tickets
+		SET ticket_type_id = $1,
+			final_price = $2,
+			tax_amount = $3,
+			currency = $4,
+			updated_at = NOW()
+		WHERE id = $5
+	`, newTypeID, newType.GetFinalPrice(), newType.BasePrice*newType.TaxRate,
+		valueobjects.CurrencyOrDefault(newType.Currency), ticketID)
+	if err != nil {
+		return r.handleError(err, "failed to reassign ticket")
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ticketing.ticket_status_history (ticket_id, from_status, to_status, reason, changed_at)
+		VALUES ($1, $2, $2, $3, NOW())
+	`, ticketID, currentStatus, fmt.Sprintf("reassigned from ticket type %d to %d", oldTypeID, newTypeID))
+	if err != nil {
+		return r.handleError(err, "failed to record ticket reassignment")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // Find busca tickets según los criterios del filtro (CON JOINS)
 func (r *TicketRepository) Find(ctx context.Context, filter *repository.TicketFilter) ([]*entities.Ticket, int64, error) {
 	baseQuery := `
     SELECT 
         t.id, t.public_uuid, t.ticket_type_id, t.event_id, t.customer_id, t.order_id,
-        t.code, t.secret_hash, t.qr_code_data, t.status, t.final_price, t.currency, t.tax_amount,
+        t.code, t.secret_hash, t.qr_code_data, t.status, t.final_price,
+        COALESCE(NULLIF(t.currency, ''), tt.currency, '') AS currency, t.tax_amount,
         t.attendee_name, t.attendee_email, t.attendee_phone,
         t.checked_in_at, t.checked_in_by, t.checkin_method, t.checkin_location,
         t.reserved_at, t.reserved_by, t.reservation_expires_at,
@@ -143,7 +289,7 @@ func (r *TicketRepository) Find(ctx context.Context, filter *repository.TicketFi
 		if len(filter.Status) > 0 {
 			statusStrings := make([]string, len(filter.Status))
 			for i, s := range filter.Status {
-				statusStrings[i] = string(s)
+				statusStrings[i] = strings.ToLower(string(s))
 			}
 			conditions = append(conditions, fmt.Sprintf("status = ANY(@status_%d)", argPos))
 			args[fmt.Sprintf("status_%d", argPos)] = statusStrings
@@ -316,6 +462,65 @@ func (r *TicketRepository) Find(ctx context.Context, filter *repository.TicketFi
 	return tickets, total, nil
 }
 
+// defaultTicketPageSize es el tamaño de página de ListTicketsPaged si no se indica otro
+const defaultTicketPageSize = 20
+
+// ListTicketsPaged lista tickets por keyset sobre t.id en lugar de OFFSET, así
+// las páginas no se desplazan ni se degradan cuando la tabla cambia entre
+// llamadas. cursor es el valor opaco devuelto por la página anterior ("" para
+// la primera) y el resultado incluye el cursor siguiente ("" si no hay más).
+// El orden es siempre por id ascendente; SortBy, SortOrder, Limit y Offset del
+// filtro se ignoran. Como el id es único no hay filas con el mismo valor de
+// cursor que puedan saltarse entre páginas.
+func (r *TicketRepository) ListTicketsPaged(ctx context.Context, filter *repository.TicketFilter, cursor string, limit int) ([]*entities.Ticket, string, error) {
+	after, err := query.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", commondto.ErrValidation, err)
+	}
+	if limit <= 0 {
+		limit = defaultTicketPageSize
+	}
+
+	qb := query.NewQueryBuilder(`SELECT ` + ticketSelectColumns + ` FROM ticketing.tickets t`)
+	if filter != nil {
+		if filter.EventID != nil {
+			qb.Where("t.event_id = ?", *filter.EventID)
+		}
+		if filter.TicketTypeID != nil {
+			qb.Where("t.ticket_type_id = ?", *filter.TicketTypeID)
+		}
+		if filter.CustomerID != nil {
+			qb.Where("t.customer_id = ?", *filter.CustomerID)
+		}
+		if filter.OrderID != nil {
+			qb.Where("t.order_id = ?", *filter.OrderID)
+		}
+		if len(filter.Status) > 0 {
+			statuses := make([]interface{}, len(filter.Status))
+			for i, s := range filter.Status {
+				statuses[i] = string(s)
+			}
+			qb.WhereInLower("t.status", statuses)
+		}
+	}
+	qb.SeekAfter("t.id", after).Limit(limit)
+
+	sql, args := qb.BuildSeek()
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, "", r.handleError(err, "failed to list tickets")
+	}
+	defer rows.Close()
+
+	tickets, err := scanTicketRows(rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tickets, next := query.SeekPage(tickets, limit, func(t *entities.Ticket) int64 { return t.ID })
+	return tickets, next, nil
+}
+
 // GetByID obtiene un ticket por su ID numérico
 func (r *TicketRepository) GetByID(ctx context.Context, id int64) (*entities.Ticket, error) {
 	filter := &repository.TicketFilter{
@@ -373,6 +578,54 @@ func (r *TicketRepository) GetByCode(ctx context.Context, code string) (*entitie
 	return tickets[0], nil
 }
 
+// GetTicketsWithDetailsByOrder obtiene los tickets de una orden con evento, tipo y
+// categoría principal en una sola consulta (confirmaciones y recibos)
+func (r *TicketRepository) GetTicketsWithDetailsByOrder(ctx context.Context, orderID int64) ([]*repository.TicketWithDetails, error) {
+	query := `
+		SELECT ` + ticketSelectColumns + `,
+			e.name, COALESCE(e.venue_name, ''), COALESCE(c.name, ''),
+			tt.name, e.public_uuid, e.starts_at
+		FROM ticketing.tickets t
+		JOIN ticketing.events e ON t.event_id = e.id
+		JOIN ticketing.ticket_types tt ON t.ticket_type_id = tt.id
+		LEFT JOIN ticketing.event_categories ec ON ec.event_id = e.id AND ec.is_primary = true
+		LEFT JOIN ticketing.categories c ON c.id = ec.category_id
+		WHERE t.order_id = $1
+		ORDER BY t.id
+	`
+
+	rows, err := r.db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get tickets with details by order")
+	}
+	defer rows.Close()
+
+	var tickets []*repository.TicketWithDetails
+	for rows.Next() {
+		var ticket entities.Ticket
+		details := &repository.TicketWithDetails{Ticket: &ticket}
+		err := rows.Scan(
+			&ticket.ID, &ticket.PublicID, &ticket.TicketTypeID, &ticket.EventID, &ticket.CustomerID, &ticket.OrderID,
+			&ticket.Code, &ticket.SecretHash, &ticket.QRCodeData, &ticket.Status, &ticket.FinalPrice, &ticket.Currency, &ticket.TaxAmount,
+			&ticket.AttendeeName, &ticket.AttendeeEmail, &ticket.AttendeePhone,
+			&ticket.CheckedInAt, &ticket.CheckedInBy, &ticket.CheckinMethod, &ticket.CheckinLocation,
+			&ticket.ReservedAt, &ticket.ReservedBy, &ticket.ReservationExpiresAt,
+			&ticket.TransferToken, &ticket.TransferredFrom, &ticket.TransferredAt,
+			&ticket.ValidationCount, &ticket.LastValidatedAt,
+			&ticket.SoldAt, &ticket.CancelledAt, &ticket.RefundedAt,
+			&ticket.CreatedAt, &ticket.UpdatedAt,
+			&ticket.EventName, &ticket.Location, &ticket.CategoryName,
+			&details.TicketTypeName, &details.EventPublicID, &details.EventStartsAt,
+		)
+		if err != nil {
+			return nil, r.handleError(err, "failed to scan ticket with details")
+		}
+		tickets = append(tickets, details)
+	}
+
+	return tickets, rows.Err()
+}
+
 // Create inserta un nuevo ticket
 func (r *TicketRepository) Create(ctx context.Context, ticket *entities.Ticket) error {
 	// Validar el ticket
@@ -479,6 +732,173 @@ func (r *TicketRepository) CreateBatch(ctx context.Context, tickets []*entities.
 	return tx.Commit(ctx)
 }
 
+// GenerateComplimentaryTickets emite tickets de cortesía (vendidos a precio 0)
+// para cada invitado en una sola transacción. El cupo del tipo de ticket se
+// valida para el lote completo: o se emiten todos o ninguno. Devuelve los
+// códigos en el mismo orden que guests.
+func (r *TicketRepository) GenerateComplimentaryTickets(ctx context.Context, eventPublicID, categoryPublicID string, guests []repository.GuestInfo) ([]string, error) {
+	if len(guests) == 0 {
+		return []string{}, nil
+	}
+	for i, guest := range guests {
+		if strings.TrimSpace(guest.Email) == "" {
+			return nil, fmt.Errorf("guest %d: email is required", i)
+		}
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var eventID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM ticketing.events WHERE public_uuid = $1`, eventPublicID).Scan(&eventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrEventNotFound
+		}
+		return nil, r.handleError(err, "failed to get event")
+	}
+
+	var typeID, typeEventID int64
+	var available int
+	var currency string
+	err = tx.QueryRow(ctx, `
+		SELECT id, event_id, (total_quantity - sold_quantity - reserved_quantity), currency
+		FROM ticketing.ticket_types
+		WHERE public_uuid = $1 AND is_active = true
+		FOR UPDATE
+	`, categoryPublicID).Scan(&typeID, &typeEventID, &available, &currency)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrTicketTypeNotFound
+		}
+		return nil, r.handleError(err, "failed to lock ticket type")
+	}
+	if typeEventID != eventID {
+		return nil, repository.ErrTicketTypeMismatch
+	}
+	if available < len(guests) {
+		return nil, fmt.Errorf("%w: only %d left", repository.ErrNotEnoughTickets, available)
+	}
+
+	customers := make(map[string]int64, len(guests))
+	codes := make([]string, 0, len(guests))
+	for _, guest := range guests {
+		email := strings.ToLower(strings.TrimSpace(guest.Email))
+
+		customerID, ok := customers[email]
+		if !ok {
+			customerID, err = r.findOrCreateGuestCustomer(ctx, tx, email, guest)
+			if err != nil {
+				return nil, err
+			}
+			customers[email] = customerID
+		}
+
+		code := fmt.Sprintf("COMP-%d-%d-%s", eventID, typeID, strings.ReplaceAll(uuid.New().String(), "-", "")[:12])
+		_, err = tx.Exec(ctx, `
+			INSERT INTO ticketing.tickets (
+				public_uuid, ticket_type_id, event_id, customer_id,
+				code, secret_hash, status, final_price, currency, tax_amount,
+				attendee_name, attendee_email, attendee_phone,
+				sold_at, created_at, updated_at
+			) VALUES (
+				gen_random_uuid(), $1, $2, $3,
+				$4, $5, $6, 0, $7, 0,
+				NULLIF($8, ''), $9, NULLIF($10, ''),
+				NOW(), NOW(), NOW()
+			)
+		`, typeID, eventID, customerID,
+			code, entities.NewTicketSecretHash(), string(enums.TicketStatusSold), currency,
+			strings.TrimSpace(guest.Name), email, strings.TrimSpace(guest.Phone),
+		)
+		if err != nil {
+			return nil, r.handleError(err, "failed to create complimentary ticket")
+		}
+		codes = append(codes, code)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ticketing.ticket_types
+		SET sold_quantity = sold_quantity + $1,
+			available_quantity = total_quantity - (sold_quantity + $1) - reserved_quantity,
+			is_sold_out = (total_quantity - (sold_quantity + $1) - reserved_quantity) <= 0,
+			updated_at = NOW()
+		WHERE id = $2
+	`, len(guests), typeID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to update ticket type inventory")
+	}
+	if err := markEventSoldOut(ctx, tx, typeID); err != nil {
+		return nil, r.handleError(err, "failed to mark event as sold out")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return codes, nil
+}
+
+// findOrCreateGuestCustomer busca un cliente por email (sin distinguir
+// mayúsculas) y, si no existe, lo crea como invitado
+func (r *TicketRepository) findOrCreateGuestCustomer(ctx context.Context, tx pgx.Tx, email string, guest repository.GuestInfo) (int64, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM crm.customers
+		WHERE LOWER(email) = $1
+		ORDER BY id
+		LIMIT 1
+	`, email).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, r.handleError(err, "failed to find guest customer")
+	}
+
+	name := strings.TrimSpace(guest.Name)
+	if name == "" {
+		name = email
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO crm.customers (
+			public_uuid, full_name, email, phone,
+			is_active, source, created_at, updated_at
+		) VALUES (
+			gen_random_uuid(), $1, $2, NULLIF($3, ''),
+			true, 'complimentary', NOW(), NOW()
+		)
+		RETURNING id
+	`, name, email, strings.TrimSpace(guest.Phone)).Scan(&id)
+	if err != nil {
+		return 0, r.handleError(err, "failed to create guest customer")
+	}
+	return id, nil
+}
+
+// SetQRCodeURL guarda la URL del QR del ticket en qr_code_data, de donde se
+// arma TicketResponse.QrCodeUrl
+func (r *TicketRepository) SetQRCodeURL(ctx context.Context, ticketID int64, url string) error {
+	cmdTag, err := r.db.Exec(ctx, `
+		UPDATE ticketing.tickets
+		SET qr_code_data = $1,
+			updated_at = NOW()
+		WHERE id = $2
+	`, url, ticketID)
+	if err != nil {
+		return r.handleError(err, "failed to set qr code url")
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return repository.ErrTicketNotFound
+	}
+
+	return nil
+}
+
 // Update actualiza un ticket existente
 func (r *TicketRepository) Update(ctx context.Context, ticket *entities.Ticket) error {
 	query := `
@@ -570,7 +990,7 @@ func (r *TicketRepository) ExistsByCode(ctx context.Context, code string) (bool,
 	return exists, nil
 }
 
-// UpdateStatus actualiza el estado de un ticket
+// UpdateStatus actualiza el estado de un ticket. Si ya tiene ese estado no hace nada.
 func (r *TicketRepository) UpdateStatus(ctx context.Context, ticketID int64, status enums.TicketStatus) error {
 	// Verificar transición válida
 	var currentStatus string
@@ -579,6 +999,11 @@ func (r *TicketRepository) UpdateStatus(ctx context.Context, ticketID int64, sta
 		return r.handleError(err, "failed to get current status")
 	}
 
+	// Reintentar el mismo estado no es una transición: se trata como no-op
+	if enums.TicketStatus(currentStatus) == status {
+		return nil
+	}
+
 	if !enums.CanTransitionTicket(enums.TicketStatus(currentStatus), status) {
 		return repository.ErrInvalidTicketStatus
 	}
@@ -601,7 +1026,7 @@ func (r *TicketRepository) UpdateStatus(ctx context.Context, ticketID int64, sta
 }
 
 // CheckIn marca un ticket como usado (check-in)
-func (r *TicketRepository) CheckIn(ctx context.Context, ticketID int64, method, location string, checkedBy *int64) error {
+func (r *TicketRepository) CheckIn(ctx context.Context, ticketID int64, method enums.CheckInMethod, location string, checkedBy *int64) error {
 	now := time.Now()
 	query := `
 		UPDATE ticketing.tickets 
@@ -613,9 +1038,9 @@ func (r *TicketRepository) CheckIn(ctx context.Context, ticketID int64, method,
 			validation_count = validation_count + 1,
 			last_validated_at = $1,
 			updated_at = $1
-		WHERE id = $5 AND status = 'sold'
+		WHERE id = $5 AND status IN ('sold', 'transferred')
 	`
-	cmdTag, err := r.db.Exec(ctx, query, now, checkedBy, method, location, ticketID)
+	cmdTag, err := r.db.Exec(ctx, query, now, checkedBy, string(method), location, ticketID)
 	if err != nil {
 		return r.handleError(err, "failed to check in ticket")
 	}
@@ -651,6 +1076,76 @@ func (r *TicketRepository) Reserve(ctx context.Context, ticketID int64, reserved
 	return nil
 }
 
+// ReserveTicket reserva un ticket disponible por UUID público a nombre del
+// usuario indicado. En la misma transacción descuenta el cupo del tipo de
+// ticket (reservados + 1, disponibles - 1); el ticket se bloquea antes que su
+// tipo, el mismo orden que usa el barrido de reservas vencidas.
+func (r *TicketRepository) ReserveTicket(ctx context.Context, ticketPublicID, userPublicID string, expiresAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ticketID, ticketTypeID int64
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT id, ticket_type_id, status
+		FROM ticketing.tickets
+		WHERE public_uuid = $1
+		FOR UPDATE
+	`, ticketPublicID).Scan(&ticketID, &ticketTypeID, &status)
+	if err != nil {
+		return r.handleError(err, "failed to lock ticket")
+	}
+
+	var userID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM auth.users WHERE public_uuid = $1`, userPublicID).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrUserNotFound
+		}
+		return r.handleError(err, "failed to get user")
+	}
+
+	if status != string(enums.TicketStatusAvailable) {
+		return repository.ErrTicketNotAvailable
+	}
+
+	cmdTag, err := tx.Exec(ctx, `
+		UPDATE ticketing.ticket_types
+		SET reserved_quantity = reserved_quantity + 1,
+			available_quantity = available_quantity - 1,
+			updated_at = NOW()
+		WHERE id = $1
+			AND total_quantity - sold_quantity - reserved_quantity >= 1
+	`, ticketTypeID)
+	if err != nil {
+		return r.handleError(err, "failed to update ticket type quantities")
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: ticket type %d has no tickets left", repository.ErrNotEnoughTickets, ticketTypeID)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ticketing.tickets
+		SET status = 'reserved',
+			reserved_at = NOW(),
+			reserved_by = $1,
+			reservation_expires_at = $2,
+			updated_at = NOW()
+		WHERE id = $3
+	`, userID, expiresAt, ticketID)
+	if err != nil {
+		return r.handleError(err, "failed to reserve ticket")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // ReleaseReservation libera una reserva
 func (r *TicketRepository) ReleaseReservation(ctx context.Context, ticketID int64) error {
 	query := `
@@ -689,9 +1184,10 @@ func (r *TicketRepository) Transfer(ctx context.Context, ticketID int64, toCusto
 			transferred_from = $2, 
 			transferred_at = NOW(),
 			transfer_token = $3,
-			status = 'sold',
+			transferred_from_ticket_id = id,
+			status = 'transferred',
 			updated_at = NOW()
-		WHERE id = $4 AND status = 'sold'
+		WHERE id = $4 AND status IN ('sold', 'transferred')
 	`
 	cmdTag, err := r.db.Exec(ctx, query, toCustomerID, fromCustomerID, transferToken, ticketID)
 	if err != nil {
@@ -705,6 +1201,87 @@ func (r *TicketRepository) Transfer(ctx context.Context, ticketID int64, toCusto
 	return nil
 }
 
+// TransferTicket cambia el dueño de un ticket vendido de fromCustomer a
+// toCustomer dentro de una transacción. El ticket pasa a 'transferred', que
+// admite check-in y reembolso igual que 'sold'; el titular anterior queda en
+// transferred_from, el ticket de origen en transferred_from_ticket_id y el cambio
+// en ticket_status_history. Los tickets usados o reembolsados no se transfieren.
+func (r *TicketRepository) TransferTicket(ctx context.Context, ticketPublicID, fromCustomerPublicID, toCustomerPublicID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ticketID int64
+	var ownerID *int64
+	var currentStatus string
+	err = tx.QueryRow(ctx, `
+		SELECT id, customer_id, status
+		FROM ticketing.tickets
+		WHERE public_uuid = $1
+		FOR UPDATE
+	`, ticketPublicID).Scan(&ticketID, &ownerID, &currentStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrTicketNotFound
+		}
+		return r.handleError(err, "failed to get ticket for transfer")
+	}
+
+	if !enums.TicketStatus(currentStatus).CanTransfer() {
+		return repository.ErrTicketNotAvailable
+	}
+
+	var fromID, toID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM crm.customers WHERE public_uuid = $1`, fromCustomerPublicID).Scan(&fromID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrCustomerNotFound
+		}
+		return r.handleError(err, "failed to get sender customer")
+	}
+	if ownerID == nil || *ownerID != fromID {
+		return repository.ErrTicketOwnerMismatch
+	}
+
+	err = tx.QueryRow(ctx, `SELECT id FROM crm.customers WHERE public_uuid = $1`, toCustomerPublicID).Scan(&toID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrCustomerNotFound
+		}
+		return r.handleError(err, "failed to get recipient customer")
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ticketing.tickets
+		SET customer_id = $1,
+			transferred_from = $2,
+			transferred_from_ticket_id = $3,
+			transferred_at = NOW(),
+			status = 'transferred',
+			updated_at = NOW()
+		WHERE id = $3
+	`, toID, fromID, ticketID)
+	if err != nil {
+		return r.handleError(err, "failed to transfer ticket")
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ticketing.ticket_status_history (ticket_id, from_status, to_status, reason, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, ticketID, currentStatus, string(enums.TicketStatusTransferred), "transferred to customer "+toCustomerPublicID, fromCustomerPublicID)
+	if err != nil {
+		return r.handleError(err, "failed to record transfer in status history")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // Cancel cancela un ticket
 func (r *TicketRepository) Cancel(ctx context.Context, ticketID int64) error {
 	now := time.Now()
@@ -713,7 +1290,7 @@ func (r *TicketRepository) Cancel(ctx context.Context, ticketID int64) error {
 		SET status = 'cancelled', 
 			cancelled_at = $1,
 			updated_at = $1
-		WHERE id = $2 AND status IN ('available', 'reserved', 'sold')
+		WHERE id = $2 AND status IN ('available', 'reserved', 'sold', 'transferred')
 	`
 	cmdTag, err := r.db.Exec(ctx, query, now, ticketID)
 	if err != nil {
@@ -735,7 +1312,7 @@ func (r *TicketRepository) Refund(ctx context.Context, ticketID int64) error {
 		SET status = 'refunded', 
 			refunded_at = $1,
 			updated_at = $1
-		WHERE id = $2 AND status = 'sold'
+		WHERE id = $2 AND status IN ('sold', 'transferred')
 	`
 	cmdTag, err := r.db.Exec(ctx, query, now, ticketID)
 	if err != nil {
@@ -749,6 +1326,87 @@ func (r *TicketRepository) Refund(ctx context.Context, ticketID int64) error {
 	return nil
 }
 
+// VoidTicket invalida un ticket por fraude o duplicado. A diferencia de Cancel lo
+// inicia un administrador: se permite en cualquier estado salvo usado o final,
+// libera el inventario que ocupaba el ticket y registra quién y por qué en el historial.
+func (r *TicketRepository) VoidTicket(ctx context.Context, ticketPublicID, reason, actor string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ticketID, ticketTypeID int64
+	var currentStatus string
+	err = tx.QueryRow(ctx, `
+		SELECT id, ticket_type_id, status
+		FROM ticketing.tickets
+		WHERE public_uuid = $1
+		FOR UPDATE
+	`, ticketPublicID).Scan(&ticketID, &ticketTypeID, &currentStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrTicketNotFound
+		}
+		return r.handleError(err, "failed to get ticket for void")
+	}
+
+	if !enums.TicketStatus(currentStatus).CanVoid() {
+		return repository.ErrTicketNotAvailable
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ticketing.tickets
+		SET status = 'voided',
+			updated_at = NOW()
+		WHERE id = $1
+	`, ticketID)
+	if err != nil {
+		return r.handleError(err, "failed to void ticket")
+	}
+
+	// Un ticket disponible no ocupa inventario; vendidos y transferidos descuentan
+	// sold_quantity y reservados reserved_quantity
+	status := enums.TicketStatus(currentStatus)
+	inventoryQuery := `
+		UPDATE ticketing.ticket_types
+		SET sold_quantity = GREATEST(0, sold_quantity - 1),
+			available_quantity = total_quantity - GREATEST(0, sold_quantity - 1) - reserved_quantity,
+			is_sold_out = (total_quantity - GREATEST(0, sold_quantity - 1) - reserved_quantity) <= 0,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+	if status == enums.TicketStatusReserved {
+		inventoryQuery = `
+			UPDATE ticketing.ticket_types
+			SET reserved_quantity = GREATEST(0, reserved_quantity - 1),
+				available_quantity = total_quantity - sold_quantity - GREATEST(0, reserved_quantity - 1),
+				is_sold_out = (total_quantity - sold_quantity - GREATEST(0, reserved_quantity - 1)) <= 0,
+				updated_at = NOW()
+			WHERE id = $1
+		`
+	}
+	if status.HoldsInventory() {
+		if _, err := tx.Exec(ctx, inventoryQuery, ticketTypeID); err != nil {
+			return r.handleError(err, "failed to restore inventory for voided ticket")
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ticketing.ticket_status_history (ticket_id, from_status, to_status, reason, changed_by, changed_at)
+		VALUES ($1, $2, 'voided', $3, $4, NOW())
+	`, ticketID, currentStatus, reason, actor)
+	if err != nil {
+		return r.handleError(err, "failed to record void in status history")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // ValidateTicket valida un ticket por código y hash secreto
 func (r *TicketRepository) ValidateTicket(ctx context.Context, code, secretHash string) (*entities.Ticket, error) {
 	query := `
@@ -800,12 +1458,12 @@ func (r *TicketRepository) GetEventStats(ctx context.Context, eventPublicID stri
             COUNT(*) as total_tickets,
             COUNT(CASE WHEN status = 'available' THEN 1 END) as available_tickets,
             COUNT(CASE WHEN status = 'reserved' THEN 1 END) as reserved_tickets,
-            COUNT(CASE WHEN status = 'sold' THEN 1 END) as sold_tickets,
+            COUNT(CASE WHEN status IN ('sold', 'transferred') THEN 1 END) as sold_tickets,
             COUNT(CASE WHEN status = 'checked_in' THEN 1 END) as checked_in_tickets,
             COUNT(CASE WHEN status = 'cancelled' THEN 1 END) as cancelled_tickets,
             COUNT(CASE WHEN status = 'refunded' THEN 1 END) as refunded_tickets,
-            COALESCE(SUM(CASE WHEN status IN ('sold', 'checked_in') THEN final_price ELSE 0 END), 0) as total_revenue,
-            COALESCE(AVG(CASE WHEN status IN ('sold', 'checked_in') THEN final_price END), 0) as avg_ticket_price
+            COALESCE(SUM(CASE WHEN status IN ('sold', 'transferred', 'checked_in') THEN final_price ELSE 0 END), 0) as total_revenue,
+            COALESCE(AVG(CASE WHEN status IN ('sold', 'transferred', 'checked_in') THEN final_price END), 0) as avg_ticket_price
         FROM ticketing.tickets
         WHERE event_id = $1
     `
@@ -875,6 +1533,303 @@ func (r *TicketRepository) GetReservedExpired(ctx context.Context) ([]*entities.
 	return tickets, nil
 }
 
+// GetRefundEligibleTickets obtiene tickets vendidos o reservados sin usar de un evento
+// cancelado o ya terminado, para alimentar reembolsos masivos
+func (r *TicketRepository) GetRefundEligibleTickets(ctx context.Context, eventPublicID string) ([]*entities.Ticket, error) {
+	query := `
+		SELECT ` + ticketSelectColumns + `
+		FROM ticketing.tickets t
+		JOIN ticketing.events e ON t.event_id = e.id
+		WHERE e.public_uuid = $1
+		  AND (e.status = 'cancelled' OR e.ends_at < NOW())
+		  AND t.status IN ('sold', 'transferred', 'reserved')
+		  AND t.checked_in_at IS NULL
+		  AND t.refunded_at IS NULL
+		ORDER BY t.id
+	`
+
+	rows, err := r.db.Query(ctx, query, eventPublicID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get refund eligible tickets")
+	}
+	defer rows.Close()
+
+	return scanTicketRows(rows)
+}
+
+// GetTransferChain devuelve los titulares de un ticket del más reciente al más
+// antiguo, reconstruidos desde las filas de transferencia de ticket_status_history
+// que escribe TransferTicket. Cada eslabón lleva la fecha en que ese titular
+// recibió el ticket (nil para el comprador original). Cada fila se usa una sola
+// vez, así que un historial con ciclos no puede repetir eslabones indefinidamente.
+func (r *TicketRepository) GetTransferChain(ctx context.Context, ticketPublicID string) ([]repository.TransferLink, error) {
+	var ticketID int64
+	var ownerID *int64
+	err := r.db.QueryRow(ctx, `
+		SELECT id, customer_id
+		FROM ticketing.tickets
+		WHERE public_uuid = $1
+	`, ticketPublicID).Scan(&ticketID, &ownerID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get transfer chain")
+	}
+
+	// El destinatario sólo queda en el motivo ("transferred to customer <uuid>")
+	rows, err := r.db.Query(ctx, `
+		SELECT h.id, sender.id, recipient.id, h.changed_at
+		FROM ticketing.ticket_status_history h
+		LEFT JOIN crm.customers sender ON sender.public_uuid::text = h.changed_by
+		LEFT JOIN crm.customers recipient
+			ON recipient.public_uuid::text = substring(h.reason FROM '^transferred to customer (.+)$')
+		WHERE h.ticket_id = $1
+		  AND h.reason LIKE 'transferred to customer %'
+		ORDER BY h.changed_at DESC, h.id DESC
+	`, ticketID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get transfer history")
+	}
+	defer rows.Close()
+
+	type transferRow struct {
+		id            int64
+		senderID      *int64
+		transferredAt time.Time
+	}
+	// Transferencias por destinatario, de la más reciente a la más antigua
+	byRecipient := make(map[int64][]transferRow)
+	for rows.Next() {
+		var row transferRow
+		var recipientID *int64
+		if err := rows.Scan(&row.id, &row.senderID, &recipientID, &row.transferredAt); err != nil {
+			return nil, r.handleError(err, "failed to scan transfer history")
+		}
+		if recipientID != nil {
+			byRecipient[*recipientID] = append(byRecipient[*recipientID], row)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, r.handleError(err, "failed to iterate transfer history")
+	}
+
+	chain := []repository.TransferLink{{TicketPublicID: ticketPublicID, CustomerID: ownerID}}
+	visited := make(map[int64]bool)
+	for current := ownerID; current != nil; {
+		var next *transferRow
+		for i := range byRecipient[*current] {
+			if !visited[byRecipient[*current][i].id] {
+				next = &byRecipient[*current][i]
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		visited[next.id] = true
+
+		transferredAt := next.transferredAt
+		chain[len(chain)-1].TransferredAt = &transferredAt
+		chain = append(chain, repository.TransferLink{TicketPublicID: ticketPublicID, CustomerID: next.senderID})
+		current = next.senderID
+	}
+
+	return chain, nil
+}
+
+// GetSeatMapAvailability devuelve cada asiento del venue del evento con su estado.
+// Los asientos sin ticket activo se reportan como disponibles; si el evento no
+// tiene venue asignado el mapa es vacío.
+func (r *TicketRepository) GetSeatMapAvailability(ctx context.Context, eventPublicID string) ([]repository.SeatStatus, error) {
+	var eventID int64
+	var venueID *int64
+	err := r.db.QueryRow(ctx, `SELECT id, venue_id FROM ticketing.events WHERE public_uuid = $1`, eventPublicID).Scan(&eventID, &venueID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrEventNotFound
+		}
+		return nil, r.handleError(err, "failed to get event for seat map")
+	}
+
+	seats := []repository.SeatStatus{}
+	if venueID == nil {
+		return seats, nil
+	}
+
+	// DISTINCT ON se queda con el ticket más avanzado si un asiento tuviera varios
+	query := `
+		SELECT seat_id, section, row_label, seat_number, seat_status
+		FROM (
+			SELECT DISTINCT ON (s.id)
+				s.id AS seat_id, s.section, s.row_label, s.seat_number,
+				CASE
+					WHEN t.status IN ('sold', 'transferred', 'checked_in') THEN 'sold'
+					WHEN t.status = 'reserved' THEN 'held'
+					ELSE 'available'
+				END AS seat_status
+			FROM ticketing.venue_seats s
+			LEFT JOIN ticketing.tickets t
+				ON t.seat_id = s.id
+				AND t.event_id = $1
+				AND t.status IN ('reserved', 'sold', 'transferred', 'checked_in')
+			WHERE s.venue_id = $2
+			ORDER BY s.id, CASE t.status WHEN 'checked_in' THEN 0 WHEN 'sold' THEN 1 WHEN 'transferred' THEN 1 WHEN 'reserved' THEN 2 ELSE 3 END
+		) seat_map
+		ORDER BY section, row_label, seat_number
+	`
+
+	rows, err := r.db.Query(ctx, query, eventID, *venueID)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get seat map availability")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var seat repository.SeatStatus
+		var status string
+		if err := rows.Scan(&seat.SeatID, &seat.Section, &seat.Row, &seat.Number, &status); err != nil {
+			return nil, r.handleError(err, "failed to scan seat status")
+		}
+		seat.Status = enums.SeatStatus(status)
+		seats = append(seats, seat)
+	}
+
+	return seats, rows.Err()
+}
+
+// GetCheckInTimeline agrupa los check-ins del evento en intervalos de tamaño bucket
+// desde la apertura de puertas (o el inicio) hasta el fin del evento. Los intervalos
+// sin check-ins se devuelven en cero para que los picos se vean en contexto.
+func (r *TicketRepository) GetCheckInTimeline(ctx context.Context, eventPublicID string, bucket time.Duration) ([]repository.CheckInBucket, error) {
+	if bucket <= 0 {
+		return nil, fmt.Errorf("bucket must be greater than zero")
+	}
+
+	var eventID int64
+	var windowStart, windowEnd time.Time
+	err := r.db.QueryRow(ctx, `
+		SELECT id, COALESCE(doors_open_at, starts_at), ends_at
+		FROM ticketing.events
+		WHERE public_uuid = $1
+	`, eventPublicID).Scan(&eventID, &windowStart, &windowEnd)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrEventNotFound
+		}
+		return nil, r.handleError(err, "failed to get event for check-in timeline")
+	}
+
+	// Cada check-in cae en el bucket floor((checked_in_at - inicio) / bucket)
+	query := `
+		WITH buckets AS (
+			SELECT generate_series($2::timestamptz, $3::timestamptz, make_interval(secs => $4)) AS bucket_start
+		),
+		checkins AS (
+			SELECT $2::timestamptz
+				+ make_interval(secs => floor(EXTRACT(EPOCH FROM (checked_in_at - $2::timestamptz)) / $4) * $4) AS bucket_start
+			FROM ticketing.tickets
+			WHERE event_id = $1
+				AND checked_in_at IS NOT NULL
+				AND checked_in_at >= $2
+				AND checked_in_at < $3
+		)
+		SELECT b.bucket_start, COUNT(c.bucket_start)
+		FROM buckets b
+		LEFT JOIN checkins c ON c.bucket_start = b.bucket_start
+		WHERE b.bucket_start < $3
+		GROUP BY b.bucket_start
+		ORDER BY b.bucket_start
+	`
+
+	rows, err := r.db.Query(ctx, query, eventID, windowStart, windowEnd, bucket.Seconds())
+	if err != nil {
+		return nil, r.handleError(err, "failed to get check-in timeline")
+	}
+	defer rows.Close()
+
+	timeline := []repository.CheckInBucket{}
+	for rows.Next() {
+		var b repository.CheckInBucket
+		if err := rows.Scan(&b.Start, &b.CheckIns); err != nil {
+			return nil, r.handleError(err, "failed to scan check-in bucket")
+		}
+		timeline = append(timeline, b)
+	}
+
+	return timeline, rows.Err()
+}
+
+// FindDuplicateCodes reporta los códigos repetidos dentro de un mismo evento.
+// Sirve para remediar datos del generador anterior antes de exigir la
+// restricción única (event_id, code).
+func (r *TicketRepository) FindDuplicateCodes(ctx context.Context) ([]repository.DuplicateCode, error) {
+	query := `
+		SELECT event_id, code, COUNT(*), array_agg(id ORDER BY id)
+		FROM ticketing.tickets
+		GROUP BY event_id, code
+		HAVING COUNT(*) > 1
+		ORDER BY event_id, code
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, r.handleError(err, "failed to find duplicate ticket codes")
+	}
+	defer rows.Close()
+
+	duplicates := []repository.DuplicateCode{}
+	for rows.Next() {
+		var d repository.DuplicateCode
+		if err := rows.Scan(&d.EventID, &d.Code, &d.Count, &d.TicketIDs); err != nil {
+			return nil, r.handleError(err, "failed to scan duplicate ticket code")
+		}
+		duplicates = append(duplicates, d)
+	}
+
+	return duplicates, rows.Err()
+}
+
+// GetRevenueRecognition separa los ingresos de tickets vendidos hasta asOf en
+// reconocidos (eventos que terminaron antes de asOf) y diferidos (eventos que
+// aún no terminan), agrupados por moneda. Un evento sin ends_at se considera
+// terminado en starts_at. Los tickets cancelados o reembolsados no cuentan.
+func (r *TicketRepository) GetRevenueRecognition(ctx context.Context, asOf time.Time) (*repository.RecognitionReport, error) {
+	query := `
+		SELECT
+			COALESCE(NULLIF(t.currency, ''), tt.currency, '') AS currency,
+			COALESCE(SUM(t.final_price) FILTER (WHERE COALESCE(e.ends_at, e.starts_at) < $1), 0) AS recognized,
+			COALESCE(SUM(t.final_price) FILTER (WHERE COALESCE(e.ends_at, e.starts_at) >= $1), 0) AS deferred,
+			COUNT(*) FILTER (WHERE COALESCE(e.ends_at, e.starts_at) < $1) AS recognized_tickets,
+			COUNT(*) FILTER (WHERE COALESCE(e.ends_at, e.starts_at) >= $1) AS deferred_tickets
+		FROM ticketing.tickets t
+		JOIN ticketing.events e ON e.id = t.event_id
+		LEFT JOIN ticketing.ticket_types tt ON tt.id = t.ticket_type_id
+		WHERE t.status IN ('sold', 'transferred', 'checked_in')
+		  AND t.refunded_at IS NULL
+		  AND t.sold_at <= $1
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := r.db.Query(ctx, query, asOf)
+	if err != nil {
+		return nil, r.handleError(err, "failed to get revenue recognition")
+	}
+	defer rows.Close()
+
+	report := &repository.RecognitionReport{
+		AsOf:       asOf,
+		ByCurrency: []repository.CurrencyRecognition{},
+	}
+	for rows.Next() {
+		var c repository.CurrencyRecognition
+		if err := rows.Scan(&c.Currency, &c.Recognized, &c.Deferred, &c.RecognizedTickets, &c.DeferredTickets); err != nil {
+			return nil, r.handleError(err, "failed to scan revenue recognition")
+		}
+		report.ByCurrency = append(report.ByCurrency, c)
+	}
+
+	return report, rows.Err()
+}
+
 // BeginTx inicia una transacción
 func (r *TicketRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	return r.db.Begin(ctx)
@@ -1048,3 +2003,44 @@ func (r *TicketRepository) GetByPublicIDForUpdate(ctx context.Context, tx pgx.Tx
 
 	return &ticket, nil
 }
+
+// ticketSelectColumns lista las columnas de ticketing.tickets (alias t) en el orden de scanTicketRows
+const ticketSelectColumns = `
+			t.id, t.public_uuid, t.ticket_type_id, t.event_id, t.customer_id, t.order_id,
+			t.code, t.secret_hash, t.qr_code_data, t.status, t.final_price, t.currency, t.tax_amount,
+			t.attendee_name, t.attendee_email, t.attendee_phone,
+			t.checked_in_at, t.checked_in_by, t.checkin_method, t.checkin_location,
+			t.reserved_at, t.reserved_by, t.reservation_expires_at,
+			t.transfer_token, t.transferred_from, t.transferred_at,
+			t.validation_count, t.last_validated_at,
+			t.sold_at, t.cancelled_at, t.refunded_at,
+			t.created_at, t.updated_at`
+
+// scanTicketRows escanea filas seleccionadas con ticketSelectColumns
+func scanTicketRows(rows pgx.Rows) ([]*entities.Ticket, error) {
+	var tickets []*entities.Ticket
+	for rows.Next() {
+		var ticket entities.Ticket
+		err := rows.Scan(
+			&ticket.ID, &ticket.PublicID, &ticket.TicketTypeID, &ticket.EventID, &ticket.CustomerID, &ticket.OrderID,
+			&ticket.Code, &ticket.SecretHash, &ticket.QRCodeData, &ticket.Status, &ticket.FinalPrice, &ticket.Currency, &ticket.TaxAmount,
+			&ticket.AttendeeName, &ticket.AttendeeEmail, &ticket.AttendeePhone,
+			&ticket.CheckedInAt, &ticket.CheckedInBy, &ticket.CheckinMethod, &ticket.CheckinLocation,
+			&ticket.ReservedAt, &ticket.ReservedBy, &ticket.ReservationExpiresAt,
+			&ticket.TransferToken, &ticket.TransferredFrom, &ticket.TransferredAt,
+			&ticket.ValidationCount, &ticket.LastValidatedAt,
+			&ticket.SoldAt, &ticket.CancelledAt, &ticket.RefundedAt,
+			&ticket.CreatedAt, &ticket.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ticket row: %w", err)
+		}
+		tickets = append(tickets, &ticket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ticket rows: %w", err)
+	}
+
+	return tickets, nil
+}
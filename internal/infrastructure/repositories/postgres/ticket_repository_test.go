@@ -0,0 +1,1119 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+func TestTicketRepositoryGetRefundEligibleTickets(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Cancelado")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 200, 10)
+	customerID := testsupport.SeedCustomer(t, tx, "Buyer", "buyer@example.com")
+
+	sold := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "sold", 200)
+	used := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "sold", 200)
+	testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "refunded", 200)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET checked_in_at = NOW() WHERE id = $1`, used); err != nil {
+		t.Fatalf("failed to check in ticket: %v", err)
+	}
+
+	eventPublicID := testsupport.PublicID(t, tx, "ticketing.events", eventID)
+
+	tickets, err := repo.GetRefundEligibleTickets(ctx, eventPublicID)
+	if err != nil {
+		t.Fatalf("GetRefundEligibleTickets: %v", err)
+	}
+	if len(tickets) != 0 {
+		t.Fatalf("active event returned %d tickets, want 0", len(tickets))
+	}
+
+	testsupport.SetEventStatus(t, tx, eventID, "cancelled")
+
+	tickets, err = repo.GetRefundEligibleTickets(ctx, eventPublicID)
+	if err != nil {
+		t.Fatalf("GetRefundEligibleTickets: %v", err)
+	}
+	if len(tickets) != 1 || tickets[0].ID != sold {
+		t.Fatalf("got %d tickets, want only the unused sold ticket %d", len(tickets), sold)
+	}
+}
+
+// seedTransferableTicket siembra un ticket vendido a owner y devuelve su public_uuid
+func seedTransferableTicket(t *testing.T, db testsupport.DB, owner int64) string {
+	t.Helper()
+	eventID := seedEvent(t, db, "Transfer")
+	typeID := testsupport.SeedTicketType(t, db, eventID, "General", 100, 10)
+	ticketID := testsupport.SeedTicket(t, db, eventID, typeID, &owner, "sold", 100)
+	return testsupport.PublicID(t, db, "ticketing.tickets", ticketID)
+}
+
+func chainCustomers(chain []repository.TransferLink) []int64 {
+	ids := make([]int64, 0, len(chain))
+	for _, link := range chain {
+		if link.CustomerID == nil {
+			ids = append(ids, 0)
+			continue
+		}
+		ids = append(ids, *link.CustomerID)
+	}
+	return ids
+}
+
+func TestTicketRepositoryGetTransferChainSingleOwner(t *testing.T) {
+	tx := testsupport.Tx(t)
+	owner := testsupport.SeedCustomer(t, tx, "Owner", "owner@example.com")
+	ticketPublicID := seedTransferableTicket(t, tx, owner)
+
+	chain, err := postgres.NewTicketRepository(tx).GetTransferChain(context.Background(), ticketPublicID)
+	if err != nil {
+		t.Fatalf("GetTransferChain: %v", err)
+	}
+	if got := chainCustomers(chain); len(got) != 1 || got[0] != owner {
+		t.Errorf("chain = %v, want [%d]", got, owner)
+	}
+}
+
+func TestTicketRepositoryGetTransferChainAfterTransfers(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	a := testsupport.SeedCustomer(t, tx, "A", "a@example.com")
+	b := testsupport.SeedCustomer(t, tx, "B", "b@example.com")
+	c := testsupport.SeedCustomer(t, tx, "C", "c@example.com")
+	aID, bID, cID := testsupport.PublicID(t, tx, "crm.customers", a), testsupport.PublicID(t, tx, "crm.customers", b), testsupport.PublicID(t, tx, "crm.customers", c)
+	ticketPublicID := seedTransferableTicket(t, tx, a)
+
+	if err := repo.TransferTicket(ctx, ticketPublicID, aID, bID); err != nil {
+		t.Fatalf("TransferTicket A->B: %v", err)
+	}
+	chain, err := repo.GetTransferChain(ctx, ticketPublicID)
+	if err != nil {
+		t.Fatalf("GetTransferChain: %v", err)
+	}
+	if got := chainCustomers(chain); len(got) != 2 || got[0] != b || got[1] != a {
+		t.Errorf("chain after A->B = %v, want [%d %d]", got, b, a)
+	}
+	if chain[0].TransferredAt == nil {
+		t.Error("newest link should carry the transfer time")
+	}
+
+	if err := repo.TransferTicket(ctx, ticketPublicID, bID, cID); err != nil {
+		t.Fatalf("TransferTicket B->C: %v", err)
+	}
+	// C lo devuelve a A: A aparece dos veces, una por cada vez que fue titular
+	if err := repo.TransferTicket(ctx, ticketPublicID, cID, aID); err != nil {
+		t.Fatalf("TransferTicket C->A: %v", err)
+	}
+	chain, err = repo.GetTransferChain(ctx, ticketPublicID)
+	if err != nil {
+		t.Fatalf("GetTransferChain: %v", err)
+	}
+	if got := chainCustomers(chain); len(got) != 4 || got[0] != a || got[1] != c || got[2] != b || got[3] != a {
+		t.Errorf("chain after A->B->C->A = %v, want [%d %d %d %d]", got, a, c, b, a)
+	}
+	if chain[3].TransferredAt != nil {
+		t.Error("the original buyer should not carry a transfer time")
+	}
+}
+
+func TestTicketRepositoryGetTransferChainCyclicHistory(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	owner := testsupport.SeedCustomer(t, tx, "Loop", "loop-"+uuid.NewString()+"@example.com")
+	ownerID := testsupport.PublicID(t, tx, "crm.customers", owner)
+	ticketPublicID := seedTransferableTicket(t, tx, owner)
+
+	// Filas de transferencia del titular hacia sí mismo: cada una apunta de vuelta
+	// al mismo eslabón y no deben recorrerse más de una vez
+	for i := 0; i < 2; i++ {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO ticketing.ticket_status_history (ticket_id, from_status, to_status, reason, changed_by, changed_at)
+			SELECT id, 'sold', 'transferred', 'transferred to customer ' || $2, $2, NOW()
+			FROM ticketing.tickets WHERE public_uuid = $1
+		`, ticketPublicID, ownerID); err != nil {
+			t.Fatalf("failed to create cyclic history: %v", err)
+		}
+	}
+
+	chain, err := postgres.NewTicketRepository(tx).GetTransferChain(ctx, ticketPublicID)
+	if err != nil {
+		t.Fatalf("GetTransferChain: %v", err)
+	}
+	if got := chainCustomers(chain); len(got) != 3 || got[0] != owner || got[1] != owner || got[2] != owner {
+		t.Errorf("chain = %v, want three links for %d", got, owner)
+	}
+}
+
+func TestTicketRepositoryGetTransferChainNotFound(t *testing.T) {
+	_, err := postgres.NewTicketRepository(testsupport.Tx(t)).GetTransferChain(context.Background(), "00000000-0000-0000-0000-000000000000")
+	if !errors.Is(err, repository.ErrTicketNotFound) {
+		t.Fatalf("GetTransferChain error = %v, want ErrTicketNotFound", err)
+	}
+}
+
+func TestTicketRepositoryGetTicketsWithDetailsByOrder(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Recibo")
+	categoryID := testsupport.SeedCategory(t, tx, "Conciertos")
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO ticketing.event_categories (event_id, category_id, is_primary) VALUES ($1, $2, true)
+	`, eventID, categoryID); err != nil {
+		t.Fatalf("failed to link category: %v", err)
+	}
+	generalID := testsupport.SeedTicketType(t, tx, eventID, "General", 300, 10)
+	vipID := testsupport.SeedTicketType(t, tx, eventID, "VIP", 900, 10)
+
+	customerID := testsupport.SeedCustomer(t, tx, "Buyer", "receipt@example.com")
+	orderID := testsupport.SeedOrder(t, tx, customerID, "receipt@example.com", 1200, "completed")
+	otherOrderID := testsupport.SeedOrder(t, tx, customerID, "receipt@example.com", 300, "completed")
+
+	general := testsupport.SeedTicket(t, tx, eventID, generalID, &customerID, "sold", 300)
+	vip := testsupport.SeedTicket(t, tx, eventID, vipID, &customerID, "sold", 900)
+	other := testsupport.SeedTicket(t, tx, eventID, generalID, &customerID, "sold", 300)
+	for ticketID, order := range map[int64]int64{general: orderID, vip: orderID, other: otherOrderID} {
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET order_id = $1 WHERE id = $2`, order, ticketID); err != nil {
+			t.Fatalf("failed to link ticket to order: %v", err)
+		}
+	}
+
+	tickets, err := repo.GetTicketsWithDetailsByOrder(ctx, orderID)
+	if err != nil {
+		t.Fatalf("GetTicketsWithDetailsByOrder: %v", err)
+	}
+	if len(tickets) != 2 {
+		t.Fatalf("got %d tickets, want 2", len(tickets))
+	}
+
+	want := []struct {
+		id       int64
+		typeName string
+		price    float64
+	}{{general, "General", 300}, {vip, "VIP", 900}}
+	eventPublicID := testsupport.PublicID(t, tx, "ticketing.events", eventID)
+	for i, w := range want {
+		got := tickets[i]
+		if got.ID != w.id || got.TicketTypeName != w.typeName || got.FinalPrice != w.price {
+			t.Errorf("ticket %d = id %d / %s / %.2f, want %d / %s / %.2f", i, got.ID, got.TicketTypeName, got.FinalPrice, w.id, w.typeName, w.price)
+		}
+		if got.EventName != "Recibo" || got.CategoryName != "Conciertos" || got.EventPublicID != eventPublicID {
+			t.Errorf("ticket %d details = %q / %q / %q", i, got.EventName, got.CategoryName, got.EventPublicID)
+		}
+	}
+}
+
+func ticketStatus(t *testing.T, db testsupport.DB, ticketID int64) string {
+	t.Helper()
+	var status string
+	if err := db.QueryRow(context.Background(), `SELECT status FROM ticketing.tickets WHERE id = $1`, ticketID).Scan(&status); err != nil {
+		t.Fatalf("failed to read ticket status: %v", err)
+	}
+	return status
+}
+
+func TestTicketRepositoryUpdateStatus(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Estados")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	customerID := testsupport.SeedCustomer(t, tx, "Holder", "status@example.com")
+	ticketID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "sold", 100)
+	refundedID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "refunded", 100)
+
+	// Repetir el estado actual es un no-op
+	if err := repo.UpdateStatus(ctx, ticketID, enums.TicketStatusSold); err != nil {
+		t.Errorf("UpdateStatus(same status) error = %v, want nil", err)
+	}
+	if err := repo.UpdateStatus(ctx, ticketID, enums.TicketStatusCheckedIn); err != nil {
+		t.Fatalf("UpdateStatus(sold -> checked_in): %v", err)
+	}
+	if got := ticketStatus(t, tx, ticketID); got != "checked_in" {
+		t.Errorf("status = %q, want checked_in", got)
+	}
+
+	if err := repo.UpdateStatus(ctx, refundedID, enums.TicketStatusSold); !errors.Is(err, repository.ErrInvalidTicketStatus) {
+		t.Errorf("UpdateStatus(refunded -> sold) error = %v, want ErrInvalidTicketStatus", err)
+	}
+	if got := ticketStatus(t, tx, refundedID); got != "refunded" {
+		t.Errorf("status after rejected transition = %q, want refunded", got)
+	}
+
+	if err := repo.UpdateStatus(ctx, -ticketID, enums.TicketStatusSold); !errors.Is(err, repository.ErrTicketNotFound) {
+		t.Errorf("UpdateStatus(missing) error = %v, want ErrTicketNotFound", err)
+	}
+}
+
+// setTypeQuantities fija los contadores de inventario de un tipo de ticket
+func setTypeQuantities(t *testing.T, db testsupport.DB, typeID int64, sold, reserved int) {
+	t.Helper()
+	if _, err := db.Exec(context.Background(), `
+		UPDATE ticketing.ticket_types
+		SET sold_quantity = $1, reserved_quantity = $2, available_quantity = total_quantity - $1 - $2
+		WHERE id = $3
+	`, sold, reserved, typeID); err != nil {
+		t.Fatalf("failed to set ticket type quantities: %v", err)
+	}
+}
+
+// typeQuantities devuelve sold, reserved y available de un tipo de ticket
+func typeQuantities(t *testing.T, db testsupport.DB, typeID int64) (sold, reserved, available int) {
+	t.Helper()
+	if err := db.QueryRow(context.Background(), `
+		SELECT sold_quantity, reserved_quantity, available_quantity FROM ticketing.ticket_types WHERE id = $1
+	`, typeID).Scan(&sold, &reserved, &available); err != nil {
+		t.Fatalf("failed to read ticket type quantities: %v", err)
+	}
+	return sold, reserved, available
+}
+
+func TestTicketRepositoryVoidTicket(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Anulación")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	customerID := testsupport.SeedCustomer(t, tx, "Holder", "void@example.com")
+	soldID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "sold", 100)
+	reservedID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "reserved", 100)
+	setTypeQuantities(t, tx, typeID, 1, 1)
+
+	if err := repo.VoidTicket(ctx, testsupport.PublicID(t, tx, "ticketing.tickets", soldID), "duplicado", "admin@example.com"); err != nil {
+		t.Fatalf("VoidTicket(sold): %v", err)
+	}
+	if err := repo.VoidTicket(ctx, testsupport.PublicID(t, tx, "ticketing.tickets", reservedID), "fraude", "admin@example.com"); err != nil {
+		t.Fatalf("VoidTicket(reserved): %v", err)
+	}
+
+	if got := ticketStatus(t, tx, soldID); got != "voided" {
+		t.Errorf("status = %q, want voided", got)
+	}
+	if sold, reserved, available := typeQuantities(t, tx, typeID); sold != 0 || reserved != 0 || available != 10 {
+		t.Errorf("quantities = sold %d / reserved %d / available %d, want 0 / 0 / 10", sold, reserved, available)
+	}
+
+	var reason, actor string
+	if err := tx.QueryRow(ctx, `
+		SELECT reason, changed_by FROM ticketing.ticket_status_history
+		WHERE ticket_id = $1 AND to_status = 'voided'
+	`, soldID).Scan(&reason, &actor); err != nil {
+		t.Fatalf("failed to read void history: %v", err)
+	}
+	if reason != "duplicado" || actor != "admin@example.com" {
+		t.Errorf("history = %q by %q, want duplicado by admin@example.com", reason, actor)
+	}
+
+	if err := repo.CheckIn(ctx, soldID, enums.CheckInMethodQR, "Puerta 1", nil); !errors.Is(err, repository.ErrTicketNotAvailable) {
+		t.Errorf("CheckIn(voided) error = %v, want ErrTicketNotAvailable", err)
+	}
+}
+
+func TestTicketRepositoryVoidTicketAvailableKeepsInventory(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Anulación disponible")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	customerID := testsupport.SeedCustomer(t, tx, "Holder", "void-"+uuid.NewString()+"@example.com")
+	availableID := testsupport.SeedTicket(t, tx, eventID, typeID, nil, "available", 100)
+	transferredID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "transferred", 100)
+	setTypeQuantities(t, tx, typeID, 1, 0)
+
+	// Un ticket disponible no ocupa inventario: anularlo no toca las cantidades
+	if err := repo.VoidTicket(ctx, testsupport.PublicID(t, tx, "ticketing.tickets", availableID), "duplicado", "admin@example.com"); err != nil {
+		t.Fatalf("VoidTicket(available): %v", err)
+	}
+	if got := ticketStatus(t, tx, availableID); got != "voided" {
+		t.Errorf("status = %q, want voided", got)
+	}
+	if sold, reserved, available := typeQuantities(t, tx, typeID); sold != 1 || reserved != 0 || available != 9 {
+		t.Errorf("quantities after voiding available = sold %d / reserved %d / available %d, want 1 / 0 / 9", sold, reserved, available)
+	}
+
+	if err := repo.VoidTicket(ctx, testsupport.PublicID(t, tx, "ticketing.tickets", transferredID), "fraude", "admin@example.com"); err != nil {
+		t.Fatalf("VoidTicket(transferred): %v", err)
+	}
+	if sold, reserved, available := typeQuantities(t, tx, typeID); sold != 0 || reserved != 0 || available != 10 {
+		t.Errorf("quantities after voiding transferred = sold %d / reserved %d / available %d, want 0 / 0 / 10", sold, reserved, available)
+	}
+}
+
+func TestTicketRepositoryVoidTicketRejectsOtherStatuses(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Anulación rechazada")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	customerID := testsupport.SeedCustomer(t, tx, "Holder", "void-rejected@example.com")
+
+	for _, status := range []string{"checked_in", "cancelled", "refunded", "expired", "voided"} {
+		ticketID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, status, 100)
+		err := repo.VoidTicket(ctx, testsupport.PublicID(t, tx, "ticketing.tickets", ticketID), "fraude", "admin@example.com")
+		if !errors.Is(err, repository.ErrTicketNotAvailable) {
+			t.Errorf("VoidTicket(%s) error = %v, want ErrTicketNotAvailable", status, err)
+		}
+	}
+
+	if err := repo.VoidTicket(ctx, "00000000-0000-0000-0000-000000000000", "fraude", "admin@example.com"); !errors.Is(err, repository.ErrTicketNotFound) {
+		t.Errorf("VoidTicket(missing) error = %v, want ErrTicketNotFound", err)
+	}
+}
+
+func TestTicketRepositoryListTicketsPagedStatusIgnoresCase(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Filtro de estado")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	sold := testsupport.SeedTicket(t, tx, eventID, typeID, nil, "sold", 100)
+	testsupport.SeedTicket(t, tx, eventID, typeID, nil, "available", 100)
+
+	filter := &repository.TicketFilter{EventID: &eventID, Status: []enums.TicketStatus{"SOLD"}}
+	tickets, _, err := repo.ListTicketsPaged(ctx, filter, "", 10)
+	if err != nil {
+		t.Fatalf("ListTicketsPaged: %v", err)
+	}
+	if len(tickets) != 1 || tickets[0].ID != sold {
+		t.Errorf("tickets = %d, want only the sold ticket %d", len(tickets), sold)
+	}
+}
+
+func TestTicketRepositoryGetSeatMapAvailability(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	venueID := testsupport.SeedVenue(t, tx, "Teatro")
+	eventID := seedEvent(t, tx, "Función numerada")
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET venue_id = $1 WHERE id = $2`, venueID, eventID); err != nil {
+		t.Fatalf("failed to set venue: %v", err)
+	}
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "Platea", 500, 10)
+
+	seat := func(section, row, number string) int64 {
+		t.Helper()
+		var id int64
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO ticketing.venue_seats (venue_id, section, row_label, seat_number) VALUES ($1, $2, $3, $4) RETURNING id
+		`, venueID, section, row, number).Scan(&id); err != nil {
+			t.Fatalf("failed to seed seat: %v", err)
+		}
+		return id
+	}
+	assign := func(seatID int64, status string) {
+		t.Helper()
+		ticketID := testsupport.SeedTicket(t, tx, eventID, typeID, nil, status, 500)
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET seat_id = $1 WHERE id = $2`, seatID, ticketID); err != nil {
+			t.Fatalf("failed to assign seat: %v", err)
+		}
+	}
+
+	soldSeat := seat("A", "1", "1")
+	heldSeat := seat("A", "1", "2")
+	releasedSeat := seat("A", "1", "3")
+	freeSeat := seat("B", "1", "1")
+	assign(soldSeat, "sold")
+	assign(heldSeat, "reserved")
+	assign(releasedSeat, "cancelled")
+
+	seats, err := repo.GetSeatMapAvailability(ctx, testsupport.PublicID(t, tx, "ticketing.events", eventID))
+	if err != nil {
+		t.Fatalf("GetSeatMapAvailability: %v", err)
+	}
+
+	want := []struct {
+		id     int64
+		status enums.SeatStatus
+	}{
+		{soldSeat, enums.SeatStatusSold},
+		{heldSeat, enums.SeatStatusHeld},
+		{releasedSeat, enums.SeatStatusAvailable},
+		{freeSeat, enums.SeatStatusAvailable},
+	}
+	if len(seats) != len(want) {
+		t.Fatalf("got %d seats, want %d", len(seats), len(want))
+	}
+	for i, w := range want {
+		if seats[i].SeatID != w.id || seats[i].Status != w.status {
+			t.Errorf("seat %d = %d / %s, want %d / %s", i, seats[i].SeatID, seats[i].Status, w.id, w.status)
+		}
+	}
+}
+
+func TestTicketRepositoryGetSeatMapAvailabilityWithoutVenue(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Sin recinto")
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET venue_id = NULL WHERE id = $1`, eventID); err != nil {
+		t.Fatalf("failed to clear venue: %v", err)
+	}
+
+	seats, err := repo.GetSeatMapAvailability(ctx, testsupport.PublicID(t, tx, "ticketing.events", eventID))
+	if err != nil || len(seats) != 0 {
+		t.Errorf("GetSeatMapAvailability(no venue) = %d seats, %v; want empty", len(seats), err)
+	}
+	if _, err := repo.GetSeatMapAvailability(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Errorf("GetSeatMapAvailability(missing) error = %v, want ErrEventNotFound", err)
+	}
+}
+
+func TestTicketRepositoryCurrencyFallsBackToTicketType(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Moneda")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.ticket_types SET currency = 'USD' WHERE id = $1`, typeID); err != nil {
+		t.Fatalf("failed to set type currency: %v", err)
+	}
+	legacy := testsupport.SeedTicket(t, tx, eventID, typeID, nil, "sold", 100)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET currency = '' WHERE id = $1`, legacy); err != nil {
+		t.Fatalf("failed to clear ticket currency: %v", err)
+	}
+	priced := testsupport.SeedTicket(t, tx, eventID, typeID, nil, "sold", 100)
+
+	for id, want := range map[int64]string{legacy: "USD", priced: "MXN"} {
+		ticket, err := repo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if ticket.Currency != want {
+			t.Errorf("ticket %d currency = %q, want %q", id, ticket.Currency, want)
+		}
+	}
+}
+
+func TestTicketRepositoryReassignCategory(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Reassign")
+	general := testsupport.SeedTicketType(t, tx, eventID, "General", 500, 10)
+	vip := testsupport.SeedTicketType(t, tx, eventID, "VIP", 1000, 5)
+	// Cargo fijo de 50 e IVA de 16%, como los calcula Create
+	if _, err := tx.Exec(ctx, `
+		UPDATE ticketing.ticket_types
+		SET service_fee_type = 'fixed', service_fee_value = 50, tax_rate = 0.16
+		WHERE id = $1
+	`, vip); err != nil {
+		t.Fatalf("failed to set fees: %v", err)
+	}
+	setTypeQuantities(t, tx, general, 3, 0)
+	customerID := testsupport.SeedCustomer(t, tx, "Upgrade", "reassign@example.com")
+	ticketID := testsupport.SeedTicket(t, tx, eventID, general, &customerID, "sold", 500)
+
+	err := repo.ReassignCategory(ctx,
+		testsupport.PublicID(t, tx, "ticketing.tickets", ticketID),
+		testsupport.PublicID(t, tx, "ticketing.ticket_types", vip),
+	)
+	if err != nil {
+		t.Fatalf("ReassignCategory: %v", err)
+	}
+
+	if sold, _, available := typeQuantities(t, tx, general); sold != 2 || available != 8 {
+		t.Errorf("old type sold/available = %d/%d, want 2/8", sold, available)
+	}
+	if sold, _, available := typeQuantities(t, tx, vip); sold != 1 || available != 4 {
+		t.Errorf("new type sold/available = %d/%d, want 1/4", sold, available)
+	}
+
+	var typeID int64
+	var finalPrice, taxAmount float64
+	if err := tx.QueryRow(ctx, `
+		SELECT ticket_type_id, final_price, tax_amount FROM ticketing.tickets WHERE id = $1
+	`, ticketID).Scan(&typeID, &finalPrice, &taxAmount); err != nil {
+		t.Fatalf("failed to read ticket: %v", err)
+	}
+	// (1000 + 50) * 1.16 = 1218; impuesto sobre el precio base = 160
+	if typeID != vip || finalPrice != 1218 || taxAmount != 160 {
+		t.Errorf("ticket type/price/tax = %d/%.2f/%.2f, want %d/1218.00/160.00", typeID, finalPrice, taxAmount, vip)
+	}
+
+	var history int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM ticketing.ticket_status_history WHERE ticket_id = $1`, ticketID).Scan(&history); err != nil {
+		t.Fatalf("failed to count history: %v", err)
+	}
+	if history != 1 {
+		t.Errorf("history rows = %d, want 1", history)
+	}
+}
+
+func TestTicketRepositoryReassignCategoryRejections(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Reassign Source")
+	otherEventID := seedEvent(t, tx, "Reassign Other")
+	general := testsupport.SeedTicketType(t, tx, eventID, "General", 500, 10)
+	foreign := testsupport.SeedTicketType(t, tx, otherEventID, "VIP", 1000, 5)
+	ticketID := testsupport.SeedTicket(t, tx, eventID, general, nil, "sold", 500)
+	ticketPublicID := testsupport.PublicID(t, tx, "ticketing.tickets", ticketID)
+
+	err := repo.ReassignCategory(ctx, ticketPublicID, testsupport.PublicID(t, tx, "ticketing.ticket_types", foreign))
+	if !errors.Is(err, repository.ErrTicketTypeMismatch) {
+		t.Errorf("cross-event category: err = %v, want ErrTicketTypeMismatch", err)
+	}
+
+	err = repo.ReassignCategory(ctx, ticketPublicID, "00000000-0000-0000-0000-000000000000")
+	if !errors.Is(err, repository.ErrTicketTypeNotFound) {
+		t.Errorf("unknown category: err = %v, want ErrTicketTypeNotFound", err)
+	}
+
+	err = repo.ReassignCategory(ctx, "00000000-0000-0000-0000-000000000000", testsupport.PublicID(t, tx, "ticketing.ticket_types", general))
+	if !errors.Is(err, repository.ErrTicketNotFound) {
+		t.Errorf("unknown ticket: err = %v, want ErrTicketNotFound", err)
+	}
+}
+
+func TestTicketRepositoryGetCheckInTimeline(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	organizerID := testsupport.SeedOrganizer(t, tx, "Timeline Organizer")
+	start := time.Date(2090, 5, 1, 18, 0, 0, 0, time.UTC)
+	eventID := testsupport.SeedEvent(t, tx, organizerID, "Timeline", start) // termina a las 21:00
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 50)
+
+	for _, offset := range []time.Duration{
+		5 * time.Minute, 20 * time.Minute, // 18:00-18:30
+		60 * time.Minute, 61 * time.Minute, 89 * time.Minute, // 19:00-19:30
+		-10 * time.Minute, 3 * time.Hour, // fuera de la ventana
+	} {
+		ticketID := testsupport.SeedTicket(t, tx, eventID, typeID, nil, "checked_in", 100)
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET checked_in_at = $1 WHERE id = $2`, start.Add(offset), ticketID); err != nil {
+			t.Fatalf("failed to set checked_in_at: %v", err)
+		}
+	}
+	// Un ticket vendido sin check-in no cuenta
+	testsupport.SeedTicket(t, tx, eventID, typeID, nil, "sold", 100)
+
+	timeline, err := repo.GetCheckInTimeline(ctx, testsupport.PublicID(t, tx, "ticketing.events", eventID), 30*time.Minute)
+	if err != nil {
+		t.Fatalf("GetCheckInTimeline: %v", err)
+	}
+
+	want := []int64{2, 0, 3, 0, 0, 0}
+	if len(timeline) != len(want) {
+		t.Fatalf("got %d buckets, want %d: %+v", len(timeline), len(want), timeline)
+	}
+	for i, w := range want {
+		wantStart := start.Add(time.Duration(i) * 30 * time.Minute)
+		if !timeline[i].Start.Equal(wantStart) || timeline[i].CheckIns != w {
+			t.Errorf("bucket %d = %s with %d, want %s with %d", i, timeline[i].Start.UTC(), timeline[i].CheckIns, wantStart, w)
+		}
+	}
+
+	if _, err := repo.GetCheckInTimeline(ctx, "00000000-0000-0000-0000-000000000000", time.Hour); !errors.Is(err, repository.ErrEventNotFound) {
+		t.Errorf("missing event error = %v, want ErrEventNotFound", err)
+	}
+	if _, err := repo.GetCheckInTimeline(ctx, "any", 0); err == nil {
+		t.Error("zero bucket succeeded, want error")
+	}
+}
+
+func TestTicketRepositoryFindDuplicateCodes(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	// Los datos heredados se generaron sin la restricción única; se quita sólo
+	// dentro de la transacción del test, que se revierte al terminar
+	if _, err := tx.Exec(ctx, `ALTER TABLE ticketing.tickets DROP CONSTRAINT IF EXISTS tickets_code_key`); err != nil {
+		t.Fatalf("failed to drop unique code constraint: %v", err)
+	}
+
+	eventA := seedEvent(t, tx, "Duplicates A")
+	eventB := seedEvent(t, tx, "Duplicates B")
+	typeA := testsupport.SeedTicketType(t, tx, eventA, "General", 100, 10)
+	typeB := testsupport.SeedTicketType(t, tx, eventB, "General", 100, 10)
+
+	setCode := func(ticketID int64, code string) {
+		t.Helper()
+		if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET code = $1 WHERE id = $2`, code, ticketID); err != nil {
+			t.Fatalf("failed to set code: %v", err)
+		}
+	}
+	dup1 := testsupport.SeedTicket(t, tx, eventA, typeA, nil, "sold", 100)
+	dup2 := testsupport.SeedTicket(t, tx, eventA, typeA, nil, "sold", 100)
+	setCode(dup1, "DUP-TEST-CODE")
+	setCode(dup2, "DUP-TEST-CODE")
+	// El mismo código en otro evento no es un duplicado dentro del evento
+	setCode(testsupport.SeedTicket(t, tx, eventB, typeB, nil, "sold", 100), "DUP-TEST-CODE")
+	unique := testsupport.SeedTicket(t, tx, eventA, typeA, nil, "sold", 100)
+
+	duplicates, err := repo.FindDuplicateCodes(ctx)
+	if err != nil {
+		t.Fatalf("FindDuplicateCodes: %v", err)
+	}
+
+	var found bool
+	for _, d := range duplicates {
+		if d.EventID == eventB {
+			t.Errorf("event B reported as duplicate: %+v", d)
+		}
+		for _, id := range d.TicketIDs {
+			if id == unique {
+				t.Errorf("unique ticket %d reported as duplicate: %+v", unique, d)
+			}
+		}
+		if d.EventID == eventA {
+			found = true
+			if d.Code != "DUP-TEST-CODE" || d.Count != 2 || len(d.TicketIDs) != 2 || d.TicketIDs[0] != dup1 || d.TicketIDs[1] != dup2 {
+				t.Errorf("duplicate = %+v, want DUP-TEST-CODE with tickets [%d %d]", d, dup1, dup2)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("duplicate in event A not reported: %+v", duplicates)
+	}
+}
+
+func TestTicketRepositoryListTicketsPagedWalksEveryTicketOnce(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Keyset")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 100)
+	var want []int64
+	for i := 0; i < 5; i++ {
+		want = append(want, testsupport.SeedTicket(t, tx, eventID, typeID, nil, "available", 100))
+	}
+	// Otro evento no debe colarse en las páginas
+	otherEventID := seedEvent(t, tx, "Keyset Otro")
+	testsupport.SeedTicket(t, tx, otherEventID, testsupport.SeedTicketType(t, tx, otherEventID, "General", 100, 10), nil, "available", 100)
+
+	filter := &repository.TicketFilter{EventID: &eventID}
+	var got []int64
+	cursor, pages := "", 0
+	for {
+		tickets, next, err := repo.ListTicketsPaged(ctx, filter, cursor, 2)
+		if err != nil {
+			t.Fatalf("ListTicketsPaged(page %d): %v", pages, err)
+		}
+		pages++
+		for _, ticket := range tickets {
+			got = append(got, ticket.ID)
+		}
+		// Un ticket creado a mitad del recorrido aparece al final, sin repetir ni saltar filas
+		if pages == 1 {
+			want = append(want, testsupport.SeedTicket(t, tx, eventID, typeID, nil, "available", 100))
+		}
+		if next == "" {
+			break
+		}
+		if pages > 10 {
+			t.Fatal("pagination did not terminate")
+		}
+		cursor = next
+	}
+
+	if pages != 3 {
+		t.Errorf("pages = %d, want 3", pages)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ticket %d = %d, want %d", i, got[i], want[i])
+		}
+	}
+
+	if _, _, err := repo.ListTicketsPaged(ctx, filter, "not-a-cursor", 2); !errors.Is(err, commondto.ErrValidation) {
+		t.Errorf("malformed cursor: err = %v, want ErrValidation", err)
+	}
+}
+
+func TestTicketRepositoryGetRevenueRecognition(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	// El reporte es global: se parte de una tabla vacía dentro de la transacción
+	if _, err := tx.Exec(ctx, `TRUNCATE ticketing.tickets CASCADE`); err != nil {
+		t.Fatalf("failed to clear tickets: %v", err)
+	}
+	asOf := time.Now()
+
+	organizerID := testsupport.SeedOrganizer(t, tx, "Organizer Recognition")
+	pastEvent := testsupport.SeedEvent(t, tx, organizerID, "Ya ocurrió", asOf.Add(-10*24*time.Hour))
+	futureEvent := testsupport.SeedEvent(t, tx, organizerID, "Próximo", asOf.Add(10*24*time.Hour))
+	pastType := testsupport.SeedTicketType(t, tx, pastEvent, "General", 100, 100)
+	futureType := testsupport.SeedTicketType(t, tx, futureEvent, "General", 250, 100)
+
+	testsupport.SeedTicket(t, tx, pastEvent, pastType, nil, "sold", 100)
+	testsupport.SeedTicket(t, tx, pastEvent, pastType, nil, "checked_in", 100)
+	testsupport.SeedTicket(t, tx, futureEvent, futureType, nil, "sold", 250)
+	usd := testsupport.SeedTicket(t, tx, futureEvent, futureType, nil, "sold", 40)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET currency = 'USD' WHERE id = $1`, usd); err != nil {
+		t.Fatalf("failed to set currency: %v", err)
+	}
+
+	// Reembolsados, cancelados, no vendidos y ventas posteriores a asOf no cuentan
+	testsupport.SeedTicket(t, tx, pastEvent, pastType, nil, "refunded", 100)
+	testsupport.SeedTicket(t, tx, futureEvent, futureType, nil, "cancelled", 250)
+	testsupport.SeedTicket(t, tx, futureEvent, futureType, nil, "available", 250)
+	refundedSold := testsupport.SeedTicket(t, tx, pastEvent, pastType, nil, "sold", 100)
+	lateSale := testsupport.SeedTicket(t, tx, futureEvent, futureType, nil, "sold", 250)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET refunded_at = NOW() WHERE id = $1`, refundedSold); err != nil {
+		t.Fatalf("failed to mark refund: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET sold_at = $1 WHERE id = $2`, asOf.Add(time.Hour), lateSale); err != nil {
+		t.Fatalf("failed to move sale: %v", err)
+	}
+
+	report, err := repo.GetRevenueRecognition(ctx, asOf)
+	if err != nil {
+		t.Fatalf("GetRevenueRecognition: %v", err)
+	}
+
+	want := []repository.CurrencyRecognition{
+		{Currency: "MXN", Recognized: 200, Deferred: 250, RecognizedTickets: 2, DeferredTickets: 1},
+		{Currency: "USD", Recognized: 0, Deferred: 40, RecognizedTickets: 0, DeferredTickets: 1},
+	}
+	if len(report.ByCurrency) != len(want) {
+		t.Fatalf("by currency = %+v, want %+v", report.ByCurrency, want)
+	}
+	for i := range want {
+		if report.ByCurrency[i] != want[i] {
+			t.Errorf("currency %d = %+v, want %+v", i, report.ByCurrency[i], want[i])
+		}
+	}
+}
+
+func TestTicketRepositoryReserveTicketUpdatesTypeQuantities(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Reserva Pregenerada")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 2)
+	first := testsupport.SeedTicket(t, tx, eventID, typeID, nil, "available", 100)
+	second := testsupport.SeedTicket(t, tx, eventID, typeID, nil, "available", 100)
+	userID := seedUser(t, tx, "reserve-ticket-"+uuid.NewString()+"@example.com")
+	userPublicID := testsupport.PublicID(t, tx, "auth.users", userID)
+	firstPublicID := testsupport.PublicID(t, tx, "ticketing.tickets", first)
+	expiresAt := time.Now().Add(10 * time.Minute).UTC().Truncate(time.Microsecond)
+
+	if err := repo.ReserveTicket(ctx, firstPublicID, userPublicID, expiresAt); err != nil {
+		t.Fatalf("ReserveTicket: %v", err)
+	}
+
+	ticket, err := repo.GetByID(ctx, first)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if ticket.Status != "reserved" || ticket.ReservedBy == nil || *ticket.ReservedBy != userID ||
+		ticket.ReservationExpiresAt == nil || !ticket.ReservationExpiresAt.Equal(expiresAt) {
+		t.Errorf("ticket = status %q, reserved_by %v, expires %v; want reserved by %d until %v",
+			ticket.Status, ticket.ReservedBy, ticket.ReservationExpiresAt, userID, expiresAt)
+	}
+	if sold, reserved, available := typeQuantities(t, tx, typeID); sold != 0 || reserved != 1 || available != 1 {
+		t.Errorf("quantities = sold %d, reserved %d, available %d; want 0/1/1", sold, reserved, available)
+	}
+
+	// Reservar dos veces el mismo ticket no vuelve a descontar cupo
+	if err := repo.ReserveTicket(ctx, firstPublicID, userPublicID, expiresAt); !errors.Is(err, repository.ErrTicketNotAvailable) {
+		t.Errorf("second reservation: err = %v, want ErrTicketNotAvailable", err)
+	}
+	if _, reserved, _ := typeQuantities(t, tx, typeID); reserved != 1 {
+		t.Errorf("reserved after failed reservation = %d, want 1", reserved)
+	}
+
+	secondPublicID := testsupport.PublicID(t, tx, "ticketing.tickets", second)
+	if err := repo.ReserveTicket(ctx, secondPublicID, "00000000-0000-4000-8000-000000000000", expiresAt); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("unknown user: err = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.ReserveTicket(ctx, "00000000-0000-4000-8000-000000000000", userPublicID, expiresAt); !errors.Is(err, repository.ErrTicketNotFound) {
+		t.Errorf("unknown ticket: err = %v, want ErrTicketNotFound", err)
+	}
+
+	// Sin cupo en el tipo la reserva se rechaza y el ticket sigue disponible
+	setTypeQuantities(t, tx, typeID, 1, 1)
+	if err := repo.ReserveTicket(ctx, secondPublicID, userPublicID, expiresAt); !errors.Is(err, repository.ErrNotEnoughTickets) {
+		t.Errorf("exhausted type: err = %v, want ErrNotEnoughTickets", err)
+	}
+	if status := ticketStatus(t, tx, second); status != "available" {
+		t.Errorf("ticket status after rejected reservation = %q, want available", status)
+	}
+}
+
+func TestTicketRepositoryTransferTicket(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	owner := testsupport.SeedCustomer(t, tx, "Sender", "sender-"+uuid.NewString()+"@example.com")
+	recipient := testsupport.SeedCustomer(t, tx, "Recipient", "recipient-"+uuid.NewString()+"@example.com")
+	stranger := testsupport.SeedCustomer(t, tx, "Stranger", "stranger-"+uuid.NewString()+"@example.com")
+	ownerID := testsupport.PublicID(t, tx, "crm.customers", owner)
+	recipientID := testsupport.PublicID(t, tx, "crm.customers", recipient)
+	strangerID := testsupport.PublicID(t, tx, "crm.customers", stranger)
+	ticketPublicID := seedTransferableTicket(t, tx, owner)
+
+	// Sólo el dueño actual puede transferir
+	if err := repo.TransferTicket(ctx, ticketPublicID, strangerID, recipientID); !errors.Is(err, repository.ErrTicketOwnerMismatch) {
+		t.Errorf("transfer by non-owner: err = %v, want ErrTicketOwnerMismatch", err)
+	}
+	if err := repo.TransferTicket(ctx, ticketPublicID, ownerID, "00000000-0000-4000-8000-000000000000"); !errors.Is(err, repository.ErrCustomerNotFound) {
+		t.Errorf("unknown recipient: err = %v, want ErrCustomerNotFound", err)
+	}
+	if err := repo.TransferTicket(ctx, "00000000-0000-4000-8000-000000000000", ownerID, recipientID); !errors.Is(err, repository.ErrTicketNotFound) {
+		t.Errorf("unknown ticket: err = %v, want ErrTicketNotFound", err)
+	}
+
+	if err := repo.TransferTicket(ctx, ticketPublicID, ownerID, recipientID); err != nil {
+		t.Fatalf("TransferTicket: %v", err)
+	}
+	ticket, err := repo.GetByPublicID(ctx, ticketPublicID)
+	if err != nil {
+		t.Fatalf("GetByPublicID: %v", err)
+	}
+	if ticket.CustomerID == nil || *ticket.CustomerID != recipient || ticket.TransferredFrom == nil || *ticket.TransferredFrom != owner || ticket.TransferredAt == nil {
+		t.Errorf("ticket owner = %v from %v at %v, want %d from %d", ticket.CustomerID, ticket.TransferredFrom, ticket.TransferredAt, recipient, owner)
+	}
+	if ticket.Status != string(enums.TicketStatusTransferred) {
+		t.Errorf("status = %q, want transferred", ticket.Status)
+	}
+	var sourceTicketID *int64
+	if err := tx.QueryRow(ctx, `SELECT transferred_from_ticket_id FROM ticketing.tickets WHERE id = $1`, ticket.ID).Scan(&sourceTicketID); err != nil {
+		t.Fatalf("failed to read transferred_from_ticket_id: %v", err)
+	}
+	if sourceTicketID == nil || *sourceTicketID != ticket.ID {
+		t.Errorf("transferred_from_ticket_id = %v, want %d", sourceTicketID, ticket.ID)
+	}
+	var history int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM ticketing.ticket_status_history
+		WHERE ticket_id = $1 AND from_status = 'sold' AND to_status = 'transferred' AND reason LIKE 'transferred to customer %'
+	`, ticket.ID).Scan(&history); err != nil {
+		t.Fatalf("failed to count history: %v", err)
+	}
+	if history != 1 {
+		t.Errorf("transfer history rows = %d, want 1", history)
+	}
+
+	// Un ticket transferido puede volver a transferirse
+	if err := repo.TransferTicket(ctx, ticketPublicID, recipientID, ownerID); err != nil {
+		t.Fatalf("re-transfer: %v", err)
+	}
+
+	// Un ticket usado ya no se puede transferir
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.tickets SET status = 'checked_in', checked_in_at = NOW() WHERE id = $1`, ticket.ID); err != nil {
+		t.Fatalf("failed to check in ticket: %v", err)
+	}
+	if err := repo.TransferTicket(ctx, ticketPublicID, ownerID, recipientID); !errors.Is(err, repository.ErrTicketNotAvailable) {
+		t.Errorf("transfer of used ticket: err = %v, want ErrTicketNotAvailable", err)
+	}
+}
+
+func TestTicketRepositoryTransferredTicketCanBeCheckedInAndRefunded(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Transferred")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	customerID := testsupport.SeedCustomer(t, tx, "Holder", "holder-"+uuid.NewString()+"@example.com")
+	checkInID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "transferred", 100)
+	refundID := testsupport.SeedTicket(t, tx, eventID, typeID, &customerID, "transferred", 100)
+
+	if err := repo.CheckIn(ctx, checkInID, enums.CheckInMethodQR, "Puerta 1", nil); err != nil {
+		t.Fatalf("CheckIn of transferred ticket: %v", err)
+	}
+	if status := ticketStatus(t, tx, checkInID); status != "checked_in" {
+		t.Errorf("status after check-in = %q, want checked_in", status)
+	}
+
+	if err := repo.Refund(ctx, refundID); err != nil {
+		t.Fatalf("Refund of transferred ticket: %v", err)
+	}
+	if status := ticketStatus(t, tx, refundID); status != "refunded" {
+		t.Errorf("status after refund = %q, want refunded", status)
+	}
+
+	// Un ticket reembolsado ya no se puede transferir
+	refundPublicID := testsupport.PublicID(t, tx, "ticketing.tickets", refundID)
+	holderPublicID := testsupport.PublicID(t, tx, "crm.customers", customerID)
+	other := testsupport.SeedCustomer(t, tx, "Other", "other-"+uuid.NewString()+"@example.com")
+	if err := repo.TransferTicket(ctx, refundPublicID, holderPublicID, testsupport.PublicID(t, tx, "crm.customers", other)); !errors.Is(err, repository.ErrTicketNotAvailable) {
+		t.Errorf("transfer of refunded ticket: err = %v, want ErrTicketNotAvailable", err)
+	}
+}
+
+func TestTicketRepositorySetQRCodeURL(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "QR")
+	ticketID := testsupport.SeedTicket(t, tx, eventID, testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10), nil, "sold", 100)
+
+	const url = "https://cdn.example.com/tickets/qr.png"
+	if err := repo.SetQRCodeURL(ctx, ticketID, url); err != nil {
+		t.Fatalf("SetQRCodeURL: %v", err)
+	}
+	ticket, err := repo.GetByID(ctx, ticketID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if ticket.QRCodeData == nil || *ticket.QRCodeData != url {
+		t.Errorf("qr_code_data = %v, want %q", ticket.QRCodeData, url)
+	}
+
+	if err := repo.SetQRCodeURL(ctx, -1, url); !errors.Is(err, repository.ErrTicketNotFound) {
+		t.Errorf("unknown ticket: err = %v, want ErrTicketNotFound", err)
+	}
+}
+
+func TestTicketRepositoryGenerateComplimentaryTickets(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Comps")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "Cortesía", 500, 5)
+	existingEmail := "comp-" + uuid.NewString() + "@example.com"
+	existingID := testsupport.SeedCustomer(t, tx, "Existing Guest", existingEmail)
+	newEmail := "comp-" + uuid.NewString() + "@example.com"
+
+	codes, err := repo.GenerateComplimentaryTickets(ctx,
+		testsupport.PublicID(t, tx, "ticketing.events", eventID),
+		testsupport.PublicID(t, tx, "ticketing.ticket_types", typeID),
+		[]repository.GuestInfo{
+			{Email: strings.ToUpper(existingEmail), Name: "Existing Guest"},
+			{Email: newEmail, Name: "New Guest", Phone: "+525512345678"},
+		},
+	)
+	if err != nil {
+		t.Fatalf("GenerateComplimentaryTickets: %v", err)
+	}
+	if len(codes) != 2 {
+		t.Fatalf("codes = %v, want 2", codes)
+	}
+
+	for i, email := range []string{existingEmail, newEmail} {
+		var customerEmail, status, secretHash string
+		var finalPrice float64
+		if err := tx.QueryRow(ctx, `
+			SELECT c.email, t.status, t.final_price, t.secret_hash
+			FROM ticketing.tickets t
+			JOIN crm.customers c ON c.id = t.customer_id
+			WHERE t.code = $1
+		`, codes[i]).Scan(&customerEmail, &status, &finalPrice, &secretHash); err != nil {
+			t.Fatalf("failed to read ticket %s: %v", codes[i], err)
+		}
+		if customerEmail != email || status != "sold" || finalPrice != 0 {
+			t.Errorf("ticket %d email/status/price = %s/%s/%.2f, want %s/sold/0.00", i, customerEmail, status, finalPrice, email)
+		}
+		if len(secretHash) != 64 {
+			t.Errorf("ticket %d secret_hash = %q, want a 64-char hex secret", i, secretHash)
+		}
+	}
+
+	var existingTickets, newCustomers int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM ticketing.tickets WHERE customer_id = $1`, existingID).Scan(&existingTickets); err != nil {
+		t.Fatalf("failed to count tickets: %v", err)
+	}
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM crm.customers WHERE email = $1 AND source = 'complimentary'`, newEmail).Scan(&newCustomers); err != nil {
+		t.Fatalf("failed to count customers: %v", err)
+	}
+	if existingTickets != 1 || newCustomers != 1 {
+		t.Errorf("existing guest tickets/new guest customers = %d/%d, want 1/1", existingTickets, newCustomers)
+	}
+
+	if sold, _, available := typeQuantities(t, tx, typeID); sold != 2 || available != 3 {
+		t.Errorf("sold/available = %d/%d, want 2/3", sold, available)
+	}
+}
+
+func TestTicketRepositoryGenerateComplimentaryTicketsExceedsInventory(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Comps Cap")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "Cortesía", 500, 3)
+	setTypeQuantities(t, tx, typeID, 1, 1)
+
+	guests := []repository.GuestInfo{
+		{Email: "cap-" + uuid.NewString() + "@example.com"},
+		{Email: "cap-" + uuid.NewString() + "@example.com"},
+	}
+	_, err := repo.GenerateComplimentaryTickets(ctx,
+		testsupport.PublicID(t, tx, "ticketing.events", eventID),
+		testsupport.PublicID(t, tx, "ticketing.ticket_types", typeID),
+		guests,
+	)
+	if !errors.Is(err, repository.ErrNotEnoughTickets) {
+		t.Fatalf("err = %v, want ErrNotEnoughTickets", err)
+	}
+
+	var tickets, customers int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM ticketing.tickets WHERE ticket_type_id = $1`, typeID).Scan(&tickets); err != nil {
+		t.Fatalf("failed to count tickets: %v", err)
+	}
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM crm.customers WHERE email IN ($1, $2)`, guests[0].Email, guests[1].Email).Scan(&customers); err != nil {
+		t.Fatalf("failed to count customers: %v", err)
+	}
+	if tickets != 0 || customers != 0 {
+		t.Errorf("tickets/customers = %d/%d, want 0/0", tickets, customers)
+	}
+	if sold, reserved, _ := typeQuantities(t, tx, typeID); sold != 1 || reserved != 1 {
+		t.Errorf("sold/reserved = %d/%d, want 1/1", sold, reserved)
+	}
+}
+
+func TestTicketRepositoryGenerateComplimentaryTicketsUnknownCategory(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketRepository(tx)
+
+	eventID := seedEvent(t, tx, "Comps Category")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "Cortesía", 500, 3)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.ticket_types SET is_active = false WHERE id = $1`, typeID); err != nil {
+		t.Fatalf("failed to deactivate ticket type: %v", err)
+	}
+	eventPublicID := testsupport.PublicID(t, tx, "ticketing.events", eventID)
+	guests := []repository.GuestInfo{{Email: "category-" + uuid.NewString() + "@example.com"}}
+
+	for name, categoryID := range map[string]string{
+		"inactive": testsupport.PublicID(t, tx, "ticketing.ticket_types", typeID),
+		"missing":  "00000000-0000-0000-0000-000000000000",
+	} {
+		_, err := repo.GenerateComplimentaryTickets(ctx, eventPublicID, categoryID, guests)
+		if !errors.Is(err, repository.ErrTicketTypeNotFound) {
+			t.Errorf("%s category: err = %v, want ErrTicketTypeNotFound", name, err)
+		}
+	}
+}
@@ -7,28 +7,52 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	tickettypedto "github.com/franciscozamorau/osmi-server/internal/api/dto/ticket_type"
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
 	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
 )
 
 // TicketTypeRepository implementa la interfaz repository.TicketTypeRepository
 type TicketTypeRepository struct {
-	db *pgxpool.Pool
+	db             DBTX
+	sweepBatchSize int
+	maxPage        int
 }
 
+// defaultSweepBatchSize es el tamaño de lote de los barridos si no se configura otro
+const defaultSweepBatchSize = 500
+
 // NewTicketTypeRepository crea una nueva instancia
-func NewTicketTypeRepository(db *pgxpool.Pool) *TicketTypeRepository {
+func NewTicketTypeRepository(db DBTX) *TicketTypeRepository {
 	return &TicketTypeRepository{
-		db: db,
+		db:             db,
+		sweepBatchSize: defaultSweepBatchSize,
+		maxPage:        commondto.DefaultMaxPage,
+	}
+}
+
+// SetMaxPage fija la página más profunda que admiten los listados paginados por
+// offset. Valores no positivos se ignoran.
+func (r *TicketTypeRepository) SetMaxPage(page int) {
+	if page > 0 {
+		r.maxPage = page
+	}
+}
+
+// SetSweepBatchSize fija cuántas filas procesa cada lote de ReleaseExpiredReservations.
+// Valores no positivos se ignoran.
+func (r *TicketTypeRepository) SetSweepBatchSize(n int) {
+	if n > 0 {
+		r.sweepBatchSize = n
 	}
 }
 
@@ -43,7 +67,7 @@ func (r *TicketTypeRepository) handleError(err error, context string) error {
 	}
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return repository.ErrTicketNotFound
+		return repository.ErrTicketTypeNotFound
 	}
 
 	var pgErr *pgconn.PgError
@@ -121,6 +145,77 @@ func (r *TicketTypeRepository) Create(ctx context.Context, ticketType *entities.
 	return nil
 }
 
+// CloneToEvent copia la configuración de un tipo de ticket a otro evento.
+// El nuevo tipo arranca sin ventas ni reservas y con un public_uuid nuevo.
+func (r *TicketTypeRepository) CloneToEvent(ctx context.Context, ticketTypePublicID, targetEventPublicID string) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var targetEventID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM ticketing.events WHERE public_uuid = $1`, targetEventPublicID).Scan(&targetEventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("%w: %s", repository.ErrEventNotFound, targetEventPublicID)
+		}
+		return "", r.handleError(err, "failed to get target event")
+	}
+
+	var nameTaken bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM ticketing.ticket_types target
+			JOIN ticketing.ticket_types source ON LOWER(source.name) = LOWER(target.name)
+			WHERE source.public_uuid = $1 AND target.event_id = $2
+		)
+	`, ticketTypePublicID, targetEventID).Scan(&nameTaken)
+	if err != nil {
+		return "", r.handleError(err, "failed to check ticket type name")
+	}
+	if nameTaken {
+		return "", repository.ErrTicketTypeDuplicateName
+	}
+
+	query := `
+		INSERT INTO ticketing.ticket_types (
+			public_uuid, event_id, name, description, ticket_class,
+			base_price, currency, tax_rate, service_fee_type, service_fee_value,
+			total_quantity, reserved_quantity, sold_quantity,
+			max_per_order, min_per_order,
+			sale_starts_at, sale_ends_at,
+			is_active, requires_approval, is_hidden, sales_channel,
+			benefits, access_type, validation_rules,
+			created_at, updated_at
+		)
+		SELECT
+			gen_random_uuid(), $2, name, description, ticket_class,
+			base_price, currency, tax_rate, service_fee_type, service_fee_value,
+			total_quantity, 0, 0,
+			max_per_order, min_per_order,
+			sale_starts_at, sale_ends_at,
+			is_active, requires_approval, is_hidden, sales_channel,
+			benefits, access_type, validation_rules,
+			NOW(), NOW()
+		FROM ticketing.ticket_types
+		WHERE public_uuid = $1
+		RETURNING public_uuid
+	`
+
+	var newPublicID string
+	if err := tx.QueryRow(ctx, query, ticketTypePublicID, targetEventID).Scan(&newPublicID); err != nil {
+		return "", r.handleError(err, "failed to clone ticket type")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return newPublicID, nil
+}
+
 // FindByID obtiene por ID numérico
 func (r *TicketTypeRepository) FindByID(ctx context.Context, id int64) (*entities.TicketType, error) {
 	query := `
@@ -233,7 +328,7 @@ func (r *TicketTypeRepository) FindByPublicID(ctx context.Context, publicID stri
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, repository.ErrTicketNotFound
+			return nil, repository.ErrTicketTypeNotFound
 		}
 		log.Printf("❌ Error en FindByPublicID: %v", err)
 		return nil, r.handleError(err, "failed to get ticket type by public ID")
@@ -272,7 +367,7 @@ func (r *TicketTypeRepository) FindByPublicID(ctx context.Context, publicID stri
 func (r *TicketTypeRepository) Update(ctx context.Context, ticketType *entities.TicketType) error {
 	_, err := r.FindByID(ctx, ticketType.ID)
 	if err != nil {
-		return repository.ErrTicketNotFound
+		return repository.ErrTicketTypeNotFound
 	}
 
 	query := `
@@ -332,7 +427,7 @@ func (r *TicketTypeRepository) Delete(ctx context.Context, id int64) error {
 		return r.handleError(err, "failed to delete ticket type")
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return repository.ErrTicketNotFound
+		return repository.ErrTicketTypeNotFound
 	}
 	return nil
 }
@@ -345,7 +440,7 @@ func (r *TicketTypeRepository) SoftDelete(ctx context.Context, publicID string)
 		return r.handleError(err, "failed to soft delete ticket type")
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return repository.ErrTicketNotFound
+		return repository.ErrTicketTypeNotFound
 	}
 	return nil
 }
@@ -367,6 +462,11 @@ func (r *TicketTypeRepository) Exists(ctx context.Context, id int64) (bool, erro
 
 // List lista con filtros y paginación
 func (r *TicketTypeRepository) List(ctx context.Context, filter tickettypedto.TicketTypeFilter, pagination commondto.Pagination) ([]*entities.TicketType, int64, error) {
+	pagination, err := pagination.Normalize(r.maxPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	where := []string{"1=1"}
 	args := []interface{}{}
 	argPos := 1
@@ -412,7 +512,7 @@ func (r *TicketTypeRepository) List(ctx context.Context, filter tickettypedto.Ti
 	// Contar total
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM ticketing.ticket_types WHERE %s", whereClause)
 	var total int64
-	err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total)
+	err = r.db.QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, r.handleError(err, "failed to count ticket types")
 	}
@@ -947,6 +1047,52 @@ func (r *TicketTypeRepository) CheckAvailability(ctx context.Context, ticketType
 	return available, nil
 }
 
+// CheckPurchasability valida si se puede comprar una cantidad de un tipo de ticket.
+// Siempre devuelve el resultado con las cifras relevantes; si no es comprable
+// también devuelve un error que envuelve repository.ErrPurchaseNotAllowed.
+func (r *TicketTypeRepository) CheckPurchasability(ctx context.Context, ticketTypePublicID string, quantity int, now time.Time) (*repository.PurchaseCheck, error) {
+	query := `
+		SELECT is_active, sale_starts_at, sale_ends_at,
+			(total_quantity - sold_quantity - reserved_quantity) AS available,
+			min_per_order, max_per_order
+		FROM ticketing.ticket_types
+		WHERE public_uuid = $1
+	`
+
+	check := &repository.PurchaseCheck{}
+	var isActive bool
+	err := r.db.QueryRow(ctx, query, ticketTypePublicID).Scan(
+		&isActive, &check.SaleStartsAt, &check.SaleEndsAt,
+		&check.Available, &check.MinPerOrder, &check.MaxPerOrder,
+	)
+	if err != nil {
+		return nil, r.handleError(err, "failed to check purchasability")
+	}
+
+	switch {
+	case !isActive:
+		check.Reason = enums.PurchaseReasonInactive
+	case now.Before(check.SaleStartsAt):
+		check.Reason = enums.PurchaseReasonSalesNotStarted
+	case check.SaleEndsAt != nil && !now.Before(*check.SaleEndsAt):
+		check.Reason = enums.PurchaseReasonSalesEnded
+	case check.Available <= 0:
+		check.Reason = enums.PurchaseReasonSoldOut
+	case check.MinPerOrder > 0 && quantity < check.MinPerOrder:
+		check.Reason = enums.PurchaseReasonBelowMinPerOrder
+	case check.MaxPerOrder > 0 && quantity > check.MaxPerOrder:
+		check.Reason = enums.PurchaseReasonAboveMaxPerOrder
+	case quantity > check.Available:
+		check.Reason = enums.PurchaseReasonInsufficientQuantity
+	default:
+		check.Reason = enums.PurchaseReasonOK
+		check.Purchasable = true
+		return check, nil
+	}
+
+	return check, fmt.Errorf("%w: %s", repository.ErrPurchaseNotAllowed, check.Reason)
+}
+
 // GetAvailableQuantity obtiene cantidad disponible
 func (r *TicketTypeRepository) GetAvailableQuantity(ctx context.Context, ticketTypeID int64) (int, error) {
 	var quantity int
@@ -1014,11 +1160,52 @@ func (r *TicketTypeRepository) UpdateStatus(ctx context.Context, ticketTypeID in
 		return r.handleError(err, "failed to update status")
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return repository.ErrTicketNotFound
+		return repository.ErrTicketTypeNotFound
 	}
 	return nil
 }
 
+// GetSalesStatus calcula el estado de venta combinando la ventana de venta con la disponibilidad.
+// El número exacto de tickets restantes sólo se expone si el evento tiene
+// show_remaining_count activo en su configuración.
+func (r *TicketTypeRepository) GetSalesStatus(ctx context.Context, ticketTypePublicID string, now time.Time) (repository.SalesStatus, error) {
+	query := `
+		SELECT tt.sale_starts_at, tt.sale_ends_at,
+			GREATEST(tt.total_quantity - tt.sold_quantity - tt.reserved_quantity, 0) AS available,
+			COALESCE((e.settings->>'show_remaining_count')::boolean, false) AS show_remaining
+		FROM ticketing.ticket_types tt
+		JOIN ticketing.events e ON e.id = tt.event_id
+		WHERE tt.public_uuid = $1
+	`
+
+	var saleStartsAt time.Time
+	var saleEndsAt *time.Time
+	var available int
+	var showRemaining bool
+
+	err := r.db.QueryRow(ctx, query, ticketTypePublicID).Scan(&saleStartsAt, &saleEndsAt, &available, &showRemaining)
+	if err != nil {
+		return repository.SalesStatus{}, r.handleError(err, "failed to get sales status")
+	}
+
+	status := repository.SalesStatus{Available: available > 0}
+	if showRemaining {
+		status.Remaining = &available
+	}
+
+	switch {
+	case now.Before(saleStartsAt):
+		status.Status, status.At = enums.SalesStatusNotStarted, &saleStartsAt
+	case saleEndsAt != nil && !now.Before(*saleEndsAt):
+		status.Status, status.At = enums.SalesStatusEnded, saleEndsAt
+	case available <= 0:
+		status.Status, status.At = enums.SalesStatusSoldOut, saleEndsAt
+	default:
+		status.Status, status.At = enums.SalesStatusActive, saleEndsAt
+	}
+	return status, nil
+}
+
 // ============================================================================
 // ESTADÍSTICAS
 // ============================================================================
@@ -1051,7 +1238,7 @@ func (r *TicketTypeRepository) GetRevenue(ctx context.Context, ticketTypeID int6
 	query := `
 		SELECT COALESCE(SUM(final_price), 0)
 		FROM ticketing.tickets
-		WHERE ticket_type_id = $1 AND status IN ('sold', 'checked_in')
+		WHERE ticket_type_id = $1 AND status IN ('sold', 'transferred', 'checked_in')
 	`
 	err := r.db.QueryRow(ctx, query, ticketTypeID).Scan(&revenue)
 	if err != nil {
@@ -1063,21 +1250,15 @@ func (r *TicketTypeRepository) GetRevenue(ctx context.Context, ticketTypeID int6
 // GetSalesVelocity obtiene velocidad de ventas (tickets por día)
 func (r *TicketTypeRepository) GetSalesVelocity(ctx context.Context, ticketTypeID int64) (float64, error) {
 	var velocity float64
+	// Sin GROUP BY para que un tipo sin ventas devuelva 0 en lugar de ninguna fila
 	query := `
-		WITH first_sale AS (
-			SELECT MIN(sold_at) as first_sale
-			FROM ticketing.tickets
-			WHERE ticket_type_id = $1 AND sold_at IS NOT NULL
-		)
-		SELECT 
-			COALESCE(
-				COUNT(*)::float / 
-				EXTRACT(EPOCH FROM (NOW() - first_sale.first_sale)) / 86400,
-				0
-			) as velocity
-		FROM ticketing.tickets, first_sale
+		SELECT COALESCE(
+			COUNT(*)::float /
+			NULLIF(EXTRACT(EPOCH FROM (NOW() - MIN(sold_at))) / 86400, 0),
+			0
+		) as velocity
+		FROM ticketing.tickets
 		WHERE ticket_type_id = $1 AND sold_at IS NOT NULL
-		GROUP BY first_sale.first_sale
 	`
 	err := r.db.QueryRow(ctx, query, ticketTypeID).Scan(&velocity)
 	if err != nil {
@@ -1086,16 +1267,18 @@ func (r *TicketTypeRepository) GetSalesVelocity(ctx context.Context, ticketTypeI
 	return velocity, nil
 }
 
-// GetStats obtiene estadísticas completas
+// GetStats obtiene estadísticas completas. La disponibilidad se calcula sólo aquí
+// (total - vendidos - reservados, igual que TicketType.GetAvailableQuantity) y
+// no debe volver a restarse en Go.
 func (r *TicketTypeRepository) GetStats(ctx context.Context, ticketTypeID int64) (*tickettypedto.TicketTypeStatsResponse, error) {
 	query := `
         SELECT 
             total_quantity as total_tickets,
             reserved_quantity as reserved_tickets,
             sold_quantity as sold_tickets,
-            total_quantity - sold_quantity - reserved_quantity as available_tickets,
-            COALESCE(SUM(sold_quantity * base_price), 0) as total_revenue,
-            COALESCE(AVG(base_price), 0) as avg_ticket_price,
+            GREATEST(total_quantity - sold_quantity - reserved_quantity, 0) as available_tickets,
+            sold_quantity * base_price as total_revenue,
+            base_price as avg_ticket_price,
             CASE 
                 WHEN total_quantity > 0 
                 THEN (sold_quantity::float / total_quantity::float) * 100 
@@ -1103,7 +1286,6 @@ func (r *TicketTypeRepository) GetStats(ctx context.Context, ticketTypeID int64)
             END as sell_through_rate
         FROM ticketing.ticket_types
         WHERE id = $1
-        GROUP BY id, total_quantity, reserved_quantity, sold_quantity, base_price
     `
 
 	var stats tickettypedto.TicketTypeStatsResponse
@@ -1126,22 +1308,21 @@ func (r *TicketTypeRepository) GetStats(ctx context.Context, ticketTypeID int64)
 func (r *TicketTypeRepository) GetEventTicketStats(ctx context.Context, eventID int64) (*tickettypedto.EventTicketStats, error) {
 	query := `
 		SELECT 
-			event_id,
+			$1::bigint as event_id,
 			COUNT(*) as ticket_type_id,
 			'' as ticket_type_name,
-			SUM(total_quantity) as total_quantity,
-			SUM(sold_quantity) as sold_quantity,
-			SUM(reserved_quantity) as reserved_quantity,
-			SUM(total_quantity - sold_quantity - reserved_quantity) as available_quantity,
+			COALESCE(SUM(total_quantity), 0) as total_quantity,
+			COALESCE(SUM(sold_quantity), 0) as sold_quantity,
+			COALESCE(SUM(reserved_quantity), 0) as reserved_quantity,
+			COALESCE(SUM(total_quantity - sold_quantity - reserved_quantity), 0) as available_quantity,
 			COALESCE(SUM(sold_quantity * base_price), 0) as revenue,
 			CASE 
-				WHEN SUM(total_quantity) > 0 
+				WHEN COALESCE(SUM(total_quantity), 0) > 0 
 				THEN (SUM(sold_quantity)::float / SUM(total_quantity)::float) * 100
 				ELSE 0
 			END as sell_through_rate
 		FROM ticketing.ticket_types
 		WHERE event_id = $1
-		GROUP BY event_id
 	`
 
 	var stats tickettypedto.EventTicketStats
@@ -1251,6 +1432,10 @@ func (r *TicketTypeRepository) ConfirmReservationTx(ctx context.Context, tx pgx.
 		return fmt.Errorf("not enough reserved tickets to confirm")
 	}
 
+	if err := markEventSoldOut(ctx, tx, ticketTypeID); err != nil {
+		return r.handleError(err, "failed to mark event as sold out")
+	}
+
 	return nil
 }
 
@@ -1275,47 +1460,121 @@ func (r *TicketTypeRepository) ReleaseReservationTx(ctx context.Context, tx pgx.
 	return nil
 }
 
-// ReleaseExpiredReservations en ticket_type_repository.go
+// ReleaseExpiredReservations libera las reservas vencidas y recalcula los
+// contadores de sus tipos de ticket. Los tickets pregenerados vuelven a
+// 'available'; los creados por la propia reserva se marcan 'expired'. Procesa lotes de a lo sumo
+// sweepBatchSize tickets, cada uno en su propia transacción, hasta vaciar el
+// backlog; así ningún lote retiene bloqueos sobre toda la tabla. Si ctx se
+// cancela entre lotes devuelve lo liberado hasta ese momento junto con el error.
 func (r *TicketTypeRepository) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
-	// 1. Marcar expirados
-	updateTicketsQuery := `
-        UPDATE ticketing.tickets 
-        SET status = 'expired', 
-            reservation_expires_at = NULL,
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		released, err := r.releaseExpiredBatch(ctx, r.sweepBatchSize)
+		total += released
+		if err != nil {
+			return total, err
+		}
+		if released < int64(r.sweepBatchSize) {
+			return total, nil
+		}
+	}
+}
+
+// releaseExpiredBatch libera hasta limit reservas vencidas y recalcula los
+// contadores de los tipos afectados. SKIP LOCKED evita esperar filas que otra
+// transacción (una compra o un barrido concurrente) ya tiene bloqueadas.
+func (r *TicketTypeRepository) releaseExpiredBatch(ctx context.Context, limit int) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Liberar vencidos, bloqueando en orden de id (ver ReserveTicketsBatchTx).
+	// Sólo el inventario pregenerado (reservado con reserved_by) vuelve a
+	// 'available'; las filas que creó la reserva por tipo quedan 'expired'.
+	expireQuery := `
+        WITH expired AS (
+            SELECT id
+            FROM ticketing.tickets
+            WHERE status = 'reserved'
+              AND reservation_expires_at < NOW()
+            ORDER BY id
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        UPDATE ticketing.tickets t
+        SET status = CASE WHEN t.reserved_by IS NULL THEN 'expired' ELSE 'available' END,
+            reserved_at = CASE WHEN t.reserved_by IS NULL THEN t.reserved_at END,
+            reservation_expires_at = CASE WHEN t.reserved_by IS NULL THEN t.reservation_expires_at END,
+            reserved_by = NULL,
             updated_at = NOW()
-        WHERE status = 'reserved' 
-          AND reservation_expires_at < NOW()
+        FROM expired
+        WHERE t.id = expired.id
+        RETURNING t.ticket_type_id
     `
-	result, err := r.db.Exec(ctx, updateTicketsQuery)
+	rows, err := tx.Query(ctx, expireQuery, limit)
 	if err != nil {
 		return 0, r.handleError(err, "failed to update expired tickets")
 	}
-	expiredCount := result.RowsAffected()
-
-	if expiredCount > 0 {
-		// 2. Recalcular contadores
-		recalcQuery := `
-            UPDATE ticketing.ticket_types tt
-            SET 
-                reserved_quantity = COALESCE(r.real_reserved, 0),
-                sold_quantity = COALESCE(r.real_sold, 0)
-            FROM (
-                SELECT 
-                    ticket_type_id,
-                    COUNT(*) FILTER (WHERE status = 'reserved') AS real_reserved,
-                    COUNT(*) FILTER (WHERE status IN ('sold', 'checked_in')) AS real_sold
-                FROM ticketing.tickets
-                GROUP BY ticket_type_id
-            ) r
-            WHERE tt.id = r.ticket_type_id
-        `
-		_, err = r.db.Exec(ctx, recalcQuery)
-		if err != nil {
-			return expiredCount, r.handleError(err, "failed to recalc counters")
+	var released int64
+	var typeIDs []int64
+	seen := make(map[int64]bool)
+	for rows.Next() {
+		var typeID int64
+		if err := rows.Scan(&typeID); err != nil {
+			rows.Close()
+			return 0, r.handleError(err, "failed to scan expired ticket")
 		}
+		released++
+		if !seen[typeID] {
+			seen[typeID] = true
+			typeIDs = append(typeIDs, typeID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, r.handleError(err, "failed to update expired tickets")
+	}
+
+	if released == 0 {
+		return 0, nil
+	}
+
+	// 2. Recalcular contadores sólo de los tipos afectados, bloqueándolos en orden de id
+	if _, err := tx.Exec(ctx, `SELECT id FROM ticketing.ticket_types WHERE id = ANY($1) ORDER BY id FOR UPDATE`, typeIDs); err != nil {
+		return 0, r.handleError(err, "failed to lock ticket types")
+	}
+	recalcQuery := `
+        UPDATE ticketing.ticket_types tt
+        SET
+            reserved_quantity = COALESCE(r.real_reserved, 0),
+            sold_quantity = COALESCE(r.real_sold, 0),
+            available_quantity = tt.total_quantity - COALESCE(r.real_sold, 0) - COALESCE(r.real_reserved, 0)
+        FROM (
+            SELECT
+                ticket_type_id,
+                COUNT(*) FILTER (WHERE status = 'reserved') AS real_reserved,
+                COUNT(*) FILTER (WHERE status IN ('sold', 'transferred', 'checked_in')) AS real_sold
+            FROM ticketing.tickets
+            WHERE ticket_type_id = ANY($1)
+            GROUP BY ticket_type_id
+        ) r
+        WHERE tt.id = r.ticket_type_id
+    `
+	if _, err := tx.Exec(ctx, recalcQuery, typeIDs); err != nil {
+		return 0, r.handleError(err, "failed to recalc counters")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return expiredCount, nil
+	return released, nil
 }
 
 // ReserveTicketWithLock reserva un ticket con bloqueo FOR UPDATE
@@ -1334,7 +1593,7 @@ func (r *TicketTypeRepository) ReserveTicketWithLock(ctx context.Context, tx pgx
 	}
 
 	if available < quantity {
-		return fmt.Errorf("not enough tickets available: only %d left", available)
+		return fmt.Errorf("%w: only %d left", repository.ErrNotEnoughTickets, available)
 	}
 
 	// Actualizar reserved_quantity
@@ -1347,3 +1606,141 @@ func (r *TicketTypeRepository) ReserveTicketWithLock(ctx context.Context, tx pgx
 	_, err = tx.Exec(ctx, updateQuery, quantity, ticketTypeID)
 	return err
 }
+
+// SellTicketsWithLock bloquea la fila del tipo de ticket, valida que queden al
+// menos quantity disponibles y suma la venta, todo dentro de tx. Así dos
+// ventas concurrentes no pueden superar total_quantity. Si la venta agota el
+// evento, éste pasa a sold_out (ver markEventSoldOut).
+func (r *TicketTypeRepository) SellTicketsWithLock(ctx context.Context, tx pgx.Tx, ticketTypeID int64, quantity int) error {
+	var available int
+	query := `
+        SELECT (total_quantity - sold_quantity - reserved_quantity)
+        FROM ticketing.ticket_types
+        WHERE id = $1 AND is_active = true
+        FOR UPDATE
+    `
+	err := tx.QueryRow(ctx, query, ticketTypeID).Scan(&available)
+	if err != nil {
+		return r.handleError(err, "failed to lock ticket type")
+	}
+
+	if available < quantity {
+		return fmt.Errorf("%w: only %d left", repository.ErrNotEnoughTickets, available)
+	}
+
+	updateQuery := `
+        UPDATE ticketing.ticket_types
+        SET sold_quantity = sold_quantity + $1,
+            updated_at = NOW()
+        WHERE id = $2
+    `
+	if _, err := tx.Exec(ctx, updateQuery, quantity, ticketTypeID); err != nil {
+		return r.handleError(err, "failed to sell tickets")
+	}
+	if err := markEventSoldOut(ctx, tx, ticketTypeID); err != nil {
+		return r.handleError(err, "failed to mark event as sold out")
+	}
+	return nil
+}
+
+// markEventSoldOut pasa a sold_out el evento del tipo de ticket cuando ya no
+// le queda ningún tipo activo con entradas sin vender, y registra el cambio en
+// event_status_history. Solo aplica a eventos publicados o en vivo; las
+// reservas no cuentan como vendidas porque pueden expirar.
+func markEventSoldOut(ctx context.Context, tx pgx.Tx, ticketTypeID int64) error {
+	_, err := tx.Exec(ctx, `
+		WITH target AS (
+			SELECT e.id, e.status
+			FROM ticketing.events e
+			JOIN ticketing.ticket_types tt ON tt.event_id = e.id
+			WHERE tt.id = $1
+			  AND e.status IN ('published', 'live')
+			  AND NOT EXISTS (
+				SELECT 1 FROM ticketing.ticket_types o
+				WHERE o.event_id = e.id
+				  AND o.is_active = true
+				  AND o.sold_quantity < o.total_quantity
+			  )
+			FOR UPDATE OF e
+		),
+		updated AS (
+			UPDATE ticketing.events e
+			SET status = 'sold_out',
+				updated_at = NOW()
+			FROM target
+			WHERE e.id = target.id
+			RETURNING e.id, target.status AS from_status
+		)
+		INSERT INTO ticketing.event_status_history (event_id, from_status, to_status, changed_at)
+		SELECT id, from_status, 'sold_out', NOW()
+		FROM updated
+	`, ticketTypeID)
+	return err
+}
+
+// ReserveTicketsBatchTx reserva varios tipos de ticket en una misma transacción
+// (p. ej. validación de carrito). Convención de bloqueo: toda operación que
+// bloquee varias filas lo hace con ORDER BY id antes de FOR UPDATE, de modo que
+// dos transacciones con conjuntos solapados adquieren los locks en el mismo
+// orden y una espera a la otra en lugar de producir un deadlock.
+func (r *TicketTypeRepository) ReserveTicketsBatchTx(ctx context.Context, tx pgx.Tx, quantities map[int64]int) error {
+	if len(quantities) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows, err := tx.Query(ctx, `
+        SELECT id, (total_quantity - sold_quantity - reserved_quantity)
+        FROM ticketing.ticket_types
+        WHERE id = ANY($1)
+        ORDER BY id
+        FOR UPDATE
+    `, ids)
+	if err != nil {
+		return r.handleError(err, "failed to lock ticket types")
+	}
+
+	available := make(map[int64]int, len(ids))
+	for rows.Next() {
+		var id int64
+		var qty int
+		if err := rows.Scan(&id, &qty); err != nil {
+			rows.Close()
+			return r.handleError(err, "failed to scan ticket type availability")
+		}
+		available[id] = qty
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return r.handleError(err, "failed to read ticket type availability")
+	}
+
+	for _, id := range ids {
+		left, ok := available[id]
+		if !ok {
+			return repository.ErrTicketTypeNotFound
+		}
+		if left < quantities[id] {
+			return fmt.Errorf("%w: ticket type %d has only %d left", repository.ErrNotEnoughTickets, id, left)
+		}
+	}
+
+	updateQuery := `
+        UPDATE ticketing.ticket_types
+        SET reserved_quantity = reserved_quantity + $1,
+            updated_at = NOW()
+        WHERE id = $2
+    `
+	for _, id := range ids {
+		if _, err := tx.Exec(ctx, updateQuery, quantities[id], id); err != nil {
+			return r.handleError(err, "failed to reserve ticket type")
+		}
+	}
+
+	return nil
+}
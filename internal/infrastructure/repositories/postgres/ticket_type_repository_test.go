@@ -0,0 +1,651 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
+	"github.com/franciscozamorau/osmi-server/internal/domain/repository"
+	"github.com/franciscozamorau/osmi-server/internal/infrastructure/repositories/postgres"
+	"github.com/franciscozamorau/osmi-server/internal/testsupport"
+)
+
+func TestTicketTypeRepositoryGetSalesStatus(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	eventID := seedEvent(t, tx, "Concierto")
+	now := time.Date(2030, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		startsAt time.Time
+		endsAt   *time.Time
+		sold     int
+		want     enums.SalesStatus
+	}{
+		{"not started", now.Add(time.Hour), nil, 0, enums.SalesStatusNotStarted},
+		{"active", now.Add(-time.Hour), timePtr(now.Add(time.Hour)), 0, enums.SalesStatusActive},
+		{"active without end", now.Add(-time.Hour), nil, 5, enums.SalesStatusActive},
+		{"ended", now.Add(-2 * time.Hour), timePtr(now.Add(-time.Hour)), 0, enums.SalesStatusEnded},
+		{"sold out within window", now.Add(-time.Hour), timePtr(now.Add(time.Hour)), 10, enums.SalesStatusSoldOut},
+		{"ended wins over sold out", now.Add(-2 * time.Hour), timePtr(now.Add(-time.Hour)), 10, enums.SalesStatusEnded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeID := testsupport.SeedTicketType(t, tx, eventID, tt.name, 100, 10)
+			_, err := tx.Exec(ctx, `
+				UPDATE ticketing.ticket_types
+				SET sale_starts_at = $1, sale_ends_at = $2, sold_quantity = $3
+				WHERE id = $4
+			`, tt.startsAt, tt.endsAt, tt.sold, typeID)
+			if err != nil {
+				t.Fatalf("failed to set sale window: %v", err)
+			}
+
+			got, err := repo.GetSalesStatus(ctx, testsupport.PublicID(t, tx, "ticketing.ticket_types", typeID), now)
+			if err != nil {
+				t.Fatalf("GetSalesStatus: %v", err)
+			}
+			if got.Status != tt.want {
+				t.Errorf("status = %s, want %s", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestTicketTypeRepositoryGetSalesStatusGatesRemainingCount(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		settings      string
+		sold          int
+		wantAvailable bool
+		wantRemaining *int
+	}{
+		{"setting on shows exact count", `{"show_remaining_count": true}`, 7, true, intPtr(3)},
+		{"setting off hides count", `{"show_remaining_count": false}`, 7, true, nil},
+		{"setting missing hides count", `{}`, 7, true, nil},
+		{"sold out with setting on", `{"show_remaining_count": true}`, 10, false, intPtr(0)},
+		{"sold out with setting off", `{}`, 10, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventID := seedEvent(t, tx, tt.name)
+			if _, err := tx.Exec(ctx, `UPDATE ticketing.events SET settings = $1::jsonb WHERE id = $2`, tt.settings, eventID); err != nil {
+				t.Fatalf("failed to set event settings: %v", err)
+			}
+			typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+			if _, err := tx.Exec(ctx, `
+				UPDATE ticketing.ticket_types SET sale_starts_at = $1, sold_quantity = $2 WHERE id = $3
+			`, now.Add(-time.Hour), tt.sold, typeID); err != nil {
+				t.Fatalf("failed to set sales: %v", err)
+			}
+
+			got, err := repo.GetSalesStatus(ctx, testsupport.PublicID(t, tx, "ticketing.ticket_types", typeID), now)
+			if err != nil {
+				t.Fatalf("GetSalesStatus: %v", err)
+			}
+			if got.Available != tt.wantAvailable {
+				t.Errorf("Available = %v, want %v", got.Available, tt.wantAvailable)
+			}
+			switch {
+			case tt.wantRemaining == nil && got.Remaining != nil:
+				t.Errorf("Remaining = %d, want hidden", *got.Remaining)
+			case tt.wantRemaining != nil && (got.Remaining == nil || *got.Remaining != *tt.wantRemaining):
+				t.Errorf("Remaining = %v, want %d", got.Remaining, *tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestTicketTypeRepositoryCloneToEvent(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	sourceEventID := seedEvent(t, tx, "Source")
+	targetEventID := seedEvent(t, tx, "Target")
+	sourceID := testsupport.SeedTicketType(t, tx, sourceEventID, "VIP", 850, 40)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.ticket_types SET sold_quantity = 12 WHERE id = $1`, sourceID); err != nil {
+		t.Fatalf("failed to mark sales: %v", err)
+	}
+
+	clonedPublicID, err := repo.CloneToEvent(ctx,
+		testsupport.PublicID(t, tx, "ticketing.ticket_types", sourceID),
+		testsupport.PublicID(t, tx, "ticketing.events", targetEventID),
+	)
+	if err != nil {
+		t.Fatalf("CloneToEvent: %v", err)
+	}
+
+	clone, err := repo.FindByPublicID(ctx, clonedPublicID)
+	if err != nil {
+		t.Fatalf("FindByPublicID: %v", err)
+	}
+	if clone.EventID != targetEventID || clone.Name != "VIP" || clone.BasePrice != 850 || clone.TotalQuantity != 40 {
+		t.Errorf("clone = %+v, want VIP at 850 with 40 tickets in event %d", clone, targetEventID)
+	}
+	if clone.SoldQuantity != 0 || clone.ReservedQuantity != 0 {
+		t.Errorf("clone starts with sold=%d reserved=%d, want 0", clone.SoldQuantity, clone.ReservedQuantity)
+	}
+}
+
+func TestTicketTypeRepositoryCloneToEventRejectsNameCollision(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	sourceEventID := seedEvent(t, tx, "Source")
+	targetEventID := seedEvent(t, tx, "Target")
+	sourceID := testsupport.SeedTicketType(t, tx, sourceEventID, "General", 300, 100)
+	testsupport.SeedTicketType(t, tx, targetEventID, "general", 250, 50)
+
+	_, err := repo.CloneToEvent(ctx,
+		testsupport.PublicID(t, tx, "ticketing.ticket_types", sourceID),
+		testsupport.PublicID(t, tx, "ticketing.events", targetEventID),
+	)
+	if !errors.Is(err, repository.ErrTicketTypeDuplicateName) {
+		t.Fatalf("CloneToEvent error = %v, want ErrTicketTypeDuplicateName", err)
+	}
+}
+
+func TestTicketTypeRepositoryCloneToEventMissingTargetEvent(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	sourceEventID := seedEvent(t, tx, "Source")
+	sourceID := testsupport.SeedTicketType(t, tx, sourceEventID, "General", 300, 100)
+	missingEvent := uuid.NewString()
+
+	_, err := repo.CloneToEvent(ctx, testsupport.PublicID(t, tx, "ticketing.ticket_types", sourceID), missingEvent)
+	if !errors.Is(err, repository.ErrEventNotFound) {
+		t.Fatalf("CloneToEvent error = %v, want ErrEventNotFound", err)
+	}
+	if !strings.Contains(err.Error(), missingEvent) {
+		t.Errorf("CloneToEvent error = %q, want it to mention %s", err, missingEvent)
+	}
+}
+
+func TestTicketTypeRepositoryCheckPurchasabilityReasons(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	eventID := seedEvent(t, tx, "Compra")
+	now := time.Date(2030, 6, 15, 12, 0, 0, 0, time.UTC)
+	open := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		active   bool
+		startsAt time.Time
+		endsAt   *time.Time
+		sold     int
+		minOrder int
+		maxOrder int
+		quantity int
+		want     enums.PurchaseReason
+	}{
+		{"ok", true, open, nil, 0, 1, 10, 2, enums.PurchaseReasonOK},
+		{"inactive", false, open, nil, 0, 1, 10, 1, enums.PurchaseReasonInactive},
+		{"not started", true, now.Add(time.Hour), nil, 0, 1, 10, 1, enums.PurchaseReasonSalesNotStarted},
+		{"ended", true, now.Add(-2 * time.Hour), timePtr(now), 0, 1, 10, 1, enums.PurchaseReasonSalesEnded},
+		{"sold out", true, open, nil, 10, 1, 10, 1, enums.PurchaseReasonSoldOut},
+		{"below min", true, open, nil, 0, 2, 10, 1, enums.PurchaseReasonBelowMinPerOrder},
+		{"above max", true, open, nil, 0, 1, 4, 5, enums.PurchaseReasonAboveMaxPerOrder},
+		{"insufficient", true, open, nil, 8, 1, 10, 3, enums.PurchaseReasonInsufficientQuantity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeID := testsupport.SeedTicketType(t, tx, eventID, tt.name, 100, 10)
+			_, err := tx.Exec(ctx, `
+				UPDATE ticketing.ticket_types
+				SET is_active = $1, sale_starts_at = $2, sale_ends_at = $3, sold_quantity = $4,
+					min_per_order = $5, max_per_order = $6
+				WHERE id = $7
+			`, tt.active, tt.startsAt, tt.endsAt, tt.sold, tt.minOrder, tt.maxOrder, typeID)
+			if err != nil {
+				t.Fatalf("failed to set up ticket type: %v", err)
+			}
+
+			check, err := repo.CheckPurchasability(ctx, testsupport.PublicID(t, tx, "ticketing.ticket_types", typeID), tt.quantity, now)
+			if check == nil {
+				t.Fatalf("CheckPurchasability returned no result: %v", err)
+			}
+			if check.Reason != tt.want {
+				t.Errorf("reason = %s, want %s", check.Reason, tt.want)
+			}
+			if check.Available != 10-tt.sold || check.MaxPerOrder != tt.maxOrder {
+				t.Errorf("figures = available %d / max %d, want %d / %d", check.Available, check.MaxPerOrder, 10-tt.sold, tt.maxOrder)
+			}
+
+			if tt.want == enums.PurchaseReasonOK {
+				if err != nil || !check.Purchasable {
+					t.Errorf("purchasable = %v, err = %v; want purchasable without error", check.Purchasable, err)
+				}
+				return
+			}
+			if check.Purchasable || !errors.Is(err, repository.ErrPurchaseNotAllowed) {
+				t.Errorf("purchasable = %v, err = %v; want ErrPurchaseNotAllowed", check.Purchasable, err)
+			}
+		})
+	}
+}
+
+func TestTicketTypeRepositoryReserveTicketsBatchTx(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	eventID := seedEvent(t, tx, "Batch")
+	general := testsupport.SeedTicketType(t, tx, eventID, "General", 300, 10)
+	vip := testsupport.SeedTicketType(t, tx, eventID, "VIP", 900, 2)
+
+	if err := repo.ReserveTicketsBatchTx(ctx, tx, map[int64]int{general: 3, vip: 2}); err != nil {
+		t.Fatalf("ReserveTicketsBatchTx: %v", err)
+	}
+	for id, want := range map[int64]int{general: 3, vip: 2} {
+		var reserved int
+		if err := tx.QueryRow(ctx, `SELECT reserved_quantity FROM ticketing.ticket_types WHERE id = $1`, id).Scan(&reserved); err != nil {
+			t.Fatalf("failed to read reserved quantity: %v", err)
+		}
+		if reserved != want {
+			t.Errorf("ticket type %d reserved = %d, want %d", id, reserved, want)
+		}
+	}
+
+	err := repo.ReserveTicketsBatchTx(ctx, tx, map[int64]int{general: 1, vip: 1})
+	if !errors.Is(err, repository.ErrNotEnoughTickets) {
+		t.Errorf("exhausted type: err = %v, want ErrNotEnoughTickets", err)
+	}
+
+	err = repo.ReserveTicketsBatchTx(ctx, tx, map[int64]int{general: 1, -1: 1})
+	if !errors.Is(err, repository.ErrTicketTypeNotFound) {
+		t.Errorf("unknown type: err = %v, want ErrTicketTypeNotFound", err)
+	}
+}
+
+func TestTicketTypeRepositoryReserveTicketsBatchTxConcurrent(t *testing.T) {
+	ctx := context.Background()
+	pool := testsupport.Pool(t)
+	repo := postgres.NewTicketTypeRepository(pool)
+
+	organizerID := testsupport.SeedOrganizer(t, pool, "Organizer Batch Concurrent")
+	eventID := testsupport.SeedEvent(t, pool, organizerID, "Batch Concurrent", time.Now().Add(30*24*time.Hour))
+	ids := []int64{
+		testsupport.SeedTicketType(t, pool, eventID, "A", 100, 1000),
+		testsupport.SeedTicketType(t, pool, eventID, "B", 100, 1000),
+		testsupport.SeedTicketType(t, pool, eventID, "C", 100, 1000),
+	}
+	t.Cleanup(func() {
+		ctx := context.Background()
+		pool.Exec(ctx, `DELETE FROM ticketing.ticket_types WHERE event_id = $1`, eventID)
+		pool.Exec(ctx, `DELETE FROM ticketing.event_status_history WHERE event_id = $1`, eventID)
+		pool.Exec(ctx, `DELETE FROM ticketing.events WHERE id = $1`, eventID)
+		pool.Exec(ctx, `DELETE FROM ticketing.organizers WHERE id = $1`, organizerID)
+	})
+
+	// Cada worker recorre los mismos tipos; sin el orden por id dos lotes
+	// solapados podrían bloquearse mutuamente
+	const workers, rounds = 4, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*rounds)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				tx, err := pool.Begin(ctx)
+				if err != nil {
+					errs <- err
+					return
+				}
+				err = repo.ReserveTicketsBatchTx(ctx, tx, map[int64]int{ids[0]: 1, ids[1]: 1, ids[2]: 1})
+				if err == nil {
+					err = tx.Commit(ctx)
+				} else {
+					tx.Rollback(ctx)
+				}
+				if err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent batch reservation: %v", err)
+	}
+
+	for _, id := range ids {
+		var reserved int
+		if err := pool.QueryRow(ctx, `SELECT reserved_quantity FROM ticketing.ticket_types WHERE id = $1`, id).Scan(&reserved); err != nil {
+			t.Fatalf("failed to read reserved quantity: %v", err)
+		}
+		if reserved != workers*rounds {
+			t.Errorf("ticket type %d reserved = %d, want %d", id, reserved, workers*rounds)
+		}
+	}
+}
+
+func TestTicketTypeRepositoryGetStatsAvailability(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	eventID := seedEvent(t, tx, "Disponibilidad")
+	tests := []struct {
+		name           string
+		sold, reserved int
+		want           int64
+	}{
+		{"sold only", 30, 0, 70},
+		{"sold and reserved", 30, 10, 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeID := testsupport.SeedTicketType(t, tx, eventID, tt.name, 100, 100)
+			if _, err := tx.Exec(ctx, `
+				UPDATE ticketing.ticket_types SET sold_quantity = $1, reserved_quantity = $2 WHERE id = $3
+			`, tt.sold, tt.reserved, typeID); err != nil {
+				t.Fatalf("failed to set quantities: %v", err)
+			}
+
+			stats, err := repo.GetStats(ctx, typeID)
+			if err != nil {
+				t.Fatalf("GetStats: %v", err)
+			}
+			if stats.AvailableTickets != tt.want {
+				t.Errorf("AvailableTickets = %d, want %d", stats.AvailableTickets, tt.want)
+			}
+			if stats.TotalTickets != 100 || stats.SoldTickets != int64(tt.sold) {
+				t.Errorf("totals = %d/%d, want 100/%d", stats.TotalTickets, stats.SoldTickets, tt.sold)
+			}
+		})
+	}
+}
+
+func TestTicketTypeRepositorySellTicketsWithLockNeverOversells(t *testing.T) {
+	ctx := context.Background()
+	pool := testsupport.Pool(t)
+	repo := postgres.NewTicketTypeRepository(pool)
+
+	organizerID := testsupport.SeedOrganizer(t, pool, "Organizer Oversell")
+	eventID := testsupport.SeedEvent(t, pool, organizerID, "Oversell", time.Now().Add(30*24*time.Hour))
+	const capacity = 5
+	typeID := testsupport.SeedTicketType(t, pool, eventID, "General", 100, capacity)
+	t.Cleanup(func() {
+		ctx := context.Background()
+		pool.Exec(ctx, `DELETE FROM ticketing.ticket_types WHERE event_id = $1`, eventID)
+		pool.Exec(ctx, `DELETE FROM ticketing.event_status_history WHERE event_id = $1`, eventID)
+		pool.Exec(ctx, `DELETE FROM ticketing.events WHERE id = $1`, eventID)
+		pool.Exec(ctx, `DELETE FROM ticketing.organizers WHERE id = $1`, organizerID)
+	})
+
+	// Muchos compradores compiten por el último cupo: sólo capacity ventas
+	// pueden confirmarse y el resto debe recibir ErrNotEnoughTickets
+	const buyers = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sold, rejected := 0, 0
+	errs := make(chan error, buyers)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, err := pool.Begin(ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer tx.Rollback(ctx)
+
+			err = repo.SellTicketsWithLock(ctx, tx, typeID, 1)
+			if err == nil {
+				err = tx.Commit(ctx)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				sold++
+			case errors.Is(err, repository.ErrNotEnoughTickets):
+				rejected++
+			default:
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent sale: %v", err)
+	}
+
+	if sold != capacity || rejected != buyers-capacity {
+		t.Errorf("sold/rejected = %d/%d, want %d/%d", sold, rejected, capacity, buyers-capacity)
+	}
+	var soldQuantity int
+	if err := pool.QueryRow(ctx, `SELECT sold_quantity FROM ticketing.ticket_types WHERE id = $1`, typeID).Scan(&soldQuantity); err != nil {
+		t.Fatalf("failed to read sold quantity: %v", err)
+	}
+	if soldQuantity != capacity {
+		t.Errorf("sold_quantity = %d, want %d", soldQuantity, capacity)
+	}
+}
+
+// beginCountingDB cuenta las transacciones que abre el repositorio
+type beginCountingDB struct {
+	postgres.DBTX
+	begins int
+}
+
+func (db *beginCountingDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	db.begins++
+	return db.DBTX.Begin(ctx)
+}
+
+func TestTicketTypeRepositoryReleaseExpiredReservationsInBatches(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+
+	// Sólo las reservas de este test deben estar vencidas
+	if _, err := tx.Exec(ctx, `TRUNCATE ticketing.tickets CASCADE`); err != nil {
+		t.Fatalf("failed to clear tickets: %v", err)
+	}
+	eventID := seedEvent(t, tx, "Barrido")
+	typeA := testsupport.SeedTicketType(t, tx, eventID, "A", 100, 100)
+	typeB := testsupport.SeedTicketType(t, tx, eventID, "B", 100, 100)
+	const backlog = 25
+	for i := 0; i < backlog; i++ {
+		typeID := typeA
+		if i%2 == 1 {
+			typeID = typeB
+		}
+		testsupport.SeedTicket(t, tx, eventID, typeID, nil, "reserved", 100)
+	}
+	// Una reserva vigente no se toca
+	testsupport.SeedTicket(t, tx, eventID, typeA, nil, "reserved", 100)
+	if _, err := tx.Exec(ctx, `
+		UPDATE ticketing.tickets
+		SET reservation_expires_at = CASE WHEN id = (SELECT MAX(id) FROM ticketing.tickets) THEN NOW() + INTERVAL '1 hour' ELSE NOW() - INTERVAL '1 hour' END
+		WHERE event_id = $1
+	`, eventID); err != nil {
+		t.Fatalf("failed to set expirations: %v", err)
+	}
+	setTypeQuantities(t, tx, typeA, 0, 14)
+	setTypeQuantities(t, tx, typeB, 0, 12)
+
+	db := &beginCountingDB{DBTX: tx}
+	repo := postgres.NewTicketTypeRepository(db)
+	repo.SetSweepBatchSize(10)
+
+	released, err := repo.ReleaseExpiredReservations(ctx)
+	if err != nil {
+		t.Fatalf("ReleaseExpiredReservations: %v", err)
+	}
+	if released != backlog {
+		t.Errorf("released = %d, want %d", released, backlog)
+	}
+	// 10 + 10 + 5: el lote corto indica que no queda backlog
+	if db.begins != 3 {
+		t.Errorf("batches = %d, want 3 of at most 10 rows", db.begins)
+	}
+
+	var stillReserved int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM ticketing.tickets WHERE event_id = $1 AND status = 'reserved'`, eventID).Scan(&stillReserved); err != nil {
+		t.Fatalf("failed to count reservations: %v", err)
+	}
+	if stillReserved != 1 {
+		t.Errorf("reserved tickets left = %d, want only the unexpired one", stillReserved)
+	}
+	if _, reserved, _ := typeQuantities(t, tx, typeA); reserved != 1 {
+		t.Errorf("type A reserved_quantity = %d, want 1", reserved)
+	}
+	if _, reserved, _ := typeQuantities(t, tx, typeB); reserved != 0 {
+		t.Errorf("type B reserved_quantity = %d, want 0", reserved)
+	}
+
+	// Sin backlog basta un lote vacío
+	db.begins = 0
+	if released, err := repo.ReleaseExpiredReservations(ctx); err != nil || released != 0 || db.begins != 1 {
+		t.Errorf("empty sweep = %d released in %d batches (err %v), want 0 in 1", released, db.begins, err)
+	}
+}
+
+func TestTicketTypeRepositoryReleaseExpiredReservationsExpiresReservationRows(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	eventID := seedEvent(t, tx, "Barrido fantasmas")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 100, 10)
+	userID := seedUser(t, tx, "sweep-"+uuid.NewString()+"@example.com")
+
+	// Inventario pregenerado reservado por un usuario
+	pregenerated := testsupport.SeedTicket(t, tx, eventID, typeID, nil, "reserved", 100)
+	// Fila creada por la reserva por tipo, sin reserved_by
+	reservationRow := testsupport.SeedTicket(t, tx, eventID, typeID, nil, "reserved", 100)
+	if _, err := tx.Exec(ctx, `
+		UPDATE ticketing.tickets
+		SET reserved_at = NOW() - INTERVAL '2 hours',
+			reservation_expires_at = NOW() - INTERVAL '1 hour',
+			reserved_by = CASE WHEN id = $1 THEN $2::bigint END
+		WHERE id IN ($1, $3)
+	`, pregenerated, userID, reservationRow); err != nil {
+		t.Fatalf("failed to expire reservations: %v", err)
+	}
+	setTypeQuantities(t, tx, typeID, 0, 2)
+
+	if _, err := repo.ReleaseExpiredReservations(ctx); err != nil {
+		t.Fatalf("ReleaseExpiredReservations: %v", err)
+	}
+
+	var status string
+	var reservedBy *int64
+	var expiresAt *time.Time
+	if err := tx.QueryRow(ctx, `SELECT status, reserved_by, reservation_expires_at FROM ticketing.tickets WHERE id = $1`, pregenerated).Scan(&status, &reservedBy, &expiresAt); err != nil {
+		t.Fatalf("failed to read pregenerated ticket: %v", err)
+	}
+	if status != "available" || reservedBy != nil || expiresAt != nil {
+		t.Errorf("pregenerated ticket = %q, reserved_by %v, expires %v; want a clean available ticket", status, reservedBy, expiresAt)
+	}
+	if status := ticketStatus(t, tx, reservationRow); status != "expired" {
+		t.Errorf("reservation row status = %q, want expired", status)
+	}
+	if sold, reserved, available := typeQuantities(t, tx, typeID); sold != 0 || reserved != 0 || available != 10 {
+		t.Errorf("quantities = sold %d, reserved %d, available %d; want 0/0/10", sold, reserved, available)
+	}
+
+	// Las filas vencidas no vuelven a contarse como disponibles en el siguiente barrido
+	if released, err := repo.ReleaseExpiredReservations(ctx); err != nil || released != 0 {
+		t.Errorf("second sweep released %d (err %v), want 0", released, err)
+	}
+}
+
+func soldOutHistory(t *testing.T, db testsupport.DB, eventID int64) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM ticketing.event_status_history
+		WHERE event_id = $1 AND from_status = 'published' AND to_status = 'sold_out'
+	`, eventID).Scan(&n); err != nil {
+		t.Fatalf("failed to count sold out history: %v", err)
+	}
+	return n
+}
+
+func TestTicketTypeRepositorySellTicketsWithLockMarksEventSoldOut(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	eventID := seedEvent(t, tx, "Sell Out")
+	general := testsupport.SeedTicketType(t, tx, eventID, "General", 300, 2)
+	vip := testsupport.SeedTicketType(t, tx, eventID, "VIP", 900, 1)
+	// Un tipo inactivo con cupo no impide que el evento se agote
+	retired := testsupport.SeedTicketType(t, tx, eventID, "Preventa", 200, 10)
+	if _, err := tx.Exec(ctx, `UPDATE ticketing.ticket_types SET is_active = false WHERE id = $1`, retired); err != nil {
+		t.Fatalf("failed to deactivate ticket type: %v", err)
+	}
+
+	if err := repo.SellTicketsWithLock(ctx, tx, general, 2); err != nil {
+		t.Fatalf("SellTicketsWithLock(general): %v", err)
+	}
+	if got := eventStatus(t, tx, eventID); got != "published" {
+		t.Errorf("status with VIP left = %s, want published", got)
+	}
+
+	if err := repo.SellTicketsWithLock(ctx, tx, vip, 1); err != nil {
+		t.Fatalf("SellTicketsWithLock(vip): %v", err)
+	}
+	if got := eventStatus(t, tx, eventID); got != "sold_out" {
+		t.Errorf("status after last sale = %s, want sold_out", got)
+	}
+	if got := soldOutHistory(t, tx, eventID); got != 1 {
+		t.Errorf("sold out history rows = %d, want 1", got)
+	}
+}
+
+func TestTicketTypeRepositoryConfirmReservationTxMarksEventSoldOut(t *testing.T) {
+	ctx := context.Background()
+	tx := testsupport.Tx(t)
+	repo := postgres.NewTicketTypeRepository(tx)
+
+	eventID := seedEvent(t, tx, "Sell Out Reserved")
+	typeID := testsupport.SeedTicketType(t, tx, eventID, "General", 300, 2)
+	setTypeQuantities(t, tx, typeID, 0, 2)
+
+	// Con una entrada aún reservada el evento sigue a la venta
+	if err := repo.ConfirmReservationTx(ctx, tx, typeID, 1); err != nil {
+		t.Fatalf("ConfirmReservationTx: %v", err)
+	}
+	if got := eventStatus(t, tx, eventID); got != "published" {
+		t.Errorf("status with a reservation left = %s, want published", got)
+	}
+
+	if err := repo.ConfirmReservationTx(ctx, tx, typeID, 1); err != nil {
+		t.Fatalf("ConfirmReservationTx: %v", err)
+	}
+	if got := eventStatus(t, tx, eventID); got != "sold_out" {
+		t.Errorf("status after last confirmation = %s, want sold_out", got)
+	}
+	if got := soldOutHistory(t, tx, eventID); got != 1 {
+		t.Errorf("sold out history rows = %d, want 1", got)
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
 	"github.com/franciscozamorau/osmi-server/internal/domain/enums"
@@ -19,11 +18,11 @@ import (
 
 // UserRepository implementa la interfaz repository.UserRepository usando PostgreSQL
 type UserRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 // NewUserRepository crea una nueva instancia del repositorio
-func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+func NewUserRepository(db DBTX) *UserRepository {
 	return &UserRepository{
 		db: db,
 	}
@@ -11,7 +11,6 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	commondto "github.com/franciscozamorau/osmi-server/internal/api/dto/common"
 	venuedto "github.com/franciscozamorau/osmi-server/internal/api/dto/venue"
@@ -21,13 +20,23 @@ import (
 
 // VenueRepository implementa la interfaz repository.VenueRepository
 type VenueRepository struct {
-	db *pgxpool.Pool
+	db      DBTX
+	maxPage int
 }
 
 // NewVenueRepository crea una nueva instancia
-func NewVenueRepository(db *pgxpool.Pool) *VenueRepository {
+func NewVenueRepository(db DBTX) *VenueRepository {
 	return &VenueRepository{
-		db: db,
+		db:      db,
+		maxPage: commondto.DefaultMaxPage,
+	}
+}
+
+// SetMaxPage fija la página más profunda que admiten los listados paginados por
+// offset. Valores no positivos se ignoran.
+func (r *VenueRepository) SetMaxPage(page int) {
+	if page > 0 {
+		r.maxPage = page
 	}
 }
 
@@ -410,6 +419,11 @@ func (r *VenueRepository) Exists(ctx context.Context, id int64) (bool, error) {
 
 // List lista venues con filtros
 func (r *VenueRepository) List(ctx context.Context, filter venuedto.VenueFilter, pagination commondto.Pagination) ([]*entities.Venue, int64, error) {
+	pagination, err := pagination.Normalize(r.maxPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	where := []string{"1=1"}
 	args := pgx.NamedArgs{}
 	argPos := 1
@@ -469,7 +483,7 @@ func (r *VenueRepository) List(ctx context.Context, filter venuedto.VenueFilter,
 	// Contar total
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM ticketing.venues WHERE %s", whereClause)
 	var total int64
-	err := r.db.QueryRow(ctx, countQuery, args).Scan(&total)
+	err = r.db.QueryRow(ctx, countQuery, args).Scan(&total)
 	if err != nil {
 		return nil, 0, r.handleError(err, "failed to count venues")
 	}
@@ -939,7 +953,7 @@ func (r *VenueRepository) GetCapacityUtilization(ctx context.Context, venueID in
 		SELECT 
 			COALESCE(
 				(SELECT SUM(max_attendees) FROM ticketing.events WHERE venue_id = $1 AND start_date > NOW()) * 1.0 /
-				(SELECT capacity FROM ticketing.venues WHERE id = $1),
+				NULLIF((SELECT capacity FROM ticketing.venues WHERE id = $1), 0),
 			0)
 	`
 	err := r.db.QueryRow(ctx, query, venueID).Scan(&utilization)
@@ -0,0 +1,106 @@
+// Package qrcode genera el QR de cada ticket a partir de un payload firmado y
+// lo publica en un Storage (disco local o S3).
+package qrcode
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	goqrcode "github.com/skip2/go-qrcode"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+)
+
+// payloadVersion prefija el payload para poder cambiar el formato sin romper los ya impresos
+const payloadVersion = "OSMI1"
+
+// DefaultSize es el lado en píxeles del PNG cuando no se configura otro
+const DefaultSize = 256
+
+// ErrInvalidPayload indica un payload mal formado o con firma inválida
+var ErrInvalidPayload = errors.New("invalid qr payload")
+
+// Payload son los datos del ticket codificados en el QR
+type Payload struct {
+	PublicID string
+	Code     string
+	EventID  int64
+}
+
+// Generator firma el payload de un ticket, lo renderiza como PNG y lo guarda
+type Generator struct {
+	secret  []byte
+	storage Storage
+	size    int
+}
+
+// NewGenerator crea un generador; secret firma los payloads con HMAC-SHA256
+func NewGenerator(secret string, storage Storage, size int) (*Generator, error) {
+	if secret == "" {
+		return nil, errors.New("qr secret is required")
+	}
+	if storage == nil {
+		return nil, errors.New("qr storage is required")
+	}
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Generator{secret: []byte(secret), storage: storage, size: size}, nil
+}
+
+// Generate renderiza el QR del ticket, lo guarda y devuelve su URL
+func (g *Generator) Generate(ctx context.Context, ticket *entities.Ticket) (string, error) {
+	payload := g.Sign(Payload{
+		PublicID: ticket.PublicID,
+		Code:     ticket.Code,
+		EventID:  ticket.EventID,
+	})
+
+	png, err := goqrcode.Encode(payload, goqrcode.Medium, g.size)
+	if err != nil {
+		return "", fmt.Errorf("failed to render qr code: %w", err)
+	}
+
+	url, err := g.storage.Put(ctx, "tickets/"+ticket.PublicID+".png", png, "image/png")
+	if err != nil {
+		return "", fmt.Errorf("failed to store qr code: %w", err)
+	}
+	return url, nil
+}
+
+// Sign arma el texto del QR: versión, campos y firma separados por '.'
+func (g *Generator) Sign(p Payload) string {
+	body := strings.Join([]string{payloadVersion, p.PublicID, p.Code, strconv.FormatInt(p.EventID, 10)}, ".")
+	return body + "." + g.signature(body)
+}
+
+// Verify valida la firma de un payload leído de un QR y devuelve sus campos
+func (g *Generator) Verify(payload string) (Payload, error) {
+	parts := strings.Split(payload, ".")
+	if len(parts) != 5 || parts[0] != payloadVersion {
+		return Payload{}, ErrInvalidPayload
+	}
+
+	body := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(parts[4]), []byte(g.signature(body))) {
+		return Payload{}, ErrInvalidPayload
+	}
+
+	eventID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return Payload{}, ErrInvalidPayload
+	}
+	return Payload{PublicID: parts[1], Code: parts[2], EventID: eventID}, nil
+}
+
+func (g *Generator) signature(body string) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
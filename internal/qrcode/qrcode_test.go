@@ -0,0 +1,89 @@
+package qrcode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/franciscozamorau/osmi-server/internal/domain/entities"
+)
+
+func newTestGenerator(t *testing.T, secret string) *Generator {
+	t.Helper()
+	g, err := NewGenerator(secret, NewLocalStorage(t.TempDir(), "http://localhost/qr"), 0)
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	return g
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	g := newTestGenerator(t, "secret")
+	want := Payload{PublicID: "3f1c2d9e-0000-4000-8000-000000000001", Code: "TKT-1-2-abcd1234", EventID: 1}
+
+	got, err := g.Verify(g.Sign(want))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got != want {
+		t.Errorf("Verify = %+v, want %+v", got, want)
+	}
+}
+
+func TestVerifyRejectsTamperedPayload(t *testing.T) {
+	g := newTestGenerator(t, "secret")
+	signed := g.Sign(Payload{PublicID: "ticket-a", Code: "TKT-1-2-abcd1234", EventID: 1})
+
+	tests := map[string]string{
+		"other ticket":    strings.Replace(signed, "ticket-a", "ticket-b", 1),
+		"other secret":    newTestGenerator(t, "other").Sign(Payload{PublicID: "ticket-a", Code: "TKT-1-2-abcd1234", EventID: 1}),
+		"unsigned":        "OSMI1.ticket-a.TKT-1-2-abcd1234.1",
+		"wrong version":   strings.Replace(signed, "OSMI1", "OSMI2", 1),
+		"plain ticket id": "ticket-a",
+	}
+	for name, payload := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := g.Verify(payload); !errors.Is(err, ErrInvalidPayload) {
+				t.Errorf("Verify error = %v, want ErrInvalidPayload", err)
+			}
+		})
+	}
+}
+
+func TestGenerateStoresSignedPNG(t *testing.T) {
+	dir := t.TempDir()
+	g, err := NewGenerator("secret", NewLocalStorage(dir, "http://localhost/qr/"), 0)
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+
+	ticket := &entities.Ticket{PublicID: "ticket-a", Code: "TKT-1-2-abcd1234", EventID: 1}
+	url, err := g.Generate(context.Background(), ticket)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if url != "http://localhost/qr/tickets/ticket-a.png" {
+		t.Errorf("url = %q", url)
+	}
+
+	png, err := os.ReadFile(filepath.Join(dir, "tickets", "ticket-a.png"))
+	if err != nil {
+		t.Fatalf("stored file: %v", err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Error("stored file is not a PNG")
+	}
+}
+
+func TestNewGeneratorRequiresSecretAndStorage(t *testing.T) {
+	if _, err := NewGenerator("", NewLocalStorage(t.TempDir(), ""), 0); err == nil {
+		t.Error("generator without secret was created")
+	}
+	if _, err := NewGenerator("secret", nil, 0); err == nil {
+		t.Error("generator without storage was created")
+	}
+}
@@ -0,0 +1,80 @@
+package qrcode
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestS3StoragePutPathStyle(t *testing.T) {
+	var gotPath, gotAuth, gotHash, gotType string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("method = %s, want PUT", r.Method)
+		}
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		gotHash = r.Header.Get("X-Amz-Content-Sha256")
+		gotType = r.Header.Get("Content-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	s := &S3Storage{
+		Bucket:    "tickets-qr",
+		Region:    "us-east-1",
+		Endpoint:  server.URL + "/",
+		AccessKey: "AKID",
+		SecretKey: "secret",
+		Client:    server.Client(),
+	}
+	url, err := s.Put(context.Background(), "tickets/a b.png", []byte("png"), "image/png")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if gotPath != "/tickets-qr/tickets/a%20b.png" {
+		t.Errorf("path = %q, want bucket-prefixed escaped key", gotPath)
+	}
+	if url != server.URL+"/tickets-qr/tickets/a%20b.png" {
+		t.Errorf("url = %q", url)
+	}
+	if string(gotBody) != "png" || gotType != "image/png" || gotHash != sha256Hex([]byte("png")) {
+		t.Errorf("body %q, content type %q, hash %q", gotBody, gotType, gotHash)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		!strings.Contains(gotAuth, "/us-east-1/s3/aws4_request") ||
+		!strings.Contains(gotAuth, "SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date") {
+		t.Errorf("authorization = %q", gotAuth)
+	}
+
+	// Con PublicBaseURL la URL devuelta es la del CDN
+	s.PublicBaseURL = "https://cdn.example.com/"
+	if url, err := s.Put(context.Background(), "tickets/a.png", []byte("png"), "image/png"); err != nil || url != "https://cdn.example.com/tickets/a.png" {
+		t.Errorf("Put with public url = %q, %v", url, err)
+	}
+}
+
+func TestS3StoragePutReportsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	s := &S3Storage{Bucket: "b", Region: "us-east-1", Endpoint: server.URL, Client: server.Client()}
+	_, err := s.Put(context.Background(), "tickets/a.png", []byte("png"), "image/png")
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("err = %v, want status and body", err)
+	}
+}
+
+func TestS3StorageObjectURLVirtualHosted(t *testing.T) {
+	s := &S3Storage{Bucket: "tickets-qr", Region: "us-west-2"}
+	if got := s.objectURL("tickets/a b.png"); got != "https://tickets-qr.s3.us-west-2.amazonaws.com/tickets/a%20b.png" {
+		t.Errorf("objectURL = %q", got)
+	}
+}
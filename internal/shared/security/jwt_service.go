@@ -52,7 +52,9 @@ func (s *JWTService) GenerateRefreshToken(userID string) (string, error) {
 }
 
 func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
-	log.Printf("🔍 Validando token: %s", tokenString[:20]+"...")
+	if len(tokenString) > 20 {
+		log.Printf("🔍 Validando token: %s", tokenString[:20]+"...")
+	}
 
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		log.Printf("🔍 Método de firma: %v", token.Method)
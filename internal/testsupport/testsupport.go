@@ -0,0 +1,297 @@
+// internal/testsupport/testsupport.go
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/franciscozamorau/osmi-server/internal/database"
+)
+
+// Variables de entorno que controlan el harness
+const (
+	// DatabaseURLEnv apunta a una base de datos desechable; si no está, los tests se omiten
+	DatabaseURLEnv = "TEST_DATABASE_URL"
+	// MigrationsDirEnv es el directorio con los .sql base del repo osmi-db (opcional)
+	MigrationsDirEnv = "TEST_MIGRATIONS_DIR"
+)
+
+// DB es lo que usan los helpers de seed: un pgx.Tx o un *pgxpool.Pool
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var (
+	poolOnce sync.Once
+	pool     *pgxpool.Pool
+	poolErr  error
+)
+
+// Pool devuelve el pool compartido contra TEST_DATABASE_URL, con el esquema base
+// de TEST_MIGRATIONS_DIR y las migraciones embebidas aplicadas una vez por
+// proceso. Lo escrito con el pool queda confirmado: sólo los tests que
+// necesitan varias conexiones (concurrencia) deberían usarlo, y limpiar sus
+// filas con t.Cleanup. El resto usa Tx.
+func Pool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv(DatabaseURLEnv)
+	if url == "" {
+		t.Skipf("%s not set, skipping integration test", DatabaseURLEnv)
+	}
+
+	poolOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		pool, poolErr = openPool(ctx, url)
+	})
+	if poolErr != nil {
+		t.Fatalf("failed to prepare test database: %v", poolErr)
+	}
+	return pool
+}
+
+// Tx abre una transacción que se revierte al terminar el test. Los
+// repositorios aceptan un pgx.Tx en lugar del pool; sus propios Begin se
+// vuelven savepoints, así que nada de lo que haga el test llega a confirmarse.
+// NOW() es constante dentro de la transacción: los datos que dependan del
+// orden temporal deben sembrarse con fechas explícitas.
+func Tx(t testing.TB) pgx.Tx {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := Pool(t).Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin test transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := tx.Rollback(context.Background()); err != nil {
+			t.Logf("warning: could not roll back test transaction: %v", err)
+		}
+	})
+	return tx
+}
+
+func openPool(ctx context.Context, url string) (*pgxpool.Pool, error) {
+	p, err := pgxpool.New(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	if err := p.Ping(ctx); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to ping: %w", err)
+	}
+	if err := applyBaseSchema(ctx, p, os.Getenv(MigrationsDirEnv)); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if err := database.MigratePool(ctx, p); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// applyBaseSchema ejecuta los .sql del directorio en orden lexicográfico.
+// Sin directorio se asume que el esquema base ya existe en la base de pruebas.
+func applyBaseSchema(ctx context.Context, p *pgxpool.Pool, dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if _, err := p.Exec(ctx, string(sql)); err != nil {
+			return fmt.Errorf("failed to apply %s: %w", filepath.Base(file), err)
+		}
+	}
+
+	return nil
+}
+
+// PublicID devuelve el public_uuid de la fila id de table
+func PublicID(t testing.TB, db DB, table string, id int64) string {
+	t.Helper()
+
+	var publicID string
+	err := db.QueryRow(context.Background(), "SELECT public_uuid::text FROM "+table+" WHERE id = $1", id).Scan(&publicID)
+	if err != nil {
+		t.Fatalf("failed to get public id from %s: %v", table, err)
+	}
+	return publicID
+}
+
+// SeedCustomer inserta un cliente activo y devuelve su id
+func SeedCustomer(t testing.TB, db DB, fullName, email string) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO crm.customers (public_uuid, full_name, email, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, true, NOW(), NOW())
+		RETURNING id
+	`, uuid.NewString(), fullName, email).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed customer: %v", err)
+	}
+	return id
+}
+
+// SeedOrganizer inserta un organizador activo y devuelve su id
+func SeedOrganizer(t testing.TB, db DB, name string) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO ticketing.organizers (public_uuid, name, slug, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, true, NOW(), NOW())
+		RETURNING id
+	`, uuid.NewString(), name, "org-"+uuid.NewString()[:8]).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed organizer: %v", err)
+	}
+	return id
+}
+
+// SeedVenue inserta un recinto activo y devuelve su id
+func SeedVenue(t testing.TB, db DB, name string) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO ticketing.venues (public_uuid, name, slug, address_line1, city, country, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, 'Calle 1', 'CDMX', 'MX', true, NOW(), NOW())
+		RETURNING id
+	`, uuid.NewString(), name, "venue-"+uuid.NewString()[:8]).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed venue: %v", err)
+	}
+	return id
+}
+
+// SeedCategory inserta una categoría activa y devuelve su id
+func SeedCategory(t testing.TB, db DB, name string) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO ticketing.categories (public_uuid, name, slug, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, true, NOW(), NOW())
+		RETURNING id
+	`, uuid.NewString(), name, "cat-"+uuid.NewString()[:8]).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed category: %v", err)
+	}
+	return id
+}
+
+// SeedEvent inserta un evento publicado que empieza en startsAt y devuelve su id
+func SeedEvent(t testing.TB, db DB, organizerID int64, name string, startsAt time.Time) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO ticketing.events (
+			public_uuid, organizer_id, slug, name, event_type, timezone,
+			starts_at, ends_at, status, visibility, published_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, 'in_person', 'UTC', $5, $6, 'published', 'public', NOW(), NOW(), NOW())
+		RETURNING id
+	`, uuid.NewString(), organizerID, "event-"+uuid.NewString()[:8], name, startsAt, startsAt.Add(3*time.Hour)).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed event: %v", err)
+	}
+	return id
+}
+
+// SetEventStatus cambia el estado de un evento sin pasar por el repositorio
+func SetEventStatus(t testing.TB, db DB, eventID int64, status string) {
+	t.Helper()
+
+	if _, err := db.Exec(context.Background(), `UPDATE ticketing.events SET status = $1 WHERE id = $2`, status, eventID); err != nil {
+		t.Fatalf("failed to set event status: %v", err)
+	}
+}
+
+// SeedTicketType inserta un tipo de ticket activo con total boletos a price y devuelve su id
+func SeedTicketType(t testing.TB, db DB, eventID int64, name string, price float64, total int) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO ticketing.ticket_types (
+			public_uuid, event_id, name, ticket_class, base_price, currency, tax_rate,
+			total_quantity, reserved_quantity, sold_quantity, available_quantity,
+			max_per_order, min_per_order, sale_starts_at, is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, 'general', $4, 'MXN', 0, $5, 0, 0, $5, 10, 1, NOW() - INTERVAL '1 day', true, NOW(), NOW())
+		RETURNING id
+	`, uuid.NewString(), eventID, name, price, total).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed ticket type: %v", err)
+	}
+	return id
+}
+
+// SeedTicket inserta un ticket del tipo ticketTypeID en el estado dado y devuelve su id.
+// customerID puede ser nil para tickets sin dueño.
+func SeedTicket(t testing.TB, db DB, eventID, ticketTypeID int64, customerID *int64, status string, price float64) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO ticketing.tickets (
+			public_uuid, ticket_type_id, event_id, customer_id, code, secret_hash,
+			status, final_price, currency, tax_amount,
+			sold_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6,
+			$7, $8, 'MXN', 0,
+			CASE WHEN $7::text IN ('sold', 'transferred', 'checked_in') THEN NOW() END, NOW(), NOW()
+		)
+		RETURNING id
+	`, uuid.NewString(), ticketTypeID, eventID, customerID, "TEST-"+uuid.NewString()[:12], uuid.NewString(),
+		status, price).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed ticket: %v", err)
+	}
+	return id
+}
+
+// SeedOrder inserta una orden del cliente con el total y estado dados y devuelve su id
+func SeedOrder(t testing.TB, db DB, customerID int64, email string, total float64, status string) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(), `
+		INSERT INTO billing.orders (
+			public_uuid, customer_id, customer_email,
+			subtotal, tax_amount, service_fee_amount, discount_amount, total_amount, currency,
+			status, order_type, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, 0, 0, 0, $4, 'MXN', $5, 'ticket', NOW(), NOW())
+		RETURNING id
+	`, uuid.NewString(), customerID, email, total, status).Scan(&id)
+	if err != nil {
+		t.Fatalf("failed to seed order: %v", err)
+	}
+	return id
+}